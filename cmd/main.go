@@ -1,52 +1,147 @@
-package main
-
-import (
-	"context"
-	"log"
-	"net/http"
-
-	"github.com/simpler-tha/internal/app"
-	"github.com/simpler-tha/internal/config"
-	infrahttp "github.com/simpler-tha/internal/infra/http"
-	"github.com/simpler-tha/internal/infra/postgresql"
-)
-
-func main() {
-	ctx := context.Background()
-
-	cfg, err := config.LoadConfig()
-	if err != nil {
-		log.Fatal("failed to load config", err)
-	}
-
-	client, err := postgresql.NewClient(ctx, cfg.Postgres)
-	if err != nil {
-		log.Fatalf("failed to initialize postgresql client: %v", err)
-	}
-	defer func() {
-		if err := client.Conn.Close(ctx); err != nil {
-			log.Printf("failed to close postgresql connection: %v", err)
-		}
-	}()
-
-	productsRepository, err := postgresql.NewRepository(client)
-	if err != nil {
-		log.Fatalf("failed to initialize products repository: %v", err)
-	}
-
-	service, err := app.NewService(productsRepository)
-	if err != nil {
-		log.Fatalf("failed to initialize service: %v", err)
-	}
-
-	router, err := infrahttp.NewRouter(service)
-	if err != nil {
-		log.Fatalf("failed to initialize HTTP router: %v", err)
-	}
-	router.RegisterRoutes()
-
-	err = http.ListenAndServe(":8080", nil)
-	if err != nil {
-		log.Fatalf("server error: %s", err)
-	}
-}
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/simpler-tha/internal/app"
+	"github.com/simpler-tha/internal/config"
+	infrahttp "github.com/simpler-tha/internal/infra/http"
+	"github.com/simpler-tha/internal/infra/postgresql"
+	"github.com/simpler-tha/internal/infra/publisher"
+	"github.com/simpler-tha/internal/worker"
+)
+
+// shutdownTimeout bounds the time given to in-flight requests on shutdown.
+const shutdownTimeout = 10 * time.Second
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatal("failed to load config", err)
+	}
+
+	client, err := postgresql.NewClient(ctx, cfg.Postgres)
+	if err != nil {
+		log.Fatalf("failed to initialize postgresql client: %v", err)
+	}
+	defer client.Close()
+
+	productsRepository, err := postgresql.NewRepository(client, cfg.Postgres.SearchLanguage)
+	if err != nil {
+		log.Fatalf("failed to initialize products repository: %v", err)
+	}
+
+	service, err := app.NewService(productsRepository)
+	if err != nil {
+		log.Fatalf("failed to initialize service: %v", err)
+	}
+
+	eventPublisher, err := newPublisher(cfg.Events)
+	if err != nil {
+		log.Fatalf("failed to initialize event publisher: %v", err)
+	}
+
+	router, err := infrahttp.NewRouter(service, cfg.HTTP)
+	if err != nil {
+		log.Fatalf("failed to initialize HTTP router: %v", err)
+	}
+	mux := http.NewServeMux()
+	router.RegisterRoutes(mux)
+
+	proxies, err := infrahttp.ParseTrustedProxies(cfg.HTTP.TrustedProxies)
+	if err != nil {
+		log.Fatalf("failed to parse trusted proxies: %v", err)
+	}
+
+	// The changes made by the workers are audited as made by the system.
+	workerCtx := app.WithActor(ctx, app.SystemActor)
+
+	var workers sync.WaitGroup
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		worker.Run(workerCtx, "reservation expiry", cfg.Workers.ReservationExpiryInterval, func(ctx context.Context) error {
+			n, err := service.ExpireReservations(ctx)
+			if n > 0 {
+				log.Printf("expired %d stock reservations", n)
+			}
+			return err
+		})
+	}()
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		worker.Run(workerCtx, "product purge", cfg.Workers.ProductPurgeInterval, func(ctx context.Context) error {
+			n, err := service.PurgeProducts(ctx, cfg.Workers.DeletedProductRetention)
+			if n > 0 {
+				log.Printf("purged %d deleted products", n)
+			}
+			return err
+		})
+	}()
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		worker.Run(workerCtx, "outbox relay", cfg.Workers.OutboxRelayInterval, func(ctx context.Context) error {
+			_, err := service.RelayEvents(ctx, eventPublisher)
+			return err
+		})
+	}()
+
+	servers := []*http.Server{
+		{Addr: ":8080", Handler: infrahttp.WithActor(proxies, infrahttp.WithRequestInfo(proxies, mux))},
+	}
+
+	// The pool statistics and the product lists including the deleted
+	// products are only served to operators, on their own listener.
+	if cfg.HTTP.AdminAddr != "" {
+		adminMux := http.NewServeMux()
+		infrahttp.RegisterStatsRoutes(adminMux, func() any { return client.Stats() })
+		router.RegisterAdminRoutes(adminMux)
+		servers = append(servers, &http.Server{Addr: cfg.HTTP.AdminAddr, Handler: adminMux})
+	}
+
+	for _, server := range servers {
+		go func() {
+			err := server.ListenAndServe()
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatalf("server error: %s", err)
+			}
+		}()
+	}
+
+	<-ctx.Done()
+	log.Print("shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	for _, server := range servers {
+		err := server.Shutdown(shutdownCtx)
+		if err != nil {
+			log.Printf("failed to shut down the server on %s gracefully: %v", server.Addr, err)
+		}
+	}
+	workers.Wait()
+}
+
+// newPublisher returns the publisher of the product events, the webhook when
+// one is configured.
+func newPublisher(cfg config.Events) (app.EventPublisher, error) {
+	if cfg.WebhookURL != "" {
+		return publisher.NewWebhook(cfg.WebhookURL)
+	}
+
+	return publisher.NewLog(log.New(os.Stdout, "event ", log.LstdFlags))
+}
@@ -0,0 +1,50 @@
+package app
+
+import "context"
+
+const (
+	// AnonymousActor is the actor of the changes made without an identified
+	// caller.
+	AnonymousActor = "anonymous"
+	// SystemActor is the actor of the changes made by the background jobs.
+	SystemActor = "system"
+)
+
+type (
+	actorKey   struct{}
+	requestKey struct{}
+)
+
+// WithActor returns a copy of ctx carrying the identity of the caller on whose
+// behalf the service changes the catalog.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor carried by ctx, or AnonymousActor.
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	if actor == "" {
+		return AnonymousActor
+	}
+
+	return actor
+}
+
+// RequestInfo identifies the request a change of the catalog was made for.
+type RequestInfo struct {
+	ID       string
+	SourceIP string
+}
+
+// WithRequest returns a copy of ctx carrying the request info.
+func WithRequest(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestKey{}, info)
+}
+
+// RequestFromContext returns the request info carried by ctx. It is empty for
+// the changes that no request asked for.
+func RequestFromContext(ctx context.Context) RequestInfo {
+	info, _ := ctx.Value(requestKey{}).(RequestInfo)
+	return info
+}
@@ -0,0 +1,449 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Product attribute limits.
+const (
+	ProductAttributesMax = 50
+	// ProductAttributesMaxSize is the maximum size of the encoded attributes.
+	ProductAttributesMaxSize = 16 << 10
+)
+
+// attributeKeyPattern restricts attribute keys to identifiers so that they
+// can be used in query parameter names.
+var attributeKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+// IsAttributeKey reports whether key is a valid attribute name.
+func IsAttributeKey(key string) bool {
+	return attributeKeyPattern.MatchString(key)
+}
+
+// DecodeJSONObject decodes a JSON object keeping numbers as json.Number. It
+// is how adapters read product attributes and attribute schemas.
+func DecodeJSONObject(b []byte) (map[string]any, error) {
+	v, err := decodeJSON(b)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return nil, errors.New("not a JSON object")
+	}
+
+	return obj, nil
+}
+
+// validateAttributes checks the shape of the attributes, regardless of the
+// schemas of the categories of the product.
+func validateAttributes(v *violations, attributes map[string]any) {
+	if len(attributes) > ProductAttributesMax {
+		v.add("attributes", "must not contain more than %d attributes", ProductAttributesMax)
+		return
+	}
+
+	for _, k := range sortedKeys(attributes) {
+		if !IsAttributeKey(k) {
+			v.add("attributes."+k, "must be named with lower case letters, digits and underscores, starting with a letter")
+		}
+	}
+
+	b, err := json.Marshal(attributes)
+	if err == nil && len(b) > ProductAttributesMaxSize {
+		v.add("attributes", "must not be larger than %d bytes once encoded", ProductAttributesMaxSize)
+	}
+}
+
+// attributeSchema is a compiled JSON Schema restricted to the keywords that
+// describe product attributes: type, enum, const, properties, required,
+// additionalProperties, items, minItems, maxItems, minimum, maximum,
+// minLength, maxLength and pattern. Annotations such as title are ignored.
+// Other keywords are rejected rather than silently not enforced.
+type attributeSchema struct {
+	types      []string
+	enum       []any
+	properties map[string]*attributeSchema
+	required   []string
+	additional *bool
+	items      *attributeSchema
+	minItems   *int
+	maxItems   *int
+	minimum    *big.Rat
+	maximum    *big.Rat
+	// bounds keeps minimum and maximum as written, for the messages.
+	bounds    [2]json.Number
+	minLength *int
+	maxLength *int
+	pattern   *regexp.Regexp
+}
+
+var schemaTypes = []string{"object", "array", "string", "number", "integer", "boolean", "null"}
+
+var schemaAnnotations = []string{"$schema", "$id", "$comment", "title", "description", "examples", "default"}
+
+// compileAttributeSchema compiles a schema document decoded by decodeJSON.
+// The returned error locates the offending keyword with a JSON pointer.
+func compileAttributeSchema(doc any) (*attributeSchema, error) {
+	s, err := compileSchemaAt(doc, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(s.types) > 0 && !slices.Contains(s.types, "object") {
+		return nil, errors.New("the schema must describe an object")
+	}
+
+	return s, nil
+}
+
+func compileSchemaAt(doc any, at string) (*attributeSchema, error) {
+	obj, ok := doc.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s must be an object", schemaLocation(at))
+	}
+
+	s := &attributeSchema{}
+
+	for _, k := range sortedKeys(obj) {
+		value := obj[k]
+		loc := at + "/" + k
+
+		var err error
+		switch k {
+		case "type":
+			s.types, err = compileSchemaTypes(value, loc)
+		case "enum":
+			arr, ok := value.([]any)
+			if !ok || len(arr) == 0 {
+				err = fmt.Errorf("%s must be a non-empty array", loc)
+			}
+			s.enum = arr
+		case "const":
+			s.enum = []any{value}
+		case "properties":
+			props, ok := value.(map[string]any)
+			if !ok {
+				err = fmt.Errorf("%s must be an object", loc)
+				break
+			}
+			s.properties = make(map[string]*attributeSchema, len(props))
+			for _, name := range sortedKeys(props) {
+				s.properties[name], err = compileSchemaAt(props[name], loc+"/"+name)
+				if err != nil {
+					break
+				}
+			}
+		case "required":
+			arr, ok := value.([]any)
+			if !ok {
+				err = fmt.Errorf("%s must be an array of strings", loc)
+				break
+			}
+			for _, elem := range arr {
+				name, ok := elem.(string)
+				if !ok {
+					err = fmt.Errorf("%s must be an array of strings", loc)
+					break
+				}
+				s.required = append(s.required, name)
+			}
+		case "additionalProperties":
+			b, ok := value.(bool)
+			if !ok {
+				err = fmt.Errorf("%s must be a boolean", loc)
+			}
+			s.additional = &b
+		case "items":
+			s.items, err = compileSchemaAt(value, loc)
+		case "minItems":
+			s.minItems, err = schemaCount(value, loc)
+		case "maxItems":
+			s.maxItems, err = schemaCount(value, loc)
+		case "minLength":
+			s.minLength, err = schemaCount(value, loc)
+		case "maxLength":
+			s.maxLength, err = schemaCount(value, loc)
+		case "minimum":
+			s.minimum, err = schemaNumber(value, loc)
+			s.bounds[0], _ = value.(json.Number)
+		case "maximum":
+			s.maximum, err = schemaNumber(value, loc)
+			s.bounds[1], _ = value.(json.Number)
+		case "pattern":
+			p, ok := value.(string)
+			if !ok {
+				err = fmt.Errorf("%s must be a string", loc)
+				break
+			}
+			s.pattern, err = regexp.Compile(p)
+			if err != nil {
+				err = fmt.Errorf("%s must be a valid regular expression", loc)
+			}
+		default:
+			if !slices.Contains(schemaAnnotations, k) {
+				err = fmt.Errorf("%s is not a supported keyword", loc)
+			}
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+func compileSchemaTypes(value any, loc string) ([]string, error) {
+	var names []any
+	switch t := value.(type) {
+	case string:
+		names = []any{t}
+	case []any:
+		names = t
+	}
+
+	types := make([]string, 0, len(names))
+	for _, n := range names {
+		name, ok := n.(string)
+		if !ok || !slices.Contains(schemaTypes, name) {
+			return nil, fmt.Errorf("%s must be a JSON Schema type or an array of them", loc)
+		}
+		types = append(types, name)
+	}
+
+	if len(types) == 0 {
+		return nil, fmt.Errorf("%s must be a JSON Schema type or an array of them", loc)
+	}
+
+	return types, nil
+}
+
+func schemaCount(value any, loc string) (*int, error) {
+	n, ok := value.(json.Number)
+	if ok {
+		if i, err := n.Int64(); err == nil && i >= 0 {
+			c := int(i)
+			return &c, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%s must be a non-negative integer", loc)
+}
+
+func schemaNumber(value any, loc string) (*big.Rat, error) {
+	n, ok := value.(json.Number)
+	if ok {
+		if r, ok := new(big.Rat).SetString(n.String()); ok {
+			return r, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%s must be a number", loc)
+}
+
+func schemaLocation(at string) string {
+	if at == "" {
+		return "the schema"
+	}
+
+	return at
+}
+
+// validate reports the violations of value under field, e.g. "attributes.size".
+func (s *attributeSchema) validate(v *violations, field string, value any) {
+	if len(s.types) > 0 && !slices.ContainsFunc(s.types, func(t string) bool { return hasSchemaType(value, t) }) {
+		v.add(field, "must be of type %s", joinOr(s.types))
+		return
+	}
+
+	if s.enum != nil && !slices.ContainsFunc(s.enum, func(e any) bool { return jsonEqual(e, value) }) {
+		v.add(field, "must be one of the allowed values")
+	}
+
+	switch t := value.(type) {
+	case map[string]any:
+		s.validateObject(v, field, t)
+	case []any:
+		if s.minItems != nil && len(t) < *s.minItems {
+			v.add(field, "must contain at least %d items", *s.minItems)
+		}
+		if s.maxItems != nil && len(t) > *s.maxItems {
+			v.add(field, "must contain at most %d items", *s.maxItems)
+		}
+		if s.items != nil {
+			for i, elem := range t {
+				s.items.validate(v, fmt.Sprintf("%s[%d]", field, i), elem)
+			}
+		}
+	case string:
+		n := utf8.RuneCountInString(t)
+		if s.minLength != nil && n < *s.minLength {
+			v.add(field, "must be at least %d characters long", *s.minLength)
+		}
+		if s.maxLength != nil && n > *s.maxLength {
+			v.add(field, "must be at most %d characters long", *s.maxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(t) {
+			v.add(field, "must match the pattern %s", s.pattern)
+		}
+	case json.Number:
+		r, ok := new(big.Rat).SetString(t.String())
+		if !ok {
+			return
+		}
+		if s.minimum != nil && r.Cmp(s.minimum) < 0 {
+			v.add(field, "must be at least %s", s.bounds[0])
+		}
+		if s.maximum != nil && r.Cmp(s.maximum) > 0 {
+			v.add(field, "must be at most %s", s.bounds[1])
+		}
+	}
+}
+
+func (s *attributeSchema) validateObject(v *violations, field string, obj map[string]any) {
+	for _, name := range s.required {
+		if _, ok := obj[name]; !ok {
+			v.add(field+"."+name, "is required")
+		}
+	}
+
+	for _, k := range sortedKeys(obj) {
+		prop, ok := s.properties[k]
+		switch {
+		case ok:
+			prop.validate(v, field+"."+k, obj[k])
+		case s.additional != nil && !*s.additional:
+			v.add(field+"."+k, "is not allowed")
+		}
+	}
+}
+
+func hasSchemaType(value any, t string) bool {
+	switch value := value.(type) {
+	case map[string]any:
+		return t == "object"
+	case []any:
+		return t == "array"
+	case string:
+		return t == "string"
+	case bool:
+		return t == "boolean"
+	case nil:
+		return t == "null"
+	case json.Number:
+		if t == "number" {
+			return true
+		}
+		r, ok := new(big.Rat).SetString(value.String())
+		return t == "integer" && ok && r.IsInt()
+	default:
+		return false
+	}
+}
+
+func joinOr(s []string) string {
+	if len(s) == 1 {
+		return s[0]
+	}
+
+	return strings.Join(s[:len(s)-1], ", ") + " or " + s[len(s)-1]
+}
+
+func sortedKeys(obj map[string]any) []string {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// checkProductAttributes validates the attributes of a product against the
+// schemas of its current categories. It runs in the transaction that writes
+// the product, which keeps the categories from changing until it ends. The
+// product is locked first: a concurrent change of its categories either
+// committed before the check reads them or checks the new attributes after.
+func (s Service) checkProductAttributes(ctx context.Context, productID uuid.UUID, attributes map[string]any) error {
+	_, err := s.repository.LockProduct(ctx, productID)
+	if err != nil {
+		return fmt.Errorf("failed to lock product: %w", err)
+	}
+
+	categories, err := s.repository.GetProductCategories(ctx, productID)
+	if err != nil {
+		return fmt.Errorf("failed to get product categories: %w", err)
+	}
+
+	schemas, err := s.attributeSchemas(ctx, categories)
+	if err != nil {
+		return err
+	}
+
+	err = validateAttributeSchemas(attributes, schemas)
+	if err != nil {
+		return fmt.Errorf("invalid product: %w", err)
+	}
+
+	return nil
+}
+
+// attributeSchemas returns the compiled attribute schemas of the categories
+// and of their ancestors: a product in "Shoes" must also satisfy the schema
+// of "Clothing".
+func (s Service) attributeSchemas(ctx context.Context, categories []*Category) ([]*attributeSchema, error) {
+	if len(categories) == 0 {
+		return nil, nil
+	}
+
+	var ids []uuid.UUID
+	for _, c := range categories {
+		for _, id := range c.Path {
+			if !slices.Contains(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	lineage, err := s.repository.GetCategoriesByID(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categories: %w", err)
+	}
+
+	var schemas []*attributeSchema
+	for _, c := range lineage {
+		if c.AttributeSchema == nil {
+			continue
+		}
+
+		schema, err := compileAttributeSchema(c.AttributeSchema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile attribute schema of category %s: %w", c.ID, err)
+		}
+		schemas = append(schemas, schema)
+	}
+
+	return schemas, nil
+}
+
+func validateAttributeSchemas(attributes map[string]any, schemas []*attributeSchema) error {
+	var v violations
+	for _, schema := range schemas {
+		schema.validate(&v, "attributes", attributes)
+	}
+
+	return v.err()
+}
@@ -0,0 +1,179 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// expectAttributesChecked expects the attributes of the product to be checked
+// against categories in the transaction of the write, the product being
+// locked before its categories are read.
+func expectAttributesChecked(m *Mockrepository, productID uuid.UUID, categories []*Category) {
+	gomock.InOrder(
+		m.EXPECT().
+			LockProduct(inTx(), productID).
+			Return(&Product{ID: productID}, nil),
+		m.EXPECT().
+			GetProductCategories(inTx(), productID).
+			Return(categories, nil),
+	)
+}
+
+func TestCompileAttributeSchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		expErr string
+	}{
+		{
+			name:   "schema with supported keywords",
+			schema: `{"title":"Shoes","type":"object","required":["size"],"additionalProperties":false,"properties":{"size":{"type":"integer","minimum":16,"maximum":50},"color":{"enum":["black","white"]},"laces":{"type":"array","items":{"type":"string","pattern":"^[a-z]+$"},"maxItems":2}}}`,
+		},
+		{
+			name:   "schema is not an object",
+			schema: `{"properties":{"size":true}}`,
+			expErr: "/properties/size must be an object",
+		},
+		{
+			name:   "unsupported keyword",
+			schema: `{"properties":{"size":{"oneOf":[{"type":"integer"}]}}}`,
+			expErr: "/properties/size/oneOf is not a supported keyword",
+		},
+		{
+			name:   "unknown type",
+			schema: `{"type":"float"}`,
+			expErr: "/type must be a JSON Schema type or an array of them",
+		},
+		{
+			name:   "invalid pattern",
+			schema: `{"properties":{"code":{"pattern":"["}}}`,
+			expErr: "/properties/code/pattern must be a valid regular expression",
+		},
+		{
+			name:   "negative length",
+			schema: `{"properties":{"code":{"maxLength":-1}}}`,
+			expErr: "/properties/code/maxLength must be a non-negative integer",
+		},
+		{
+			name:   "schema does not describe an object",
+			schema: `{"type":"string"}`,
+			expErr: "the schema must describe an object",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := DecodeJSONObject([]byte(tt.schema))
+			assert.NoError(t, err)
+
+			_, err = compileAttributeSchema(doc)
+			if tt.expErr != "" {
+				assert.EqualError(t, err, tt.expErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAttributeSchema_validate(t *testing.T) {
+	doc, err := DecodeJSONObject([]byte(`{
+		"required": ["size"],
+		"additionalProperties": false,
+		"properties": {
+			"size": {"type": "integer", "minimum": 16, "maximum": 50},
+			"color": {"enum": ["black", "white"]},
+			"weight": {"type": "number", "maximum": 2.5},
+			"laces": {"type": "array", "items": {"type": "string", "maxLength": 5}, "maxItems": 2}
+		}
+	}`))
+	assert.NoError(t, err)
+
+	schema, err := compileAttributeSchema(doc)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		attributes    string
+		expViolations []Violation
+	}{
+		{
+			name:       "valid attributes",
+			attributes: `{"size":42,"color":"black","weight":1.25,"laces":["red"]}`,
+		},
+		{
+			name:       "integer written as a decimal",
+			attributes: `{"size":42.0}`,
+		},
+		{
+			name:       "missing required attribute",
+			attributes: `{"color":"black"}`,
+			expViolations: []Violation{
+				{Field: "attributes.size", Message: "is required"},
+			},
+		},
+		{
+			name:       "invalid values",
+			attributes: `{"size":42.5,"color":"red","weight":2.75,"laces":["yellow","red","blue"],"brand":"acme"}`,
+			expViolations: []Violation{
+				{Field: "attributes.brand", Message: "is not allowed"},
+				{Field: "attributes.color", Message: "must be one of the allowed values"},
+				{Field: "attributes.laces", Message: "must contain at most 2 items"},
+				{Field: "attributes.laces[0]", Message: "must be at most 5 characters long"},
+				{Field: "attributes.size", Message: "must be of type integer"},
+				{Field: "attributes.weight", Message: "must be at most 2.5"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attributes, err := DecodeJSONObject([]byte(tt.attributes))
+			assert.NoError(t, err)
+
+			var v violations
+			schema.validate(&v, "attributes", attributes)
+			assert.Equal(t, tt.expViolations, []Violation(v))
+		})
+	}
+}
+
+func TestValidateAttributes(t *testing.T) {
+	tests := []struct {
+		name          string
+		attributes    map[string]any
+		expViolations []Violation
+	}{
+		{
+			name:       "valid attributes",
+			attributes: map[string]any{"size": "M", "shoe_size_eu": "42"},
+		},
+		{
+			name:       "invalid names",
+			attributes: map[string]any{"Size": "M", "2nd_color": "red"},
+			expViolations: []Violation{
+				{Field: "attributes.2nd_color", Message: "must be named with lower case letters, digits and underscores, starting with a letter"},
+				{Field: "attributes.Size", Message: "must be named with lower case letters, digits and underscores, starting with a letter"},
+			},
+		},
+		{
+			name:       "too large once encoded",
+			attributes: map[string]any{"notes": strings.Repeat("a", ProductAttributesMaxSize)},
+			expViolations: []Violation{
+				{Field: "attributes", Message: "must not be larger than 16384 bytes once encoded"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v violations
+			validateAttributes(&v, tt.attributes)
+			assert.Equal(t, tt.expViolations, []Violation(v))
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditAction is the kind of mutation an audit entry records.
+type AuditAction string
+
+const (
+	AuditCreated       AuditAction = "created"
+	AuditUpdated       AuditAction = "updated"
+	AuditDeleted       AuditAction = "deleted"
+	AuditRestored      AuditAction = "restored"
+	AuditReverted      AuditAction = "reverted"
+	AuditPurged        AuditAction = "purged"
+	AuditCategoriesSet AuditAction = "categories_set"
+	AuditStockAdjusted AuditAction = "stock_adjusted"
+	AuditCommitted     AuditAction = "committed"
+	AuditReleased      AuditAction = "released"
+	AuditExpired       AuditAction = "expired"
+)
+
+// AuditResource is the kind of resource a mutation applies to. The inventory
+// of a product has the ID of the product.
+type AuditResource string
+
+const (
+	AuditProduct     AuditResource = "product"
+	AuditCategory    AuditResource = "category"
+	AuditVariant     AuditResource = "variant"
+	AuditInventory   AuditResource = "inventory"
+	AuditReservation AuditResource = "reservation"
+)
+
+// AuditResources lists the resources in the order they are documented.
+var AuditResources = []AuditResource{AuditProduct, AuditCategory, AuditVariant, AuditInventory, AuditReservation}
+
+// AuditEntry records a mutation made through the service, by whom and on
+// behalf of which request. Before and After are the JSON documents of the
+// resource as the mutation found and left it. Either is nil when there is no
+// such state or when the mutation does not read it: creations have no Before,
+// deletions no After, and the batch jobs record neither. Entries are never
+// modified once recorded.
+type AuditEntry struct {
+	ID           uuid.UUID
+	Actor        string
+	Action       AuditAction
+	ResourceType AuditResource
+	ResourceID   uuid.UUID
+	RequestID    string
+	SourceIP     string
+	Before       any
+	After        any
+	CreatedAt    time.Time
+}
+
+// AuditFilter selects audit entries. Zero fields match every entry, Since is
+// inclusive and Until exclusive.
+type AuditFilter struct {
+	Actor        string
+	ResourceType AuditResource
+	ResourceID   uuid.UUID
+	Since        time.Time
+	Until        time.Time
+}
+
+// AuditQuery selects a slice of the filtered audit entries, the most recent
+// first.
+type AuditQuery struct {
+	Filter AuditFilter
+	Limit  int
+	Offset int
+}
+
+// AuditPage is a page of audit entries.
+type AuditPage struct {
+	Entries []AuditEntry
+	HasMore bool
+}
+
+// audit records a mutation of a resource by the actor of ctx. It runs in the
+// transaction of the mutation so that mutations and their entries are stored
+// together or not at all.
+func (s Service) audit(ctx context.Context, action AuditAction, resource AuditResource, resourceID uuid.UUID, before, after any) error {
+	req := RequestFromContext(ctx)
+
+	e := &AuditEntry{
+		ID:           uuid.New(),
+		Actor:        ActorFromContext(ctx),
+		Action:       action,
+		ResourceType: resource,
+		ResourceID:   resourceID,
+		RequestID:    req.ID,
+		SourceIP:     req.SourceIP,
+		Before:       auditDocument(before),
+		After:        auditDocument(after),
+		CreatedAt:    time.Now().UTC(),
+	}
+
+	err := s.repository.CreateAuditEntry(ctx, e)
+	if err != nil {
+		return fmt.Errorf("failed to create audit entry: %w", err)
+	}
+
+	return nil
+}
+
+// auditDocument returns the JSON document of a resource as the API names its
+// fields, so that the audit log does not depend on the Go structs.
+func auditDocument(v any) any {
+	switch v := v.(type) {
+	case *Product:
+		if v == nil {
+			return nil
+		}
+		return productStateDocument(v)
+	case *Category:
+		if v == nil {
+			return nil
+		}
+		doc := map[string]any{
+			"id":               v.ID.String(),
+			"parent_id":        nil,
+			"name":             v.Name,
+			"attribute_schema": deepCopy(v.AttributeSchema),
+			"version":          v.Version,
+		}
+		if v.ParentID != nil {
+			doc["parent_id"] = v.ParentID.String()
+		}
+		return doc
+	case *Variant:
+		if v == nil {
+			return nil
+		}
+		doc := map[string]any{
+			"id":         v.ID.String(),
+			"product_id": v.ProductID.String(),
+			"sku":        v.SKU,
+			"price":      nil,
+			"currency":   nil,
+			"options":    v.Options,
+			"stock":      v.Stock,
+			"version":    v.Version,
+		}
+		if v.Price != nil {
+			doc["price"] = v.Price.Decimal()
+			doc["currency"] = v.Price.Currency
+		}
+		return doc
+	case *Inventory:
+		if v == nil {
+			return nil
+		}
+		return map[string]any{
+			"product_id": v.ProductID.String(),
+			"on_hand":    v.OnHand,
+			"reserved":   v.Reserved,
+			"available":  v.Available(),
+		}
+	case *Reservation:
+		if v == nil {
+			return nil
+		}
+		items := make([]any, len(v.Items))
+		for i, item := range v.Items {
+			items[i] = map[string]any{"product_id": item.ProductID.String(), "quantity": item.Quantity}
+		}
+		return map[string]any{
+			"id":         v.ID.String(),
+			"status":     string(v.Status),
+			"items":      items,
+			"expires_at": v.ExpiresAt.UTC().Format(time.RFC3339Nano),
+		}
+	case []uuid.UUID:
+		ids := make([]any, len(v))
+		for i, id := range v {
+			ids[i] = id.String()
+		}
+		return ids
+	}
+
+	return v
+}
+
+// GetAuditEntries returns a page of the audit entries matching the filter,
+// the most recent first.
+func (s Service) GetAuditEntries(ctx context.Context, dto GetAuditEntriesDTO) (*AuditPage, error) {
+	q := AuditQuery{
+		Filter: dto.Filter,
+		Limit:  dto.Limit + 1,
+		Offset: dto.Offset,
+	}
+
+	entries, err := s.repository.GetAuditEntries(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit entries: %w", err)
+	}
+
+	page := &AuditPage{Entries: entries}
+	if len(entries) > dto.Limit {
+		page.Entries = entries[:dto.Limit]
+		page.HasMore = true
+	}
+
+	return page, nil
+}
+
+type GetAuditEntriesDTO struct {
+	Filter AuditFilter
+	Limit  int
+	Offset int
+}
@@ -0,0 +1,202 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// txKey marks the contexts that the mocked WithinTx passes to its function.
+type txKey struct{}
+
+// inTx matches the contexts of the mocked transactions, for the reads that
+// must happen in the transaction of a write.
+func inTx() gomock.Matcher {
+	return gomock.Cond(func(x any) bool {
+		ctx, ok := x.(context.Context)
+		return ok && ctx.Value(txKey{}) != nil
+	})
+}
+
+// expectAudited expects a change to run in a transaction, and to record an
+// audit entry when stored is true.
+func expectAudited(m *Mockrepository, stored bool) {
+	m.EXPECT().
+		WithinTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(context.WithValue(ctx, txKey{}, true))
+		})
+
+	if stored {
+		m.EXPECT().
+			CreateAuditEntry(gomock.Any(), gomock.Any()).
+			Return(nil)
+	}
+}
+
+// newIDs returns n random IDs.
+func newIDs(n int) []uuid.UUID {
+	ids := make([]uuid.UUID, n)
+	for i := range ids {
+		ids[i] = uuid.New()
+	}
+
+	return ids
+}
+
+func TestAuditDocument(t *testing.T) {
+	productID := uuid.MustParse("9f9f4340-6bf9-4948-808c-ebf2dd604e2c")
+	price := NewMoney(2500, "USD")
+
+	tests := []struct {
+		name   string
+		v      any
+		expDoc any
+	}{
+		{name: "nil", v: nil, expDoc: nil},
+		{name: "nil product", v: (*Product)(nil), expDoc: nil},
+		{
+			name: "variant",
+			v:    &Variant{ID: productID, ProductID: productID, SKU: "LAMP-RED", Price: &price, Options: map[string]string{"color": "red"}, Stock: 3, Version: 2},
+			expDoc: map[string]any{
+				"id":         "9f9f4340-6bf9-4948-808c-ebf2dd604e2c",
+				"product_id": "9f9f4340-6bf9-4948-808c-ebf2dd604e2c",
+				"sku":        "LAMP-RED",
+				"price":      "25.00",
+				"currency":   "USD",
+				"options":    map[string]string{"color": "red"},
+				"stock":      3,
+				"version":    int64(2),
+			},
+		},
+		{
+			name: "inventory",
+			v:    &Inventory{ProductID: productID, OnHand: 10, Reserved: 4},
+			expDoc: map[string]any{
+				"product_id": "9f9f4340-6bf9-4948-808c-ebf2dd604e2c",
+				"on_hand":    10,
+				"reserved":   4,
+				"available":  6,
+			},
+		},
+		{name: "category IDs", v: []uuid.UUID{productID}, expDoc: []any{"9f9f4340-6bf9-4948-808c-ebf2dd604e2c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expDoc, auditDocument(tt.v))
+		})
+	}
+}
+
+func TestService_audit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	resourceID := uuid.New()
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		expActor   string
+		expRequest RequestInfo
+		expRepoErr error
+		expErr     error
+	}{
+		{
+			name:       "change made for a request",
+			ctx:        WithRequest(WithActor(context.Background(), "alice"), RequestInfo{ID: "req-1", SourceIP: "203.0.113.7"}),
+			expActor:   "alice",
+			expRequest: RequestInfo{ID: "req-1", SourceIP: "203.0.113.7"},
+		},
+		{
+			name:     "change made without an identified caller",
+			ctx:      context.Background(),
+			expActor: AnonymousActor,
+		},
+		{
+			name:       "entry cannot be stored",
+			ctx:        context.Background(),
+			expActor:   AnonymousActor,
+			expRepoErr: errors.New("connection refused"),
+			expErr:     fmt.Errorf("failed to create audit entry: %w", errors.New("connection refused")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepository := NewMockrepository(ctrl)
+
+			mockRepository.EXPECT().
+				CreateAuditEntry(gomock.Any(), gomock.Any()).
+				Do(func(_ context.Context, e *AuditEntry) {
+					assert.Equal(t, tt.expActor, e.Actor)
+					assert.Equal(t, AuditDeleted, e.Action)
+					assert.Equal(t, AuditVariant, e.ResourceType)
+					assert.Equal(t, resourceID, e.ResourceID)
+					assert.Equal(t, tt.expRequest.ID, e.RequestID)
+					assert.Equal(t, tt.expRequest.SourceIP, e.SourceIP)
+					assert.NotNil(t, e.Before)
+					assert.Nil(t, e.After)
+					assert.WithinDuration(t, time.Now(), e.CreatedAt, time.Minute)
+				}).
+				Return(tt.expRepoErr)
+
+			s, err := NewService(mockRepository)
+			assert.NoError(t, err)
+
+			err = s.audit(tt.ctx, AuditDeleted, AuditVariant, resourceID, &Variant{ID: resourceID}, (*Variant)(nil))
+			assert.Equal(t, tt.expErr, err)
+		})
+	}
+}
+
+func TestService_GetAuditEntries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+
+	filter := AuditFilter{Actor: "alice", ResourceType: AuditProduct}
+	entries := []AuditEntry{{ID: uuid.New()}, {ID: uuid.New()}, {ID: uuid.New()}}
+
+	tests := []struct {
+		name         string
+		limit        int
+		expRepoLimit int
+		expPage      *AuditPage
+	}{
+		{
+			name:         "last page",
+			limit:        3,
+			expRepoLimit: 4,
+			expPage:      &AuditPage{Entries: entries},
+		},
+		{
+			name:         "more entries follow",
+			limit:        2,
+			expRepoLimit: 3,
+			expPage:      &AuditPage{Entries: entries[:2], HasMore: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepository := NewMockrepository(ctrl)
+
+			mockRepository.EXPECT().
+				GetAuditEntries(gomock.Any(), AuditQuery{Filter: filter, Limit: tt.expRepoLimit, Offset: 10}).
+				Return(entries, nil)
+
+			s, err := NewService(mockRepository)
+			assert.NoError(t, err)
+
+			page, err := s.GetAuditEntries(ctx, GetAuditEntriesDTO{Filter: filter, Limit: tt.limit, Offset: 10})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expPage, page)
+		})
+	}
+}
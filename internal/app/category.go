@@ -0,0 +1,427 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CategoryNameMaxLength is the maximum length of a category name.
+const CategoryNameMaxLength = 100
+
+// ProductCategoriesMax is the maximum number of categories of a product.
+const ProductCategoriesMax = 50
+
+// Category is a node of the product taxonomy. Path lists the IDs of the
+// ancestors of the category from the root, followed by its own ID, so the
+// descendants of a category are the ones whose path starts with its path.
+//
+// AttributeSchema is an optional JSON Schema that the attributes of the
+// products of the category and of its descendants must satisfy. It is
+// checked when a product is written, and the products already stored are
+// checked again when the schema or the parent of a category changes.
+type Category struct {
+	ID              uuid.UUID
+	ParentID        *uuid.UUID
+	Name            string
+	Path            []uuid.UUID
+	AttributeSchema map[string]any
+	Version         int64
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// NewCategory returns a category under parent, or a root category when
+// parent is nil.
+func NewCategory(name string, parent *Category, attributeSchema map[string]any) *Category {
+	now := time.Now().UTC()
+
+	c := &Category{
+		ID:              uuid.New(),
+		Name:            name,
+		AttributeSchema: attributeSchema,
+		Version:         1,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	c.setParent(parent)
+
+	return c
+}
+
+// Update renames the category and moves it under parent. The caller must
+// have checked that parent is not the category or one of its descendants.
+func (c *Category) Update(name string, parent *Category, attributeSchema map[string]any) {
+	c.Name = name
+	c.AttributeSchema = attributeSchema
+	c.setParent(parent)
+	c.UpdatedAt = time.Now().UTC()
+}
+
+// CheckVersion returns ErrPreconditionFailed when expected is set and does not
+// match the current version of the category.
+func (c *Category) CheckVersion(expected int64) error {
+	if expected != 0 && expected != c.Version {
+		return fmt.Errorf("%w: category %s is at version %d, not %d", ErrPreconditionFailed, c.ID, c.Version, expected)
+	}
+
+	return nil
+}
+
+// IsAncestorOf reports whether other is the category itself or one of its
+// descendants.
+func (c *Category) IsAncestorOf(other *Category) bool {
+	return slices.Contains(other.Path, c.ID)
+}
+
+func (c *Category) setParent(parent *Category) {
+	if parent == nil {
+		c.ParentID = nil
+		c.Path = []uuid.UUID{c.ID}
+		return
+	}
+
+	parentID := parent.ID
+	c.ParentID = &parentID
+	c.Path = append(slices.Clone(parent.Path), c.ID)
+}
+
+// categoryInput holds the fields of a category that clients can set.
+type categoryInput struct {
+	Name            string
+	AttributeSchema map[string]any
+}
+
+func (in categoryInput) normalize() categoryInput {
+	in.Name = normalizeName(in.Name)
+
+	return in
+}
+
+func (in categoryInput) validate() error {
+	var v violations
+
+	validateName(&v, in.Name, CategoryNameMaxLength)
+
+	if in.AttributeSchema != nil {
+		_, err := compileAttributeSchema(in.AttributeSchema)
+		if err != nil {
+			v.add("attribute_schema", "%s", err)
+		}
+	}
+
+	return v.err()
+}
+
+func (s Service) CreateCategory(ctx context.Context, dto CreateCategoryDTO) (*Category, error) {
+	in := categoryInput{Name: dto.Name, AttributeSchema: dto.AttributeSchema}.normalize()
+
+	err := in.validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid category: %w", err)
+	}
+
+	parent, err := s.parentCategory(ctx, dto.ParentID)
+	if err != nil {
+		return nil, err
+	}
+
+	c := NewCategory(in.Name, parent, in.AttributeSchema)
+
+	err = s.repository.WithinTx(ctx, func(ctx context.Context) error {
+		err := s.repository.CreateCategory(ctx, c)
+		if err != nil {
+			return fmt.Errorf("failed to create category: %w", err)
+		}
+
+		return s.audit(ctx, AuditCreated, AuditCategory, c.ID, nil, c)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// UpdateCategory renames a category and moves it, along with its subtree,
+// under another parent.
+func (s Service) UpdateCategory(ctx context.Context, dto UpdateCategoryDTO) (*Category, error) {
+	in := categoryInput{Name: dto.Name, AttributeSchema: dto.AttributeSchema}.normalize()
+
+	err := in.validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid category: %w", err)
+	}
+
+	c, err := s.repository.GetCategory(ctx, dto.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category: %w", err)
+	}
+
+	err = c.CheckVersion(dto.ExpectedVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	parent, err := s.parentCategory(ctx, dto.ParentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if parent != nil && c.IsAncestorOf(parent) {
+		var v violations
+		v.add("parent_id", "must not be the category itself or one of its descendants")
+		return nil, fmt.Errorf("invalid category: %w", v.err())
+	}
+
+	before := *c
+	c.Update(in.Name, parent, in.AttributeSchema)
+
+	err = s.repository.WithinTx(ctx, func(ctx context.Context) error {
+		err := s.repository.UpdateCategory(ctx, c)
+		if err != nil {
+			return fmt.Errorf("failed to update category: %w", err)
+		}
+
+		// The products of the subtree must satisfy the schemas of their
+		// categories once they changed.
+		if !slices.Equal(before.Path, c.Path) || !sameAttributeSchema(before.AttributeSchema, c.AttributeSchema) {
+			err = s.checkCategoryProducts(ctx, c.ID)
+			if err != nil {
+				return fmt.Errorf("invalid category: %w", err)
+			}
+		}
+
+		return s.audit(ctx, AuditUpdated, AuditCategory, c.ID, &before, c)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// checkCategoryProducts validates the attributes of the products of the
+// category and of its descendants against the schemas of their categories.
+// The violations are reported under the ID of their product.
+func (s Service) checkCategoryProducts(ctx context.Context, categoryID uuid.UUID) error {
+	products, err := s.repository.GetCategoryProducts(ctx, categoryID)
+	if err != nil {
+		return fmt.Errorf("failed to get category products: %w", err)
+	}
+
+	var v violations
+	for _, p := range products {
+		categories, err := s.repository.GetProductCategories(ctx, p.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get product categories: %w", err)
+		}
+
+		schemas, err := s.attributeSchemas(ctx, categories)
+		if err != nil {
+			return err
+		}
+
+		var validationErr *ValidationError
+		err = validateAttributeSchemas(p.Attributes, schemas)
+		if !errors.As(err, &validationErr) {
+			continue
+		}
+
+		for _, violation := range validationErr.Violations {
+			v.add("products."+p.ID.String()+"."+violation.Field, "%s", violation.Message)
+		}
+	}
+
+	return v.err()
+}
+
+// sameAttributeSchema reports whether two attribute schemas are equal once
+// encoded, whatever the Go types their numbers were decoded to.
+func sameAttributeSchema(a, b map[string]any) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	encodedA, errA := json.Marshal(a)
+	encodedB, errB := json.Marshal(b)
+
+	return errA == nil && errB == nil && bytes.Equal(encodedA, encodedB)
+}
+
+// parentCategory returns the category with the given ID, or nil when id is
+// nil. A missing parent is a validation error of the request, not a missing
+// resource.
+func (s Service) parentCategory(ctx context.Context, id *uuid.UUID) (*Category, error) {
+	if id == nil {
+		return nil, nil
+	}
+
+	parent, err := s.repository.GetCategory(ctx, *id)
+	if errors.Is(err, ErrNotFound) {
+		var v violations
+		v.add("parent_id", "must refer to an existing category")
+		return nil, fmt.Errorf("invalid category: %w", v.err())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parent category: %w", err)
+	}
+
+	return parent, nil
+}
+
+// DeleteCategory deletes a category that has no subcategories. Its products
+// are unassigned from it, not deleted.
+func (s Service) DeleteCategory(ctx context.Context, dto DeleteCategoryDTO) error {
+	return s.repository.WithinTx(ctx, func(ctx context.Context) error {
+		c, err := s.repository.DeleteCategory(ctx, dto.ID, dto.ExpectedVersion)
+		if err != nil {
+			return fmt.Errorf("failed to delete category: %w", err)
+		}
+
+		return s.audit(ctx, AuditDeleted, AuditCategory, c.ID, c, nil)
+	})
+}
+
+func (s Service) GetCategory(ctx context.Context, categoryID uuid.UUID) (*Category, error) {
+	c, err := s.repository.GetCategory(ctx, categoryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category: %w", err)
+	}
+
+	return c, nil
+}
+
+// GetCategories returns the whole taxonomy, each category following its
+// parent.
+func (s Service) GetCategories(ctx context.Context) ([]*Category, error) {
+	categories, err := s.repository.GetCategories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categories: %w", err)
+	}
+
+	return categories, nil
+}
+
+// SetProductCategories replaces the categories of a product and returns them.
+// The attributes of the product must satisfy the schemas of the new
+// categories, which are checked in the transaction that links them with
+// the product locked.
+func (s Service) SetProductCategories(ctx context.Context, dto SetProductCategoriesDTO) ([]*Category, error) {
+	ids := make([]uuid.UUID, 0, len(dto.CategoryIDs))
+	for _, id := range dto.CategoryIDs {
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+
+	var v violations
+	if len(ids) > ProductCategoriesMax {
+		v.add("category_ids", "must not contain more than %d categories", ProductCategoriesMax)
+		return nil, fmt.Errorf("invalid product categories: %w", v.err())
+	}
+
+	var categories []*Category
+	err := s.repository.WithinTx(ctx, func(ctx context.Context) error {
+		// The attributes are checked as no concurrent update can change
+		// them until the categories are linked.
+		p, err := s.repository.LockProduct(ctx, dto.ProductID)
+		if err != nil {
+			return fmt.Errorf("failed to lock product: %w", err)
+		}
+
+		categories, err = s.repository.GetCategoriesByID(ctx, ids)
+		if err != nil {
+			return fmt.Errorf("failed to get categories: %w", err)
+		}
+
+		if len(categories) != len(ids) {
+			v.add("category_ids", "must refer to existing categories")
+			return fmt.Errorf("invalid product categories: %w", v.err())
+		}
+
+		schemas, err := s.attributeSchemas(ctx, categories)
+		if err != nil {
+			return err
+		}
+
+		err = validateAttributeSchemas(p.Attributes, schemas)
+		if err != nil {
+			return fmt.Errorf("invalid product categories: %w", err)
+		}
+
+		before, err := s.repository.GetProductCategories(ctx, dto.ProductID)
+		if err != nil {
+			return fmt.Errorf("failed to get product categories: %w", err)
+		}
+
+		err = s.repository.SetProductCategories(ctx, dto.ProductID, ids)
+		if err != nil {
+			return fmt.Errorf("failed to set product categories: %w", err)
+		}
+
+		return s.audit(ctx, AuditCategoriesSet, AuditProduct, dto.ProductID, categoryIDs(before), ids)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+// categoryIDs returns the IDs of the categories.
+func categoryIDs(categories []*Category) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(categories))
+	for _, c := range categories {
+		ids = append(ids, c.ID)
+	}
+
+	return ids
+}
+
+func (s Service) GetProductCategories(ctx context.Context, productID uuid.UUID) ([]*Category, error) {
+	_, err := s.repository.GetProduct(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	categories, err := s.repository.GetProductCategories(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product categories: %w", err)
+	}
+
+	return categories, nil
+}
+
+// CreateCategoryDTO creates a root category when ParentID is nil.
+type CreateCategoryDTO struct {
+	Name            string
+	ParentID        *uuid.UUID
+	AttributeSchema map[string]any
+}
+
+// UpdateCategoryDTO replaces the fields of a category. A nil ParentID makes
+// it a root category.
+type UpdateCategoryDTO struct {
+	ID              uuid.UUID
+	ExpectedVersion int64
+	Name            string
+	ParentID        *uuid.UUID
+	AttributeSchema map[string]any
+}
+
+type DeleteCategoryDTO struct {
+	ID              uuid.UUID
+	ExpectedVersion int64
+}
+
+type SetProductCategoriesDTO struct {
+	ProductID   uuid.UUID
+	CategoryIDs []uuid.UUID
+}
@@ -0,0 +1,391 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNewCategory(t *testing.T) {
+	root := NewCategory("Electronics", nil, nil)
+	assert.Nil(t, root.ParentID)
+	assert.Equal(t, []uuid.UUID{root.ID}, root.Path)
+	assert.Equal(t, int64(1), root.Version)
+
+	child := NewCategory("Audio", root, nil)
+	assert.Equal(t, &root.ID, child.ParentID)
+	assert.Equal(t, []uuid.UUID{root.ID, child.ID}, child.Path)
+
+	assert.True(t, root.IsAncestorOf(child))
+	assert.True(t, child.IsAncestorOf(child))
+	assert.False(t, child.IsAncestorOf(root))
+}
+
+func TestCategory_Update(t *testing.T) {
+	root := NewCategory("Electronics", nil, nil)
+	other := NewCategory("Home", nil, nil)
+	c := NewCategory("Audio", root, nil)
+
+	c.Update("Sound", other, nil)
+	assert.Equal(t, "Sound", c.Name)
+	assert.Equal(t, &other.ID, c.ParentID)
+	assert.Equal(t, []uuid.UUID{other.ID, c.ID}, c.Path)
+
+	c.Update("Sound", nil, nil)
+	assert.Nil(t, c.ParentID)
+	assert.Equal(t, []uuid.UUID{c.ID}, c.Path)
+}
+
+func TestService_CreateCategory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+
+	parent := NewCategory("Electronics", nil, nil)
+
+	tests := []struct {
+		name                     string
+		dto                      CreateCategoryDTO
+		expRepoGetCategoryResult *Category
+		expRepoGetCategoryErr    error
+		expRepoCreateCategoryErr error
+		expErr                   error
+	}{
+		{
+			name: "root category was created successfully",
+			dto:  CreateCategoryDTO{Name: "Electronics"},
+		},
+		{
+			name:                     "subcategory was created successfully",
+			dto:                      CreateCategoryDTO{Name: "Audio", ParentID: &parent.ID},
+			expRepoGetCategoryResult: parent,
+		},
+		{
+			name:                  "parent does not exist",
+			dto:                   CreateCategoryDTO{Name: "Audio", ParentID: &parent.ID},
+			expRepoGetCategoryErr: ErrNotFound,
+			expErr: fmt.Errorf("invalid category: %w", &ValidationError{Violations: []Violation{
+				{Field: "parent_id", Message: "must refer to an existing category"},
+			}}),
+		},
+		{
+			name:                     "error creating category",
+			dto:                      CreateCategoryDTO{Name: "Electronics"},
+			expRepoCreateCategoryErr: errors.New("repo error"),
+			expErr:                   fmt.Errorf("failed to create category: %w", errors.New("repo error")),
+		},
+		{
+			name: "invalid attribute schema",
+			dto:  CreateCategoryDTO{Name: "Shoes", AttributeSchema: map[string]any{"properties": map[string]any{"size": map[string]any{"type": "float"}}}},
+			expErr: fmt.Errorf("invalid category: %w", &ValidationError{Violations: []Violation{
+				{Field: "attribute_schema", Message: "/properties/size/type must be a JSON Schema type or an array of them"},
+			}}),
+		},
+		{
+			name: "invalid category",
+			dto:  CreateCategoryDTO{Name: " "},
+			expErr: fmt.Errorf("invalid category: %w", &ValidationError{Violations: []Violation{
+				{Field: "name", Message: "must not be empty"},
+			}}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mock expectations
+			mockRepository := NewMockrepository(ctrl)
+
+			if tt.dto.ParentID != nil {
+				mockRepository.EXPECT().
+					GetCategory(gomock.Any(), *tt.dto.ParentID).
+					Return(tt.expRepoGetCategoryResult, tt.expRepoGetCategoryErr)
+			}
+
+			if tt.expErr == nil || tt.expRepoCreateCategoryErr != nil {
+				expectAudited(mockRepository, tt.expErr == nil)
+				mockRepository.EXPECT().
+					CreateCategory(gomock.Any(), gomock.Any()).
+					Return(tt.expRepoCreateCategoryErr)
+			}
+
+			// Exercise
+			s, err := NewService(mockRepository)
+			assert.NoError(t, err)
+
+			c, err := s.CreateCategory(ctx, tt.dto)
+			if tt.expErr != nil {
+				assert.Equal(t, tt.expErr, err)
+				assert.Nil(t, c)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.dto.Name, c.Name)
+				assert.Equal(t, tt.dto.ParentID, c.ParentID)
+			}
+		})
+	}
+}
+
+func TestService_UpdateCategory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+
+	root := NewCategory("Electronics", nil, nil)
+	category := NewCategory("Audio", root, nil)
+	child := NewCategory("Headphones", category, nil)
+	other := NewCategory("Video", nil, nil)
+
+	sizeSchema := map[string]any{"required": []any{"size"}}
+	sized := &Product{ID: uuid.MustParse("9f9f4340-6bf9-4948-808c-ebf2dd604e2c"), Attributes: map[string]any{"size": json.Number("42")}}
+	unsized := &Product{ID: uuid.MustParse("0b6f3c1e-3d2a-4f7e-9c5b-8a1d2e3f4a5b"), Attributes: map[string]any{}}
+
+	tests := []struct {
+		name                     string
+		dto                      UpdateCategoryDTO
+		expRepoUpdateCategoryErr error
+		expCheckedProducts       []*Product
+		expProductsChecked       bool
+		expErr                   error
+	}{
+		{
+			name:               "category was moved to the root",
+			dto:                UpdateCategoryDTO{ID: category.ID, Name: "Audio"},
+			expProductsChecked: true,
+		},
+		{
+			name:               "category was moved under another one",
+			dto:                UpdateCategoryDTO{ID: category.ID, Name: "Audio", ParentID: &other.ID},
+			expProductsChecked: true,
+		},
+		{
+			name: "category was renamed, its products are not checked",
+			dto:  UpdateCategoryDTO{ID: category.ID, Name: "Sound", ParentID: &root.ID},
+		},
+		{
+			name:               "schema is satisfied by the products of the subtree",
+			dto:                UpdateCategoryDTO{ID: category.ID, Name: "Audio", ParentID: &root.ID, AttributeSchema: sizeSchema},
+			expCheckedProducts: []*Product{sized},
+			expProductsChecked: true,
+		},
+		{
+			name:               "schema is violated by a product of the subtree",
+			dto:                UpdateCategoryDTO{ID: category.ID, Name: "Audio", ParentID: &root.ID, AttributeSchema: sizeSchema},
+			expCheckedProducts: []*Product{sized, unsized},
+			expProductsChecked: true,
+			expErr: fmt.Errorf("invalid category: %w", &ValidationError{Violations: []Violation{
+				{Field: "products.0b6f3c1e-3d2a-4f7e-9c5b-8a1d2e3f4a5b.attributes.size", Message: "is required"},
+			}}),
+		},
+		{
+			name: "category moved under itself",
+			dto:  UpdateCategoryDTO{ID: category.ID, Name: "Audio", ParentID: &category.ID},
+			expErr: fmt.Errorf("invalid category: %w", &ValidationError{Violations: []Violation{
+				{Field: "parent_id", Message: "must not be the category itself or one of its descendants"},
+			}}),
+		},
+		{
+			name: "category moved under a descendant",
+			dto:  UpdateCategoryDTO{ID: category.ID, Name: "Audio", ParentID: &child.ID},
+			expErr: fmt.Errorf("invalid category: %w", &ValidationError{Violations: []Violation{
+				{Field: "parent_id", Message: "must not be the category itself or one of its descendants"},
+			}}),
+		},
+		{
+			name:   "stale expected version",
+			dto:    UpdateCategoryDTO{ID: category.ID, ExpectedVersion: 5, Name: "Audio"},
+			expErr: fmt.Errorf("%w: category %s is at version 1, not 5", ErrPreconditionFailed, category.ID),
+		},
+		{
+			name:                     "error updating category",
+			dto:                      UpdateCategoryDTO{ID: category.ID, Name: "Audio"},
+			expRepoUpdateCategoryErr: errors.New("repo error"),
+			expErr:                   fmt.Errorf("failed to update category: %w", errors.New("repo error")),
+		},
+	}
+
+	categories := map[uuid.UUID]*Category{root.ID: root, category.ID: category, child.ID: child, other.ID: other}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mock expectations
+			mockRepository := NewMockrepository(ctrl)
+
+			mockRepository.EXPECT().
+				GetCategory(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, id uuid.UUID) (*Category, error) {
+					c := *categories[id]
+					return &c, nil
+				}).
+				AnyTimes()
+
+			if tt.expErr == nil || tt.expRepoUpdateCategoryErr != nil || tt.expProductsChecked {
+				expectAudited(mockRepository, tt.expErr == nil)
+				mockRepository.EXPECT().
+					UpdateCategory(gomock.Any(), gomock.Any()).
+					Return(tt.expRepoUpdateCategoryErr)
+			}
+
+			// The products of the subtree are read in the transaction of the
+			// update, and checked against the updated category.
+			if tt.expProductsChecked {
+				mockRepository.EXPECT().
+					GetCategoryProducts(inTx(), category.ID).
+					Return(tt.expCheckedProducts, nil)
+				mockRepository.EXPECT().
+					GetProductCategories(inTx(), gomock.Any()).
+					Return([]*Category{child}, nil).
+					AnyTimes()
+				mockRepository.EXPECT().
+					GetCategoriesByID(inTx(), gomock.Any()).
+					Return([]*Category{{ID: category.ID, AttributeSchema: tt.dto.AttributeSchema}}, nil).
+					AnyTimes()
+			}
+
+			// Exercise
+			s, err := NewService(mockRepository)
+			assert.NoError(t, err)
+
+			c, err := s.UpdateCategory(ctx, tt.dto)
+			if tt.expErr != nil {
+				assert.Equal(t, tt.expErr, err)
+				assert.Nil(t, c)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.dto.ParentID, c.ParentID)
+				assert.Equal(t, category.ID, c.Path[len(c.Path)-1])
+			}
+		})
+	}
+}
+
+func TestService_SetProductCategories(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+
+	productID := uuid.New()
+	audio := NewCategory("Audio", nil, nil)
+	video := NewCategory("Video", nil, nil)
+	clothing := NewCategory("Clothing", nil, map[string]any{"required": []any{"size"}})
+	shoes := NewCategory("Shoes", clothing, nil)
+	unknownID := uuid.New()
+
+	categories := map[uuid.UUID]*Category{audio.ID: audio, video.ID: video, clothing.ID: clothing, shoes.ID: shoes}
+
+	tests := []struct {
+		name                           string
+		categoryIDs                    []uuid.UUID
+		expRepoGetProductErr           error
+		expRepoSetProductCategoriesErr error
+		expCategories                  []*Category
+		expErr                         error
+	}{
+		{
+			name:          "categories were set, duplicates ignored",
+			categoryIDs:   []uuid.UUID{audio.ID, video.ID, audio.ID},
+			expCategories: []*Category{audio, video},
+		},
+		{
+			name:          "categories were cleared",
+			categoryIDs:   nil,
+			expCategories: nil,
+		},
+		{
+			name:        "unknown category",
+			categoryIDs: []uuid.UUID{audio.ID, unknownID},
+			expErr: fmt.Errorf("invalid product categories: %w", &ValidationError{Violations: []Violation{
+				{Field: "category_ids", Message: "must refer to existing categories"},
+			}}),
+		},
+		{
+			name:        "attributes violate the schema of an ancestor",
+			categoryIDs: []uuid.UUID{shoes.ID},
+			expErr: fmt.Errorf("invalid product categories: %w", &ValidationError{Violations: []Violation{
+				{Field: "attributes.size", Message: "is required"},
+			}}),
+		},
+		{
+			name:                 "product does not exist",
+			categoryIDs:          []uuid.UUID{audio.ID},
+			expRepoGetProductErr: ErrNotFound,
+			expErr:               fmt.Errorf("failed to lock product: %w", ErrNotFound),
+		},
+		{
+			name:                           "error setting categories",
+			categoryIDs:                    []uuid.UUID{audio.ID},
+			expRepoSetProductCategoriesErr: errors.New("repo error"),
+			expErr:                         fmt.Errorf("failed to set product categories: %w", errors.New("repo error")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mock expectations
+			mockRepository := NewMockrepository(ctrl)
+
+			expectAudited(mockRepository, tt.expErr == nil)
+
+			// The product is locked before the categories are checked, so
+			// that its attributes cannot change until they are linked.
+			var lock *gomock.Call
+			if tt.expRepoGetProductErr != nil {
+				lock = mockRepository.EXPECT().
+					LockProduct(inTx(), productID).
+					Return(nil, tt.expRepoGetProductErr)
+			} else {
+				lock = mockRepository.EXPECT().
+					LockProduct(inTx(), productID).
+					Return(&Product{ID: productID, Attributes: map[string]any{}}, nil)
+			}
+
+			mockRepository.EXPECT().
+				GetCategoriesByID(inTx(), gomock.Any()).
+				After(lock).
+				DoAndReturn(func(_ context.Context, ids []uuid.UUID) ([]*Category, error) {
+					var found []*Category
+					for _, id := range ids {
+						if c, ok := categories[id]; ok {
+							found = append(found, c)
+						}
+					}
+					return found, nil
+				}).
+				AnyTimes()
+
+			if tt.expErr == nil || tt.expRepoSetProductCategoriesErr != nil {
+				mockRepository.EXPECT().
+					GetProductCategories(inTx(), productID).
+					Return(nil, nil)
+				mockRepository.EXPECT().
+					SetProductCategories(gomock.Any(), productID, gomock.Any()).
+					Return(tt.expRepoSetProductCategoriesErr)
+			}
+
+			// Exercise
+			s, err := NewService(mockRepository)
+			assert.NoError(t, err)
+
+			got, err := s.SetProductCategories(ctx, SetProductCategoriesDTO{ProductID: productID, CategoryIDs: tt.categoryIDs})
+			if tt.expErr != nil {
+				assert.Equal(t, tt.expErr, err)
+				assert.Nil(t, got)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expCategories, got)
+			}
+		})
+	}
+}
+
+func TestSameAttributeSchema(t *testing.T) {
+	assert.True(t, sameAttributeSchema(nil, nil))
+	assert.True(t, sameAttributeSchema(
+		map[string]any{"properties": map[string]any{"size": map[string]any{"minimum": json.Number("16")}}},
+		map[string]any{"properties": map[string]any{"size": map[string]any{"minimum": float64(16)}}},
+	))
+	assert.False(t, sameAttributeSchema(nil, map[string]any{}))
+	assert.False(t, sameAttributeSchema(map[string]any{"required": []any{"size"}}, map[string]any{"required": []any{"color"}}))
+}
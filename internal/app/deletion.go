@@ -0,0 +1,67 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PurgeProductsBatchSize bounds the products hard deleted in one statement.
+const PurgeProductsBatchSize = 100
+
+// RestoreProduct brings back a soft deleted product as it was when it was
+// deleted.
+func (s Service) RestoreProduct(ctx context.Context, dto RestoreProductDTO) (*Product, error) {
+	return s.saveProduct(ctx, RevisionRestored, nil, func(ctx context.Context) (*Product, error) {
+		p, err := s.repository.RestoreProduct(ctx, dto.ID, dto.ExpectedVersion)
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore product: %w", err)
+		}
+
+		return p, nil
+	})
+}
+
+// PurgeProducts hard deletes the products that have been soft deleted for
+// longer than retention. It returns the number of products it deleted.
+func (s Service) PurgeProducts(ctx context.Context, retention time.Duration) (int, error) {
+	deletedBefore := time.Now().UTC().Add(-retention)
+
+	var total int
+	for {
+		var n int
+		err := s.repository.WithinTx(ctx, func(ctx context.Context) error {
+			purged, err := s.repository.PurgeProducts(ctx, deletedBefore, PurgeProductsBatchSize)
+			if err != nil {
+				return fmt.Errorf("failed to purge products: %w", err)
+			}
+
+			for _, id := range purged {
+				err := s.audit(ctx, AuditPurged, AuditProduct, id, nil, nil)
+				if err != nil {
+					return err
+				}
+			}
+
+			n = len(purged)
+			return nil
+		})
+		if err != nil {
+			return total, err
+		}
+
+		total += n
+		if n < PurgeProductsBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// RestoreProductDTO restores a soft deleted product. A non-zero
+// ExpectedVersion makes the restore conditional on the current version.
+type RestoreProductDTO struct {
+	ID              uuid.UUID
+	ExpectedVersion int64
+}
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_RestoreProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+
+	productID := uuid.New()
+	restored := &Product{ID: productID, Status: ProductPublished, Version: 3}
+
+	tests := []struct {
+		name                     string
+		dto                      RestoreProductDTO
+		expRepoRestoreProductErr error
+		expErr                   error
+	}{
+		{
+			name: "product was restored successfully",
+			dto:  RestoreProductDTO{ID: productID, ExpectedVersion: 2},
+		},
+		{
+			name:                     "product is not deleted",
+			dto:                      RestoreProductDTO{ID: productID},
+			expRepoRestoreProductErr: ErrConflict,
+			expErr:                   fmt.Errorf("failed to restore product: %w", ErrConflict),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mock expectations
+			mockRepository := NewMockrepository(ctrl)
+
+			var result *Product
+			if tt.expRepoRestoreProductErr == nil {
+				result = restored
+			}
+			expectSaveProduct(mockRepository, tt.expRepoRestoreProductErr == nil)
+			mockRepository.EXPECT().
+				RestoreProduct(gomock.Any(), productID, tt.dto.ExpectedVersion).
+				Return(result, tt.expRepoRestoreProductErr)
+
+			// Exercise
+			s, err := NewService(mockRepository)
+			assert.NoError(t, err)
+
+			p, err := s.RestoreProduct(ctx, tt.dto)
+			if tt.expErr != nil {
+				assert.Equal(t, tt.expErr, err)
+				assert.Nil(t, p)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, restored, p)
+			}
+		})
+	}
+}
+
+func TestService_PurgeProducts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+
+	retention := 30 * 24 * time.Hour
+
+	deletedBefore := func(_ context.Context, before time.Time, _ int) {
+		assert.WithinDuration(t, time.Now().Add(-retention), before, time.Minute)
+	}
+
+	mockRepository := NewMockrepository(ctrl)
+	mockRepository.EXPECT().
+		WithinTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		}).
+		Times(2)
+	gomock.InOrder(
+		mockRepository.EXPECT().
+			PurgeProducts(gomock.Any(), gomock.Any(), PurgeProductsBatchSize).
+			Do(deletedBefore).
+			Return(newIDs(PurgeProductsBatchSize), nil),
+		mockRepository.EXPECT().
+			PurgeProducts(gomock.Any(), gomock.Any(), PurgeProductsBatchSize).
+			Do(deletedBefore).
+			Return(newIDs(7), nil),
+	)
+	mockRepository.EXPECT().
+		CreateAuditEntry(gomock.Any(), gomock.Any()).
+		Do(func(ctx context.Context, e *AuditEntry) {
+			assert.Equal(t, AuditPurged, e.Action)
+			assert.Equal(t, SystemActor, e.Actor)
+		}).
+		Return(nil).
+		Times(PurgeProductsBatchSize + 7)
+
+	s, err := NewService(mockRepository)
+	assert.NoError(t, err)
+
+	n, err := s.PurgeProducts(WithActor(ctx, SystemActor), retention)
+	assert.NoError(t, err)
+	assert.Equal(t, PurgeProductsBatchSize+7, n)
+}
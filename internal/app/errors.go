@@ -0,0 +1,19 @@
+package app
+
+import "errors"
+
+// Domain errors shared by the service and its adapters. Infrastructure
+// packages wrap their own failures with one of these so that callers can
+// classify an error with errors.Is without knowing where it came from.
+var (
+	// ErrNotFound is returned when the requested resource does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is returned when an operation clashes with the current state of a resource.
+	ErrConflict = errors.New("conflict")
+	// ErrValidation is returned when input does not satisfy the domain rules.
+	ErrValidation = errors.New("validation failed")
+	// ErrPreconditionFailed is returned when a conditional request does not match the current version of a resource.
+	ErrPreconditionFailed = errors.New("precondition failed")
+	// ErrUnavailable is returned when a dependency is temporarily unable to serve the request.
+	ErrUnavailable = errors.New("unavailable")
+)
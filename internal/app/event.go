@@ -0,0 +1,137 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// RelayEventsBatchSize is the number of outbox events claimed at once.
+	RelayEventsBatchSize = 20
+	// EventLeaseDuration is how long claimed events are left to the relay
+	// that claimed them. It outlasts the publication of a batch, otherwise
+	// another relay may publish the events of the batch again.
+	EventLeaseDuration = 5 * time.Minute
+)
+
+const (
+	// EventRetryBaseDelay is the delay before the first retry of an event
+	// that failed to publish. It doubles with every failed attempt up to
+	// EventRetryMaxDelay.
+	EventRetryBaseDelay = time.Second
+	EventRetryMaxDelay  = time.Hour
+)
+
+// EventType is the kind of change a domain event announces.
+type EventType string
+
+const (
+	ProductCreated EventType = "product.created"
+	ProductUpdated EventType = "product.updated"
+	ProductDeleted EventType = "product.deleted"
+)
+
+// Event is a domain event announcing a change of a product to the downstream
+// systems. It is written to the outbox in the transaction of the change and
+// published afterwards. Payload is the state of the product as the change
+// left it. Events of a product are published in the order of its versions.
+type Event struct {
+	ID               uuid.UUID
+	Type             EventType
+	AggregateID      uuid.UUID
+	AggregateVersion int64
+	Payload          map[string]any
+	OccurredAt       time.Time
+
+	// Attempts is the number of failed attempts to publish the event.
+	Attempts int
+}
+
+// NewProductEvent returns the event announcing the change of the product
+// that action made. Restorations and reverts are announced as updates, the
+// product being available again in the state they left it in.
+func NewProductEvent(action RevisionAction, p *Product) *Event {
+	t := ProductUpdated
+	switch action {
+	case RevisionCreated:
+		t = ProductCreated
+	case RevisionDeleted:
+		t = ProductDeleted
+	}
+
+	return &Event{
+		ID:               uuid.New(),
+		Type:             t,
+		AggregateID:      p.ID,
+		AggregateVersion: p.Version,
+		Payload:          productStateDocument(p),
+		OccurredAt:       time.Now().UTC(),
+	}
+}
+
+// EventPublisher delivers events to the downstream systems. Delivery is at
+// least once: an event whose publication failed, or whose success could not
+// be recorded, is published again, so consumers must be idempotent, e.g. by
+// ignoring events of a product older than its last seen version.
+type EventPublisher interface {
+	Publish(ctx context.Context, e Event) error
+}
+
+// RelayEvents publishes the pending events of the outbox with p. Events are
+// leased rather than locked so that no transaction stays open while they are
+// published, and each event is marked on its own as soon as it is published.
+// An event that fails to publish is retried with an exponential backoff, the
+// later events of its product wait for it. It returns the number of events it
+// published.
+func (s Service) RelayEvents(ctx context.Context, p EventPublisher) (int, error) {
+	var total int
+	for {
+		now := time.Now().UTC()
+
+		events, err := s.repository.ClaimOutboxEvents(ctx, now, now.Add(EventLeaseDuration), RelayEventsBatchSize)
+		if err != nil {
+			return total, fmt.Errorf("failed to claim outbox events: %w", err)
+		}
+
+		var n int
+		for _, e := range events {
+			pubErr := p.Publish(ctx, e)
+			if pubErr != nil {
+				retryAt := time.Now().UTC().Add(eventRetryDelay(e.Attempts + 1))
+
+				err := s.repository.MarkOutboxEventFailed(ctx, e.ID, retryAt, pubErr.Error())
+				if err != nil {
+					return total, fmt.Errorf("failed to mark outbox event as failed: %w", err)
+				}
+				continue
+			}
+
+			err := s.repository.MarkOutboxEventPublished(ctx, e.ID, time.Now().UTC())
+			if err != nil {
+				return total, fmt.Errorf("failed to mark outbox event as published: %w", err)
+			}
+			n++
+			total++
+		}
+
+		// A published event may have unblocked the next event of its
+		// product, which the following batch picks up.
+		if n == 0 {
+			return total, nil
+		}
+	}
+}
+
+// eventRetryDelay returns the delay before the next attempt to publish an
+// event after its attempts-th failure.
+func eventRetryDelay(attempts int) time.Duration {
+	delay := EventRetryBaseDelay
+	for i := 1; i < attempts && delay < EventRetryMaxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, EventRetryMaxDelay)
+}
@@ -0,0 +1,150 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// publisherFunc adapts a function to the EventPublisher interface.
+type publisherFunc func(ctx context.Context, e Event) error
+
+func (f publisherFunc) Publish(ctx context.Context, e Event) error {
+	return f(ctx, e)
+}
+
+func TestNewProductEvent(t *testing.T) {
+	p := &Product{ID: uuid.New(), Name: "Boots", Price: NewMoney(10000, "USD"), Status: ProductDraft, Version: 3}
+
+	tests := []struct {
+		action  RevisionAction
+		expType EventType
+	}{
+		{action: RevisionCreated, expType: ProductCreated},
+		{action: RevisionUpdated, expType: ProductUpdated},
+		{action: RevisionReverted, expType: ProductUpdated},
+		{action: RevisionRestored, expType: ProductUpdated},
+		{action: RevisionDeleted, expType: ProductDeleted},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.action), func(t *testing.T) {
+			e := NewProductEvent(tt.action, p)
+
+			assert.Equal(t, tt.expType, e.Type)
+			assert.Equal(t, p.ID, e.AggregateID)
+			assert.Equal(t, int64(3), e.AggregateVersion)
+			assert.Equal(t, "Boots", e.Payload["name"])
+			assert.Equal(t, p.ID.String(), e.Payload["id"])
+			assert.False(t, e.OccurredAt.IsZero())
+		})
+	}
+}
+
+func TestEventRetryDelay(t *testing.T) {
+	assert.Equal(t, time.Second, eventRetryDelay(1))
+	assert.Equal(t, 2*time.Second, eventRetryDelay(2))
+	assert.Equal(t, 8*time.Second, eventRetryDelay(4))
+	assert.Equal(t, EventRetryMaxDelay, eventRetryDelay(13))
+	assert.Equal(t, EventRetryMaxDelay, eventRetryDelay(1000))
+}
+
+func TestService_RelayEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+
+	first := Event{ID: uuid.New(), Type: ProductCreated, AggregateID: uuid.New()}
+	failing := Event{ID: uuid.New(), Type: ProductUpdated, AggregateID: uuid.New(), Attempts: 2}
+	last := Event{ID: uuid.New(), Type: ProductDeleted, AggregateID: uuid.New()}
+
+	publisher := publisherFunc(func(_ context.Context, e Event) error {
+		if e.ID == failing.ID {
+			return errors.New("broker unavailable")
+		}
+		return nil
+	})
+
+	// Claims are leased for EventLeaseDuration, outside of any transaction:
+	// WithinTx is never expected.
+	expectClaim := func(m *Mockrepository, events []Event) *gomock.Call {
+		return m.EXPECT().
+			ClaimOutboxEvents(gomock.Any(), gomock.Any(), gomock.Any(), RelayEventsBatchSize).
+			Do(func(_ context.Context, now, leaseUntil time.Time, _ int) {
+				assert.Equal(t, EventLeaseDuration, leaseUntil.Sub(now))
+			}).
+			Return(events, nil)
+	}
+
+	t.Run("publication fails partway through a batch", func(t *testing.T) {
+		mockRepository := NewMockrepository(ctrl)
+
+		gomock.InOrder(
+			expectClaim(mockRepository, []Event{first, failing, last}),
+			mockRepository.EXPECT().
+				MarkOutboxEventPublished(gomock.Any(), first.ID, gomock.Any()).
+				Return(nil),
+			mockRepository.EXPECT().
+				MarkOutboxEventFailed(gomock.Any(), failing.ID, gomock.Any(), "broker unavailable").
+				Do(func(_ context.Context, _ uuid.UUID, retryAt time.Time, _ string) {
+					// The third attempt failed.
+					assert.WithinDuration(t, time.Now().Add(4*time.Second), retryAt, time.Second)
+				}).
+				Return(nil),
+			mockRepository.EXPECT().
+				MarkOutboxEventPublished(gomock.Any(), last.ID, gomock.Any()).
+				Return(nil),
+			expectClaim(mockRepository, nil),
+		)
+
+		s, err := NewService(mockRepository)
+		assert.NoError(t, err)
+
+		n, err := s.RelayEvents(ctx, publisher)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+	})
+
+	t.Run("event cannot be marked partway through a batch", func(t *testing.T) {
+		mockRepository := NewMockrepository(ctrl)
+
+		// The first event stays marked as published, the last one is left
+		// to be claimed again once its lease ends.
+		gomock.InOrder(
+			expectClaim(mockRepository, []Event{first, last}),
+			mockRepository.EXPECT().
+				MarkOutboxEventPublished(gomock.Any(), first.ID, gomock.Any()).
+				Return(nil),
+			mockRepository.EXPECT().
+				MarkOutboxEventPublished(gomock.Any(), last.ID, gomock.Any()).
+				Return(errors.New("connection refused")),
+		)
+
+		s, err := NewService(mockRepository)
+		assert.NoError(t, err)
+
+		n, err := s.RelayEvents(ctx, publisher)
+		assert.Equal(t, fmt.Errorf("failed to mark outbox event as published: %w", errors.New("connection refused")), err)
+		assert.Equal(t, 1, n)
+	})
+
+	t.Run("events cannot be claimed", func(t *testing.T) {
+		mockRepository := NewMockrepository(ctrl)
+
+		mockRepository.EXPECT().
+			ClaimOutboxEvents(gomock.Any(), gomock.Any(), gomock.Any(), RelayEventsBatchSize).
+			Return(nil, errors.New("connection refused"))
+
+		s, err := NewService(mockRepository)
+		assert.NoError(t, err)
+
+		n, err := s.RelayEvents(ctx, publisher)
+		assert.Equal(t, fmt.Errorf("failed to claim outbox events: %w", errors.New("connection refused")), err)
+		assert.Zero(t, n)
+	})
+}
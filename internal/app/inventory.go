@@ -0,0 +1,420 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Inventory limits.
+const (
+	StockAdjustmentNoteMaxLength = 500
+	ReservationItemsMax          = 100
+	DefaultReservationTTL        = 15 * time.Minute
+	MaxReservationTTL            = 24 * time.Hour
+	// StockAdjustmentsLimit is the number of adjustments returned by
+	// GetStockAdjustments, the most recent ones.
+	StockAdjustmentsLimit = 100
+	// ExpireReservationsBatchSize bounds the reservations expired in one
+	// transaction so that a backlog does not hold the inventory locks for long.
+	ExpireReservationsBatchSize = 100
+)
+
+// Inventory is the stock of a product. Reserved units are held by pending
+// reservations: they are still on hand but cannot be reserved again. A
+// product whose stock was never adjusted has an empty inventory with a zero
+// UpdatedAt.
+type Inventory struct {
+	ProductID uuid.UUID
+	OnHand    int
+	Reserved  int
+	UpdatedAt time.Time
+}
+
+// Available returns the number of units that can be reserved.
+func (i Inventory) Available() int {
+	return i.OnHand - i.Reserved
+}
+
+// StockAdjustmentReason tells why the stock on hand of a product changed.
+type StockAdjustmentReason string
+
+const (
+	StockAdjustmentReceived   StockAdjustmentReason = "received"
+	StockAdjustmentReturned   StockAdjustmentReason = "returned"
+	StockAdjustmentDamaged    StockAdjustmentReason = "damaged"
+	StockAdjustmentLost       StockAdjustmentReason = "lost"
+	StockAdjustmentCorrection StockAdjustmentReason = "correction"
+	// StockAdjustmentSale is recorded when a reservation is committed. It
+	// cannot be used to adjust the stock directly.
+	StockAdjustmentSale StockAdjustmentReason = "sale"
+)
+
+// adjustableReasons are the reasons clients can adjust the stock for.
+var adjustableReasons = []StockAdjustmentReason{
+	StockAdjustmentReceived,
+	StockAdjustmentReturned,
+	StockAdjustmentDamaged,
+	StockAdjustmentLost,
+	StockAdjustmentCorrection,
+}
+
+// StockAdjustment is a change of the stock on hand of a product. Sales record
+// the reservation they committed, other adjustments have a nil ReservationID.
+type StockAdjustment struct {
+	ID            uuid.UUID
+	ProductID     uuid.UUID
+	Delta         int
+	Reason        StockAdjustmentReason
+	Note          string
+	ReservationID uuid.UUID
+	CreatedAt     time.Time
+}
+
+func NewStockAdjustment(productID uuid.UUID, delta int, reason StockAdjustmentReason, note string) *StockAdjustment {
+	return &StockAdjustment{
+		ID:        uuid.New(),
+		ProductID: productID,
+		Delta:     delta,
+		Reason:    reason,
+		Note:      note,
+		CreatedAt: time.Now().UTC(),
+	}
+}
+
+// ReservationStatus is the state of a reservation. Pending is the only state
+// a reservation can leave.
+type ReservationStatus string
+
+const (
+	ReservationPending   ReservationStatus = "pending"
+	ReservationCommitted ReservationStatus = "committed"
+	ReservationReleased  ReservationStatus = "released"
+	ReservationExpired   ReservationStatus = "expired"
+)
+
+// Reservation holds stock of one or more products for a checkout. Committing
+// it takes the units out of the stock on hand, releasing or letting it expire
+// makes them available again.
+type Reservation struct {
+	ID        uuid.UUID
+	Status    ReservationStatus
+	Items     []ReservationItem
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type ReservationItem struct {
+	ProductID uuid.UUID
+	Quantity  int
+}
+
+func NewReservation(items []ReservationItem, ttl time.Duration) *Reservation {
+	now := time.Now().UTC()
+
+	return &Reservation{
+		ID:        uuid.New(),
+		Status:    ReservationPending,
+		Items:     items,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Commit moves a pending reservation that has not expired to committed.
+func (r *Reservation) Commit(now time.Time) error {
+	if r.Status != ReservationPending {
+		return fmt.Errorf("%w: reservation %s is %s", ErrConflict, r.ID, r.Status)
+	}
+	if !now.Before(r.ExpiresAt) {
+		return fmt.Errorf("%w: reservation %s has expired", ErrConflict, r.ID)
+	}
+
+	r.Status = ReservationCommitted
+	r.UpdatedAt = now
+
+	return nil
+}
+
+// Release moves a pending reservation to released. A reservation past its
+// expiry that was not expired yet can still be released.
+func (r *Reservation) Release(now time.Time) error {
+	if r.Status != ReservationPending {
+		return fmt.Errorf("%w: reservation %s is %s", ErrConflict, r.ID, r.Status)
+	}
+
+	r.Status = ReservationReleased
+	r.UpdatedAt = now
+
+	return nil
+}
+
+// StockShortage describes a product that does not have enough stock available
+// for a reservation or an adjustment.
+type StockShortage struct {
+	ProductID uuid.UUID
+	Requested int
+	Available int
+}
+
+// InsufficientStockError is returned when a reservation or an adjustment
+// needs more units than are available. It matches ErrConflict with errors.Is.
+type InsufficientStockError struct {
+	Shortages []StockShortage
+}
+
+func (e *InsufficientStockError) Error() string {
+	msgs := make([]string, 0, len(e.Shortages))
+	for _, s := range e.Shortages {
+		msgs = append(msgs, fmt.Sprintf("product %s: %d requested, %d available", s.ProductID, s.Requested, s.Available))
+	}
+
+	return fmt.Sprintf("%s: insufficient stock: %s", ErrConflict, strings.Join(msgs, "; "))
+}
+
+func (e *InsufficientStockError) Unwrap() error {
+	return ErrConflict
+}
+
+func validateStockAdjustment(dto AdjustStockDTO) error {
+	var v violations
+
+	if dto.Delta == 0 {
+		v.add("delta", "must not be zero")
+	}
+
+	if !slices.Contains(adjustableReasons, dto.Reason) {
+		v.add("reason", "must be one of received, returned, damaged, lost or correction")
+	}
+
+	switch {
+	case !utf8.ValidString(dto.Note):
+		v.add("note", "must be valid UTF-8")
+	case utf8.RuneCountInString(dto.Note) > StockAdjustmentNoteMaxLength:
+		v.add("note", "must be at most %d characters long", StockAdjustmentNoteMaxLength)
+	}
+
+	return v.err()
+}
+
+func validateReservation(dto CreateReservationDTO) error {
+	var v violations
+
+	switch {
+	case len(dto.Items) == 0:
+		v.add("items", "must not be empty")
+	case len(dto.Items) > ReservationItemsMax:
+		v.add("items", "must not contain more than %d items", ReservationItemsMax)
+	}
+
+	seen := make(map[uuid.UUID]bool, len(dto.Items))
+	for i, item := range dto.Items {
+		switch {
+		case item.ProductID == uuid.Nil:
+			v.add(fmt.Sprintf("items[%d].product_id", i), "must not be empty")
+		case seen[item.ProductID]:
+			v.add(fmt.Sprintf("items[%d].product_id", i), "must not repeat the product of another item")
+		}
+		seen[item.ProductID] = true
+
+		if item.Quantity <= 0 {
+			v.add(fmt.Sprintf("items[%d].quantity", i), "must be positive")
+		}
+	}
+
+	if dto.TTL < 0 || dto.TTL > MaxReservationTTL {
+		v.add("ttl_seconds", "must be between 0 and %d", int(MaxReservationTTL/time.Second))
+	}
+
+	return v.err()
+}
+
+// GetInventory returns the stock of a product.
+func (s Service) GetInventory(ctx context.Context, productID uuid.UUID) (*Inventory, error) {
+	inv, err := s.repository.GetInventory(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inventory: %w", err)
+	}
+
+	return inv, nil
+}
+
+// AdjustStock changes the stock on hand of a product and records why. The
+// stock on hand cannot drop below the reserved units.
+func (s Service) AdjustStock(ctx context.Context, dto AdjustStockDTO) (*Inventory, error) {
+	dto.Note = strings.TrimSpace(dto.Note)
+
+	err := validateStockAdjustment(dto)
+	if err != nil {
+		return nil, fmt.Errorf("invalid stock adjustment: %w", err)
+	}
+
+	a := NewStockAdjustment(dto.ProductID, dto.Delta, dto.Reason, dto.Note)
+
+	var inv *Inventory
+	err = s.repository.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		inv, err = s.repository.AdjustStock(ctx, a)
+		if err != nil {
+			return fmt.Errorf("failed to adjust stock: %w", err)
+		}
+
+		return s.audit(ctx, AuditStockAdjusted, AuditInventory, a.ProductID, nil, inv)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return inv, nil
+}
+
+// GetStockAdjustments returns the most recent stock adjustments of a product,
+// the most recent first.
+func (s Service) GetStockAdjustments(ctx context.Context, productID uuid.UUID) ([]StockAdjustment, error) {
+	_, err := s.repository.GetProduct(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	adjustments, err := s.repository.GetStockAdjustments(ctx, productID, StockAdjustmentsLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock adjustments: %w", err)
+	}
+
+	return adjustments, nil
+}
+
+// CreateReservation reserves stock of every item or of none of them. It
+// returns an *InsufficientStockError listing the items that cannot be
+// reserved. A zero TTL is DefaultReservationTTL.
+func (s Service) CreateReservation(ctx context.Context, dto CreateReservationDTO) (*Reservation, error) {
+	err := validateReservation(dto)
+	if err != nil {
+		return nil, fmt.Errorf("invalid reservation: %w", err)
+	}
+
+	if dto.TTL == 0 {
+		dto.TTL = DefaultReservationTTL
+	}
+
+	r := NewReservation(dto.Items, dto.TTL)
+
+	err = s.repository.WithinTx(ctx, func(ctx context.Context) error {
+		err := s.repository.CreateReservation(ctx, r)
+		if err != nil {
+			return fmt.Errorf("failed to create reservation: %w", err)
+		}
+
+		return s.audit(ctx, AuditCreated, AuditReservation, r.ID, nil, r)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (s Service) GetReservation(ctx context.Context, reservationID uuid.UUID) (*Reservation, error) {
+	r, err := s.repository.GetReservation(ctx, reservationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reservation: %w", err)
+	}
+
+	return r, nil
+}
+
+// CommitReservation takes the reserved units out of the stock on hand and
+// records them as sold.
+func (s Service) CommitReservation(ctx context.Context, reservationID uuid.UUID) (*Reservation, error) {
+	return s.transitionReservation(ctx, reservationID, AuditCommitted, (*Reservation).Commit)
+}
+
+// ReleaseReservation makes the reserved units available again.
+func (s Service) ReleaseReservation(ctx context.Context, reservationID uuid.UUID) (*Reservation, error) {
+	return s.transitionReservation(ctx, reservationID, AuditReleased, (*Reservation).Release)
+}
+
+// transitionReservation applies a transition to a pending reservation. The
+// repository stores it only if the reservation still is pending, so two
+// concurrent transitions cannot both succeed.
+func (s Service) transitionReservation(ctx context.Context, reservationID uuid.UUID, action AuditAction, transition func(*Reservation, time.Time) error) (*Reservation, error) {
+	r, err := s.repository.GetReservation(ctx, reservationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reservation: %w", err)
+	}
+
+	before := *r
+	err = transition(r, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.repository.WithinTx(ctx, func(ctx context.Context) error {
+		err := s.repository.UpdateReservationStatus(ctx, r)
+		if err != nil {
+			return fmt.Errorf("failed to update reservation: %w", err)
+		}
+
+		return s.audit(ctx, action, AuditReservation, r.ID, &before, r)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// ExpireReservations expires the pending reservations past their expiry and
+// makes their units available again. It returns the number of reservations
+// it expired.
+func (s Service) ExpireReservations(ctx context.Context) (int, error) {
+	var total int
+	for {
+		var n int
+		err := s.repository.WithinTx(ctx, func(ctx context.Context) error {
+			expired, err := s.repository.ExpireReservations(ctx, time.Now().UTC(), ExpireReservationsBatchSize)
+			if err != nil {
+				return fmt.Errorf("failed to expire reservations: %w", err)
+			}
+
+			for _, id := range expired {
+				err := s.audit(ctx, AuditExpired, AuditReservation, id, nil, nil)
+				if err != nil {
+					return err
+				}
+			}
+
+			n = len(expired)
+			return nil
+		})
+		if err != nil {
+			return total, err
+		}
+
+		total += n
+		if n < ExpireReservationsBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// AdjustStockDTO changes the stock on hand by Delta, which is negative for
+// units that left the stock.
+type AdjustStockDTO struct {
+	ProductID uuid.UUID
+	Delta     int
+	Reason    StockAdjustmentReason
+	Note      string
+}
+
+type CreateReservationDTO struct {
+	Items []ReservationItem
+	TTL   time.Duration
+}
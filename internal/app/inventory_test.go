@@ -0,0 +1,294 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestReservation_Commit(t *testing.T) {
+	now := time.Now().UTC()
+
+	tests := []struct {
+		name      string
+		status    ReservationStatus
+		expiresAt time.Time
+		expStatus ReservationStatus
+		expErr    bool
+	}{
+		{name: "pending reservation", status: ReservationPending, expiresAt: now.Add(time.Minute), expStatus: ReservationCommitted},
+		{name: "expired reservation", status: ReservationPending, expiresAt: now, expStatus: ReservationPending, expErr: true},
+		{name: "released reservation", status: ReservationReleased, expiresAt: now.Add(time.Minute), expStatus: ReservationReleased, expErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Reservation{ID: uuid.New(), Status: tt.status, ExpiresAt: tt.expiresAt}
+
+			err := r.Commit(now)
+			if tt.expErr {
+				assert.True(t, errors.Is(err, ErrConflict))
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, now, r.UpdatedAt)
+			}
+			assert.Equal(t, tt.expStatus, r.Status)
+		})
+	}
+}
+
+func TestReservation_Release(t *testing.T) {
+	now := time.Now().UTC()
+
+	r := &Reservation{ID: uuid.New(), Status: ReservationPending, ExpiresAt: now.Add(-time.Minute)}
+	assert.NoError(t, r.Release(now))
+	assert.Equal(t, ReservationReleased, r.Status)
+
+	err := r.Release(now)
+	assert.Equal(t, fmt.Errorf("%w: reservation %s is released", ErrConflict, r.ID), err)
+}
+
+func TestService_AdjustStock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+
+	productID := uuid.New()
+	inventory := &Inventory{ProductID: productID, OnHand: 12, Reserved: 2}
+	shortage := &InsufficientStockError{Shortages: []StockShortage{{ProductID: productID, Requested: 20, Available: 10}}}
+
+	tests := []struct {
+		name                  string
+		dto                   AdjustStockDTO
+		expRepoAdjustStockErr error
+		expErr                error
+	}{
+		{
+			name: "stock was adjusted successfully",
+			dto:  AdjustStockDTO{ProductID: productID, Delta: 10, Reason: StockAdjustmentReceived, Note: " PO-1234 "},
+		},
+		{
+			name: "invalid adjustment",
+			dto:  AdjustStockDTO{ProductID: productID, Reason: StockAdjustmentSale},
+			expErr: fmt.Errorf("invalid stock adjustment: %w", &ValidationError{Violations: []Violation{
+				{Field: "delta", Message: "must not be zero"},
+				{Field: "reason", Message: "must be one of received, returned, damaged, lost or correction"},
+			}}),
+		},
+		{
+			name:                  "not enough stock on hand",
+			dto:                   AdjustStockDTO{ProductID: productID, Delta: -20, Reason: StockAdjustmentDamaged},
+			expRepoAdjustStockErr: shortage,
+			expErr:                fmt.Errorf("failed to adjust stock: %w", shortage),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mock expectations
+			mockRepository := NewMockrepository(ctrl)
+
+			if !errors.Is(tt.expErr, ErrValidation) {
+				expectAudited(mockRepository, tt.expErr == nil)
+				mockRepository.EXPECT().
+					AdjustStock(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, a *StockAdjustment) (*Inventory, error) {
+						assert.Equal(t, tt.dto.Delta, a.Delta)
+						assert.Equal(t, strings.TrimSpace(tt.dto.Note), a.Note)
+						if tt.expRepoAdjustStockErr != nil {
+							return nil, tt.expRepoAdjustStockErr
+						}
+						return inventory, nil
+					})
+			}
+
+			// Exercise
+			s, err := NewService(mockRepository)
+			assert.NoError(t, err)
+
+			inv, err := s.AdjustStock(ctx, tt.dto)
+			if tt.expErr != nil {
+				assert.Equal(t, tt.expErr, err)
+				assert.Nil(t, inv)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, inventory, inv)
+			}
+		})
+	}
+}
+
+func TestService_CreateReservation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+
+	productID := uuid.New()
+	shortage := &InsufficientStockError{Shortages: []StockShortage{{ProductID: productID, Requested: 3, Available: 1}}}
+
+	tests := []struct {
+		name                        string
+		dto                         CreateReservationDTO
+		expRepoCreateReservationErr error
+		expTTL                      time.Duration
+		expErr                      error
+	}{
+		{
+			name:   "reservation with the default TTL",
+			dto:    CreateReservationDTO{Items: []ReservationItem{{ProductID: productID, Quantity: 1}}},
+			expTTL: DefaultReservationTTL,
+		},
+		{
+			name:   "reservation with a TTL",
+			dto:    CreateReservationDTO{Items: []ReservationItem{{ProductID: productID, Quantity: 1}}, TTL: time.Minute},
+			expTTL: time.Minute,
+		},
+		{
+			name: "invalid reservation",
+			dto: CreateReservationDTO{
+				Items: []ReservationItem{{ProductID: productID, Quantity: 1}, {ProductID: productID}, {Quantity: 2}},
+				TTL:   48 * time.Hour,
+			},
+			expErr: fmt.Errorf("invalid reservation: %w", &ValidationError{Violations: []Violation{
+				{Field: "items[1].product_id", Message: "must not repeat the product of another item"},
+				{Field: "items[1].quantity", Message: "must be positive"},
+				{Field: "items[2].product_id", Message: "must not be empty"},
+				{Field: "ttl_seconds", Message: "must be between 0 and 86400"},
+			}}),
+		},
+		{
+			name:                        "not enough stock available",
+			dto:                         CreateReservationDTO{Items: []ReservationItem{{ProductID: productID, Quantity: 3}}},
+			expRepoCreateReservationErr: shortage,
+			expErr:                      fmt.Errorf("failed to create reservation: %w", shortage),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mock expectations
+			mockRepository := NewMockrepository(ctrl)
+
+			if !errors.Is(tt.expErr, ErrValidation) {
+				expectAudited(mockRepository, tt.expErr == nil)
+				mockRepository.EXPECT().
+					CreateReservation(gomock.Any(), gomock.Any()).
+					Return(tt.expRepoCreateReservationErr)
+			}
+
+			// Exercise
+			s, err := NewService(mockRepository)
+			assert.NoError(t, err)
+
+			r, err := s.CreateReservation(ctx, tt.dto)
+			if tt.expErr != nil {
+				assert.Equal(t, tt.expErr, err)
+				assert.Nil(t, r)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, ReservationPending, r.Status)
+				assert.Equal(t, tt.dto.Items, r.Items)
+				assert.Equal(t, tt.expTTL, r.ExpiresAt.Sub(r.CreatedAt))
+			}
+		})
+	}
+}
+
+func TestService_CommitReservation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+
+	reservationID := uuid.New()
+
+	tests := []struct {
+		name                              string
+		reservation                       Reservation
+		expRepoUpdateReservationStatusErr error
+		expErr                            error
+	}{
+		{
+			name:        "reservation was committed successfully",
+			reservation: Reservation{ID: reservationID, Status: ReservationPending, ExpiresAt: time.Now().Add(time.Minute)},
+		},
+		{
+			name:        "reservation has expired",
+			reservation: Reservation{ID: reservationID, Status: ReservationPending, ExpiresAt: time.Now().Add(-time.Minute)},
+			expErr:      fmt.Errorf("%w: reservation %s has expired", ErrConflict, reservationID),
+		},
+		{
+			name:                              "reservation released concurrently",
+			reservation:                       Reservation{ID: reservationID, Status: ReservationPending, ExpiresAt: time.Now().Add(time.Minute)},
+			expRepoUpdateReservationStatusErr: ErrConflict,
+			expErr:                            fmt.Errorf("failed to update reservation: %w", ErrConflict),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mock expectations
+			mockRepository := NewMockrepository(ctrl)
+
+			r := tt.reservation
+			mockRepository.EXPECT().
+				GetReservation(gomock.Any(), reservationID).
+				Return(&r, nil)
+
+			if tt.expErr == nil || tt.expRepoUpdateReservationStatusErr != nil {
+				expectAudited(mockRepository, tt.expErr == nil)
+				mockRepository.EXPECT().
+					UpdateReservationStatus(gomock.Any(), gomock.Any()).
+					Return(tt.expRepoUpdateReservationStatusErr)
+			}
+
+			// Exercise
+			s, err := NewService(mockRepository)
+			assert.NoError(t, err)
+
+			committed, err := s.CommitReservation(ctx, reservationID)
+			if tt.expErr != nil {
+				assert.Equal(t, tt.expErr, err)
+				assert.Nil(t, committed)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, ReservationCommitted, committed.Status)
+			}
+		})
+	}
+}
+
+func TestService_ExpireReservations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+
+	mockRepository := NewMockrepository(ctrl)
+	mockRepository.EXPECT().
+		WithinTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		}).
+		Times(2)
+	gomock.InOrder(
+		mockRepository.EXPECT().
+			ExpireReservations(gomock.Any(), gomock.Any(), ExpireReservationsBatchSize).
+			Return(newIDs(ExpireReservationsBatchSize), nil),
+		mockRepository.EXPECT().
+			ExpireReservations(gomock.Any(), gomock.Any(), ExpireReservationsBatchSize).
+			Return(newIDs(3), nil),
+	)
+	mockRepository.EXPECT().
+		CreateAuditEntry(gomock.Any(), gomock.Any()).
+		Return(nil).
+		Times(ExpireReservationsBatchSize + 3)
+
+	s, err := NewService(mockRepository)
+	assert.NoError(t, err)
+
+	n, err := s.ExpireReservations(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, ExpireReservationsBatchSize+3, n)
+}
@@ -0,0 +1,135 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is used when a client does not specify a currency.
+const DefaultCurrency = "USD"
+
+// currencyExponents maps the supported ISO 4217 currency codes to the number
+// of digits of their minor unit. Prices are stored as NUMERIC(12, 2), so only
+// currencies with at most two minor digits can be supported.
+var currencyExponents = map[string]int{
+	"AED": 2, "AUD": 2, "BRL": 2, "CAD": 2, "CHF": 2, "CNY": 2, "CZK": 2,
+	"DKK": 2, "EUR": 2, "GBP": 2, "HKD": 2, "HUF": 2, "IDR": 2, "ILS": 2,
+	"INR": 2, "ISK": 0, "JPY": 0, "KRW": 0, "MXN": 2, "NOK": 2, "NZD": 2,
+	"PHP": 2, "PLN": 2, "RON": 2, "SAR": 2, "SEK": 2, "SGD": 2, "THB": 2,
+	"TRY": 2, "TWD": 2, "USD": 2, "ZAR": 2,
+}
+
+var (
+	errUnsupportedCurrency = errors.New("unsupported currency")
+	errInvalidAmount       = errors.New("invalid decimal amount")
+	errAmountPrecision     = errors.New("too many decimal places for currency")
+	errAmountOverflow      = errors.New("amount out of range")
+)
+
+// Money is an exact monetary amount expressed in the minor unit of its
+// currency, e.g. 1999 with currency USD is 19.99 USD.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// NewMoney returns a Money of amount minor units of currency.
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// ParseMoney parses a decimal string such as "19.99" in the given currency.
+// Trailing zeros beyond the precision of the currency are accepted, any other
+// extra digit is an error because it cannot be represented exactly.
+func ParseMoney(amount, currency string) (Money, error) {
+	exp, ok := CurrencyExponent(currency)
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %q", errUnsupportedCurrency, currency)
+	}
+
+	s := amount
+	negative := false
+	switch {
+	case strings.HasPrefix(s, "-"):
+		negative = true
+		s = s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+
+	intPart, fracPart, hasPoint := strings.Cut(s, ".")
+	if intPart == "" && fracPart == "" || hasPoint && fracPart == "" || !isDigits(intPart) || !isDigits(fracPart) {
+		return Money{}, fmt.Errorf("%w: %q", errInvalidAmount, amount)
+	}
+
+	trimmed := strings.TrimRight(fracPart, "0")
+	if len(trimmed) > exp {
+		return Money{}, fmt.Errorf("%w: %q has more than %d decimal places", errAmountPrecision, amount, exp)
+	}
+	fracPart = trimmed + strings.Repeat("0", exp-len(trimmed))
+
+	var minor int64
+	for _, c := range strings.TrimLeft(intPart, "0") + fracPart {
+		d := int64(c - '0')
+		if minor > (math.MaxInt64-d)/10 {
+			return Money{}, fmt.Errorf("%w: %q", errAmountOverflow, amount)
+		}
+		minor = minor*10 + d
+	}
+
+	if negative {
+		minor = -minor
+	}
+
+	return Money{Amount: minor, Currency: currency}, nil
+}
+
+// CurrencyExponent returns the number of minor unit digits of a supported currency.
+func CurrencyExponent(currency string) (int, bool) {
+	exp, ok := currencyExponents[currency]
+	return exp, ok
+}
+
+// Decimal formats the amount as a decimal string in major units, e.g. "19.99".
+func (m Money) Decimal() string {
+	exp, ok := CurrencyExponent(m.Currency)
+	if !ok {
+		exp = 2
+	}
+
+	digits := strconv.FormatInt(m.Amount, 10)
+
+	sign := ""
+	if m.Amount < 0 {
+		sign = "-"
+		digits = digits[1:]
+	}
+
+	if exp == 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+
+	return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
+
+// String returns the amount followed by its currency code, e.g. "19.99 USD".
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
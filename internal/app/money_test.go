@@ -0,0 +1,59 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   string
+		currency string
+		expMoney Money
+		expErr   string
+	}{
+		{name: "two decimals", amount: "19.99", currency: "USD", expMoney: NewMoney(1999, "USD")},
+		{name: "one decimal", amount: "19.9", currency: "EUR", expMoney: NewMoney(1990, "EUR")},
+		{name: "integer", amount: "100", currency: "USD", expMoney: NewMoney(10000, "USD")},
+		{name: "leading point", amount: ".5", currency: "USD", expMoney: NewMoney(50, "USD")},
+		{name: "leading zeros", amount: "007.10", currency: "USD", expMoney: NewMoney(710, "USD")},
+		{name: "negative", amount: "-0.01", currency: "USD", expMoney: NewMoney(-1, "USD")},
+		{name: "explicit plus sign", amount: "+1", currency: "USD", expMoney: NewMoney(100, "USD")},
+		{name: "no minor unit", amount: "1500", currency: "JPY", expMoney: NewMoney(1500, "JPY")},
+		{name: "trailing zeros beyond precision", amount: "1500.00", currency: "JPY", expMoney: NewMoney(1500, "JPY")},
+		{name: "too many decimals", amount: "19.999", currency: "USD", expErr: `too many decimal places for currency: "19.999" has more than 2 decimal places`},
+		{name: "decimals on currency without minor unit", amount: "1500.5", currency: "JPY", expErr: `too many decimal places for currency: "1500.5" has more than 0 decimal places`},
+		{name: "empty", amount: "", currency: "USD", expErr: `invalid decimal amount: ""`},
+		{name: "lone point", amount: ".", currency: "USD", expErr: `invalid decimal amount: "."`},
+		{name: "trailing point", amount: "1.", currency: "USD", expErr: `invalid decimal amount: "1."`},
+		{name: "exponent", amount: "1e3", currency: "USD", expErr: `invalid decimal amount: "1e3"`},
+		{name: "not a number", amount: "abc", currency: "USD", expErr: `invalid decimal amount: "abc"`},
+		{name: "NaN", amount: "NaN", currency: "USD", expErr: `invalid decimal amount: "NaN"`},
+		{name: "overflow", amount: "99999999999999999999", currency: "USD", expErr: `amount out of range: "99999999999999999999"`},
+		{name: "unsupported currency", amount: "1", currency: "usd", expErr: `unsupported currency: "usd"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := ParseMoney(tt.amount, tt.currency)
+			if tt.expErr != "" {
+				assert.EqualError(t, err, tt.expErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expMoney, m)
+		})
+	}
+}
+
+func TestMoney_Decimal(t *testing.T) {
+	assert.Equal(t, "19.99", NewMoney(1999, "USD").Decimal())
+	assert.Equal(t, "0.05", NewMoney(5, "USD").Decimal())
+	assert.Equal(t, "0.00", NewMoney(0, "USD").Decimal())
+	assert.Equal(t, "-0.01", NewMoney(-1, "USD").Decimal())
+	assert.Equal(t, "1500", NewMoney(1500, "JPY").Decimal())
+	assert.Equal(t, "19.99 USD", NewMoney(1999, "USD").String())
+}
@@ -0,0 +1,141 @@
+package app
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is a position in the product list ordered by Sort. Keys holds the
+// sort keys of the product the cursor points at, followed by its ID.
+type Cursor struct {
+	Sort Sort
+	Keys []string
+}
+
+func cursorOf(p *Product, sort Sort) *Cursor {
+	keys := make([]string, 0, len(sort)+1)
+	for _, k := range sort {
+		keys = append(keys, sortFields[k.Field].value(p))
+	}
+	keys = append(keys, p.ID.String())
+
+	return &Cursor{Sort: sort, Keys: keys}
+}
+
+type cursorToken struct {
+	Sort string   `json:"s"`
+	Keys []string `json:"k"`
+}
+
+// Encode returns the cursor as an opaque URL-safe token.
+func (c Cursor) Encode() string {
+	// Marshalling a struct of strings cannot fail.
+	b, _ := json.Marshal(cursorToken{Sort: c.Sort.String(), Keys: c.Keys})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor parses a token produced by Cursor.Encode. The keys are
+// checked against the sort so that they can be passed to the database.
+func DecodeCursor(token string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	var t cursorToken
+	err = json.Unmarshal(raw, &t)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	sort, err := ParseSort(t.Sort)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	if len(t.Keys) != len(sort)+1 {
+		return Cursor{}, fmt.Errorf("%w: expected %d keys, got %d", ErrInvalidCursor, len(sort)+1, len(t.Keys))
+	}
+	for i, k := range sort {
+		if !sortFields[k.Field].valid(t.Keys[i]) {
+			return Cursor{}, fmt.Errorf("%w: invalid %s key %q", ErrInvalidCursor, k.Field, t.Keys[i])
+		}
+	}
+	_, err = uuid.Parse(t.Keys[len(sort)])
+	if err != nil {
+		return Cursor{}, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	return Cursor{Sort: sort, Keys: t.Keys}, nil
+}
+
+// formatCursorTime keeps the full precision of the database timestamps.
+func formatCursorTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func isCursorTime(s string) bool {
+	_, err := time.Parse(time.RFC3339Nano, s)
+	return err == nil
+}
+
+// ProductPage is a page of the product list. The cursors are nil when there
+// is no page in their direction. Total is only set when it was requested
+// because counting needs a scan of the whole list.
+type ProductPage struct {
+	Products   []*Product
+	NextCursor *Cursor
+	PrevCursor *Cursor
+	Total      *int
+}
+
+// newProductPage builds the page from products fetched with one extra row,
+// which tells whether more products exist beyond the page. When paging
+// backwards the extra row is the first one.
+func newProductPage(products []*Product, dto GetProductsDTO) *ProductPage {
+	more := len(products) > dto.Limit
+	if more {
+		if dto.Before != nil {
+			products = products[1:]
+		} else {
+			products = products[:dto.Limit]
+		}
+	}
+
+	page := &ProductPage{Products: products}
+	if len(products) == 0 {
+		return page
+	}
+
+	first, last := cursorOf(products[0], dto.Sort), cursorOf(products[len(products)-1], dto.Sort)
+
+	switch {
+	case dto.Before != nil:
+		page.NextCursor = last
+		if more {
+			page.PrevCursor = first
+		}
+	case dto.After != nil:
+		page.PrevCursor = first
+		if more {
+			page.NextCursor = last
+		}
+	default:
+		if dto.Offset > 0 {
+			page.PrevCursor = first
+		}
+		if more {
+			page.NextCursor = last
+		}
+	}
+
+	return page
+}
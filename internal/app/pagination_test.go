@@ -0,0 +1,120 @@
+package app
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursor_Encode(t *testing.T) {
+	p := &Product{
+		ID:        uuid.MustParse("9f9f4340-6bf9-4948-808c-ebf2dd604e2c"),
+		Name:      "Test Product",
+		Price:     NewMoney(1999, "USD"),
+		CreatedAt: time.Date(2024, 10, 2, 14, 28, 34, 123000000, time.UTC),
+	}
+	sort := Sort{{Field: SortByPrice}, {Field: SortByCreatedAt, Desc: true}}
+
+	c := cursorOf(p, sort)
+	assert.Equal(t, []string{"19.99", "2024-10-02T14:28:34.123Z", "9f9f4340-6bf9-4948-808c-ebf2dd604e2c"}, c.Keys)
+
+	decoded, err := DecodeCursor(c.Encode())
+	assert.NoError(t, err)
+	assert.Equal(t, *c, decoded)
+
+	tokens := []string{
+		"",
+		"not base64!",
+		base64.RawURLEncoding.EncodeToString([]byte(`not json`)),
+		base64.RawURLEncoding.EncodeToString([]byte(`{"s":"id","k":["9f9f4340-6bf9-4948-808c-ebf2dd604e2c"]}`)),
+		base64.RawURLEncoding.EncodeToString([]byte(`{"s":"price","k":["9f9f4340-6bf9-4948-808c-ebf2dd604e2c"]}`)),
+		base64.RawURLEncoding.EncodeToString([]byte(`{"s":"price","k":["1e3","9f9f4340-6bf9-4948-808c-ebf2dd604e2c"]}`)),
+		base64.RawURLEncoding.EncodeToString([]byte(`{"s":"-created_at","k":["yesterday","9f9f4340-6bf9-4948-808c-ebf2dd604e2c"]}`)),
+		base64.RawURLEncoding.EncodeToString([]byte(`{"s":"name","k":["Test Product","42"]}`)),
+	}
+	for _, token := range tokens {
+		_, err := DecodeCursor(token)
+		assert.ErrorIs(t, err, ErrInvalidCursor, token)
+	}
+}
+
+func TestNewProductPage(t *testing.T) {
+	now := time.Now()
+
+	products := make([]*Product, 4)
+	for i := range products {
+		products[i] = &Product{ID: uuid.New(), CreatedAt: now.Add(-time.Duration(i) * time.Second)}
+	}
+	cursor := cursorOf(&Product{ID: uuid.New(), CreatedAt: now.Add(time.Second)}, DefaultSort)
+
+	tests := []struct {
+		name        string
+		products    []*Product
+		dto         GetProductsDTO
+		expProducts []*Product
+		expNext     *Cursor
+		expPrev     *Cursor
+	}{
+		{
+			name:        "first page with more products",
+			products:    products[:3],
+			dto:         GetProductsDTO{Sort: DefaultSort, Limit: 2},
+			expProducts: products[:2],
+			expNext:     cursorOf(products[1], DefaultSort),
+		},
+		{
+			name:        "only page",
+			products:    products[:2],
+			dto:         GetProductsDTO{Sort: DefaultSort, Limit: 2},
+			expProducts: products[:2],
+		},
+		{
+			name:        "offset page",
+			products:    products[2:],
+			dto:         GetProductsDTO{Sort: DefaultSort, Limit: 2, Offset: 2},
+			expProducts: products[2:],
+			expPrev:     cursorOf(products[2], DefaultSort),
+		},
+		{
+			name:        "page after a cursor with more products",
+			products:    products[1:4],
+			dto:         GetProductsDTO{Sort: DefaultSort, Limit: 2, After: cursor},
+			expProducts: products[1:3],
+			expNext:     cursorOf(products[2], DefaultSort),
+			expPrev:     cursorOf(products[1], DefaultSort),
+		},
+		{
+			name:        "page before a cursor with more products",
+			products:    products[:3],
+			dto:         GetProductsDTO{Sort: DefaultSort, Limit: 2, Before: cursor},
+			expProducts: products[1:3],
+			expNext:     cursorOf(products[2], DefaultSort),
+			expPrev:     cursorOf(products[1], DefaultSort),
+		},
+		{
+			name:        "first page before a cursor",
+			products:    products[:2],
+			dto:         GetProductsDTO{Sort: DefaultSort, Limit: 2, Before: cursor},
+			expProducts: products[:2],
+			expNext:     cursorOf(products[1], DefaultSort),
+		},
+		{
+			name:     "empty page",
+			products: nil,
+			dto:      GetProductsDTO{Sort: DefaultSort, Limit: 2, After: cursor},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := newProductPage(tt.products, tt.dto)
+
+			assert.Equal(t, tt.expProducts, page.Products)
+			assert.Equal(t, tt.expNext, page.NextCursor)
+			assert.Equal(t, tt.expPrev, page.PrevCursor)
+		})
+	}
+}
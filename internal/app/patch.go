@@ -0,0 +1,415 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// PatchFormat identifies the format of a patch document.
+type PatchFormat string
+
+const (
+	// PatchFormatMergePatch is a JSON Merge Patch document (RFC 7396).
+	PatchFormatMergePatch PatchFormat = "merge-patch"
+	// PatchFormatJSONPatch is a JSON Patch document (RFC 6902).
+	PatchFormatJSONPatch PatchFormat = "json-patch"
+)
+
+// ErrInvalidPatch is returned when a patch document is malformed or refers
+// to a location that does not exist in the patched document.
+var ErrInvalidPatch = errors.New("invalid patch")
+
+// applyPatch applies a patch document in the given format to doc, which must
+// only contain values produced by decodeJSON. doc is never modified.
+func applyPatch(format PatchFormat, doc any, patch []byte) (any, error) {
+	switch format {
+	case PatchFormatMergePatch:
+		return applyMergePatch(doc, patch)
+	case PatchFormatJSONPatch:
+		return applyJSONPatch(doc, patch)
+	default:
+		return nil, fmt.Errorf("%w: unsupported format %q", ErrInvalidPatch, format)
+	}
+}
+
+// applyMergePatch implements the MergePatch algorithm of RFC 7396.
+func applyMergePatch(doc any, patch []byte) (any, error) {
+	p, err := decodeJSON(patch)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPatch, err)
+	}
+
+	return mergePatch(deepCopy(doc), p), nil
+}
+
+func mergePatch(target, patch any) any {
+	patchObj, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]any)
+	if !ok {
+		targetObj = map[string]any{}
+	}
+
+	for k, v := range patchObj {
+		if v == nil {
+			delete(targetObj, k)
+			continue
+		}
+		targetObj[k] = mergePatch(targetObj[k], v)
+	}
+
+	return targetObj
+}
+
+type jsonPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// applyJSONPatch implements RFC 6902. Operations are applied in order and the
+// whole patch fails if any of them fails.
+func applyJSONPatch(doc any, patch []byte) (any, error) {
+	var ops []jsonPatchOperation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, fmt.Errorf("%w: a JSON Patch must be an array of operations: %w", ErrInvalidPatch, err)
+	}
+
+	result := deepCopy(doc)
+	for i, op := range ops {
+		var err error
+		result, err = applyJSONPatchOperation(result, op)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d (%s): %w", i, op.Op, err)
+		}
+	}
+
+	return result, nil
+}
+
+func applyJSONPatchOperation(doc any, op jsonPatchOperation) (any, error) {
+	if op.Path == nil {
+		return nil, fmt.Errorf("%w: missing path", ErrInvalidPatch)
+	}
+	path, err := parseJSONPointer(*op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	value := func() (any, error) {
+		if len(op.Value) == 0 {
+			return nil, fmt.Errorf("%w: missing value", ErrInvalidPatch)
+		}
+		return decodeJSON(op.Value)
+	}
+	from := func() ([]string, error) {
+		if op.From == nil {
+			return nil, fmt.Errorf("%w: missing from", ErrInvalidPatch)
+		}
+		return parseJSONPointer(*op.From)
+	}
+
+	switch op.Op {
+	case "add":
+		v, err := value()
+		if err != nil {
+			return nil, err
+		}
+		return pointerAdd(doc, path, v)
+	case "remove":
+		doc, _, err := pointerRemove(doc, path)
+		return doc, err
+	case "replace":
+		v, err := value()
+		if err != nil {
+			return nil, err
+		}
+		doc, _, err = pointerRemove(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		return pointerAdd(doc, path, v)
+	case "move":
+		fromPath, err := from()
+		if err != nil {
+			return nil, err
+		}
+		if isPrefix(fromPath, path) && len(fromPath) < len(path) {
+			return nil, fmt.Errorf("%w: cannot move a value into one of its children", ErrInvalidPatch)
+		}
+		doc, v, err := pointerRemove(doc, fromPath)
+		if err != nil {
+			return nil, err
+		}
+		return pointerAdd(doc, path, v)
+	case "copy":
+		fromPath, err := from()
+		if err != nil {
+			return nil, err
+		}
+		v, err := pointerGet(doc, fromPath)
+		if err != nil {
+			return nil, err
+		}
+		return pointerAdd(doc, path, deepCopy(v))
+	case "test":
+		v, err := value()
+		if err != nil {
+			return nil, err
+		}
+		actual, err := pointerGet(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !jsonEqual(actual, v) {
+			return nil, fmt.Errorf("%w: test failed, value at %q does not match", ErrConflict, *op.Path)
+		}
+		return doc, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown operation %q", ErrInvalidPatch, op.Op)
+	}
+}
+
+// parseJSONPointer splits an RFC 6901 JSON Pointer into unescaped tokens.
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%w: JSON pointer %q must start with /", ErrInvalidPatch, pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(t)
+	}
+
+	return tokens, nil
+}
+
+func pointerGet(doc any, path []string) (any, error) {
+	current := doc
+	for _, token := range path {
+		switch c := current.(type) {
+		case map[string]any:
+			v, ok := c[token]
+			if !ok {
+				return nil, fmt.Errorf("%w: member %q does not exist", ErrInvalidPatch, token)
+			}
+			current = v
+		case []any:
+			i, err := arrayIndex(token, len(c)-1)
+			if err != nil {
+				return nil, err
+			}
+			current = c[i]
+		default:
+			return nil, fmt.Errorf("%w: cannot reference %q in a scalar value", ErrInvalidPatch, token)
+		}
+	}
+
+	return current, nil
+}
+
+func pointerAdd(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	parent, err := pointerGet(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := path[len(path)-1]
+
+	switch p := parent.(type) {
+	case map[string]any:
+		p[last] = value
+		return doc, nil
+	case []any:
+		i := len(p)
+		if last != "-" {
+			i, err = arrayIndex(last, len(p))
+			if err != nil {
+				return nil, err
+			}
+		}
+		updated := append(p[:i:i], append([]any{value}, p[i:]...)...)
+		return replaceAt(doc, path[:len(path)-1], updated)
+	default:
+		return nil, fmt.Errorf("%w: cannot add %q to a scalar value", ErrInvalidPatch, last)
+	}
+}
+
+func pointerRemove(doc any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, doc, nil
+	}
+
+	parent, err := pointerGet(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, nil, err
+	}
+	last := path[len(path)-1]
+
+	switch p := parent.(type) {
+	case map[string]any:
+		v, ok := p[last]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: member %q does not exist", ErrInvalidPatch, last)
+		}
+		delete(p, last)
+		return doc, v, nil
+	case []any:
+		i, err := arrayIndex(last, len(p)-1)
+		if err != nil {
+			return nil, nil, err
+		}
+		v := p[i]
+		updated := append(p[:i:i], p[i+1:]...)
+		doc, err = replaceAt(doc, path[:len(path)-1], updated)
+		return doc, v, err
+	default:
+		return nil, nil, fmt.Errorf("%w: cannot remove %q from a scalar value", ErrInvalidPatch, last)
+	}
+}
+
+// replaceAt sets the value at path, which must exist. It is needed for
+// arrays because changing their length produces a new slice header.
+func replaceAt(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	parent, err := pointerGet(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := path[len(path)-1]
+
+	switch p := parent.(type) {
+	case map[string]any:
+		p[last] = value
+	case []any:
+		i, err := arrayIndex(last, len(p)-1)
+		if err != nil {
+			return nil, err
+		}
+		p[i] = value
+	}
+
+	return doc, nil
+}
+
+// arrayIndex parses an array index token, which must be within [0, max].
+func arrayIndex(token string, max int) (int, error) {
+	if token == "" || len(token) > 1 && token[0] == '0' {
+		return 0, fmt.Errorf("%w: invalid array index %q", ErrInvalidPatch, token)
+	}
+
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i > max {
+		return 0, fmt.Errorf("%w: array index %q out of bounds", ErrInvalidPatch, token)
+	}
+
+	return i, nil
+}
+
+func isPrefix(prefix, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// decodeJSON decodes a JSON value keeping numbers as json.Number so that no
+// precision is lost on prices.
+func decodeJSON(b []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, errors.New("unexpected data after the JSON value")
+	}
+
+	return v, nil
+}
+
+func deepCopy(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		c := make(map[string]any, len(t))
+		for k, e := range t {
+			c[k] = deepCopy(e)
+		}
+		return c
+	case []any:
+		c := make([]any, len(t))
+		for i, e := range t {
+			c[i] = deepCopy(e)
+		}
+		return c
+	default:
+		return v
+	}
+}
+
+// jsonEqual compares two decoded JSON values, treating numbers as equal when
+// they have the same mathematical value.
+func jsonEqual(a, b any) bool {
+	an, aok := a.(json.Number)
+	bn, bok := b.(json.Number)
+	if aok && bok {
+		ar, aok := new(big.Rat).SetString(an.String())
+		br, bok := new(big.Rat).SetString(bn.String())
+		return aok && bok && ar.Cmp(br) == 0
+	}
+
+	switch at := a.(type) {
+	case map[string]any:
+		bt, ok := b.(map[string]any)
+		if !ok || len(at) != len(bt) {
+			return false
+		}
+		for k, av := range at {
+			bv, ok := bt[k]
+			if !ok || !jsonEqual(av, bv) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		bt, ok := b.([]any)
+		if !ok || len(at) != len(bt) {
+			return false
+		}
+		for i := range at {
+			if !jsonEqual(at[i], bt[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(a, b)
+	}
+}
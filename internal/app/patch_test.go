@@ -0,0 +1,111 @@
+package app
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyMergePatch(t *testing.T) {
+	// Examples from Appendix A of RFC 7396.
+	tests := []struct {
+		name   string
+		target string
+		patch  string
+		exp    string
+	}{
+		{name: "replace member", target: `{"a":"b"}`, patch: `{"a":"c"}`, exp: `{"a":"c"}`},
+		{name: "add member", target: `{"a":"b"}`, patch: `{"b":"c"}`, exp: `{"a":"b","b":"c"}`},
+		{name: "remove member", target: `{"a":"b"}`, patch: `{"a":null}`, exp: `{}`},
+		{name: "remove one of several members", target: `{"a":"b","b":"c"}`, patch: `{"a":null}`, exp: `{"b":"c"}`},
+		{name: "replace array", target: `{"a":["b"]}`, patch: `{"a":"c"}`, exp: `{"a":"c"}`},
+		{name: "array replaces value", target: `{"a":"c"}`, patch: `{"a":["b"]}`, exp: `{"a":["b"]}`},
+		{name: "nested objects", target: `{"a":{"b":"c"}}`, patch: `{"a":{"b":"d","c":null}}`, exp: `{"a":{"b":"d"}}`},
+		{name: "arrays are not merged", target: `{"a":[{"b":"c"}]}`, patch: `{"a":[1]}`, exp: `{"a":[1]}`},
+		{name: "non object patch replaces target", target: `{"a":"foo"}`, patch: `"bar"`, exp: `"bar"`},
+		{name: "null members are kept", target: `{"e":null}`, patch: `{"a":1}`, exp: `{"e":null,"a":1}`},
+		{name: "object patch on non object target", target: `[1,2]`, patch: `{"a":"b","c":null}`, exp: `{"a":"b"}`},
+		{name: "nested object creation", target: `{}`, patch: `{"a":{"bb":{"ccc":null}}}`, exp: `{"a":{"bb":{}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := decodeJSON([]byte(tt.target))
+			assert.NoError(t, err)
+			exp, err := decodeJSON([]byte(tt.exp))
+			assert.NoError(t, err)
+
+			res, err := applyPatch(PatchFormatMergePatch, target, []byte(tt.patch))
+			assert.NoError(t, err)
+			assert.True(t, jsonEqual(exp, res), "got %v", res)
+		})
+	}
+}
+
+func TestApplyMergePatch_InvalidDocument(t *testing.T) {
+	_, err := applyPatch(PatchFormatMergePatch, map[string]any{}, []byte(`{`))
+	assert.True(t, errors.Is(err, ErrInvalidPatch))
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		patch  string
+		exp    string
+		expErr error
+	}{
+		{name: "add object member", target: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/baz","value":"qux"}]`, exp: `{"baz":"qux","foo":"bar"}`},
+		{name: "add array element", target: `{"foo":["bar","baz"]}`, patch: `[{"op":"add","path":"/foo/1","value":"qux"}]`, exp: `{"foo":["bar","qux","baz"]}`},
+		{name: "append array element", target: `{"foo":["bar"]}`, patch: `[{"op":"add","path":"/foo/-","value":"qux"}]`, exp: `{"foo":["bar","qux"]}`},
+		{name: "add null value", target: `{}`, patch: `[{"op":"add","path":"/foo","value":null}]`, exp: `{"foo":null}`},
+		{name: "remove object member", target: `{"baz":"qux","foo":"bar"}`, patch: `[{"op":"remove","path":"/baz"}]`, exp: `{"foo":"bar"}`},
+		{name: "remove array element", target: `{"foo":["bar","qux","baz"]}`, patch: `[{"op":"remove","path":"/foo/1"}]`, exp: `{"foo":["bar","baz"]}`},
+		{name: "replace value", target: `{"baz":"qux","foo":"bar"}`, patch: `[{"op":"replace","path":"/baz","value":"boo"}]`, exp: `{"baz":"boo","foo":"bar"}`},
+		{name: "move value", target: `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, patch: `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, exp: `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{name: "move array element", target: `{"foo":["all","grass","cows","eat"]}`, patch: `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, exp: `{"foo":["all","cows","eat","grass"]}`},
+		{name: "copy value", target: `{"foo":{"bar":1}}`, patch: `[{"op":"copy","from":"/foo","path":"/baz"}]`, exp: `{"foo":{"bar":1},"baz":{"bar":1}}`},
+		{name: "test success", target: `{"baz":"qux","foo":["a",2,"c"]}`, patch: `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2.0}]`, exp: `{"baz":"qux","foo":["a",2,"c"]}`},
+		{name: "escaped pointer", target: `{"a/b":1,"m~n":2}`, patch: `[{"op":"replace","path":"/a~1b","value":3},{"op":"remove","path":"/m~0n"}]`, exp: `{"a/b":3}`},
+		{name: "test failure", target: `{"baz":"qux"}`, patch: `[{"op":"test","path":"/baz","value":"bar"}]`, expErr: ErrConflict},
+		{name: "remove missing member", target: `{"foo":"bar"}`, patch: `[{"op":"remove","path":"/baz"}]`, expErr: ErrInvalidPatch},
+		{name: "replace missing member", target: `{"foo":"bar"}`, patch: `[{"op":"replace","path":"/baz","value":1}]`, expErr: ErrInvalidPatch},
+		{name: "add to missing parent", target: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/baz/bat","value":"qux"}]`, expErr: ErrInvalidPatch},
+		{name: "array index out of bounds", target: `{"foo":["bar"]}`, patch: `[{"op":"add","path":"/foo/3","value":"qux"}]`, expErr: ErrInvalidPatch},
+		{name: "array index with leading zero", target: `{"foo":["bar","baz"]}`, patch: `[{"op":"remove","path":"/foo/01"}]`, expErr: ErrInvalidPatch},
+		{name: "move into child", target: `{"foo":{"bar":1}}`, patch: `[{"op":"move","from":"/foo","path":"/foo/bar/baz"}]`, expErr: ErrInvalidPatch},
+		{name: "missing value", target: `{}`, patch: `[{"op":"add","path":"/foo"}]`, expErr: ErrInvalidPatch},
+		{name: "missing path", target: `{}`, patch: `[{"op":"add","value":1}]`, expErr: ErrInvalidPatch},
+		{name: "unknown operation", target: `{}`, patch: `[{"op":"merge","path":"/foo","value":1}]`, expErr: ErrInvalidPatch},
+		{name: "pointer without leading slash", target: `{"foo":1}`, patch: `[{"op":"remove","path":"foo"}]`, expErr: ErrInvalidPatch},
+		{name: "patch is not an array", target: `{}`, patch: `{"op":"add","path":"/foo","value":1}`, expErr: ErrInvalidPatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := decodeJSON([]byte(tt.target))
+			assert.NoError(t, err)
+
+			res, err := applyPatch(PatchFormatJSONPatch, target, []byte(tt.patch))
+			if tt.expErr != nil {
+				assert.True(t, errors.Is(err, tt.expErr), "got %v", err)
+				return
+			}
+
+			assert.NoError(t, err)
+
+			exp, err := decodeJSON([]byte(tt.exp))
+			assert.NoError(t, err)
+			assert.True(t, jsonEqual(exp, res), "got %v", res)
+		})
+	}
+}
+
+func TestApplyJSONPatch_DoesNotModifyTarget(t *testing.T) {
+	target := map[string]any{"foo": []any{"bar"}}
+
+	_, err := applyPatch(PatchFormatJSONPatch, target, []byte(`[{"op":"add","path":"/foo/0","value":"baz"},{"op":"add","path":"/qux","value":1}]`))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"foo": []any{"bar"}}, target)
+}
@@ -1,36 +1,175 @@
-package app
-
-import (
-	"time"
-
-	"github.com/google/uuid"
-)
-
-type Product struct {
-	ID          uuid.UUID
-	Name        string
-	Description string
-	Price       float32
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-}
-
-func NewProduct(name, description string, price float32) *Product {
-	now := time.Now().UTC()
-
-	return &Product{
-		ID:          uuid.New(),
-		Name:        name,
-		Description: description,
-		Price:       price,
-		CreatedAt:   now,
-		UpdatedAt:   now,
-	}
-}
-
-func (p *Product) Update(name, description string, price float32) {
-	p.Name = name
-	p.Description = description
-	p.Price = price
-	p.UpdatedAt = time.Now().UTC()
-}
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Product is addressable by its ID, by its slug and, when it has one, by its
+// SKU. The slug is derived from the name when the product is created and does
+// not change afterwards, so that links to the product keep working. The
+// status only changes through the transitions of the product lifecycle.
+// DeletedAt is set while the product is soft deleted.
+type Product struct {
+	ID          uuid.UUID
+	SKU         string
+	Slug        string
+	Status      ProductStatus
+	Name        string
+	Description string
+	Price       Money
+	Tags        []string
+	Attributes  map[string]any
+	Version     int64
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   *time.Time
+}
+
+func NewProduct(name, description string, price Money, tags []string, attributes map[string]any, sku string) *Product {
+	now := time.Now().UTC()
+
+	return &Product{
+		ID:          uuid.New(),
+		SKU:         sku,
+		Slug:        Slugify(name),
+		Status:      ProductDraft,
+		Name:        name,
+		Description: description,
+		Price:       price,
+		Tags:        tags,
+		Attributes:  attributes,
+		Version:     1,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// CheckVersion returns ErrPreconditionFailed when expected is set and does not
+// match the current version of the product. A zero expected version matches
+// any version. The version is incremented by the repository on every change.
+func (p *Product) CheckVersion(expected int64) error {
+	if expected != 0 && expected != p.Version {
+		return fmt.Errorf("%w: product %s is at version %d, not %d", ErrPreconditionFailed, p.ID, p.Version, expected)
+	}
+
+	return nil
+}
+
+func (p *Product) Update(name, description string, price Money, tags []string, attributes map[string]any, sku string) {
+	p.SKU = sku
+	p.Name = name
+	p.Description = description
+	p.Price = price
+	p.Tags = tags
+	p.Attributes = attributes
+	p.UpdatedAt = time.Now().UTC()
+}
+
+// productDocument returns the JSON representation of the fields of the
+// product that clients can modify. It is the document that patches apply to.
+func productDocument(p *Product) map[string]any {
+	tags := make([]any, len(p.Tags))
+	for i, tag := range p.Tags {
+		tags[i] = tag
+	}
+
+	return map[string]any{
+		"name":        p.Name,
+		"description": p.Description,
+		"price":       p.Price.Decimal(),
+		"currency":    p.Price.Currency,
+		"tags":        tags,
+		"attributes":  deepCopy(p.Attributes),
+		"sku":         p.SKU,
+	}
+}
+
+// productInputFromDocument reads the fields of a patched product document.
+// Unknown members and members of the wrong type are reported as violations.
+func productInputFromDocument(doc any) (productInput, error) {
+	obj, ok := doc.(map[string]any)
+	if !ok {
+		return productInput{}, fmt.Errorf("%w: the patched document must be a JSON object", ErrInvalidPatch)
+	}
+
+	var (
+		in     productInput
+		v      violations
+		amount string
+	)
+
+	for field, value := range obj {
+		switch field {
+		case "name":
+			in.Name, ok = value.(string)
+		case "description":
+			in.Description, ok = value.(string)
+		case "price":
+			switch n := value.(type) {
+			case string:
+				amount, ok = n, true
+			case json.Number:
+				amount, ok = n.String(), true
+			default:
+				ok = false
+			}
+		case "currency":
+			in.Price.Currency, ok = value.(string)
+		case "tags":
+			in.Tags, ok = stringsFromDocument(value)
+		case "attributes":
+			in.Attributes, ok = value.(map[string]any)
+		case "sku":
+			in.SKU, ok = value.(string)
+		default:
+			v.add(field, "is not a modifiable product field")
+			continue
+		}
+
+		if !ok {
+			v.add(field, "has an invalid type")
+		}
+	}
+
+	if err := v.err(); err != nil {
+		sortViolations(v)
+		return productInput{}, err
+	}
+
+	if _, ok := CurrencyExponent(in.Price.Currency); !ok {
+		return productInput{}, &ValidationError{Violations: []Violation{
+			{Field: "currency", Message: "must be a supported ISO 4217 currency code"},
+		}}
+	}
+
+	price, err := ParseMoney(amount, in.Price.Currency)
+	if err != nil {
+		return productInput{}, &ValidationError{Violations: []Violation{
+			{Field: "price", Message: "must be a decimal number matching the precision of the currency"},
+		}}
+	}
+	in.Price = price
+
+	return in, nil
+}
+
+// stringsFromDocument reads an array of strings of a JSON document.
+func stringsFromDocument(value any) ([]string, bool) {
+	arr, ok := value.([]any)
+	if !ok {
+		return nil, false
+	}
+
+	s := make([]string, len(arr))
+	for i, elem := range arr {
+		if s[i], ok = elem.(string); !ok {
+			return nil, false
+		}
+	}
+
+	return s, true
+}
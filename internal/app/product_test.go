@@ -1,51 +1,77 @@
-package app
-
-import (
-	"testing"
-	"time"
-
-	"github.com/google/uuid"
-	"github.com/stretchr/testify/assert"
-)
-
-func TestNewProduct(t *testing.T) {
-	name := "Test Product"
-	description := "Test Product Description"
-	var price float32 = 100.0
-
-	product := NewProduct(name, description, price)
-
-	assert.NotNil(t, product.ID)
-	assert.Equal(t, name, product.Name)
-	assert.Equal(t, description, product.Description)
-	assert.Equal(t, price, product.Price)
-	assert.False(t, product.CreatedAt.IsZero())
-	assert.False(t, product.UpdatedAt.IsZero())
-}
-
-func TestProduct_Update(t *testing.T) {
-	productID := uuid.New()
-	now := time.Now()
-
-	product := Product{
-		ID:          productID,
-		Name:        "Test Product",
-		Description: "Test Product Description",
-		Price:       200.0,
-		CreatedAt:   now,
-		UpdatedAt:   now,
-	}
-
-	newName := "Test Product 2"
-	newDescription := "Test Product 2 Description"
-	var newPrice float32 = 200.0
-
-	product.Update(newName, newDescription, newPrice)
-
-	assert.Equal(t, productID, product.ID)
-	assert.Equal(t, newName, product.Name)
-	assert.Equal(t, newDescription, product.Description)
-	assert.Equal(t, newPrice, product.Price)
-	assert.Equal(t, now, product.CreatedAt)
-	assert.NotEqual(t, now, product.UpdatedAt)
-}
+package app
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewProduct(t *testing.T) {
+	name := "Test Product"
+	description := "Test Product Description"
+	price := NewMoney(10000, "USD")
+	tags := []string{"clearance", "seasonal"}
+	attributes := map[string]any{"color": "red"}
+	sku := "TP-001"
+
+	product := NewProduct(name, description, price, tags, attributes, sku)
+
+	assert.NotNil(t, product.ID)
+	assert.Equal(t, sku, product.SKU)
+	assert.Equal(t, "test-product", product.Slug)
+	assert.Equal(t, ProductDraft, product.Status)
+	assert.Equal(t, name, product.Name)
+	assert.Equal(t, description, product.Description)
+	assert.Equal(t, price, product.Price)
+	assert.Equal(t, tags, product.Tags)
+	assert.Equal(t, attributes, product.Attributes)
+	assert.Equal(t, int64(1), product.Version)
+	assert.False(t, product.CreatedAt.IsZero())
+	assert.False(t, product.UpdatedAt.IsZero())
+}
+
+func TestProduct_Update(t *testing.T) {
+	productID := uuid.New()
+	now := time.Now()
+
+	product := Product{
+		ID:          productID,
+		Slug:        "test-product",
+		Name:        "Test Product",
+		Description: "Test Product Description",
+		Price:       NewMoney(20000, "USD"),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	newName := "Test Product 2"
+	newDescription := "Test Product 2 Description"
+	newPrice := NewMoney(25000, "EUR")
+	newTags := []string{"clearance"}
+	newAttributes := map[string]any{"color": "blue"}
+	newSKU := "TP-002"
+
+	product.Update(newName, newDescription, newPrice, newTags, newAttributes, newSKU)
+
+	assert.Equal(t, productID, product.ID)
+	assert.Equal(t, newSKU, product.SKU)
+	assert.Equal(t, "test-product", product.Slug)
+	assert.Equal(t, newName, product.Name)
+	assert.Equal(t, newDescription, product.Description)
+	assert.Equal(t, newPrice, product.Price)
+	assert.Equal(t, newTags, product.Tags)
+	assert.Equal(t, newAttributes, product.Attributes)
+	assert.Equal(t, now, product.CreatedAt)
+	assert.NotEqual(t, now, product.UpdatedAt)
+}
+
+func TestProduct_CheckVersion(t *testing.T) {
+	product := Product{ID: uuid.New(), Version: 3}
+
+	assert.NoError(t, product.CheckVersion(0))
+	assert.NoError(t, product.CheckVersion(3))
+	assert.True(t, errors.Is(product.CheckVersion(2), ErrPreconditionFailed))
+}
@@ -0,0 +1,154 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidSort is returned when a sort expression refers to a field that
+// the product list cannot be ordered by.
+var ErrInvalidSort = errors.New("invalid sort")
+
+// SortField is a product field that the product list can be ordered by.
+type SortField string
+
+const (
+	SortByName      SortField = "name"
+	SortByPrice     SortField = "price"
+	SortByCreatedAt SortField = "created_at"
+	SortByUpdatedAt SortField = "updated_at"
+)
+
+var sortFields = map[SortField]struct {
+	// value returns the key of a product in a cursor.
+	value func(p *Product) string
+	// valid reports whether a key read from a cursor is well formed.
+	valid func(v string) bool
+}{
+	SortByName:      {value: func(p *Product) string { return p.Name }, valid: func(string) bool { return true }},
+	SortByPrice:     {value: func(p *Product) string { return p.Price.Decimal() }, valid: isDecimal},
+	SortByCreatedAt: {value: func(p *Product) string { return formatCursorTime(p.CreatedAt) }, valid: isCursorTime},
+	SortByUpdatedAt: {value: func(p *Product) string { return formatCursorTime(p.UpdatedAt) }, valid: isCursorTime},
+}
+
+// SortKey orders the list by a field, ascending unless Desc is set.
+type SortKey struct {
+	Field SortField
+	Desc  bool
+}
+
+// Sort is a list of sort keys, the first one being the most significant.
+// Products with equal keys are ordered by ID in the direction of the last
+// key so that the order is total, which keyset pagination depends on.
+type Sort []SortKey
+
+// DefaultSort lists the newest products first.
+var DefaultSort = Sort{{Field: SortByCreatedAt, Desc: true}}
+
+// ParseSort parses a comma separated list of fields, each of them prefixed
+// with - for a descending order, e.g. "price,-created_at".
+func ParseSort(s string) (Sort, error) {
+	var sort Sort
+	seen := make(map[SortField]bool)
+
+	for _, part := range strings.Split(s, ",") {
+		key := SortKey{Field: SortField(strings.TrimPrefix(part, "-")), Desc: strings.HasPrefix(part, "-")}
+
+		if _, ok := sortFields[key.Field]; !ok {
+			return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidSort, key.Field)
+		}
+		if seen[key.Field] {
+			return nil, fmt.Errorf("%w: field %q is repeated", ErrInvalidSort, key.Field)
+		}
+		seen[key.Field] = true
+
+		sort = append(sort, key)
+	}
+
+	return sort, nil
+}
+
+// String returns the sort in the syntax accepted by ParseSort.
+func (s Sort) String() string {
+	parts := make([]string, len(s))
+	for i, k := range s {
+		parts[i] = string(k.Field)
+		if k.Desc {
+			parts[i] = "-" + parts[i]
+		}
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// ProductFilter restricts the product list. Zero values do not filter.
+// Time ranges are half-open: the After bounds are inclusive and the Before
+// bounds are exclusive. Price bounds compare amounts regardless of their
+// currency unless Currency is set too. CategoryID selects the products of
+// the category and of all its descendants. TagsAny selects the products
+// carrying at least one of the tags, TagsAll the ones carrying all of them.
+// An empty Statuses keeps the published products only. Soft deleted
+// products are left out unless IncludeDeleted is set.
+type ProductFilter struct {
+	NameContains  string
+	Currency      string
+	MinPrice      *Money
+	MaxPrice      *Money
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	UpdatedAfter  time.Time
+	UpdatedBefore time.Time
+	IDs           []uuid.UUID
+	CategoryID    uuid.UUID
+	TagsAny       []string
+	TagsAll       []string
+	// Attributes keeps the products having all of these attributes with
+	// these values.
+	Attributes     map[string]any
+	Statuses       []ProductStatus
+	IncludeDeleted bool
+	// AsOf, when set, matches the products as they were at that instant
+	// instead of as they are. Category membership is not historized, the
+	// current one applies.
+	AsOf time.Time
+}
+
+// normalize normalizes the tags of the filter like product tags so that
+// they compare equal, and defaults the statuses to published.
+func (f ProductFilter) normalize() ProductFilter {
+	if len(f.Statuses) == 0 {
+		f.Statuses = []ProductStatus{ProductPublished}
+	}
+	if f.TagsAny != nil {
+		f.TagsAny = normalizeTags(f.TagsAny)
+	}
+	if f.TagsAll != nil {
+		f.TagsAll = normalizeTags(f.TagsAll)
+	}
+
+	return f
+}
+
+// ProductsQuery selects a slice of the product list. The repository returns
+// at most Limit products in list order. After and Before select the products
+// strictly after or before a cursor, Offset skips products from the start of
+// the list; only one of them is set at a time. Sort is never empty.
+type ProductsQuery struct {
+	Filter ProductFilter
+	Sort   Sort
+	Limit  int
+	Offset int
+	After  *Cursor
+	Before *Cursor
+}
+
+func isDecimal(s string) bool {
+	s = strings.TrimPrefix(s, "-")
+	intPart, fracPart, hasPoint := strings.Cut(s, ".")
+
+	return intPart != "" && isDigits(intPart) && isDigits(fracPart) && !(hasPoint && fracPart == "")
+}
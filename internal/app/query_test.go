@@ -0,0 +1,56 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		name    string
+		sort    string
+		expSort Sort
+		expErr  bool
+	}{
+		{
+			name:    "single field",
+			sort:    "name",
+			expSort: Sort{{Field: SortByName}},
+		},
+		{
+			name:    "multiple fields",
+			sort:    "price,-created_at",
+			expSort: Sort{{Field: SortByPrice}, {Field: SortByCreatedAt, Desc: true}},
+		},
+		{
+			name:   "unknown field",
+			sort:   "description",
+			expErr: true,
+		},
+		{
+			name:   "repeated field",
+			sort:   "price,-price",
+			expErr: true,
+		},
+		{
+			name:   "empty field",
+			sort:   "price,",
+			expErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sort, err := ParseSort(tt.sort)
+			if tt.expErr {
+				assert.ErrorIs(t, err, ErrInvalidSort)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expSort, sort)
+			assert.Equal(t, tt.sort, sort.String())
+		})
+	}
+}
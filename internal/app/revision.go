@@ -0,0 +1,264 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RevisionAction is the kind of change a product revision records.
+type RevisionAction string
+
+const (
+	RevisionCreated  RevisionAction = "created"
+	RevisionUpdated  RevisionAction = "updated"
+	RevisionDeleted  RevisionAction = "deleted"
+	RevisionRestored RevisionAction = "restored"
+	RevisionReverted RevisionAction = "reverted"
+)
+
+// ProductRevision is an immutable snapshot of a product, taken by every change
+// of the product in the transaction that stores the change. A revision is
+// numbered by the version of the product it captured. ChangedFields lists the
+// fields of the product document that the change modified, it is empty for
+// the revision of a creation.
+type ProductRevision struct {
+	ProductID     uuid.UUID
+	Version       int64
+	Action        RevisionAction
+	Product       Product
+	ChangedFields []string
+	Actor         string
+	CreatedAt     time.Time
+}
+
+// NewProductRevision captures after, the product as action left it. before is
+// the product as it was, it is only needed for updates and reverts.
+func NewProductRevision(action RevisionAction, before, after *Product, actor string) *ProductRevision {
+	r := &ProductRevision{
+		ProductID: after.ID,
+		Version:   after.Version,
+		Action:    action,
+		Product:   *after,
+		Actor:     actor,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	switch action {
+	case RevisionCreated:
+	case RevisionDeleted, RevisionRestored:
+		r.ChangedFields = []string{"deleted_at"}
+	default:
+		for _, c := range diffProducts(before, after) {
+			r.ChangedFields = append(r.ChangedFields, c.Field)
+		}
+	}
+
+	return r
+}
+
+// FieldChange is a field of the product document that differs between two
+// revisions, with its value in each of them.
+type FieldChange struct {
+	Field string
+	From  any
+	To    any
+}
+
+// ProductDiff lists the changes of a product from one revision to another,
+// by field name.
+type ProductDiff struct {
+	ProductID uuid.UUID
+	From      int64
+	To        int64
+	Changes   []FieldChange
+}
+
+// revisionDocument returns the product document extended with the fields that
+// only change through dedicated operations.
+func revisionDocument(p *Product) map[string]any {
+	doc := productDocument(p)
+	doc["status"] = string(p.Status)
+	doc["deleted_at"] = nil
+	if p.DeletedAt != nil {
+		doc["deleted_at"] = p.DeletedAt.UTC().Format(time.RFC3339Nano)
+	}
+
+	return doc
+}
+
+// productStateDocument returns the revision document along with the identity
+// and the version of the product, the state of the product as the audit log
+// and the product events report it.
+func productStateDocument(p *Product) map[string]any {
+	doc := revisionDocument(p)
+	doc["id"] = p.ID.String()
+	doc["slug"] = p.Slug
+	doc["version"] = p.Version
+
+	return doc
+}
+
+func diffProducts(from, to *Product) []FieldChange {
+	fromDoc, toDoc := revisionDocument(from), revisionDocument(to)
+
+	fields := make([]string, 0, len(toDoc))
+	for field := range toDoc {
+		fields = append(fields, field)
+	}
+	slices.Sort(fields)
+
+	var changes []FieldChange
+	for _, field := range fields {
+		if !reflect.DeepEqual(fromDoc[field], toDoc[field]) {
+			changes = append(changes, FieldChange{Field: field, From: fromDoc[field], To: toDoc[field]})
+		}
+	}
+
+	return changes
+}
+
+// saveProduct runs write, which stores a change of a product, and records the
+// revision of the product write returns, the audit entry of the change and the
+// event announcing it in the same transaction.
+func (s Service) saveProduct(ctx context.Context, action RevisionAction, before *Product, write func(ctx context.Context) (*Product, error)) (*Product, error) {
+	var after *Product
+	err := s.repository.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		after, err = write(ctx)
+		if err != nil {
+			return err
+		}
+
+		err = s.repository.CreateProductRevision(ctx, NewProductRevision(action, before, after, ActorFromContext(ctx)))
+		if err != nil {
+			return fmt.Errorf("failed to create product revision: %w", err)
+		}
+
+		err = s.repository.CreateOutboxEvent(ctx, NewProductEvent(action, after))
+		if err != nil {
+			return fmt.Errorf("failed to create product event: %w", err)
+		}
+
+		// The revision actions are audit actions of the same name.
+		return s.audit(ctx, AuditAction(action), AuditProduct, after.ID, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return after, nil
+}
+
+// GetProductRevisions returns the revisions of a product, the oldest first.
+// The revisions of a deleted product remain readable until it is purged.
+func (s Service) GetProductRevisions(ctx context.Context, productID uuid.UUID) ([]ProductRevision, error) {
+	revisions, err := s.repository.GetProductRevisions(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product revisions: %w", err)
+	}
+
+	if len(revisions) == 0 {
+		// Products created before revisions were recorded have none.
+		_, err := s.repository.GetProduct(ctx, productID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get product: %w", err)
+		}
+	}
+
+	return revisions, nil
+}
+
+func (s Service) GetProductRevision(ctx context.Context, productID uuid.UUID, version int64) (*ProductRevision, error) {
+	r, err := s.repository.GetProductRevision(ctx, productID, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product revision: %w", err)
+	}
+
+	return r, nil
+}
+
+// DiffProductRevisions compares two revisions of a product. From may be the
+// later one, the changes then undo the ones made in between.
+func (s Service) DiffProductRevisions(ctx context.Context, dto DiffProductRevisionsDTO) (*ProductDiff, error) {
+	from, err := s.repository.GetProductRevision(ctx, dto.ProductID, dto.From)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product revision: %w", err)
+	}
+
+	to, err := s.repository.GetProductRevision(ctx, dto.ProductID, dto.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product revision: %w", err)
+	}
+
+	return &ProductDiff{
+		ProductID: dto.ProductID,
+		From:      dto.From,
+		To:        dto.To,
+		Changes:   diffProducts(&from.Product, &to.Product),
+	}, nil
+}
+
+// RevertProduct brings the fields of a product that clients can modify back
+// to their value in a revision. The status and the slug of the product are
+// left alone, and the reverted product must satisfy the current validation
+// rules and attribute schemas.
+func (s Service) RevertProduct(ctx context.Context, dto RevertProductDTO) (*Product, error) {
+	p, err := s.repository.GetProduct(ctx, dto.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	err = p.CheckVersion(dto.ExpectedVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := s.repository.GetProductRevision(ctx, dto.ID, dto.Version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product revision: %w", err)
+	}
+
+	old := r.Product
+	in := productInput{Name: old.Name, Description: old.Description, Price: old.Price, Tags: old.Tags, Attributes: old.Attributes, SKU: old.SKU}.normalize()
+
+	err = in.validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid product: %w", err)
+	}
+
+	before := *p
+	p.Update(in.Name, in.Description, in.Price, in.Tags, in.Attributes, in.SKU)
+
+	return s.saveProduct(ctx, RevisionReverted, &before, func(ctx context.Context) (*Product, error) {
+		err := s.checkProductAttributes(ctx, p.ID, in.Attributes)
+		if err != nil {
+			return nil, err
+		}
+
+		err = s.repository.UpdateProduct(ctx, p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update product: %w", err)
+		}
+
+		return p, nil
+	})
+}
+
+type DiffProductRevisionsDTO struct {
+	ProductID uuid.UUID
+	From      int64
+	To        int64
+}
+
+// RevertProductDTO reverts a product to the revision Version. A non-zero
+// ExpectedVersion makes the revert conditional on the current version.
+type RevertProductDTO struct {
+	ID              uuid.UUID
+	Version         int64
+	ExpectedVersion int64
+}
@@ -0,0 +1,254 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// expectSaveProduct expects a product change to run in a transaction, and to
+// record a revision, an event and an audit entry when stored is true.
+func expectSaveProduct(m *Mockrepository, stored bool) {
+	m.EXPECT().
+		WithinTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(context.WithValue(ctx, txKey{}, true))
+		})
+
+	if stored {
+		m.EXPECT().
+			CreateProductRevision(gomock.Any(), gomock.Any()).
+			Return(nil)
+		m.EXPECT().
+			CreateOutboxEvent(gomock.Any(), gomock.Any()).
+			Return(nil)
+		m.EXPECT().
+			CreateAuditEntry(gomock.Any(), gomock.Any()).
+			Return(nil)
+	}
+}
+
+func TestNewProductRevision(t *testing.T) {
+	before := &Product{ID: uuid.New(), Name: "Boots", Price: NewMoney(10000, "USD"), Version: 1}
+	after := *before
+	after.Name = "Hiking Boots"
+	after.Price = NewMoney(12000, "USD")
+	after.Version = 2
+
+	tests := []struct {
+		name             string
+		action           RevisionAction
+		before           *Product
+		expChangedFields []string
+	}{
+		{name: "creation", action: RevisionCreated},
+		{name: "update", action: RevisionUpdated, before: before, expChangedFields: []string{"name", "price"}},
+		{name: "revert", action: RevisionReverted, before: before, expChangedFields: []string{"name", "price"}},
+		{name: "deletion", action: RevisionDeleted, before: before, expChangedFields: []string{"deleted_at"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewProductRevision(tt.action, tt.before, &after, "alice")
+
+			assert.Equal(t, after.ID, r.ProductID)
+			assert.Equal(t, int64(2), r.Version)
+			assert.Equal(t, tt.action, r.Action)
+			assert.Equal(t, after, r.Product)
+			assert.Equal(t, tt.expChangedFields, r.ChangedFields)
+			assert.Equal(t, "alice", r.Actor)
+			assert.False(t, r.CreatedAt.IsZero())
+		})
+	}
+}
+
+func TestDiffProducts(t *testing.T) {
+	deletedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	from := &Product{Name: "Boots", Price: NewMoney(10000, "USD"), Tags: []string{"outdoor"}, Status: ProductDraft}
+	to := &Product{Name: "Boots", Price: NewMoney(10000, "EUR"), Tags: []string{"outdoor", "winter"}, Status: ProductPublished, DeletedAt: &deletedAt}
+
+	changes := diffProducts(from, to)
+
+	assert.Equal(t, []FieldChange{
+		{Field: "currency", From: "USD", To: "EUR"},
+		{Field: "deleted_at", From: nil, To: "2024-05-01T12:00:00Z"},
+		{Field: "status", From: "draft", To: "published"},
+		{Field: "tags", From: []any{"outdoor"}, To: []any{"outdoor", "winter"}},
+	}, changes)
+	assert.Empty(t, diffProducts(from, from))
+}
+
+func TestService_GetProductRevisions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+
+	productID := uuid.New()
+
+	t.Run("product without revisions", func(t *testing.T) {
+		mockRepository := NewMockrepository(ctrl)
+
+		mockRepository.EXPECT().
+			GetProductRevisions(gomock.Any(), productID).
+			Return(nil, nil)
+		mockRepository.EXPECT().
+			GetProduct(gomock.Any(), productID).
+			Return(&Product{ID: productID}, nil)
+
+		s, err := NewService(mockRepository)
+		assert.NoError(t, err)
+
+		revisions, err := s.GetProductRevisions(ctx, productID)
+		assert.NoError(t, err)
+		assert.Empty(t, revisions)
+	})
+
+	t.Run("product does not exist", func(t *testing.T) {
+		mockRepository := NewMockrepository(ctrl)
+
+		mockRepository.EXPECT().
+			GetProductRevisions(gomock.Any(), productID).
+			Return(nil, nil)
+		mockRepository.EXPECT().
+			GetProduct(gomock.Any(), productID).
+			Return(nil, ErrNotFound)
+
+		s, err := NewService(mockRepository)
+		assert.NoError(t, err)
+
+		revisions, err := s.GetProductRevisions(ctx, productID)
+		assert.Equal(t, fmt.Errorf("failed to get product: %w", ErrNotFound), err)
+		assert.Nil(t, revisions)
+	})
+}
+
+func TestService_DiffProductRevisions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+
+	productID := uuid.New()
+
+	mockRepository := NewMockrepository(ctrl)
+
+	mockRepository.EXPECT().
+		GetProductRevision(gomock.Any(), productID, int64(3)).
+		Return(&ProductRevision{Version: 3, Product: Product{Name: "Hiking Boots"}}, nil)
+	mockRepository.EXPECT().
+		GetProductRevision(gomock.Any(), productID, int64(1)).
+		Return(&ProductRevision{Version: 1, Product: Product{Name: "Boots"}}, nil)
+
+	s, err := NewService(mockRepository)
+	assert.NoError(t, err)
+
+	diff, err := s.DiffProductRevisions(ctx, DiffProductRevisionsDTO{ProductID: productID, From: 3, To: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, &ProductDiff{
+		ProductID: productID,
+		From:      3,
+		To:        1,
+		Changes:   []FieldChange{{Field: "name", From: "Hiking Boots", To: "Boots"}},
+	}, diff)
+}
+
+func TestService_RevertProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+
+	productID := uuid.New()
+
+	newProduct := func() *Product {
+		return &Product{
+			ID:      productID,
+			Name:    "Hiking Boots",
+			Price:   NewMoney(12000, "USD"),
+			Slug:    "hiking-boots",
+			Status:  ProductPublished,
+			Version: 3,
+		}
+	}
+	revision := &ProductRevision{
+		ProductID: productID,
+		Version:   1,
+		Product: Product{
+			ID:     productID,
+			Name:   "Boots",
+			Price:  NewMoney(10000, "USD"),
+			Slug:   "boots",
+			Status: ProductDraft,
+		},
+	}
+
+	tests := []struct {
+		name                  string
+		dto                   RevertProductDTO
+		expRepoGetRevisionErr error
+		expRepoUpdateProduct  bool
+		expErr                error
+	}{
+		{
+			name:                 "product was reverted successfully",
+			dto:                  RevertProductDTO{ID: productID, Version: 1, ExpectedVersion: 3},
+			expRepoUpdateProduct: true,
+		},
+		{
+			name:   "product was modified concurrently",
+			dto:    RevertProductDTO{ID: productID, Version: 1, ExpectedVersion: 2},
+			expErr: fmt.Errorf("%w: product %s is at version 3, not 2", ErrPreconditionFailed, productID),
+		},
+		{
+			name:                  "revision does not exist",
+			dto:                   RevertProductDTO{ID: productID, Version: 9},
+			expRepoGetRevisionErr: ErrNotFound,
+			expErr:                fmt.Errorf("failed to get product revision: %w", ErrNotFound),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mock expectations
+			mockRepository := NewMockrepository(ctrl)
+
+			mockRepository.EXPECT().
+				GetProduct(gomock.Any(), productID).
+				Return(newProduct(), nil)
+
+			if tt.expRepoGetRevisionErr != nil {
+				mockRepository.EXPECT().
+					GetProductRevision(gomock.Any(), productID, tt.dto.Version).
+					Return(nil, tt.expRepoGetRevisionErr)
+			}
+
+			if tt.expRepoUpdateProduct {
+				mockRepository.EXPECT().
+					GetProductRevision(gomock.Any(), productID, tt.dto.Version).
+					Return(revision, nil)
+				expectAttributesChecked(mockRepository, productID, nil)
+				expectSaveProduct(mockRepository, true)
+				mockRepository.EXPECT().
+					UpdateProduct(gomock.Any(), gomock.Any()).
+					Return(nil)
+			}
+
+			// Exercise
+			s, err := NewService(mockRepository)
+			assert.NoError(t, err)
+
+			p, err := s.RevertProduct(ctx, tt.dto)
+			if tt.expErr != nil {
+				assert.Equal(t, tt.expErr, err)
+				assert.Nil(t, p)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "Boots", p.Name)
+				assert.Equal(t, NewMoney(10000, "USD"), p.Price)
+				assert.Equal(t, "hiking-boots", p.Slug)
+				assert.Equal(t, ProductPublished, p.Status)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package app
+
+import "github.com/google/uuid"
+
+// ProductMatch is a product found by a full-text search. The highlights are
+// HTML fragments of the name and description in which the matching words
+// are wrapped in <mark> elements, the rest of the text being escaped.
+type ProductMatch struct {
+	Product              *Product
+	Rank                 float64
+	NameHighlight        string
+	DescriptionHighlight string
+}
+
+// ProductSearchQuery selects a slice of the products matching Text, most
+// relevant first. Text uses the web search syntax: quoted phrases, OR and
+// a leading - to exclude a word.
+type ProductSearchQuery struct {
+	Text   string
+	Filter ProductFilter
+	Limit  int
+	Offset int
+}
+
+// ProductSearchPage is a page of search results.
+type ProductSearchPage struct {
+	Matches []ProductMatch
+	HasMore bool
+}
+
+// ProductSuggestion is a product whose name is close to a typed prefix.
+// Score is the trigram word similarity between the prefix and the name,
+// from 0 to 1.
+type ProductSuggestion struct {
+	ID    uuid.UUID
+	Name  string
+	Score float64
+}
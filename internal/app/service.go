@@ -1,99 +1,450 @@
-package app
-
-import (
-	"context"
-	"errors"
-	"fmt"
-
-	"github.com/google/uuid"
-)
-
-type repository interface {
-	CreateProduct(ctx context.Context, p *Product) error
-	UpdateProduct(ctx context.Context, p *Product) error
-	DeleteProduct(ctx context.Context, productID uuid.UUID) error
-	GetProduct(ctx context.Context, productID uuid.UUID) (*Product, error)
-	GetProducts(ctx context.Context, limit, offset int) ([]*Product, error)
-}
-
-type Service struct {
-	repository repository
-}
-
-func NewService(r repository) (Service, error) {
-	if r == nil {
-		return Service{}, errors.New("repository is nil")
-	}
-
-	return Service{
-		repository: r,
-	}, nil
-}
-
-func (s Service) CreateProduct(ctx context.Context, dto CreateProductDTO) (*Product, error) {
-	p := NewProduct(dto.Name, dto.Description, dto.Price)
-
-	err := s.repository.CreateProduct(ctx, p)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create product: %w", err)
-	}
-
-	return p, nil
-}
-
-func (s Service) UpdateProduct(ctx context.Context, dto UpdateProductDTO) (*Product, error) {
-
-	p, err := s.repository.GetProduct(ctx, dto.ID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get product: %w", err)
-	}
-
-	p.Update(dto.Name, dto.Description, dto.Price)
-
-	err = s.repository.UpdateProduct(ctx, p)
-	if err != nil {
-		return nil, fmt.Errorf("failed to update product: %w", err)
-	}
-
-	return p, nil
-}
-
-func (s Service) DeleteProduct(ctx context.Context, productID uuid.UUID) error {
-	err := s.repository.DeleteProduct(ctx, productID)
-	if err != nil {
-		return fmt.Errorf("failed to delete product: %w", err)
-	}
-
-	return nil
-}
-
-func (s Service) GetProduct(ctx context.Context, productID uuid.UUID) (*Product, error) {
-	p, err := s.repository.GetProduct(ctx, productID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get product: %w", err)
-	}
-
-	return p, nil
-}
-
-func (s Service) GetProducts(ctx context.Context, limit, offset int) ([]*Product, error) {
-	products, err := s.repository.GetProducts(ctx, limit, offset)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get products: %w", err)
-	}
-
-	return products, nil
-}
-
-type CreateProductDTO struct {
-	Name        string
-	Description string
-	Price       float32
-}
-
-type UpdateProductDTO struct {
-	ID          uuid.UUID
-	Name        string
-	Description string
-	Price       float32
-}
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/text/unicode/norm"
+)
+
+type repository interface {
+	// WithinTx runs fn in a transaction, committed when fn returns nil. The
+	// repository calls made with the context passed to fn take part in the
+	// transaction.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+
+	CreateProduct(ctx context.Context, p *Product) error
+	// UpdateProduct stores p only if its version in the database still is
+	// p.Version and increments the version on success.
+	UpdateProduct(ctx context.Context, p *Product) error
+	// DeleteProduct soft deletes the product if its version is
+	// expectedVersion, or regardless of its version when expectedVersion is
+	// zero, increments the version and returns the deleted product. Soft
+	// deleted products are not found by the other methods unless stated
+	// otherwise.
+	DeleteProduct(ctx context.Context, productID uuid.UUID, expectedVersion int64) (*Product, error)
+	// RestoreProduct undoes the soft deletion of the product under the same
+	// version condition. It returns ErrConflict when the product is not
+	// deleted.
+	RestoreProduct(ctx context.Context, productID uuid.UUID, expectedVersion int64) (*Product, error)
+	// PurgeProducts hard deletes up to limit products soft deleted before
+	// deletedBefore, along with their variants, and returns the IDs of the
+	// products it deleted.
+	PurgeProducts(ctx context.Context, deletedBefore time.Time, limit int) ([]uuid.UUID, error)
+	GetProduct(ctx context.Context, productID uuid.UUID) (*Product, error)
+	// LockProduct reads the product and locks it until the transaction of
+	// ctx ends, so that the writes checking its attributes against its
+	// categories apply one after the other.
+	LockProduct(ctx context.Context, productID uuid.UUID) (*Product, error)
+	// GetProductAsOf returns the product as it was at asOf. A product that
+	// did not exist yet or was soft deleted at that instant is not found.
+	GetProductAsOf(ctx context.Context, productID uuid.UUID, asOf time.Time) (*Product, error)
+	GetProductBySKU(ctx context.Context, sku string) (*Product, error)
+	GetProductBySlug(ctx context.Context, slug string) (*Product, error)
+	// GetSlugs returns base and the slugs made of base and a numeric suffix,
+	// e.g. base-2, that are already taken, soft deleted products included.
+	GetSlugs(ctx context.Context, base string) ([]string, error)
+	GetProducts(ctx context.Context, q ProductsQuery) ([]*Product, error)
+	CountProducts(ctx context.Context, f ProductFilter) (int, error)
+	SearchProducts(ctx context.Context, q ProductSearchQuery) ([]ProductMatch, error)
+	// SuggestProducts returns the products whose name is the most similar to
+	// prefix, names starting with it first.
+	SuggestProducts(ctx context.Context, prefix string, limit int) ([]ProductSuggestion, error)
+	// GetTags returns the tags starting with prefix and their usage counts,
+	// the most used first.
+	GetTags(ctx context.Context, prefix string, limit int) ([]TagCount, error)
+
+	CreateProductRevision(ctx context.Context, r *ProductRevision) error
+	// GetProductRevisions returns the revisions of the product, soft deleted
+	// or not, the oldest first.
+	GetProductRevisions(ctx context.Context, productID uuid.UUID) ([]ProductRevision, error)
+	GetProductRevision(ctx context.Context, productID uuid.UUID, version int64) (*ProductRevision, error)
+
+	CreateCategory(ctx context.Context, c *Category) error
+	// UpdateCategory stores c only if its version in the database still is
+	// c.Version, rewrites the paths of its descendants when it moved and
+	// increments the version on success.
+	UpdateCategory(ctx context.Context, c *Category) error
+	// DeleteCategory deletes the category if its version is expectedVersion,
+	// or regardless of its version when expectedVersion is zero, and returns
+	// the deleted category. It returns ErrConflict when the category has
+	// subcategories.
+	DeleteCategory(ctx context.Context, categoryID uuid.UUID, expectedVersion int64) (*Category, error)
+	GetCategory(ctx context.Context, categoryID uuid.UUID) (*Category, error)
+	GetCategories(ctx context.Context) ([]*Category, error)
+	// GetCategoriesByID returns the existing categories among ids.
+	GetCategoriesByID(ctx context.Context, ids []uuid.UUID) ([]*Category, error)
+	SetProductCategories(ctx context.Context, productID uuid.UUID, categoryIDs []uuid.UUID) error
+	GetProductCategories(ctx context.Context, productID uuid.UUID) ([]*Category, error)
+	// GetCategoryProducts returns the products of the category and of its
+	// descendants.
+	GetCategoryProducts(ctx context.Context, categoryID uuid.UUID) ([]*Product, error)
+
+	CreateVariant(ctx context.Context, v *Variant) error
+	// UpdateVariant stores v only if its version in the database still is
+	// v.Version and increments the version on success.
+	UpdateVariant(ctx context.Context, v *Variant) error
+	// DeleteVariant deletes the variant of the product if its version is
+	// expectedVersion, or regardless of its version when expectedVersion is
+	// zero, and returns the deleted variant.
+	DeleteVariant(ctx context.Context, productID, variantID uuid.UUID, expectedVersion int64) (*Variant, error)
+	GetVariant(ctx context.Context, productID, variantID uuid.UUID) (*Variant, error)
+	GetVariants(ctx context.Context, productID uuid.UUID) ([]*Variant, error)
+
+	// GetInventory returns the inventory of a product, an empty one when its
+	// stock was never adjusted.
+	GetInventory(ctx context.Context, productID uuid.UUID) (*Inventory, error)
+	// AdjustStock applies a to the inventory of its product and records it.
+	// It returns an *InsufficientStockError when the stock on hand would
+	// drop below the reserved units.
+	AdjustStock(ctx context.Context, a *StockAdjustment) (*Inventory, error)
+	GetStockAdjustments(ctx context.Context, productID uuid.UUID, limit int) ([]StockAdjustment, error)
+	// CreateReservation reserves the items of r, all of them or none. It
+	// returns an *InsufficientStockError when some are not available.
+	CreateReservation(ctx context.Context, r *Reservation) error
+	GetReservation(ctx context.Context, reservationID uuid.UUID) (*Reservation, error)
+	// UpdateReservationStatus stores the status a pending reservation moved
+	// to and applies it to the inventory. It returns ErrConflict when the
+	// reservation is no longer pending.
+	UpdateReservationStatus(ctx context.Context, r *Reservation) error
+	// ExpireReservations expires up to limit pending reservations that
+	// expired before now and returns the IDs of the reservations it expired.
+	ExpireReservations(ctx context.Context, now time.Time, limit int) ([]uuid.UUID, error)
+
+	// CreateAuditEntry appends e to the audit log.
+	CreateAuditEntry(ctx context.Context, e *AuditEntry) error
+	GetAuditEntries(ctx context.Context, q AuditQuery) ([]AuditEntry, error)
+
+	// CreateOutboxEvent writes e to the outbox.
+	CreateOutboxEvent(ctx context.Context, e *Event) error
+	// ClaimOutboxEvents leases up to limit pending events due at now until
+	// leaseUntil, the oldest first, and returns them. Leased events are not
+	// due again before the lease ends, so a relay that stopped before marking
+	// them leaves them to the next claim. Only the oldest pending event of a
+	// product is claimed.
+	ClaimOutboxEvents(ctx context.Context, now, leaseUntil time.Time, limit int) ([]Event, error)
+	MarkOutboxEventPublished(ctx context.Context, eventID uuid.UUID, publishedAt time.Time) error
+	// MarkOutboxEventFailed counts a failed attempt to publish the event and
+	// postpones the next one to retryAt.
+	MarkOutboxEventFailed(ctx context.Context, eventID uuid.UUID, retryAt time.Time, reason string) error
+}
+
+type Service struct {
+	repository repository
+}
+
+func NewService(r repository) (Service, error) {
+	if r == nil {
+		return Service{}, errors.New("repository is nil")
+	}
+
+	return Service{
+		repository: r,
+	}, nil
+}
+
+// CreateProduct creates a product with a slug derived from its name, suffixed
+// with a number when the slug is taken. A product created concurrently with
+// the same slug makes the creation fail with ErrConflict.
+func (s Service) CreateProduct(ctx context.Context, dto CreateProductDTO) (*Product, error) {
+	in := productInput{Name: dto.Name, Description: dto.Description, Price: dto.Price, Tags: dto.Tags, Attributes: dto.Attributes, SKU: dto.SKU}.normalize()
+
+	err := in.validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid product: %w", err)
+	}
+
+	p := NewProduct(in.Name, in.Description, in.Price, in.Tags, in.Attributes, in.SKU)
+
+	taken, err := s.repository.GetSlugs(ctx, p.Slug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get slugs: %w", err)
+	}
+	p.Slug = uniqueSlug(p.Slug, taken)
+
+	return s.saveProduct(ctx, RevisionCreated, nil, func(ctx context.Context) (*Product, error) {
+		err := s.repository.CreateProduct(ctx, p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create product: %w", err)
+		}
+
+		return p, nil
+	})
+}
+
+func (s Service) UpdateProduct(ctx context.Context, dto UpdateProductDTO) (*Product, error) {
+	in := productInput{Name: dto.Name, Description: dto.Description, Price: dto.Price, Tags: dto.Tags, Attributes: dto.Attributes, SKU: dto.SKU}.normalize()
+
+	err := in.validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid product: %w", err)
+	}
+
+	p, err := s.repository.GetProduct(ctx, dto.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	err = p.CheckVersion(dto.ExpectedVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *p
+	p.Update(in.Name, in.Description, in.Price, in.Tags, in.Attributes, in.SKU)
+
+	return s.saveProduct(ctx, RevisionUpdated, &before, func(ctx context.Context) (*Product, error) {
+		err := s.checkProductAttributes(ctx, p.ID, in.Attributes)
+		if err != nil {
+			return nil, err
+		}
+
+		err = s.repository.UpdateProduct(ctx, p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update product: %w", err)
+		}
+
+		return p, nil
+	})
+}
+
+// PatchProduct applies a partial update to a product. Fields that the patch
+// does not touch keep their current value.
+func (s Service) PatchProduct(ctx context.Context, dto PatchProductDTO) (*Product, error) {
+	p, err := s.repository.GetProduct(ctx, dto.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	err = p.CheckVersion(dto.ExpectedVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := applyPatch(dto.Format, productDocument(p), dto.Patch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply patch: %w", err)
+	}
+
+	in, err := productInputFromDocument(doc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid product: %w", err)
+	}
+
+	in = in.normalize()
+
+	err = in.validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid product: %w", err)
+	}
+
+	before := *p
+	p.Update(in.Name, in.Description, in.Price, in.Tags, in.Attributes, in.SKU)
+
+	return s.saveProduct(ctx, RevisionUpdated, &before, func(ctx context.Context) (*Product, error) {
+		err := s.checkProductAttributes(ctx, p.ID, in.Attributes)
+		if err != nil {
+			return nil, err
+		}
+
+		err = s.repository.UpdateProduct(ctx, p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update product: %w", err)
+		}
+
+		return p, nil
+	})
+}
+
+// DeleteProduct soft deletes the product. It can be restored until it is
+// purged.
+func (s Service) DeleteProduct(ctx context.Context, dto DeleteProductDTO) error {
+	_, err := s.saveProduct(ctx, RevisionDeleted, nil, func(ctx context.Context) (*Product, error) {
+		p, err := s.repository.DeleteProduct(ctx, dto.ID, dto.ExpectedVersion)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete product: %w", err)
+		}
+
+		return p, nil
+	})
+
+	return err
+}
+
+func (s Service) GetProduct(ctx context.Context, productID uuid.UUID) (*Product, error) {
+	p, err := s.repository.GetProduct(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	return p, nil
+}
+
+// GetProductAsOf returns the product as it was at asOf, e.g. to reproduce the
+// catalog at the end of a month.
+func (s Service) GetProductAsOf(ctx context.Context, productID uuid.UUID, asOf time.Time) (*Product, error) {
+	p, err := s.repository.GetProductAsOf(ctx, productID, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	return p, nil
+}
+
+func (s Service) GetProductBySKU(ctx context.Context, sku string) (*Product, error) {
+	p, err := s.repository.GetProductBySKU(ctx, sku)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	return p, nil
+}
+
+func (s Service) GetProductBySlug(ctx context.Context, slug string) (*Product, error) {
+	p, err := s.repository.GetProductBySlug(ctx, slug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	return p, nil
+}
+
+func (s Service) GetProducts(ctx context.Context, dto GetProductsDTO) (*ProductPage, error) {
+	if len(dto.Sort) == 0 {
+		dto.Sort = DefaultSort
+	}
+	dto.Filter = dto.Filter.normalize()
+
+	if dto.Filter.CategoryID != uuid.Nil {
+		_, err := s.repository.GetCategory(ctx, dto.Filter.CategoryID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get category: %w", err)
+		}
+	}
+
+	q := ProductsQuery{
+		Filter: dto.Filter,
+		Sort:   dto.Sort,
+		Limit:  dto.Limit + 1,
+		Offset: dto.Offset,
+		After:  dto.After,
+		Before: dto.Before,
+	}
+
+	products, err := s.repository.GetProducts(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get products: %w", err)
+	}
+
+	page := newProductPage(products, dto)
+
+	if dto.Count {
+		total, err := s.repository.CountProducts(ctx, dto.Filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count products: %w", err)
+		}
+		page.Total = &total
+	}
+
+	return page, nil
+}
+
+func (s Service) SearchProducts(ctx context.Context, dto SearchProductsDTO) (*ProductSearchPage, error) {
+	q := ProductSearchQuery{
+		Text:   norm.NFC.String(strings.TrimSpace(dto.Query)),
+		Filter: dto.Filter.normalize(),
+		Limit:  dto.Limit + 1,
+		Offset: dto.Offset,
+	}
+
+	matches, err := s.repository.SearchProducts(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search products: %w", err)
+	}
+
+	page := &ProductSearchPage{Matches: matches}
+	if len(matches) > dto.Limit {
+		page.Matches = matches[:dto.Limit]
+		page.HasMore = true
+	}
+
+	return page, nil
+}
+
+// SuggestProducts returns the product names closest to what the user typed
+// so far. The prefix is normalized like product names so that it compares
+// with them on equal terms.
+func (s Service) SuggestProducts(ctx context.Context, dto SuggestProductsDTO) ([]ProductSuggestion, error) {
+	suggestions, err := s.repository.SuggestProducts(ctx, normalizeName(dto.Prefix), dto.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest products: %w", err)
+	}
+
+	return suggestions, nil
+}
+
+type CreateProductDTO struct {
+	Name        string
+	Description string
+	Price       Money
+	Tags        []string
+	Attributes  map[string]any
+	SKU         string
+}
+
+type PatchProductDTO struct {
+	ID              uuid.UUID
+	ExpectedVersion int64
+	Format          PatchFormat
+	Patch           []byte
+}
+
+// UpdateProductDTO replaces the fields of a product. A non-zero
+// ExpectedVersion makes the update conditional on the current version.
+type UpdateProductDTO struct {
+	ID              uuid.UUID
+	ExpectedVersion int64
+	Name            string
+	Description     string
+	Price           Money
+	Tags            []string
+	Attributes      map[string]any
+	SKU             string
+}
+
+type DeleteProductDTO struct {
+	ID              uuid.UUID
+	ExpectedVersion int64
+}
+
+// GetProductsDTO selects a page of the filtered and sorted products either
+// by cursor or, for backwards compatibility, by offset. Cursors must have
+// been issued for the same sort. Count requests the total number of matching
+// products along with the page. An empty Sort is DefaultSort.
+type GetProductsDTO struct {
+	Filter ProductFilter
+	Sort   Sort
+	Limit  int
+	Offset int
+	After  *Cursor
+	Before *Cursor
+	Count  bool
+}
+
+// SearchProductsDTO selects a page of the products matching Query among the
+// filtered ones.
+type SearchProductsDTO struct {
+	Query  string
+	Filter ProductFilter
+	Limit  int
+	Offset int
+}
+
+type SuggestProductsDTO struct {
+	Prefix string
+	Limit  int
+}
@@ -1,113 +1,732 @@
-// Code generated by MockGen. DO NOT EDIT.
-// Source: ./internal/app/service.go
-//
-// Generated by this command:
-//
-//	mockgen -source ./internal/app/service.go -destination ./internal/app/service_mocks.go -package app
-//
-
-// Package app is a generated GoMock package.
-package app
-
-import (
-	context "context"
-	reflect "reflect"
-
-	uuid "github.com/google/uuid"
-	gomock "go.uber.org/mock/gomock"
-)
-
-// Mockrepository is a mock of repository interface.
-type Mockrepository struct {
-	ctrl     *gomock.Controller
-	recorder *MockrepositoryMockRecorder
-}
-
-// MockrepositoryMockRecorder is the mock recorder for Mockrepository.
-type MockrepositoryMockRecorder struct {
-	mock *Mockrepository
-}
-
-// NewMockrepository creates a new mock instance.
-func NewMockrepository(ctrl *gomock.Controller) *Mockrepository {
-	mock := &Mockrepository{ctrl: ctrl}
-	mock.recorder = &MockrepositoryMockRecorder{mock}
-	return mock
-}
-
-// EXPECT returns an object that allows the caller to indicate expected use.
-func (m *Mockrepository) EXPECT() *MockrepositoryMockRecorder {
-	return m.recorder
-}
-
-// CreateProduct mocks base method.
-func (m *Mockrepository) CreateProduct(ctx context.Context, p *Product) error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateProduct", ctx, p)
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// CreateProduct indicates an expected call of CreateProduct.
-func (mr *MockrepositoryMockRecorder) CreateProduct(ctx, p any) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProduct", reflect.TypeOf((*Mockrepository)(nil).CreateProduct), ctx, p)
-}
-
-// DeleteProduct mocks base method.
-func (m *Mockrepository) DeleteProduct(ctx context.Context, productID uuid.UUID) error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteProduct", ctx, productID)
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// DeleteProduct indicates an expected call of DeleteProduct.
-func (mr *MockrepositoryMockRecorder) DeleteProduct(ctx, productID any) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProduct", reflect.TypeOf((*Mockrepository)(nil).DeleteProduct), ctx, productID)
-}
-
-// GetProduct mocks base method.
-func (m *Mockrepository) GetProduct(ctx context.Context, productID uuid.UUID) (*Product, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetProduct", ctx, productID)
-	ret0, _ := ret[0].(*Product)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// GetProduct indicates an expected call of GetProduct.
-func (mr *MockrepositoryMockRecorder) GetProduct(ctx, productID any) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProduct", reflect.TypeOf((*Mockrepository)(nil).GetProduct), ctx, productID)
-}
-
-// GetProducts mocks base method.
-func (m *Mockrepository) GetProducts(ctx context.Context, limit, offset int) ([]*Product, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetProducts", ctx, limit, offset)
-	ret0, _ := ret[0].([]*Product)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// GetProducts indicates an expected call of GetProducts.
-func (mr *MockrepositoryMockRecorder) GetProducts(ctx, limit, offset any) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProducts", reflect.TypeOf((*Mockrepository)(nil).GetProducts), ctx, limit, offset)
-}
-
-// UpdateProduct mocks base method.
-func (m *Mockrepository) UpdateProduct(ctx context.Context, p *Product) error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateProduct", ctx, p)
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// UpdateProduct indicates an expected call of UpdateProduct.
-func (mr *MockrepositoryMockRecorder) UpdateProduct(ctx, p any) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProduct", reflect.TypeOf((*Mockrepository)(nil).UpdateProduct), ctx, p)
-}
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/app/service.go
+//
+// Generated by this command:
+//
+//	mockgen -source ./internal/app/service.go -destination ./internal/app/service_mocks.go -package app
+//
+
+// Package app is a generated GoMock package.
+package app
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	uuid "github.com/google/uuid"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// Mockrepository is a mock of repository interface.
+type Mockrepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockrepositoryMockRecorder
+}
+
+// MockrepositoryMockRecorder is the mock recorder for Mockrepository.
+type MockrepositoryMockRecorder struct {
+	mock *Mockrepository
+}
+
+// NewMockrepository creates a new mock instance.
+func NewMockrepository(ctrl *gomock.Controller) *Mockrepository {
+	mock := &Mockrepository{ctrl: ctrl}
+	mock.recorder = &MockrepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mockrepository) EXPECT() *MockrepositoryMockRecorder {
+	return m.recorder
+}
+
+// AdjustStock mocks base method.
+func (m *Mockrepository) AdjustStock(ctx context.Context, a *StockAdjustment) (*Inventory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustStock", ctx, a)
+	ret0, _ := ret[0].(*Inventory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdjustStock indicates an expected call of AdjustStock.
+func (mr *MockrepositoryMockRecorder) AdjustStock(ctx, a any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustStock", reflect.TypeOf((*Mockrepository)(nil).AdjustStock), ctx, a)
+}
+
+// ClaimOutboxEvents mocks base method.
+func (m *Mockrepository) ClaimOutboxEvents(ctx context.Context, now, leaseUntil time.Time, limit int) ([]Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimOutboxEvents", ctx, now, leaseUntil, limit)
+	ret0, _ := ret[0].([]Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimOutboxEvents indicates an expected call of ClaimOutboxEvents.
+func (mr *MockrepositoryMockRecorder) ClaimOutboxEvents(ctx, now, leaseUntil, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimOutboxEvents", reflect.TypeOf((*Mockrepository)(nil).ClaimOutboxEvents), ctx, now, leaseUntil, limit)
+}
+
+// CountProducts mocks base method.
+func (m *Mockrepository) CountProducts(ctx context.Context, f ProductFilter) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountProducts", ctx, f)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountProducts indicates an expected call of CountProducts.
+func (mr *MockrepositoryMockRecorder) CountProducts(ctx, f any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountProducts", reflect.TypeOf((*Mockrepository)(nil).CountProducts), ctx, f)
+}
+
+// CreateAuditEntry mocks base method.
+func (m *Mockrepository) CreateAuditEntry(ctx context.Context, e *AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuditEntry", ctx, e)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAuditEntry indicates an expected call of CreateAuditEntry.
+func (mr *MockrepositoryMockRecorder) CreateAuditEntry(ctx, e any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuditEntry", reflect.TypeOf((*Mockrepository)(nil).CreateAuditEntry), ctx, e)
+}
+
+// CreateCategory mocks base method.
+func (m *Mockrepository) CreateCategory(ctx context.Context, c *Category) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCategory", ctx, c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateCategory indicates an expected call of CreateCategory.
+func (mr *MockrepositoryMockRecorder) CreateCategory(ctx, c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCategory", reflect.TypeOf((*Mockrepository)(nil).CreateCategory), ctx, c)
+}
+
+// CreateOutboxEvent mocks base method.
+func (m *Mockrepository) CreateOutboxEvent(ctx context.Context, e *Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOutboxEvent", ctx, e)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOutboxEvent indicates an expected call of CreateOutboxEvent.
+func (mr *MockrepositoryMockRecorder) CreateOutboxEvent(ctx, e any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOutboxEvent", reflect.TypeOf((*Mockrepository)(nil).CreateOutboxEvent), ctx, e)
+}
+
+// CreateProduct mocks base method.
+func (m *Mockrepository) CreateProduct(ctx context.Context, p *Product) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateProduct", ctx, p)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateProduct indicates an expected call of CreateProduct.
+func (mr *MockrepositoryMockRecorder) CreateProduct(ctx, p any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProduct", reflect.TypeOf((*Mockrepository)(nil).CreateProduct), ctx, p)
+}
+
+// CreateProductRevision mocks base method.
+func (m *Mockrepository) CreateProductRevision(ctx context.Context, r *ProductRevision) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateProductRevision", ctx, r)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateProductRevision indicates an expected call of CreateProductRevision.
+func (mr *MockrepositoryMockRecorder) CreateProductRevision(ctx, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProductRevision", reflect.TypeOf((*Mockrepository)(nil).CreateProductRevision), ctx, r)
+}
+
+// CreateReservation mocks base method.
+func (m *Mockrepository) CreateReservation(ctx context.Context, r *Reservation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReservation", ctx, r)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateReservation indicates an expected call of CreateReservation.
+func (mr *MockrepositoryMockRecorder) CreateReservation(ctx, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReservation", reflect.TypeOf((*Mockrepository)(nil).CreateReservation), ctx, r)
+}
+
+// CreateVariant mocks base method.
+func (m *Mockrepository) CreateVariant(ctx context.Context, v *Variant) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateVariant", ctx, v)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateVariant indicates an expected call of CreateVariant.
+func (mr *MockrepositoryMockRecorder) CreateVariant(ctx, v any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVariant", reflect.TypeOf((*Mockrepository)(nil).CreateVariant), ctx, v)
+}
+
+// DeleteCategory mocks base method.
+func (m *Mockrepository) DeleteCategory(ctx context.Context, categoryID uuid.UUID, expectedVersion int64) (*Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCategory", ctx, categoryID, expectedVersion)
+	ret0, _ := ret[0].(*Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteCategory indicates an expected call of DeleteCategory.
+func (mr *MockrepositoryMockRecorder) DeleteCategory(ctx, categoryID, expectedVersion any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCategory", reflect.TypeOf((*Mockrepository)(nil).DeleteCategory), ctx, categoryID, expectedVersion)
+}
+
+// DeleteProduct mocks base method.
+func (m *Mockrepository) DeleteProduct(ctx context.Context, productID uuid.UUID, expectedVersion int64) (*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProduct", ctx, productID, expectedVersion)
+	ret0, _ := ret[0].(*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteProduct indicates an expected call of DeleteProduct.
+func (mr *MockrepositoryMockRecorder) DeleteProduct(ctx, productID, expectedVersion any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProduct", reflect.TypeOf((*Mockrepository)(nil).DeleteProduct), ctx, productID, expectedVersion)
+}
+
+// DeleteVariant mocks base method.
+func (m *Mockrepository) DeleteVariant(ctx context.Context, productID, variantID uuid.UUID, expectedVersion int64) (*Variant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteVariant", ctx, productID, variantID, expectedVersion)
+	ret0, _ := ret[0].(*Variant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteVariant indicates an expected call of DeleteVariant.
+func (mr *MockrepositoryMockRecorder) DeleteVariant(ctx, productID, variantID, expectedVersion any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVariant", reflect.TypeOf((*Mockrepository)(nil).DeleteVariant), ctx, productID, variantID, expectedVersion)
+}
+
+// ExpireReservations mocks base method.
+func (m *Mockrepository) ExpireReservations(ctx context.Context, now time.Time, limit int) ([]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireReservations", ctx, now, limit)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpireReservations indicates an expected call of ExpireReservations.
+func (mr *MockrepositoryMockRecorder) ExpireReservations(ctx, now, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireReservations", reflect.TypeOf((*Mockrepository)(nil).ExpireReservations), ctx, now, limit)
+}
+
+// GetAuditEntries mocks base method.
+func (m *Mockrepository) GetAuditEntries(ctx context.Context, q AuditQuery) ([]AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditEntries", ctx, q)
+	ret0, _ := ret[0].([]AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditEntries indicates an expected call of GetAuditEntries.
+func (mr *MockrepositoryMockRecorder) GetAuditEntries(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditEntries", reflect.TypeOf((*Mockrepository)(nil).GetAuditEntries), ctx, q)
+}
+
+// GetCategories mocks base method.
+func (m *Mockrepository) GetCategories(ctx context.Context) ([]*Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategories", ctx)
+	ret0, _ := ret[0].([]*Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategories indicates an expected call of GetCategories.
+func (mr *MockrepositoryMockRecorder) GetCategories(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategories", reflect.TypeOf((*Mockrepository)(nil).GetCategories), ctx)
+}
+
+// GetCategoriesByID mocks base method.
+func (m *Mockrepository) GetCategoriesByID(ctx context.Context, ids []uuid.UUID) ([]*Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategoriesByID", ctx, ids)
+	ret0, _ := ret[0].([]*Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategoriesByID indicates an expected call of GetCategoriesByID.
+func (mr *MockrepositoryMockRecorder) GetCategoriesByID(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategoriesByID", reflect.TypeOf((*Mockrepository)(nil).GetCategoriesByID), ctx, ids)
+}
+
+// GetCategory mocks base method.
+func (m *Mockrepository) GetCategory(ctx context.Context, categoryID uuid.UUID) (*Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategory", ctx, categoryID)
+	ret0, _ := ret[0].(*Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategory indicates an expected call of GetCategory.
+func (mr *MockrepositoryMockRecorder) GetCategory(ctx, categoryID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategory", reflect.TypeOf((*Mockrepository)(nil).GetCategory), ctx, categoryID)
+}
+
+// GetCategoryProducts mocks base method.
+func (m *Mockrepository) GetCategoryProducts(ctx context.Context, categoryID uuid.UUID) ([]*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategoryProducts", ctx, categoryID)
+	ret0, _ := ret[0].([]*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategoryProducts indicates an expected call of GetCategoryProducts.
+func (mr *MockrepositoryMockRecorder) GetCategoryProducts(ctx, categoryID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategoryProducts", reflect.TypeOf((*Mockrepository)(nil).GetCategoryProducts), ctx, categoryID)
+}
+
+// GetInventory mocks base method.
+func (m *Mockrepository) GetInventory(ctx context.Context, productID uuid.UUID) (*Inventory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInventory", ctx, productID)
+	ret0, _ := ret[0].(*Inventory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInventory indicates an expected call of GetInventory.
+func (mr *MockrepositoryMockRecorder) GetInventory(ctx, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInventory", reflect.TypeOf((*Mockrepository)(nil).GetInventory), ctx, productID)
+}
+
+// GetProduct mocks base method.
+func (m *Mockrepository) GetProduct(ctx context.Context, productID uuid.UUID) (*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProduct", ctx, productID)
+	ret0, _ := ret[0].(*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProduct indicates an expected call of GetProduct.
+func (mr *MockrepositoryMockRecorder) GetProduct(ctx, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProduct", reflect.TypeOf((*Mockrepository)(nil).GetProduct), ctx, productID)
+}
+
+// GetProductAsOf mocks base method.
+func (m *Mockrepository) GetProductAsOf(ctx context.Context, productID uuid.UUID, asOf time.Time) (*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductAsOf", ctx, productID, asOf)
+	ret0, _ := ret[0].(*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductAsOf indicates an expected call of GetProductAsOf.
+func (mr *MockrepositoryMockRecorder) GetProductAsOf(ctx, productID, asOf any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductAsOf", reflect.TypeOf((*Mockrepository)(nil).GetProductAsOf), ctx, productID, asOf)
+}
+
+// GetProductBySKU mocks base method.
+func (m *Mockrepository) GetProductBySKU(ctx context.Context, sku string) (*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductBySKU", ctx, sku)
+	ret0, _ := ret[0].(*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductBySKU indicates an expected call of GetProductBySKU.
+func (mr *MockrepositoryMockRecorder) GetProductBySKU(ctx, sku any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductBySKU", reflect.TypeOf((*Mockrepository)(nil).GetProductBySKU), ctx, sku)
+}
+
+// GetProductBySlug mocks base method.
+func (m *Mockrepository) GetProductBySlug(ctx context.Context, slug string) (*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductBySlug", ctx, slug)
+	ret0, _ := ret[0].(*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductBySlug indicates an expected call of GetProductBySlug.
+func (mr *MockrepositoryMockRecorder) GetProductBySlug(ctx, slug any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductBySlug", reflect.TypeOf((*Mockrepository)(nil).GetProductBySlug), ctx, slug)
+}
+
+// GetProductCategories mocks base method.
+func (m *Mockrepository) GetProductCategories(ctx context.Context, productID uuid.UUID) ([]*Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductCategories", ctx, productID)
+	ret0, _ := ret[0].([]*Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductCategories indicates an expected call of GetProductCategories.
+func (mr *MockrepositoryMockRecorder) GetProductCategories(ctx, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductCategories", reflect.TypeOf((*Mockrepository)(nil).GetProductCategories), ctx, productID)
+}
+
+// GetProductRevision mocks base method.
+func (m *Mockrepository) GetProductRevision(ctx context.Context, productID uuid.UUID, version int64) (*ProductRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductRevision", ctx, productID, version)
+	ret0, _ := ret[0].(*ProductRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductRevision indicates an expected call of GetProductRevision.
+func (mr *MockrepositoryMockRecorder) GetProductRevision(ctx, productID, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductRevision", reflect.TypeOf((*Mockrepository)(nil).GetProductRevision), ctx, productID, version)
+}
+
+// GetProductRevisions mocks base method.
+func (m *Mockrepository) GetProductRevisions(ctx context.Context, productID uuid.UUID) ([]ProductRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductRevisions", ctx, productID)
+	ret0, _ := ret[0].([]ProductRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductRevisions indicates an expected call of GetProductRevisions.
+func (mr *MockrepositoryMockRecorder) GetProductRevisions(ctx, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductRevisions", reflect.TypeOf((*Mockrepository)(nil).GetProductRevisions), ctx, productID)
+}
+
+// GetProducts mocks base method.
+func (m *Mockrepository) GetProducts(ctx context.Context, q ProductsQuery) ([]*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProducts", ctx, q)
+	ret0, _ := ret[0].([]*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProducts indicates an expected call of GetProducts.
+func (mr *MockrepositoryMockRecorder) GetProducts(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProducts", reflect.TypeOf((*Mockrepository)(nil).GetProducts), ctx, q)
+}
+
+// GetReservation mocks base method.
+func (m *Mockrepository) GetReservation(ctx context.Context, reservationID uuid.UUID) (*Reservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReservation", ctx, reservationID)
+	ret0, _ := ret[0].(*Reservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReservation indicates an expected call of GetReservation.
+func (mr *MockrepositoryMockRecorder) GetReservation(ctx, reservationID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReservation", reflect.TypeOf((*Mockrepository)(nil).GetReservation), ctx, reservationID)
+}
+
+// GetSlugs mocks base method.
+func (m *Mockrepository) GetSlugs(ctx context.Context, base string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSlugs", ctx, base)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSlugs indicates an expected call of GetSlugs.
+func (mr *MockrepositoryMockRecorder) GetSlugs(ctx, base any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSlugs", reflect.TypeOf((*Mockrepository)(nil).GetSlugs), ctx, base)
+}
+
+// GetStockAdjustments mocks base method.
+func (m *Mockrepository) GetStockAdjustments(ctx context.Context, productID uuid.UUID, limit int) ([]StockAdjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStockAdjustments", ctx, productID, limit)
+	ret0, _ := ret[0].([]StockAdjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStockAdjustments indicates an expected call of GetStockAdjustments.
+func (mr *MockrepositoryMockRecorder) GetStockAdjustments(ctx, productID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStockAdjustments", reflect.TypeOf((*Mockrepository)(nil).GetStockAdjustments), ctx, productID, limit)
+}
+
+// GetTags mocks base method.
+func (m *Mockrepository) GetTags(ctx context.Context, prefix string, limit int) ([]TagCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTags", ctx, prefix, limit)
+	ret0, _ := ret[0].([]TagCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTags indicates an expected call of GetTags.
+func (mr *MockrepositoryMockRecorder) GetTags(ctx, prefix, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTags", reflect.TypeOf((*Mockrepository)(nil).GetTags), ctx, prefix, limit)
+}
+
+// GetVariant mocks base method.
+func (m *Mockrepository) GetVariant(ctx context.Context, productID, variantID uuid.UUID) (*Variant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVariant", ctx, productID, variantID)
+	ret0, _ := ret[0].(*Variant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVariant indicates an expected call of GetVariant.
+func (mr *MockrepositoryMockRecorder) GetVariant(ctx, productID, variantID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVariant", reflect.TypeOf((*Mockrepository)(nil).GetVariant), ctx, productID, variantID)
+}
+
+// GetVariants mocks base method.
+func (m *Mockrepository) GetVariants(ctx context.Context, productID uuid.UUID) ([]*Variant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVariants", ctx, productID)
+	ret0, _ := ret[0].([]*Variant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVariants indicates an expected call of GetVariants.
+func (mr *MockrepositoryMockRecorder) GetVariants(ctx, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVariants", reflect.TypeOf((*Mockrepository)(nil).GetVariants), ctx, productID)
+}
+
+// LockProduct mocks base method.
+func (m *Mockrepository) LockProduct(ctx context.Context, productID uuid.UUID) (*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockProduct", ctx, productID)
+	ret0, _ := ret[0].(*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockProduct indicates an expected call of LockProduct.
+func (mr *MockrepositoryMockRecorder) LockProduct(ctx, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockProduct", reflect.TypeOf((*Mockrepository)(nil).LockProduct), ctx, productID)
+}
+
+// MarkOutboxEventFailed mocks base method.
+func (m *Mockrepository) MarkOutboxEventFailed(ctx context.Context, eventID uuid.UUID, retryAt time.Time, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOutboxEventFailed", ctx, eventID, retryAt, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkOutboxEventFailed indicates an expected call of MarkOutboxEventFailed.
+func (mr *MockrepositoryMockRecorder) MarkOutboxEventFailed(ctx, eventID, retryAt, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOutboxEventFailed", reflect.TypeOf((*Mockrepository)(nil).MarkOutboxEventFailed), ctx, eventID, retryAt, reason)
+}
+
+// MarkOutboxEventPublished mocks base method.
+func (m *Mockrepository) MarkOutboxEventPublished(ctx context.Context, eventID uuid.UUID, publishedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOutboxEventPublished", ctx, eventID, publishedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkOutboxEventPublished indicates an expected call of MarkOutboxEventPublished.
+func (mr *MockrepositoryMockRecorder) MarkOutboxEventPublished(ctx, eventID, publishedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOutboxEventPublished", reflect.TypeOf((*Mockrepository)(nil).MarkOutboxEventPublished), ctx, eventID, publishedAt)
+}
+
+// PurgeProducts mocks base method.
+func (m *Mockrepository) PurgeProducts(ctx context.Context, deletedBefore time.Time, limit int) ([]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeProducts", ctx, deletedBefore, limit)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeProducts indicates an expected call of PurgeProducts.
+func (mr *MockrepositoryMockRecorder) PurgeProducts(ctx, deletedBefore, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeProducts", reflect.TypeOf((*Mockrepository)(nil).PurgeProducts), ctx, deletedBefore, limit)
+}
+
+// RestoreProduct mocks base method.
+func (m *Mockrepository) RestoreProduct(ctx context.Context, productID uuid.UUID, expectedVersion int64) (*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreProduct", ctx, productID, expectedVersion)
+	ret0, _ := ret[0].(*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreProduct indicates an expected call of RestoreProduct.
+func (mr *MockrepositoryMockRecorder) RestoreProduct(ctx, productID, expectedVersion any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreProduct", reflect.TypeOf((*Mockrepository)(nil).RestoreProduct), ctx, productID, expectedVersion)
+}
+
+// SearchProducts mocks base method.
+func (m *Mockrepository) SearchProducts(ctx context.Context, q ProductSearchQuery) ([]ProductMatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchProducts", ctx, q)
+	ret0, _ := ret[0].([]ProductMatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchProducts indicates an expected call of SearchProducts.
+func (mr *MockrepositoryMockRecorder) SearchProducts(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchProducts", reflect.TypeOf((*Mockrepository)(nil).SearchProducts), ctx, q)
+}
+
+// SetProductCategories mocks base method.
+func (m *Mockrepository) SetProductCategories(ctx context.Context, productID uuid.UUID, categoryIDs []uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetProductCategories", ctx, productID, categoryIDs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetProductCategories indicates an expected call of SetProductCategories.
+func (mr *MockrepositoryMockRecorder) SetProductCategories(ctx, productID, categoryIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetProductCategories", reflect.TypeOf((*Mockrepository)(nil).SetProductCategories), ctx, productID, categoryIDs)
+}
+
+// SuggestProducts mocks base method.
+func (m *Mockrepository) SuggestProducts(ctx context.Context, prefix string, limit int) ([]ProductSuggestion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SuggestProducts", ctx, prefix, limit)
+	ret0, _ := ret[0].([]ProductSuggestion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SuggestProducts indicates an expected call of SuggestProducts.
+func (mr *MockrepositoryMockRecorder) SuggestProducts(ctx, prefix, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SuggestProducts", reflect.TypeOf((*Mockrepository)(nil).SuggestProducts), ctx, prefix, limit)
+}
+
+// UpdateCategory mocks base method.
+func (m *Mockrepository) UpdateCategory(ctx context.Context, c *Category) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCategory", ctx, c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCategory indicates an expected call of UpdateCategory.
+func (mr *MockrepositoryMockRecorder) UpdateCategory(ctx, c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCategory", reflect.TypeOf((*Mockrepository)(nil).UpdateCategory), ctx, c)
+}
+
+// UpdateProduct mocks base method.
+func (m *Mockrepository) UpdateProduct(ctx context.Context, p *Product) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProduct", ctx, p)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProduct indicates an expected call of UpdateProduct.
+func (mr *MockrepositoryMockRecorder) UpdateProduct(ctx, p any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProduct", reflect.TypeOf((*Mockrepository)(nil).UpdateProduct), ctx, p)
+}
+
+// UpdateReservationStatus mocks base method.
+func (m *Mockrepository) UpdateReservationStatus(ctx context.Context, r *Reservation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateReservationStatus", ctx, r)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateReservationStatus indicates an expected call of UpdateReservationStatus.
+func (mr *MockrepositoryMockRecorder) UpdateReservationStatus(ctx, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReservationStatus", reflect.TypeOf((*Mockrepository)(nil).UpdateReservationStatus), ctx, r)
+}
+
+// UpdateVariant mocks base method.
+func (m *Mockrepository) UpdateVariant(ctx context.Context, v *Variant) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateVariant", ctx, v)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateVariant indicates an expected call of UpdateVariant.
+func (mr *MockrepositoryMockRecorder) UpdateVariant(ctx, v any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateVariant", reflect.TypeOf((*Mockrepository)(nil).UpdateVariant), ctx, v)
+}
+
+// WithinTx mocks base method.
+func (m *Mockrepository) WithinTx(ctx context.Context, fn func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithinTx", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithinTx indicates an expected call of WithinTx.
+func (mr *MockrepositoryMockRecorder) WithinTx(ctx, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithinTx", reflect.TypeOf((*Mockrepository)(nil).WithinTx), ctx, fn)
+}
@@ -1,374 +1,871 @@
-package app
-
-import (
-	"context"
-	"errors"
-	"fmt"
-	"testing"
-	"time"
-
-	"github.com/google/uuid"
-	"github.com/stretchr/testify/assert"
-	"go.uber.org/mock/gomock"
-)
-
-func TestNewService(t *testing.T) {
-	s, err := NewService(nil)
-	assert.EqualError(t, err, "repository is nil")
-	assert.Empty(t, s)
-}
-
-func TestService_CreateProduct(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	ctx := context.Background()
-
-	dto := CreateProductDTO{
-		Name:        "Test Product",
-		Description: "Test Description",
-		Price:       100.0,
-	}
-
-	tests := []struct {
-		name                    string
-		dto                     CreateProductDTO
-		expRepoCreateProductErr error
-		expErr                  error
-	}{
-		{
-			name:                    "product was created successfully",
-			dto:                     dto,
-			expRepoCreateProductErr: nil,
-			expErr:                  nil,
-		},
-		{
-			name:                    "error creating product",
-			dto:                     dto,
-			expRepoCreateProductErr: errors.New("repo error"),
-			expErr:                  fmt.Errorf("failed to create product: %w", errors.New("repo error")),
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// Mock expectations
-			mockRepository := NewMockrepository(ctrl)
-
-			mockRepository.EXPECT().
-				CreateProduct(gomock.Any(), gomock.Any()).
-				Return(tt.expRepoCreateProductErr)
-
-			// Exercise
-			s, err := NewService(mockRepository)
-			assert.NoError(t, err)
-
-			p, err := s.CreateProduct(ctx, tt.dto)
-			if tt.expErr != nil {
-				assert.Equal(t, tt.expErr, err)
-				assert.Nil(t, p)
-			} else {
-				assert.NoError(t, err)
-				assert.NotNil(t, p.ID)
-				assert.Equal(t, tt.dto.Name, p.Name)
-				assert.Equal(t, tt.dto.Description, p.Description)
-				assert.Equal(t, tt.dto.Price, p.Price)
-				assert.False(t, p.CreatedAt.IsZero())
-				assert.False(t, p.UpdatedAt.IsZero())
-			}
-		})
-	}
-}
-
-func TestService_UpdateProduct(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	ctx := context.Background()
-
-	productID := uuid.New()
-
-	dto := UpdateProductDTO{
-		ID:          productID,
-		Name:        "New Product Name",
-		Description: "New Product Description",
-		Price:       200.0,
-	}
-
-	now := time.Now()
-
-	expProduct := &Product{
-		ID:          productID,
-		Name:        "Test Product",
-		Description: "Test Description",
-		Price:       100.0,
-		CreatedAt:   now,
-		UpdatedAt:   now,
-	}
-
-	tests := []struct {
-		name                    string
-		dto                     UpdateProductDTO
-		expRepoGetProductResult *Product
-		expRepoGetProductErr    error
-		expRepoUpdateProductErr error
-		expErr                  error
-	}{
-		{
-			name:                    "product was updated successfully",
-			dto:                     dto,
-			expRepoGetProductResult: expProduct,
-			expRepoGetProductErr:    nil,
-			expRepoUpdateProductErr: nil,
-			expErr:                  nil,
-		},
-		{
-			name:                    "error getting product",
-			dto:                     dto,
-			expRepoGetProductResult: nil,
-			expRepoGetProductErr:    errors.New("repo error"),
-			expRepoUpdateProductErr: nil,
-			expErr:                  fmt.Errorf("failed to get product: %w", errors.New("repo error")),
-		},
-		{
-			name:                    "error updating product",
-			dto:                     dto,
-			expRepoGetProductResult: expProduct,
-			expRepoGetProductErr:    nil,
-			expRepoUpdateProductErr: errors.New("repo error"),
-			expErr:                  fmt.Errorf("failed to update product: %w", errors.New("repo error")),
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// Mock expectations
-			mockRepository := NewMockrepository(ctrl)
-
-			mockRepository.EXPECT().
-				GetProduct(gomock.Any(), productID).
-				Return(tt.expRepoGetProductResult, tt.expRepoGetProductErr)
-
-			if tt.expRepoGetProductErr == nil {
-				mockRepository.EXPECT().
-					UpdateProduct(gomock.Any(), gomock.Any()).
-					Return(tt.expRepoUpdateProductErr)
-			}
-
-			// Exercise
-			s, err := NewService(mockRepository)
-			assert.NoError(t, err)
-
-			p, err := s.UpdateProduct(ctx, tt.dto)
-			if tt.expErr != nil {
-				assert.Equal(t, tt.expErr, err)
-				assert.Nil(t, p)
-			} else {
-				assert.NoError(t, err)
-				assert.Equal(t, productID, p.ID)
-				assert.Equal(t, tt.dto.Name, p.Name)
-				assert.Equal(t, tt.dto.Description, p.Description)
-				assert.Equal(t, tt.dto.Price, p.Price)
-				assert.False(t, p.CreatedAt.IsZero())
-				assert.False(t, p.UpdatedAt.IsZero())
-			}
-		})
-	}
-}
-
-func TestService_DeleteProduct(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	ctx := context.Background()
-
-	productID := uuid.New()
-
-	tests := []struct {
-		name                    string
-		expRepoDeleteProductErr error
-		expErr                  error
-	}{
-		{
-			name:                    "product was deleted successfully",
-			expRepoDeleteProductErr: nil,
-			expErr:                  nil,
-		},
-		{
-			name:                    "error deleting product",
-			expRepoDeleteProductErr: errors.New("repo error"),
-			expErr:                  fmt.Errorf("failed to delete product: %w", errors.New("repo error")),
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// Mock expectations
-			mockRepository := NewMockrepository(ctrl)
-
-			mockRepository.EXPECT().
-				DeleteProduct(gomock.Any(), productID).
-				Return(tt.expRepoDeleteProductErr)
-
-			// Exercise
-			s, err := NewService(mockRepository)
-			assert.NoError(t, err)
-
-			err = s.DeleteProduct(ctx, productID)
-			if tt.expErr != nil {
-				assert.Equal(t, tt.expErr, err)
-			} else {
-				assert.NoError(t, err)
-			}
-		})
-	}
-}
-
-func TestService_GetProduct(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	ctx := context.Background()
-
-	productID := uuid.New()
-
-	now := time.Now()
-
-	expProduct := &Product{
-		ID:          productID,
-		Name:        "Test Product",
-		Description: "Test Description",
-		Price:       100.0,
-		CreatedAt:   now,
-		UpdatedAt:   now,
-	}
-
-	tests := []struct {
-		name                    string
-		expRepoGetProductResult *Product
-		expRepoGetProductErr    error
-		expErr                  error
-	}{
-		{
-			name:                    "product was retrieved successfully",
-			expRepoGetProductResult: expProduct,
-			expRepoGetProductErr:    nil,
-			expErr:                  nil,
-		},
-		{
-			name:                    "error getting product",
-			expRepoGetProductResult: nil,
-			expRepoGetProductErr:    errors.New("repo error"),
-			expErr:                  fmt.Errorf("failed to get product: %w", errors.New("repo error")),
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// Mock expectations
-			mockRepository := NewMockrepository(ctrl)
-
-			mockRepository.EXPECT().
-				GetProduct(gomock.Any(), productID).
-				Return(tt.expRepoGetProductResult, tt.expRepoGetProductErr)
-
-			// Exercise
-			s, err := NewService(mockRepository)
-			assert.NoError(t, err)
-
-			p, err := s.GetProduct(ctx, productID)
-			if tt.expErr != nil {
-				assert.Equal(t, tt.expErr, err)
-				assert.Nil(t, p)
-			} else {
-				assert.NoError(t, err)
-				assert.Equal(t, tt.expRepoGetProductResult.ID, p.ID)
-				assert.Equal(t, tt.expRepoGetProductResult.Name, p.Name)
-				assert.Equal(t, tt.expRepoGetProductResult.Description, p.Description)
-				assert.Equal(t, tt.expRepoGetProductResult.Price, p.Price)
-				assert.Equal(t, tt.expRepoGetProductResult.CreatedAt, p.CreatedAt)
-				assert.Equal(t, tt.expRepoGetProductResult.UpdatedAt, p.UpdatedAt)
-			}
-		})
-	}
-}
-
-func TestService_GetProducts(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	ctx := context.Background()
-
-	now := time.Now()
-
-	expProductA := &Product{
-		ID:          uuid.New(),
-		Name:        "Test Product A",
-		Description: "Test Description A",
-		Price:       100.0,
-		CreatedAt:   now,
-		UpdatedAt:   now,
-	}
-
-	expProductB := &Product{
-		ID:          uuid.New(),
-		Name:        "Test Product B",
-		Description: "Test Description B",
-		Price:       200.0,
-		CreatedAt:   now,
-		UpdatedAt:   now,
-	}
-
-	limit := 20
-	offset := 0
-
-	tests := []struct {
-		name                     string
-		expRepoGetProductsResult []*Product
-		expRepoGetProductsErr    error
-		expErr                   error
-	}{
-		{
-			name:                     "products were retrieved successfully",
-			expRepoGetProductsResult: []*Product{expProductA, expProductB},
-			expRepoGetProductsErr:    nil,
-			expErr:                   nil,
-		},
-		{
-			name:                     "error getting products",
-			expRepoGetProductsResult: nil,
-			expRepoGetProductsErr:    errors.New("repo error"),
-			expErr:                   fmt.Errorf("failed to get products: %w", errors.New("repo error")),
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// Mock expectations
-			mockRepository := NewMockrepository(ctrl)
-
-			mockRepository.EXPECT().
-				GetProducts(gomock.Any(), limit, offset).
-				Return(tt.expRepoGetProductsResult, tt.expRepoGetProductsErr)
-
-			// Exercise
-			s, err := NewService(mockRepository)
-			assert.NoError(t, err)
-
-			products, err := s.GetProducts(ctx, limit, offset)
-			if tt.expErr != nil {
-				assert.Equal(t, tt.expErr, err)
-				assert.Nil(t, products)
-			} else {
-				assert.NoError(t, err)
-				assert.Len(t, products, len(tt.expRepoGetProductsResult))
-
-				productA := products[0]
-				assert.Equal(t, tt.expRepoGetProductsResult[0].ID, productA.ID)
-				assert.Equal(t, tt.expRepoGetProductsResult[0].Name, productA.Name)
-				assert.Equal(t, tt.expRepoGetProductsResult[0].Description, productA.Description)
-				assert.Equal(t, tt.expRepoGetProductsResult[0].Price, productA.Price)
-				assert.Equal(t, tt.expRepoGetProductsResult[0].CreatedAt, productA.CreatedAt)
-				assert.Equal(t, tt.expRepoGetProductsResult[0].UpdatedAt, productA.UpdatedAt)
-
-				productB := products[1]
-				assert.Equal(t, tt.expRepoGetProductsResult[1].ID, productB.ID)
-				assert.Equal(t, tt.expRepoGetProductsResult[1].Name, productB.Name)
-				assert.Equal(t, tt.expRepoGetProductsResult[1].Description, productB.Description)
-				assert.Equal(t, tt.expRepoGetProductsResult[1].Price, productB.Price)
-				assert.Equal(t, tt.expRepoGetProductsResult[1].CreatedAt, productB.CreatedAt)
-				assert.Equal(t, tt.expRepoGetProductsResult[1].UpdatedAt, productB.UpdatedAt)
-			}
-		})
-	}
-}
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNewService(t *testing.T) {
+	s, err := NewService(nil)
+	assert.EqualError(t, err, "repository is nil")
+	assert.Empty(t, s)
+}
+
+func TestService_CreateProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+
+	dto := CreateProductDTO{
+		Name:        "Test Product",
+		Description: "Test Description",
+		Price:       NewMoney(10000, "USD"),
+	}
+
+	tests := []struct {
+		name                    string
+		dto                     CreateProductDTO
+		expRepoGetSlugsResult   []string
+		expRepoCreateProductErr error
+		expSlug                 string
+		expErr                  error
+	}{
+		{
+			name:                    "product was created successfully",
+			dto:                     dto,
+			expRepoCreateProductErr: nil,
+			expSlug:                 "test-product",
+			expErr:                  nil,
+		},
+		{
+			name:                  "slug taken",
+			dto:                   dto,
+			expRepoGetSlugsResult: []string{"test-product", "test-product-3"},
+			expSlug:               "test-product-2",
+		},
+		{
+			name: "product with a SKU",
+			dto: CreateProductDTO{
+				Name:  "Test Product",
+				Price: NewMoney(10000, "USD"),
+				SKU:   " TP-001 ",
+			},
+			expSlug: "test-product",
+		},
+		{
+			name:                    "error creating product",
+			dto:                     dto,
+			expRepoCreateProductErr: errors.New("repo error"),
+			expErr:                  fmt.Errorf("failed to create product: %w", errors.New("repo error")),
+		},
+		{
+			name: "invalid SKU",
+			dto: CreateProductDTO{
+				Name:  "Test Product",
+				Price: NewMoney(10000, "USD"),
+				SKU:   "TP 001",
+			},
+			expErr: fmt.Errorf("invalid product: %w", &ValidationError{Violations: []Violation{
+				{Field: "sku", Message: "must contain only letters, digits, dots, dashes, underscores and slashes, starting with a letter or a digit"},
+			}}),
+		},
+		{
+			name: "invalid product",
+			dto: CreateProductDTO{
+				Name:        "   ",
+				Description: "Test Description",
+				Price:       NewMoney(-1, "USD"),
+			},
+			expErr: fmt.Errorf("invalid product: %w", &ValidationError{Violations: []Violation{
+				{Field: "name", Message: "must not be empty"},
+				{Field: "price", Message: "must not be negative"},
+			}}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mock expectations
+			mockRepository := NewMockrepository(ctrl)
+
+			if !errors.Is(tt.expErr, ErrValidation) {
+				mockRepository.EXPECT().
+					GetSlugs(gomock.Any(), "test-product").
+					Return(tt.expRepoGetSlugsResult, nil)
+				expectSaveProduct(mockRepository, tt.expRepoCreateProductErr == nil)
+				mockRepository.EXPECT().
+					CreateProduct(gomock.Any(), gomock.Any()).
+					Return(tt.expRepoCreateProductErr)
+			}
+
+			// Exercise
+			s, err := NewService(mockRepository)
+			assert.NoError(t, err)
+
+			p, err := s.CreateProduct(ctx, tt.dto)
+			if tt.expErr != nil {
+				assert.Equal(t, tt.expErr, err)
+				assert.Nil(t, p)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, p.ID)
+				assert.Equal(t, tt.dto.Name, p.Name)
+				assert.Equal(t, tt.dto.Description, p.Description)
+				assert.Equal(t, tt.dto.Price, p.Price)
+				assert.Equal(t, strings.TrimSpace(tt.dto.SKU), p.SKU)
+				assert.Equal(t, tt.expSlug, p.Slug)
+				assert.False(t, p.CreatedAt.IsZero())
+				assert.False(t, p.UpdatedAt.IsZero())
+			}
+		})
+	}
+}
+
+func TestService_UpdateProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+
+	productID := uuid.New()
+
+	dto := UpdateProductDTO{
+		ID:          productID,
+		Name:        "New Product Name",
+		Description: "New Product Description",
+		Price:       NewMoney(20000, "USD"),
+	}
+
+	now := time.Now()
+
+	expProduct := &Product{
+		ID:          productID,
+		Name:        "Test Product",
+		Description: "Test Description",
+		Price:       NewMoney(10000, "USD"),
+		Version:     1,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	shoes := NewCategory("Shoes", nil, map[string]any{
+		"type":     "object",
+		"required": []any{"size"},
+		"properties": map[string]any{
+			"size": map[string]any{"type": "integer", "minimum": json.Number("16")},
+		},
+	})
+
+	tests := []struct {
+		name                              string
+		dto                               UpdateProductDTO
+		expRepoGetProductResult           *Product
+		expRepoGetProductErr              error
+		expRepoGetProductCategoriesResult []*Category
+		expRepoUpdateProductErr           error
+		expErr                            error
+	}{
+		{
+			name:                    "product was updated successfully",
+			dto:                     dto,
+			expRepoGetProductResult: expProduct,
+			expRepoGetProductErr:    nil,
+			expRepoUpdateProductErr: nil,
+			expErr:                  nil,
+		},
+		{
+			name:                    "error getting product",
+			dto:                     dto,
+			expRepoGetProductResult: nil,
+			expRepoGetProductErr:    errors.New("repo error"),
+			expRepoUpdateProductErr: nil,
+			expErr:                  fmt.Errorf("failed to get product: %w", errors.New("repo error")),
+		},
+		{
+			name:                    "error updating product",
+			dto:                     dto,
+			expRepoGetProductResult: expProduct,
+			expRepoGetProductErr:    nil,
+			expRepoUpdateProductErr: errors.New("repo error"),
+			expErr:                  fmt.Errorf("failed to update product: %w", errors.New("repo error")),
+		},
+		{
+			name: "stale expected version",
+			dto: UpdateProductDTO{
+				ID:              productID,
+				ExpectedVersion: 5,
+				Name:            "New Product Name",
+				Description:     "New Product Description",
+				Price:           NewMoney(20000, "USD"),
+			},
+			expRepoGetProductResult: expProduct,
+			expErr:                  fmt.Errorf("%w: product %s is at version 1, not 5", ErrPreconditionFailed, productID),
+		},
+		{
+			name: "attributes satisfy the category schema",
+			dto: UpdateProductDTO{
+				ID:          productID,
+				Name:        "New Product Name",
+				Description: "New Product Description",
+				Price:       NewMoney(20000, "USD"),
+				Attributes:  map[string]any{"size": json.Number("42")},
+			},
+			expRepoGetProductResult:           expProduct,
+			expRepoGetProductCategoriesResult: []*Category{shoes},
+		},
+		{
+			name: "attributes violate the category schema",
+			dto: UpdateProductDTO{
+				ID:          productID,
+				Name:        "New Product Name",
+				Description: "New Product Description",
+				Price:       NewMoney(20000, "USD"),
+				Attributes:  map[string]any{"size": json.Number("8")},
+			},
+			expRepoGetProductResult:           expProduct,
+			expRepoGetProductCategoriesResult: []*Category{shoes},
+			expErr: fmt.Errorf("invalid product: %w", &ValidationError{Violations: []Violation{
+				{Field: "attributes.size", Message: "must be at least 16"},
+			}}),
+		},
+		{
+			name: "invalid product",
+			dto: UpdateProductDTO{
+				ID:          productID,
+				Name:        "New Product Name",
+				Description: "New Product Description",
+				Price:       NewMoney(1000, "XXX"),
+			},
+			expErr: fmt.Errorf("invalid product: %w", &ValidationError{Violations: []Violation{
+				{Field: "currency", Message: "must be a supported ISO 4217 currency code"},
+			}}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mock expectations
+			mockRepository := NewMockrepository(ctrl)
+
+			if tt.expRepoGetProductResult != nil || tt.expRepoGetProductErr != nil {
+				mockRepository.EXPECT().
+					GetProduct(gomock.Any(), productID).
+					Return(tt.expRepoGetProductResult, tt.expRepoGetProductErr)
+			}
+
+			if tt.expRepoGetProductResult != nil && !errors.Is(tt.expErr, ErrPreconditionFailed) {
+				expectSaveProduct(mockRepository, tt.expErr == nil)
+				expectAttributesChecked(mockRepository, productID, tt.expRepoGetProductCategoriesResult)
+			}
+
+			if len(tt.expRepoGetProductCategoriesResult) > 0 {
+				mockRepository.EXPECT().
+					GetCategoriesByID(inTx(), []uuid.UUID{shoes.ID}).
+					Return(tt.expRepoGetProductCategoriesResult, nil)
+			}
+
+			if tt.expRepoGetProductResult != nil && !errors.Is(tt.expErr, ErrPreconditionFailed) && !errors.Is(tt.expErr, ErrValidation) {
+				mockRepository.EXPECT().
+					UpdateProduct(gomock.Any(), gomock.Any()).
+					Return(tt.expRepoUpdateProductErr)
+			}
+
+			// Exercise
+			s, err := NewService(mockRepository)
+			assert.NoError(t, err)
+
+			p, err := s.UpdateProduct(ctx, tt.dto)
+			if tt.expErr != nil {
+				assert.Equal(t, tt.expErr, err)
+				assert.Nil(t, p)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, productID, p.ID)
+				assert.Equal(t, tt.dto.Name, p.Name)
+				assert.Equal(t, tt.dto.Description, p.Description)
+				assert.Equal(t, tt.dto.Price, p.Price)
+				assert.False(t, p.CreatedAt.IsZero())
+				assert.False(t, p.UpdatedAt.IsZero())
+			}
+		})
+	}
+}
+
+func TestService_PatchProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+
+	productID := uuid.New()
+
+	now := time.Now()
+
+	newProduct := func() *Product {
+		return &Product{
+			ID:          productID,
+			Name:        "Test Product",
+			Description: "Test Description",
+			Price:       NewMoney(10000, "USD"),
+			Tags:        []string{"seasonal"},
+			Attributes:  map[string]any{"color": "red", "size": json.Number("42")},
+			Version:     2,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+	}
+
+	tests := []struct {
+		name                    string
+		dto                     PatchProductDTO
+		expRepoGetProductErr    error
+		expRepoUpdateProductErr error
+		expProduct              *Product
+		expErr                  error
+	}{
+		{
+			name: "merge patch only changes the supplied fields",
+			dto: PatchProductDTO{
+				ID:     productID,
+				Format: PatchFormatMergePatch,
+				Patch:  []byte(`{"name":"  Patched Product ","price":"12.50"}`),
+			},
+			expProduct: &Product{
+				ID:          productID,
+				Name:        "Patched Product",
+				Description: "Test Description",
+				Price:       NewMoney(1250, "USD"),
+			},
+		},
+		{
+			name: "json patch changes the price and currency",
+			dto: PatchProductDTO{
+				ID:     productID,
+				Format: PatchFormatJSONPatch,
+				Patch:  []byte(`[{"op":"test","path":"/currency","value":"USD"},{"op":"replace","path":"/price","value":1500},{"op":"replace","path":"/currency","value":"JPY"}]`),
+			},
+			expProduct: &Product{
+				ID:          productID,
+				Name:        "Test Product",
+				Description: "Test Description",
+				Price:       NewMoney(1500, "JPY"),
+			},
+		},
+		{
+			name: "json patch appends a tag",
+			dto: PatchProductDTO{
+				ID:     productID,
+				Format: PatchFormatJSONPatch,
+				Patch:  []byte(`[{"op":"add","path":"/tags/-","value":"Clearance"}]`),
+			},
+			expProduct: &Product{
+				ID:          productID,
+				Name:        "Test Product",
+				Description: "Test Description",
+				Price:       NewMoney(10000, "USD"),
+				Tags:        []string{"clearance", "seasonal"},
+			},
+		},
+		{
+			name: "merge patch merges the attributes",
+			dto: PatchProductDTO{
+				ID:     productID,
+				Format: PatchFormatMergePatch,
+				Patch:  []byte(`{"attributes":{"size":null,"material":"leather"}}`),
+			},
+			expProduct: &Product{
+				ID:          productID,
+				Name:        "Test Product",
+				Description: "Test Description",
+				Price:       NewMoney(10000, "USD"),
+				Attributes:  map[string]any{"color": "red", "material": "leather"},
+			},
+		},
+		{
+			name: "error getting product",
+			dto: PatchProductDTO{
+				ID:     productID,
+				Format: PatchFormatMergePatch,
+				Patch:  []byte(`{"name":"Patched Product"}`),
+			},
+			expRepoGetProductErr: errors.New("repo error"),
+			expErr:               fmt.Errorf("failed to get product: %w", errors.New("repo error")),
+		},
+		{
+			name: "matching expected version",
+			dto: PatchProductDTO{
+				ID:              productID,
+				ExpectedVersion: 2,
+				Format:          PatchFormatMergePatch,
+				Patch:           []byte(`{"description":"Patched Description"}`),
+			},
+			expProduct: &Product{
+				ID:          productID,
+				Name:        "Test Product",
+				Description: "Patched Description",
+				Price:       NewMoney(10000, "USD"),
+			},
+		},
+		{
+			name: "stale expected version",
+			dto: PatchProductDTO{
+				ID:              productID,
+				ExpectedVersion: 1,
+				Format:          PatchFormatMergePatch,
+				Patch:           []byte(`{"name":"Patched Product"}`),
+			},
+			expErr: ErrPreconditionFailed,
+		},
+		{
+			name: "malformed patch",
+			dto: PatchProductDTO{
+				ID:     productID,
+				Format: PatchFormatMergePatch,
+				Patch:  []byte(`{`),
+			},
+			expErr: ErrInvalidPatch,
+		},
+		{
+			name: "failed test operation",
+			dto: PatchProductDTO{
+				ID:     productID,
+				Format: PatchFormatJSONPatch,
+				Patch:  []byte(`[{"op":"test","path":"/name","value":"Other Product"}]`),
+			},
+			expErr: ErrConflict,
+		},
+		{
+			name: "patch sets an unknown field",
+			dto: PatchProductDTO{
+				ID:     productID,
+				Format: PatchFormatMergePatch,
+				Patch:  []byte(`{"id":"c0ffee"}`),
+			},
+			expErr: fmt.Errorf("invalid product: %w", &ValidationError{Violations: []Violation{
+				{Field: "id", Message: "is not a modifiable product field"},
+			}}),
+		},
+		{
+			name: "patched product fails validation",
+			dto: PatchProductDTO{
+				ID:     productID,
+				Format: PatchFormatJSONPatch,
+				Patch:  []byte(`[{"op":"remove","path":"/name"}]`),
+			},
+			expErr: fmt.Errorf("invalid product: %w", &ValidationError{Violations: []Violation{
+				{Field: "name", Message: "must not be empty"},
+			}}),
+		},
+		{
+			name: "error updating product",
+			dto: PatchProductDTO{
+				ID:     productID,
+				Format: PatchFormatMergePatch,
+				Patch:  []byte(`{"name":"Patched Product"}`),
+			},
+			expRepoUpdateProductErr: errors.New("repo error"),
+			expErr:                  fmt.Errorf("failed to update product: %w", errors.New("repo error")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mock expectations
+			mockRepository := NewMockrepository(ctrl)
+
+			if tt.expRepoGetProductErr != nil {
+				mockRepository.EXPECT().
+					GetProduct(gomock.Any(), productID).
+					Return(nil, tt.expRepoGetProductErr)
+			} else {
+				mockRepository.EXPECT().
+					GetProduct(gomock.Any(), productID).
+					Return(newProduct(), nil)
+			}
+
+			if tt.expProduct != nil || tt.expRepoUpdateProductErr != nil {
+				expectAttributesChecked(mockRepository, productID, nil)
+
+				expectSaveProduct(mockRepository, tt.expRepoUpdateProductErr == nil)
+				mockRepository.EXPECT().
+					UpdateProduct(gomock.Any(), gomock.Any()).
+					Return(tt.expRepoUpdateProductErr)
+			}
+
+			// Exercise
+			s, err := NewService(mockRepository)
+			assert.NoError(t, err)
+
+			p, err := s.PatchProduct(ctx, tt.dto)
+			switch {
+			case tt.expErr == ErrInvalidPatch || tt.expErr == ErrConflict || tt.expErr == ErrPreconditionFailed:
+				assert.True(t, errors.Is(err, tt.expErr), "got %v", err)
+				assert.Nil(t, p)
+			case tt.expErr != nil:
+				assert.Equal(t, tt.expErr, err)
+				assert.Nil(t, p)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expProduct.ID, p.ID)
+				assert.Equal(t, tt.expProduct.Name, p.Name)
+				assert.Equal(t, tt.expProduct.Description, p.Description)
+				assert.Equal(t, tt.expProduct.Price, p.Price)
+				if tt.expProduct.Tags != nil {
+					assert.Equal(t, tt.expProduct.Tags, p.Tags)
+				}
+				if tt.expProduct.Attributes != nil {
+					assert.Equal(t, tt.expProduct.Attributes, p.Attributes)
+				}
+				assert.Equal(t, now, p.CreatedAt)
+				assert.True(t, p.UpdatedAt.After(now))
+			}
+		})
+	}
+}
+
+func TestService_DeleteProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+
+	productID := uuid.New()
+
+	tests := []struct {
+		name                    string
+		expRepoDeleteProductErr error
+		expErr                  error
+	}{
+		{
+			name:                    "product was deleted successfully",
+			expRepoDeleteProductErr: nil,
+			expErr:                  nil,
+		},
+		{
+			name:                    "error deleting product",
+			expRepoDeleteProductErr: errors.New("repo error"),
+			expErr:                  fmt.Errorf("failed to delete product: %w", errors.New("repo error")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mock expectations
+			mockRepository := NewMockrepository(ctrl)
+
+			var result *Product
+			if tt.expRepoDeleteProductErr == nil {
+				result = &Product{ID: productID, Version: 2}
+			}
+			expectSaveProduct(mockRepository, tt.expRepoDeleteProductErr == nil)
+			mockRepository.EXPECT().
+				DeleteProduct(gomock.Any(), productID, int64(0)).
+				Return(result, tt.expRepoDeleteProductErr)
+
+			// Exercise
+			s, err := NewService(mockRepository)
+			assert.NoError(t, err)
+
+			err = s.DeleteProduct(ctx, DeleteProductDTO{ID: productID})
+			if tt.expErr != nil {
+				assert.Equal(t, tt.expErr, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestService_GetProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+
+	productID := uuid.New()
+
+	now := time.Now()
+
+	expProduct := &Product{
+		ID:          productID,
+		Name:        "Test Product",
+		Description: "Test Description",
+		Price:       NewMoney(10000, "USD"),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	tests := []struct {
+		name                    string
+		expRepoGetProductResult *Product
+		expRepoGetProductErr    error
+		expErr                  error
+	}{
+		{
+			name:                    "product was retrieved successfully",
+			expRepoGetProductResult: expProduct,
+			expRepoGetProductErr:    nil,
+			expErr:                  nil,
+		},
+		{
+			name:                    "error getting product",
+			expRepoGetProductResult: nil,
+			expRepoGetProductErr:    errors.New("repo error"),
+			expErr:                  fmt.Errorf("failed to get product: %w", errors.New("repo error")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mock expectations
+			mockRepository := NewMockrepository(ctrl)
+
+			mockRepository.EXPECT().
+				GetProduct(gomock.Any(), productID).
+				Return(tt.expRepoGetProductResult, tt.expRepoGetProductErr)
+
+			// Exercise
+			s, err := NewService(mockRepository)
+			assert.NoError(t, err)
+
+			p, err := s.GetProduct(ctx, productID)
+			if tt.expErr != nil {
+				assert.Equal(t, tt.expErr, err)
+				assert.Nil(t, p)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expRepoGetProductResult.ID, p.ID)
+				assert.Equal(t, tt.expRepoGetProductResult.Name, p.Name)
+				assert.Equal(t, tt.expRepoGetProductResult.Description, p.Description)
+				assert.Equal(t, tt.expRepoGetProductResult.Price, p.Price)
+				assert.Equal(t, tt.expRepoGetProductResult.CreatedAt, p.CreatedAt)
+				assert.Equal(t, tt.expRepoGetProductResult.UpdatedAt, p.UpdatedAt)
+			}
+		})
+	}
+}
+
+func TestService_GetProducts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+
+	now := time.Now()
+
+	// Only published products are listed by default.
+	published := ProductFilter{Statuses: []ProductStatus{ProductPublished}}
+
+	expProductA := &Product{
+		ID:          uuid.New(),
+		Name:        "Test Product A",
+		Description: "Test Description A",
+		Price:       NewMoney(10000, "USD"),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	expProductB := &Product{
+		ID:          uuid.New(),
+		Name:        "Test Product B",
+		Description: "Test Description B",
+		Price:       NewMoney(20000, "USD"),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	limit := 20
+	offset := 0
+
+	tests := []struct {
+		name                       string
+		count                      bool
+		expRepoGetProductsResult   []*Product
+		expRepoGetProductsErr      error
+		expRepoCountProductsResult int
+		expRepoCountProductsErr    error
+		expErr                     error
+	}{
+		{
+			name:                     "products were retrieved successfully",
+			expRepoGetProductsResult: []*Product{expProductA, expProductB},
+			expRepoGetProductsErr:    nil,
+			expErr:                   nil,
+		},
+		{
+			name:                       "products were retrieved with their total count",
+			count:                      true,
+			expRepoGetProductsResult:   []*Product{expProductA, expProductB},
+			expRepoCountProductsResult: 42,
+		},
+		{
+			name:                    "error counting products",
+			count:                   true,
+			expRepoCountProductsErr: errors.New("repo error"),
+			expErr:                  fmt.Errorf("failed to count products: %w", errors.New("repo error")),
+		},
+		{
+			name:                     "error getting products",
+			expRepoGetProductsResult: nil,
+			expRepoGetProductsErr:    errors.New("repo error"),
+			expErr:                   fmt.Errorf("failed to get products: %w", errors.New("repo error")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mock expectations
+			mockRepository := NewMockrepository(ctrl)
+
+			mockRepository.EXPECT().
+				GetProducts(gomock.Any(), ProductsQuery{Filter: published, Sort: DefaultSort, Limit: limit + 1, Offset: offset}).
+				Return(tt.expRepoGetProductsResult, tt.expRepoGetProductsErr)
+
+			if tt.count {
+				mockRepository.EXPECT().
+					CountProducts(gomock.Any(), published).
+					Return(tt.expRepoCountProductsResult, tt.expRepoCountProductsErr)
+			}
+
+			// Exercise
+			s, err := NewService(mockRepository)
+			assert.NoError(t, err)
+
+			page, err := s.GetProducts(ctx, GetProductsDTO{Limit: limit, Offset: offset, Count: tt.count})
+			if tt.expErr != nil {
+				assert.Equal(t, tt.expErr, err)
+				assert.Nil(t, page)
+			} else {
+				assert.NoError(t, err)
+				assert.Nil(t, page.NextCursor)
+				assert.Nil(t, page.PrevCursor)
+				if tt.count {
+					assert.Equal(t, &tt.expRepoCountProductsResult, page.Total)
+				} else {
+					assert.Nil(t, page.Total)
+				}
+
+				products := page.Products
+				assert.Len(t, products, len(tt.expRepoGetProductsResult))
+
+				productA := products[0]
+				assert.Equal(t, tt.expRepoGetProductsResult[0].ID, productA.ID)
+				assert.Equal(t, tt.expRepoGetProductsResult[0].Name, productA.Name)
+				assert.Equal(t, tt.expRepoGetProductsResult[0].Description, productA.Description)
+				assert.Equal(t, tt.expRepoGetProductsResult[0].Price, productA.Price)
+				assert.Equal(t, tt.expRepoGetProductsResult[0].CreatedAt, productA.CreatedAt)
+				assert.Equal(t, tt.expRepoGetProductsResult[0].UpdatedAt, productA.UpdatedAt)
+
+				productB := products[1]
+				assert.Equal(t, tt.expRepoGetProductsResult[1].ID, productB.ID)
+				assert.Equal(t, tt.expRepoGetProductsResult[1].Name, productB.Name)
+				assert.Equal(t, tt.expRepoGetProductsResult[1].Description, productB.Description)
+				assert.Equal(t, tt.expRepoGetProductsResult[1].Price, productB.Price)
+				assert.Equal(t, tt.expRepoGetProductsResult[1].CreatedAt, productB.CreatedAt)
+				assert.Equal(t, tt.expRepoGetProductsResult[1].UpdatedAt, productB.UpdatedAt)
+			}
+		})
+	}
+}
+
+func TestService_SearchProducts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+
+	matches := []ProductMatch{
+		{Product: &Product{ID: uuid.New(), Name: "Desk Lamp"}, Rank: 0.6, NameHighlight: "Desk <mark>Lamp</mark>"},
+		{Product: &Product{ID: uuid.New(), Name: "Floor Lamp"}, Rank: 0.5, NameHighlight: "Floor <mark>Lamp</mark>"},
+		{Product: &Product{ID: uuid.New(), Name: "Lamp Shade"}, Rank: 0.4, NameHighlight: "<mark>Lamp</mark> Shade"},
+	}
+
+	tests := []struct {
+		name                        string
+		dto                         SearchProductsDTO
+		expRepoSearchProductsResult []ProductMatch
+		expRepoSearchProductsErr    error
+		expPage                     *ProductSearchPage
+		expErr                      error
+	}{
+		{
+			name:                        "more matches than the limit",
+			dto:                         SearchProductsDTO{Query: "  lamp ", Limit: 2},
+			expRepoSearchProductsResult: matches,
+			expPage:                     &ProductSearchPage{Matches: matches[:2], HasMore: true},
+		},
+		{
+			name:                        "last page of matches",
+			dto:                         SearchProductsDTO{Query: "  lamp ", Limit: 3},
+			expRepoSearchProductsResult: matches,
+			expPage:                     &ProductSearchPage{Matches: matches},
+		},
+		{
+			name:                     "error searching products",
+			dto:                      SearchProductsDTO{Query: "  lamp ", Limit: 2},
+			expRepoSearchProductsErr: errors.New("repo error"),
+			expErr:                   fmt.Errorf("failed to search products: %w", errors.New("repo error")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mock expectations
+			mockRepository := NewMockrepository(ctrl)
+
+			mockRepository.EXPECT().
+				SearchProducts(gomock.Any(), ProductSearchQuery{Text: "lamp", Filter: ProductFilter{Statuses: []ProductStatus{ProductPublished}}, Limit: tt.dto.Limit + 1}).
+				Return(tt.expRepoSearchProductsResult, tt.expRepoSearchProductsErr)
+
+			// Exercise
+			s, err := NewService(mockRepository)
+			assert.NoError(t, err)
+
+			page, err := s.SearchProducts(ctx, tt.dto)
+			assert.Equal(t, tt.expErr, err)
+			assert.Equal(t, tt.expPage, page)
+		})
+	}
+}
+
+func TestService_SuggestProducts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+
+	suggestions := []ProductSuggestion{
+		{ID: uuid.New(), Name: "Desk Lamp", Score: 1},
+		{ID: uuid.New(), Name: "Desk Chair", Score: 0.7},
+	}
+
+	tests := []struct {
+		name                         string
+		expRepoSuggestProductsResult []ProductSuggestion
+		expRepoSuggestProductsErr    error
+		expErr                       error
+	}{
+		{
+			name:                         "products were suggested",
+			expRepoSuggestProductsResult: suggestions,
+		},
+		{
+			name:                      "error suggesting products",
+			expRepoSuggestProductsErr: errors.New("repo error"),
+			expErr:                    fmt.Errorf("failed to suggest products: %w", errors.New("repo error")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mock expectations
+			mockRepository := NewMockrepository(ctrl)
+
+			mockRepository.EXPECT().
+				SuggestProducts(gomock.Any(), "Desk L", 5).
+				Return(tt.expRepoSuggestProductsResult, tt.expRepoSuggestProductsErr)
+
+			// Exercise
+			s, err := NewService(mockRepository)
+			assert.NoError(t, err)
+
+			got, err := s.SuggestProducts(ctx, SuggestProductsDTO{Prefix: " Desk   L", Limit: 5})
+			assert.Equal(t, tt.expErr, err)
+			assert.Equal(t, tt.expRepoSuggestProductsResult, got)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// SlugMaxLength is the maximum length of a generated slug, before the suffix
+// that makes it unique.
+const SlugMaxLength = 100
+
+// Slugify returns the URL-safe form of a product name: lower case ASCII
+// letters and digits separated by single dashes, e.g. "Crème Brûlée 2" gives
+// "creme-brulee-2". Letters are stripped of their diacritics, other characters
+// separate words. A name without any letter or digit gives "product".
+func Slugify(name string) string {
+	var b strings.Builder
+
+	dash := false
+	for _, r := range norm.NFD.String(name) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Combining mark of the previous letter.
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			dash = false
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			dash = true
+		}
+	}
+
+	slug := b.String()
+	if len(slug) > SlugMaxLength {
+		slug = strings.TrimRight(slug[:SlugMaxLength], "-")
+	}
+	if slug == "" {
+		return "product"
+	}
+
+	return slug
+}
+
+// uniqueSlug returns base, or base suffixed with the lowest number from 2
+// that is not taken.
+func uniqueSlug(base string, taken []string) string {
+	if !slices.Contains(taken, base) {
+		return base
+	}
+
+	for n := 2; ; n++ {
+		slug := base + "-" + strconv.Itoa(n)
+		if !slices.Contains(taken, slug) {
+			return slug
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name    string
+		expSlug string
+	}{
+		{name: "Test Product", expSlug: "test-product"},
+		{name: "Crème Brûlée 2", expSlug: "creme-brulee-2"},
+		{name: "  USB-C -- Cable (2m)  ", expSlug: "usb-c-cable-2m"},
+		{name: "日本茶", expSlug: "product"},
+		{name: strings.Repeat("abc ", 30), expSlug: strings.TrimSuffix(strings.Repeat("abc-", 25), "-")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expSlug, Slugify(tt.name))
+		})
+	}
+}
+
+func TestUniqueSlug(t *testing.T) {
+	assert.Equal(t, "shoe", uniqueSlug("shoe", nil))
+	assert.Equal(t, "shoe", uniqueSlug("shoe", []string{"shoe-2"}))
+	assert.Equal(t, "shoe-2", uniqueSlug("shoe", []string{"shoe"}))
+	assert.Equal(t, "shoe-4", uniqueSlug("shoe", []string{"shoe-3", "shoe", "shoe-2"}))
+}
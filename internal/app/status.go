@@ -0,0 +1,120 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProductStatus is the lifecycle state of a product. Products are created as
+// drafts and only published products are listed by default.
+type ProductStatus string
+
+const (
+	ProductDraft     ProductStatus = "draft"
+	ProductPublished ProductStatus = "published"
+	ProductArchived  ProductStatus = "archived"
+)
+
+// ProductStatuses lists the statuses in lifecycle order.
+var ProductStatuses = []ProductStatus{ProductDraft, ProductPublished, ProductArchived}
+
+// ProductTransition moves a product from one status to another.
+type ProductTransition string
+
+const (
+	ProductPublish   ProductTransition = "publish"
+	ProductUnpublish ProductTransition = "unpublish"
+	ProductArchive   ProductTransition = "archive"
+	ProductUnarchive ProductTransition = "unarchive"
+)
+
+// productTransitions is the state machine of the product lifecycle: the
+// statuses each transition applies to and the status it leads to.
+var productTransitions = map[ProductTransition]struct {
+	from []ProductStatus
+	to   ProductStatus
+}{
+	ProductPublish:   {from: []ProductStatus{ProductDraft}, to: ProductPublished},
+	ProductUnpublish: {from: []ProductStatus{ProductPublished}, to: ProductDraft},
+	ProductArchive:   {from: []ProductStatus{ProductDraft, ProductPublished}, to: ProductArchived},
+	ProductUnarchive: {from: []ProductStatus{ProductArchived}, to: ProductDraft},
+}
+
+// Transition applies t to the product. It returns ErrConflict when t does not
+// apply to the current status, e.g. when publishing an archived product.
+func (p *Product) Transition(t ProductTransition) error {
+	rule, ok := productTransitions[t]
+	if !ok {
+		return fmt.Errorf("unknown product transition %q", t)
+	}
+
+	if !slices.Contains(rule.from, p.Status) {
+		return fmt.Errorf("%w: cannot %s product %s, it is %s", ErrConflict, t, p.ID, p.Status)
+	}
+
+	p.Status = rule.to
+	p.UpdatedAt = time.Now().UTC()
+
+	return nil
+}
+
+// Publish makes a draft visible in the product list.
+func (p *Product) Publish() error {
+	return p.Transition(ProductPublish)
+}
+
+// Unpublish takes a published product back to draft.
+func (p *Product) Unpublish() error {
+	return p.Transition(ProductUnpublish)
+}
+
+// Archive retires a draft or published product.
+func (p *Product) Archive() error {
+	return p.Transition(ProductArchive)
+}
+
+// Unarchive brings an archived product back as a draft.
+func (p *Product) Unarchive() error {
+	return p.Transition(ProductUnarchive)
+}
+
+// TransitionProduct applies a lifecycle transition to a product.
+func (s Service) TransitionProduct(ctx context.Context, dto TransitionProductDTO) (*Product, error) {
+	p, err := s.repository.GetProduct(ctx, dto.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	err = p.CheckVersion(dto.ExpectedVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *p
+
+	err = p.Transition(dto.Transition)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.saveProduct(ctx, RevisionUpdated, &before, func(ctx context.Context) (*Product, error) {
+		err := s.repository.UpdateProduct(ctx, p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update product: %w", err)
+		}
+
+		return p, nil
+	})
+}
+
+// TransitionProductDTO applies Transition to a product. A non-zero
+// ExpectedVersion makes the transition conditional on the current version.
+type TransitionProductDTO struct {
+	ID              uuid.UUID
+	ExpectedVersion int64
+	Transition      ProductTransition
+}
@@ -0,0 +1,116 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestProduct_Transition(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     ProductStatus
+		transition ProductTransition
+		expStatus  ProductStatus
+		expErr     bool
+	}{
+		{name: "publish a draft", status: ProductDraft, transition: ProductPublish, expStatus: ProductPublished},
+		{name: "publish a published product", status: ProductPublished, transition: ProductPublish, expStatus: ProductPublished, expErr: true},
+		{name: "publish an archived product", status: ProductArchived, transition: ProductPublish, expStatus: ProductArchived, expErr: true},
+		{name: "unpublish a published product", status: ProductPublished, transition: ProductUnpublish, expStatus: ProductDraft},
+		{name: "unpublish a draft", status: ProductDraft, transition: ProductUnpublish, expStatus: ProductDraft, expErr: true},
+		{name: "archive a draft", status: ProductDraft, transition: ProductArchive, expStatus: ProductArchived},
+		{name: "archive a published product", status: ProductPublished, transition: ProductArchive, expStatus: ProductArchived},
+		{name: "unarchive an archived product", status: ProductArchived, transition: ProductUnarchive, expStatus: ProductDraft},
+		{name: "unarchive a published product", status: ProductPublished, transition: ProductUnarchive, expStatus: ProductPublished, expErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Product{ID: uuid.New(), Status: tt.status}
+
+			err := p.Transition(tt.transition)
+			if tt.expErr {
+				assert.Equal(t, fmt.Errorf("%w: cannot %s product %s, it is %s", ErrConflict, tt.transition, p.ID, tt.status), err)
+				assert.True(t, p.UpdatedAt.IsZero())
+			} else {
+				assert.NoError(t, err)
+				assert.False(t, p.UpdatedAt.IsZero())
+			}
+			assert.Equal(t, tt.expStatus, p.Status)
+		})
+	}
+}
+
+func TestService_TransitionProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+
+	productID := uuid.New()
+
+	tests := []struct {
+		name                 string
+		dto                  TransitionProductDTO
+		expRepoUpdateProduct bool
+		expRepoUpdateErr     error
+		expErr               error
+	}{
+		{
+			name:                 "product was published successfully",
+			dto:                  TransitionProductDTO{ID: productID, Transition: ProductPublish},
+			expRepoUpdateProduct: true,
+		},
+		{
+			name:   "product version does not match",
+			dto:    TransitionProductDTO{ID: productID, ExpectedVersion: 2, Transition: ProductPublish},
+			expErr: fmt.Errorf("%w: product %s is at version 1, not 2", ErrPreconditionFailed, productID),
+		},
+		{
+			name:   "transition does not apply",
+			dto:    TransitionProductDTO{ID: productID, Transition: ProductUnarchive},
+			expErr: fmt.Errorf("%w: cannot unarchive product %s, it is draft", ErrConflict, productID),
+		},
+		{
+			name:                 "product updated concurrently",
+			dto:                  TransitionProductDTO{ID: productID, Transition: ProductArchive},
+			expRepoUpdateProduct: true,
+			expRepoUpdateErr:     ErrPreconditionFailed,
+			expErr:               fmt.Errorf("failed to update product: %w", ErrPreconditionFailed),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mock expectations
+			mockRepository := NewMockrepository(ctrl)
+
+			mockRepository.EXPECT().
+				GetProduct(gomock.Any(), productID).
+				Return(&Product{ID: productID, Status: ProductDraft, Version: 1}, nil)
+
+			if tt.expRepoUpdateProduct {
+				expectSaveProduct(mockRepository, tt.expRepoUpdateErr == nil)
+				mockRepository.EXPECT().
+					UpdateProduct(gomock.Any(), gomock.Any()).
+					Return(tt.expRepoUpdateErr)
+			}
+
+			// Exercise
+			s, err := NewService(mockRepository)
+			assert.NoError(t, err)
+
+			p, err := s.TransitionProduct(ctx, tt.dto)
+			if tt.expErr != nil {
+				assert.Equal(t, tt.expErr, err)
+				assert.Nil(t, p)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, ProductPublished, p.Status)
+			}
+		})
+	}
+}
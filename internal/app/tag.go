@@ -0,0 +1,81 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Product tag limits.
+const (
+	ProductTagsMax      = 20
+	ProductTagMaxLength = 50
+)
+
+// TagCount is a tag along with the number of published products that carry
+// it.
+type TagCount struct {
+	Tag   string
+	Count int
+}
+
+// normalizeTags normalizes each tag like a name and lower cases it, then
+// sorts the tags and drops duplicates so that equal sets of tags compare
+// equal. The result is never nil.
+func normalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		normalized = append(normalized, strings.ToLower(normalizeName(tag)))
+	}
+
+	slices.Sort(normalized)
+
+	return slices.Compact(normalized)
+}
+
+// validateTags checks normalized tags. Commas are rejected because the list
+// filters take comma separated tags.
+func validateTags(v *violations, tags []string) {
+	if len(tags) > ProductTagsMax {
+		v.add("tags", "must not contain more than %d tags", ProductTagsMax)
+		return
+	}
+
+	for _, tag := range tags {
+		switch {
+		case tag == "":
+			v.add("tags", "must not contain empty tags")
+		case !utf8.ValidString(tag):
+			v.add("tags", "must be valid UTF-8")
+		case utf8.RuneCountInString(tag) > ProductTagMaxLength:
+			v.add("tags", "must not contain tags longer than %d characters", ProductTagMaxLength)
+		case strings.ContainsRune(tag, ',') || strings.IndexFunc(tag, unicode.IsControl) >= 0:
+			v.add("tags", "must not contain commas or control characters")
+		default:
+			continue
+		}
+
+		return
+	}
+}
+
+// GetTags returns the tags of the published products, the most used first.
+// The prefix is normalized like tags.
+func (s Service) GetTags(ctx context.Context, dto GetTagsDTO) ([]TagCount, error) {
+	prefix := strings.ToLower(normalizeName(dto.Prefix))
+
+	tags, err := s.repository.GetTags(ctx, prefix, dto.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+type GetTagsDTO struct {
+	Prefix string
+	Limit  int
+}
@@ -0,0 +1,59 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	assert.Equal(t, []string{"clearance", "summer sale"}, normalizeTags([]string{" Summer  Sale", "clearance", "CLEARANCE"}))
+	assert.Equal(t, []string{}, normalizeTags(nil))
+}
+
+func TestService_GetTags(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+
+	tags := []TagCount{{Tag: "seasonal", Count: 3}, {Tag: "sale", Count: 1}}
+
+	tests := []struct {
+		name                 string
+		expRepoGetTagsResult []TagCount
+		expRepoGetTagsErr    error
+		expErr               error
+	}{
+		{
+			name:                 "tags were returned",
+			expRepoGetTagsResult: tags,
+		},
+		{
+			name:              "error getting tags",
+			expRepoGetTagsErr: errors.New("repo error"),
+			expErr:            fmt.Errorf("failed to get tags: %w", errors.New("repo error")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mock expectations
+			mockRepository := NewMockrepository(ctrl)
+
+			mockRepository.EXPECT().
+				GetTags(gomock.Any(), "sea", 10).
+				Return(tt.expRepoGetTagsResult, tt.expRepoGetTagsErr)
+
+			// Exercise
+			s, err := NewService(mockRepository)
+			assert.NoError(t, err)
+
+			got, err := s.GetTags(ctx, GetTagsDTO{Prefix: " Sea", Limit: 10})
+			assert.Equal(t, tt.expErr, err)
+			assert.Equal(t, tt.expRepoGetTagsResult, got)
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Product field limits.
+const (
+	ProductNameMaxLength        = 200
+	ProductDescriptionMaxLength = 5000
+	// ProductPriceMaxIntegerDigits matches the NUMERIC(12, 2) price column.
+	ProductPriceMaxIntegerDigits = 10
+	// SKUMaxLength applies to the SKUs of products and of variants.
+	SKUMaxLength = 64
+)
+
+var skuPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/-]*$`)
+
+// Violation describes why a single field failed validation.
+type Violation struct {
+	Field   string
+	Message string
+}
+
+// ValidationError is returned when one or more fields fail validation. It
+// matches ErrValidation with errors.Is.
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		msgs = append(msgs, v.Field+": "+v.Message)
+	}
+
+	return fmt.Sprintf("%s: %s", ErrValidation, strings.Join(msgs, "; "))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
+
+// violations collects field violations while validating an input.
+type violations []Violation
+
+func (v *violations) add(field, format string, args ...any) {
+	*v = append(*v, Violation{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// err returns a *ValidationError when any violation was collected, nil otherwise.
+func (v violations) err() error {
+	if len(v) == 0 {
+		return nil
+	}
+
+	return &ValidationError{Violations: v}
+}
+
+// productInput holds the fields of a product that clients can set.
+type productInput struct {
+	Name        string
+	Description string
+	Price       Money
+	Tags        []string
+	Attributes  map[string]any
+	SKU         string
+}
+
+// normalize returns a copy of the input with its text fields normalized.
+func (in productInput) normalize() productInput {
+	in.Name = normalizeName(in.Name)
+	in.Description = normalizeDescription(in.Description)
+	in.Tags = normalizeTags(in.Tags)
+	in.SKU = strings.TrimSpace(in.SKU)
+	if in.Attributes == nil {
+		in.Attributes = map[string]any{}
+	}
+
+	return in
+}
+
+func sortViolations(v violations) {
+	sort.SliceStable(v, func(i, j int) bool { return v[i].Field < v[j].Field })
+}
+
+// normalizeName converts the name to NFC, trims it and collapses inner
+// whitespace runs into a single space.
+func normalizeName(name string) string {
+	return strings.Join(strings.Fields(norm.NFC.String(name)), " ")
+}
+
+// normalizeDescription converts the description to NFC and trims it. Inner
+// whitespace is preserved because descriptions may be formatted.
+func normalizeDescription(description string) string {
+	return strings.TrimSpace(norm.NFC.String(description))
+}
+
+// validate checks the user supplied fields of a product. The input is
+// expected to be normalized already.
+func (in productInput) validate() error {
+	var v violations
+
+	description := in.Description
+
+	validateName(&v, in.Name, ProductNameMaxLength)
+
+	switch {
+	case !utf8.ValidString(description):
+		v.add("description", "must be valid UTF-8")
+	case utf8.RuneCountInString(description) > ProductDescriptionMaxLength:
+		v.add("description", "must be at most %d characters long", ProductDescriptionMaxLength)
+	}
+
+	validatePrice(&v, in.Price)
+	validateTags(&v, in.Tags)
+	validateAttributes(&v, in.Attributes)
+	if in.SKU != "" {
+		validateSKU(&v, in.SKU)
+	}
+
+	return v.err()
+}
+
+// validateName checks a required single line name of at most maxLength
+// characters.
+func validateName(v *violations, name string, maxLength int) {
+	switch {
+	case name == "":
+		v.add("name", "must not be empty")
+	case !utf8.ValidString(name):
+		v.add("name", "must be valid UTF-8")
+	case utf8.RuneCountInString(name) > maxLength:
+		v.add("name", "must be at most %d characters long", maxLength)
+	case strings.IndexFunc(name, unicode.IsControl) >= 0:
+		v.add("name", "must not contain control characters")
+	}
+}
+
+// validateSKU checks a non-empty SKU. SKUs are restricted to the characters
+// that ERPs commonly accept and that need no escaping in a URL path.
+func validateSKU(v *violations, sku string) {
+	switch {
+	case len(sku) > SKUMaxLength:
+		v.add("sku", "must be at most %d characters long", SKUMaxLength)
+	case !skuPattern.MatchString(sku):
+		v.add("sku", "must contain only letters, digits, dots, dashes, underscores and slashes, starting with a letter or a digit")
+	}
+}
+
+func validatePrice(v *violations, price Money) {
+	exp, ok := CurrencyExponent(price.Currency)
+	if !ok {
+		v.add("currency", "must be a supported ISO 4217 currency code")
+		return
+	}
+
+	maxAmount := int64(math.Pow10(ProductPriceMaxIntegerDigits+exp)) - 1
+
+	switch {
+	case price.Amount < 0:
+		v.add("price", "must not be negative")
+	case price.Amount > maxAmount:
+		v.add("price", "must not be greater than %s", NewMoney(maxAmount, price.Currency).Decimal())
+	}
+}
@@ -0,0 +1,158 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeName(t *testing.T) {
+	assert.Equal(t, "Test Product", normalizeName("  Test \t  Product\n"))
+	assert.Equal(t, "Café", normalizeName("Café"))
+}
+
+func TestNormalizeDescription(t *testing.T) {
+	assert.Equal(t, "line one\n\nline two", normalizeDescription("\n line one\n\nline two  "))
+}
+
+func TestProductInput_validate(t *testing.T) {
+	tests := []struct {
+		name          string
+		productName   string
+		description   string
+		price         Money
+		tags          []string
+		expViolations []Violation
+	}{
+		{
+			name:        "valid product",
+			productName: "Test Product",
+			description: "Test Description",
+			price:       NewMoney(1999, "USD"),
+		},
+		{
+			name:        "zero price and empty description are allowed",
+			productName: "Test Product",
+			price:       NewMoney(0, "USD"),
+		},
+		{
+			name:          "empty name",
+			productName:   "",
+			price:         NewMoney(100, "USD"),
+			expViolations: []Violation{{Field: "name", Message: "must not be empty"}},
+		},
+		{
+			name:          "name too long",
+			productName:   strings.Repeat("a", ProductNameMaxLength+1),
+			price:         NewMoney(100, "USD"),
+			expViolations: []Violation{{Field: "name", Message: "must be at most 200 characters long"}},
+		},
+		{
+			name:          "name with control characters",
+			productName:   "Test\u0000Product",
+			price:         NewMoney(100, "USD"),
+			expViolations: []Violation{{Field: "name", Message: "must not contain control characters"}},
+		},
+		{
+			name:          "description too long",
+			productName:   "Test Product",
+			description:   strings.Repeat("a", ProductDescriptionMaxLength+1),
+			price:         NewMoney(100, "USD"),
+			expViolations: []Violation{{Field: "description", Message: "must be at most 5000 characters long"}},
+		},
+		{
+			name:          "unsupported currency",
+			productName:   "Test Product",
+			price:         NewMoney(100, "BTC"),
+			expViolations: []Violation{{Field: "currency", Message: "must be a supported ISO 4217 currency code"}},
+		},
+		{
+			name:          "negative price",
+			productName:   "Test Product",
+			price:         NewMoney(-1, "USD"),
+			expViolations: []Violation{{Field: "price", Message: "must not be negative"}},
+		},
+		{
+			name:        "largest price",
+			productName: "Test Product",
+			price:       NewMoney(999_999_999_999, "USD"),
+		},
+		{
+			name:          "price too large",
+			productName:   "Test Product",
+			price:         NewMoney(1_000_000_000_000, "USD"),
+			expViolations: []Violation{{Field: "price", Message: "must not be greater than 9999999999.99"}},
+		},
+		{
+			name:          "price too large for a currency without minor units",
+			productName:   "Test Product",
+			price:         NewMoney(10_000_000_000, "JPY"),
+			expViolations: []Violation{{Field: "price", Message: "must not be greater than 9999999999"}},
+		},
+		{
+			name:        "valid tags",
+			productName: "Test Product",
+			price:       NewMoney(1999, "USD"),
+			tags:        []string{"clearance", "seasonal"},
+		},
+		{
+			name:          "tag with a comma",
+			productName:   "Test Product",
+			price:         NewMoney(1999, "USD"),
+			tags:          []string{"a,b"},
+			expViolations: []Violation{{Field: "tags", Message: "must not contain commas or control characters"}},
+		},
+		{
+			name:          "too many tags",
+			productName:   "Test Product",
+			price:         NewMoney(1999, "USD"),
+			tags:          strings.Split(strings.Repeat("t,", ProductTagsMax+1), ",")[:ProductTagsMax+1],
+			expViolations: []Violation{{Field: "tags", Message: "must not contain more than 20 tags"}},
+		},
+		{
+			name:          "tag too long",
+			productName:   "Test Product",
+			price:         NewMoney(1999, "USD"),
+			tags:          []string{strings.Repeat("a", ProductTagMaxLength+1)},
+			expViolations: []Violation{{Field: "tags", Message: "must not contain tags longer than 50 characters"}},
+		},
+		{
+			name:        "several violations at once",
+			productName: "",
+			price:       NewMoney(-100, "USD"),
+			expViolations: []Violation{
+				{Field: "name", Message: "must not be empty"},
+				{Field: "price", Message: "must not be negative"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := productInput{Name: tt.productName, Description: tt.description, Price: tt.price, Tags: tt.tags}
+
+			err := in.validate()
+			if tt.expViolations == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.True(t, errors.Is(err, ErrValidation))
+
+			var validationErr *ValidationError
+			assert.True(t, errors.As(err, &validationErr))
+			assert.Equal(t, tt.expViolations, validationErr.Violations)
+		})
+	}
+}
+
+func TestValidationError_Error(t *testing.T) {
+	err := &ValidationError{Violations: []Violation{
+		{Field: "name", Message: "must not be empty"},
+		{Field: "price", Message: "must not be negative"},
+	}}
+
+	assert.EqualError(t, err, "validation failed: name: must not be empty; price: must not be negative")
+}
@@ -0,0 +1,287 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Variant field limits.
+const (
+	VariantOptionsMax           = 10
+	VariantOptionValueMaxLength = 100
+)
+
+// Variant is a purchasable version of a product, e.g. a T-shirt in size M.
+// It has its own SKU and is told apart from the other variants of the product
+// by its option values. A nil Price means the price of the product.
+//
+// Stock is informational only: it is shown to the clients but never reserved
+// nor adjusted. The stock that is sold is the Inventory of the product, shared
+// by all its variants.
+type Variant struct {
+	ID        uuid.UUID
+	ProductID uuid.UUID
+	SKU       string
+	Price     *Money
+	Options   map[string]string
+	Stock     int
+	Version   int64
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func NewVariant(productID uuid.UUID, sku string, price *Money, options map[string]string, stock int) *Variant {
+	now := time.Now().UTC()
+
+	return &Variant{
+		ID:        uuid.New(),
+		ProductID: productID,
+		SKU:       sku,
+		Price:     price,
+		Options:   options,
+		Stock:     stock,
+		Version:   1,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func (v *Variant) Update(sku string, price *Money, options map[string]string, stock int) {
+	v.SKU = sku
+	v.Price = price
+	v.Options = options
+	v.Stock = stock
+	v.UpdatedAt = time.Now().UTC()
+}
+
+// CheckVersion returns ErrPreconditionFailed when expected is set and does not
+// match the current version of the variant.
+func (v *Variant) CheckVersion(expected int64) error {
+	if expected != 0 && expected != v.Version {
+		return fmt.Errorf("%w: variant %s is at version %d, not %d", ErrPreconditionFailed, v.ID, v.Version, expected)
+	}
+
+	return nil
+}
+
+// variantInput holds the fields of a variant that clients can set.
+type variantInput struct {
+	SKU     string
+	Price   *Money
+	Options map[string]string
+	Stock   int
+}
+
+func (in variantInput) normalize() variantInput {
+	in.SKU = strings.TrimSpace(in.SKU)
+
+	options := make(map[string]string, len(in.Options))
+	for k, value := range in.Options {
+		options[k] = normalizeName(value)
+	}
+	in.Options = options
+
+	return in
+}
+
+// validate checks the fields of a variant of product p. A price override must
+// be in the currency of the product.
+func (in variantInput) validate(p *Product) error {
+	var v violations
+
+	if in.SKU == "" {
+		v.add("sku", "must not be empty")
+	} else {
+		validateSKU(&v, in.SKU)
+	}
+
+	if in.Price != nil {
+		validatePrice(&v, *in.Price)
+		if in.Price.Currency != p.Price.Currency {
+			v.add("currency", "must be the currency of the product, %s", p.Price.Currency)
+		}
+	}
+
+	if len(in.Options) > VariantOptionsMax {
+		v.add("options", "must not contain more than %d options", VariantOptionsMax)
+	}
+	for k, value := range in.Options {
+		field := "options." + k
+		switch {
+		case !IsAttributeKey(k):
+			v.add(field, "must be named with lower case letters, digits and underscores, starting with a letter")
+		case value == "":
+			v.add(field, "must not be empty")
+		case !utf8.ValidString(value):
+			v.add(field, "must be valid UTF-8")
+		case utf8.RuneCountInString(value) > VariantOptionValueMaxLength:
+			v.add(field, "must be at most %d characters long", VariantOptionValueMaxLength)
+		case strings.IndexFunc(value, unicode.IsControl) >= 0:
+			v.add(field, "must not contain control characters")
+		}
+	}
+
+	if in.Stock < 0 {
+		v.add("stock", "must not be negative")
+	}
+
+	sortViolations(v)
+
+	return v.err()
+}
+
+// CreateVariant adds a variant to a product. Two variants of a product cannot
+// have the same option values, and SKUs are unique across all products.
+func (s Service) CreateVariant(ctx context.Context, dto CreateVariantDTO) (*Variant, error) {
+	p, err := s.repository.GetProduct(ctx, dto.ProductID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	in := variantInput{SKU: dto.SKU, Price: dto.Price, Options: dto.Options, Stock: dto.Stock}.normalize()
+
+	err = in.validate(p)
+	if err != nil {
+		return nil, fmt.Errorf("invalid variant: %w", err)
+	}
+
+	v := NewVariant(p.ID, in.SKU, in.Price, in.Options, in.Stock)
+
+	err = s.repository.WithinTx(ctx, func(ctx context.Context) error {
+		err := s.repository.CreateVariant(ctx, v)
+		if err != nil {
+			return fmt.Errorf("failed to create variant: %w", err)
+		}
+
+		return s.audit(ctx, AuditCreated, AuditVariant, v.ID, nil, v)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func (s Service) UpdateVariant(ctx context.Context, dto UpdateVariantDTO) (*Variant, error) {
+	p, err := s.repository.GetProduct(ctx, dto.ProductID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	in := variantInput{SKU: dto.SKU, Price: dto.Price, Options: dto.Options, Stock: dto.Stock}.normalize()
+
+	err = in.validate(p)
+	if err != nil {
+		return nil, fmt.Errorf("invalid variant: %w", err)
+	}
+
+	v, err := s.repository.GetVariant(ctx, dto.ProductID, dto.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get variant: %w", err)
+	}
+
+	err = v.CheckVersion(dto.ExpectedVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *v
+	v.Update(in.SKU, in.Price, in.Options, in.Stock)
+
+	err = s.repository.WithinTx(ctx, func(ctx context.Context) error {
+		err := s.repository.UpdateVariant(ctx, v)
+		if err != nil {
+			return fmt.Errorf("failed to update variant: %w", err)
+		}
+
+		return s.audit(ctx, AuditUpdated, AuditVariant, v.ID, &before, v)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// DeleteVariant deletes a variant of a product. The variants of a deleted
+// product are left alone so that restoring the product brings them back.
+func (s Service) DeleteVariant(ctx context.Context, dto DeleteVariantDTO) error {
+	return s.repository.WithinTx(ctx, func(ctx context.Context) error {
+		_, err := s.repository.GetProduct(ctx, dto.ProductID)
+		if err != nil {
+			return fmt.Errorf("failed to get product: %w", err)
+		}
+
+		v, err := s.repository.DeleteVariant(ctx, dto.ProductID, dto.ID, dto.ExpectedVersion)
+		if err != nil {
+			return fmt.Errorf("failed to delete variant: %w", err)
+		}
+
+		return s.audit(ctx, AuditDeleted, AuditVariant, v.ID, v, nil)
+	})
+}
+
+// GetVariant returns a variant of a product, the variants of a deleted
+// product are not found.
+func (s Service) GetVariant(ctx context.Context, productID, variantID uuid.UUID) (*Variant, error) {
+	_, err := s.repository.GetProduct(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	v, err := s.repository.GetVariant(ctx, productID, variantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get variant: %w", err)
+	}
+
+	return v, nil
+}
+
+// GetVariants returns the variants of a product, oldest first.
+func (s Service) GetVariants(ctx context.Context, productID uuid.UUID) ([]*Variant, error) {
+	_, err := s.repository.GetProduct(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	variants, err := s.repository.GetVariants(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get variants: %w", err)
+	}
+
+	return variants, nil
+}
+
+// CreateVariantDTO creates a variant sold at the price of the product when
+// Price is nil.
+type CreateVariantDTO struct {
+	ProductID uuid.UUID
+	SKU       string
+	Price     *Money
+	Options   map[string]string
+	Stock     int
+}
+
+// UpdateVariantDTO replaces the fields of a variant. A non-zero
+// ExpectedVersion makes the update conditional on the current version.
+type UpdateVariantDTO struct {
+	ProductID       uuid.UUID
+	ID              uuid.UUID
+	ExpectedVersion int64
+	SKU             string
+	Price           *Money
+	Options         map[string]string
+	Stock           int
+}
+
+type DeleteVariantDTO struct {
+	ProductID       uuid.UUID
+	ID              uuid.UUID
+	ExpectedVersion int64
+}
@@ -0,0 +1,309 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNewVariant(t *testing.T) {
+	productID := uuid.New()
+	price := NewMoney(1250, "USD")
+	options := map[string]string{"size": "M"}
+
+	v := NewVariant(productID, "TS-M", &price, options, 3)
+
+	assert.NotEqual(t, uuid.Nil, v.ID)
+	assert.Equal(t, productID, v.ProductID)
+	assert.Equal(t, "TS-M", v.SKU)
+	assert.Equal(t, &price, v.Price)
+	assert.Equal(t, options, v.Options)
+	assert.Equal(t, 3, v.Stock)
+	assert.Equal(t, int64(1), v.Version)
+	assert.False(t, v.CreatedAt.IsZero())
+}
+
+func TestVariant_Update(t *testing.T) {
+	v := NewVariant(uuid.New(), "TS-M", nil, map[string]string{"size": "M"}, 3)
+	createdAt := v.CreatedAt
+
+	v.Update("TS-L", nil, map[string]string{"size": "L"}, 0)
+
+	assert.Equal(t, "TS-L", v.SKU)
+	assert.Nil(t, v.Price)
+	assert.Equal(t, map[string]string{"size": "L"}, v.Options)
+	assert.Equal(t, 0, v.Stock)
+	assert.Equal(t, createdAt, v.CreatedAt)
+}
+
+func TestService_CreateVariant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+
+	product := NewProduct("T-shirt", "", NewMoney(1000, "EUR"), nil, nil, "")
+	eur := NewMoney(1200, "EUR")
+	usd := NewMoney(1200, "USD")
+
+	tests := []struct {
+		name                    string
+		dto                     CreateVariantDTO
+		expRepoGetProductErr    error
+		expRepoCreateVariantErr error
+		expErr                  error
+	}{
+		{
+			name: "variant was created successfully",
+			dto:  CreateVariantDTO{ProductID: product.ID, SKU: " TS-M ", Options: map[string]string{"size": " M "}, Stock: 5},
+		},
+		{
+			name: "variant with a price override",
+			dto:  CreateVariantDTO{ProductID: product.ID, SKU: "TS-XL", Price: &eur, Options: map[string]string{"size": "XL"}},
+		},
+		{
+			name:                 "product does not exist",
+			dto:                  CreateVariantDTO{ProductID: product.ID, SKU: "TS-M"},
+			expRepoGetProductErr: ErrNotFound,
+			expErr:               fmt.Errorf("failed to get product: %w", ErrNotFound),
+		},
+		{
+			name: "invalid variant",
+			dto:  CreateVariantDTO{ProductID: product.ID, SKU: "TS M", Price: &usd, Options: map[string]string{"Size": "M", "color": ""}, Stock: -1},
+			expErr: fmt.Errorf("invalid variant: %w", &ValidationError{Violations: []Violation{
+				{Field: "currency", Message: "must be the currency of the product, EUR"},
+				{Field: "options.Size", Message: "must be named with lower case letters, digits and underscores, starting with a letter"},
+				{Field: "options.color", Message: "must not be empty"},
+				{Field: "sku", Message: "must contain only letters, digits, dots, dashes, underscores and slashes, starting with a letter or a digit"},
+				{Field: "stock", Message: "must not be negative"},
+			}}),
+		},
+		{
+			name:                    "SKU or options taken",
+			dto:                     CreateVariantDTO{ProductID: product.ID, SKU: "TS-M", Options: map[string]string{"size": "M"}},
+			expRepoCreateVariantErr: ErrConflict,
+			expErr:                  fmt.Errorf("failed to create variant: %w", ErrConflict),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mock expectations
+			mockRepository := NewMockrepository(ctrl)
+
+			if tt.expRepoGetProductErr != nil {
+				mockRepository.EXPECT().
+					GetProduct(gomock.Any(), product.ID).
+					Return(nil, tt.expRepoGetProductErr)
+			} else {
+				mockRepository.EXPECT().
+					GetProduct(gomock.Any(), product.ID).
+					Return(product, nil)
+			}
+
+			if tt.expErr == nil || tt.expRepoCreateVariantErr != nil {
+				expectAudited(mockRepository, tt.expErr == nil)
+				mockRepository.EXPECT().
+					CreateVariant(gomock.Any(), gomock.Any()).
+					Return(tt.expRepoCreateVariantErr)
+			}
+
+			// Exercise
+			s, err := NewService(mockRepository)
+			assert.NoError(t, err)
+
+			v, err := s.CreateVariant(ctx, tt.dto)
+			if tt.expErr != nil {
+				assert.Equal(t, tt.expErr, err)
+				assert.Nil(t, v)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, product.ID, v.ProductID)
+				assert.Equal(t, strings.TrimSpace(tt.dto.SKU), v.SKU)
+				assert.Equal(t, tt.dto.Price, v.Price)
+				assert.Equal(t, tt.dto.Stock, v.Stock)
+			}
+		})
+	}
+}
+
+func TestService_UpdateVariant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+
+	product := NewProduct("T-shirt", "", NewMoney(1000, "USD"), nil, nil, "")
+	variant := NewVariant(product.ID, "TS-M", nil, map[string]string{"size": "M"}, 5)
+
+	tests := []struct {
+		name                    string
+		dto                     UpdateVariantDTO
+		expRepoGetVariantErr    error
+		expRepoUpdateVariantErr error
+		expErr                  error
+	}{
+		{
+			name: "variant was updated successfully",
+			dto:  UpdateVariantDTO{ProductID: product.ID, ID: variant.ID, SKU: "TS-M", Options: map[string]string{"size": "M"}, Stock: 2},
+		},
+		{
+			name:                 "variant does not exist",
+			dto:                  UpdateVariantDTO{ProductID: product.ID, ID: variant.ID, SKU: "TS-M"},
+			expRepoGetVariantErr: ErrNotFound,
+			expErr:               fmt.Errorf("failed to get variant: %w", ErrNotFound),
+		},
+		{
+			name:   "stale expected version",
+			dto:    UpdateVariantDTO{ProductID: product.ID, ID: variant.ID, ExpectedVersion: 3, SKU: "TS-M"},
+			expErr: fmt.Errorf("%w: variant %s is at version 1, not 3", ErrPreconditionFailed, variant.ID),
+		},
+		{
+			name:                    "error updating variant",
+			dto:                     UpdateVariantDTO{ProductID: product.ID, ID: variant.ID, SKU: "TS-M"},
+			expRepoUpdateVariantErr: errors.New("repo error"),
+			expErr:                  fmt.Errorf("failed to update variant: %w", errors.New("repo error")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mock expectations
+			mockRepository := NewMockrepository(ctrl)
+
+			mockRepository.EXPECT().
+				GetProduct(gomock.Any(), product.ID).
+				Return(product, nil)
+
+			if tt.expRepoGetVariantErr != nil {
+				mockRepository.EXPECT().
+					GetVariant(gomock.Any(), product.ID, variant.ID).
+					Return(nil, tt.expRepoGetVariantErr)
+			} else {
+				v := *variant
+				mockRepository.EXPECT().
+					GetVariant(gomock.Any(), product.ID, variant.ID).
+					Return(&v, nil)
+			}
+
+			if tt.expErr == nil || tt.expRepoUpdateVariantErr != nil {
+				expectAudited(mockRepository, tt.expErr == nil)
+				mockRepository.EXPECT().
+					UpdateVariant(gomock.Any(), gomock.Any()).
+					Return(tt.expRepoUpdateVariantErr)
+			}
+
+			// Exercise
+			s, err := NewService(mockRepository)
+			assert.NoError(t, err)
+
+			v, err := s.UpdateVariant(ctx, tt.dto)
+			if tt.expErr != nil {
+				assert.Equal(t, tt.expErr, err)
+				assert.Nil(t, v)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.dto.Stock, v.Stock)
+			}
+		})
+	}
+}
+
+func TestService_GetVariant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+
+	productID := uuid.New()
+	variant := &Variant{ID: uuid.New(), ProductID: productID, SKU: "TS-M"}
+
+	tests := []struct {
+		name                 string
+		expRepoGetProductErr error
+		expErr               error
+	}{
+		{
+			name: "variant found",
+		},
+		{
+			name:                 "product is deleted",
+			expRepoGetProductErr: ErrNotFound,
+			expErr:               fmt.Errorf("failed to get product: %w", ErrNotFound),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepository := NewMockrepository(ctrl)
+
+			mockRepository.EXPECT().
+				GetProduct(gomock.Any(), productID).
+				Return(&Product{ID: productID}, tt.expRepoGetProductErr)
+
+			if tt.expErr == nil {
+				mockRepository.EXPECT().
+					GetVariant(gomock.Any(), productID, variant.ID).
+					Return(variant, nil)
+			}
+
+			s, err := NewService(mockRepository)
+			assert.NoError(t, err)
+
+			v, err := s.GetVariant(ctx, productID, variant.ID)
+			if tt.expErr != nil {
+				assert.Equal(t, tt.expErr, err)
+				assert.Nil(t, v)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, variant, v)
+			}
+		})
+	}
+}
+
+func TestService_DeleteVariant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+
+	productID := uuid.New()
+	variant := &Variant{ID: uuid.New(), ProductID: productID, SKU: "TS-M", Version: 2}
+
+	tests := []struct {
+		name                 string
+		expRepoGetProductErr error
+		expErr               error
+	}{
+		{
+			name: "variant was deleted successfully",
+		},
+		{
+			name:                 "product is deleted",
+			expRepoGetProductErr: ErrNotFound,
+			expErr:               fmt.Errorf("failed to get product: %w", ErrNotFound),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepository := NewMockrepository(ctrl)
+
+			expectAudited(mockRepository, tt.expErr == nil)
+			mockRepository.EXPECT().
+				GetProduct(gomock.Any(), productID).
+				Return(&Product{ID: productID}, tt.expRepoGetProductErr)
+
+			if tt.expErr == nil {
+				mockRepository.EXPECT().
+					DeleteVariant(gomock.Any(), productID, variant.ID, int64(2)).
+					Return(variant, nil)
+			}
+
+			s, err := NewService(mockRepository)
+			assert.NoError(t, err)
+
+			err = s.DeleteVariant(ctx, DeleteVariantDTO{ProductID: productID, ID: variant.ID, ExpectedVersion: 2})
+			assert.Equal(t, tt.expErr, err)
+		})
+	}
+}
@@ -1,41 +1,128 @@
-package config
-
-import (
-	"github.com/spf13/viper"
-)
-
-type Config struct {
-	Postgres Postgres
-}
-
-type Postgres struct {
-	Username string `mapstructure:"POSTGRES_USERNAME"`
-	Pass     string `mapstructure:"POSTGRES_PASSWORD"`
-	Host     string `mapstructure:"POSTGRES_HOSTNAME"`
-	Database string `mapstructure:"POSTGRES_DATABASE"`
-	Port     int    `mapstructure:"POSTGRES_PORT"`
-}
-
-// LoadConfig loads configuration values from a file or env vars.
-func LoadConfig() (Config, error) {
-	viper.AddConfigPath(".")
-	viper.SetConfigName("app")
-	viper.SetConfigType("env")
-
-	err := viper.ReadInConfig()
-	if err != nil {
-		return Config{}, err
-	}
-
-	viper.AutomaticEnv()
-
-	var p Postgres
-	err = viper.Unmarshal(&p)
-	if err != nil {
-		return Config{}, err
-	}
-
-	return Config{
-		Postgres: p,
-	}, nil
-}
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+type Config struct {
+	Postgres Postgres
+	HTTP     HTTP
+	Workers  Workers
+	Events   Events
+}
+
+type Postgres struct {
+	Username string `mapstructure:"POSTGRES_USERNAME"`
+	Pass     string `mapstructure:"POSTGRES_PASSWORD"`
+	Host     string `mapstructure:"POSTGRES_HOSTNAME"`
+	Database string `mapstructure:"POSTGRES_DATABASE"`
+	Port     int    `mapstructure:"POSTGRES_PORT"`
+
+	// Connection pool settings, zero values keep the pgxpool defaults.
+	MaxConns          int32         `mapstructure:"POSTGRES_MAX_CONNS"`
+	MinConns          int32         `mapstructure:"POSTGRES_MIN_CONNS"`
+	MaxConnIdleTime   time.Duration `mapstructure:"POSTGRES_MAX_CONN_IDLE_TIME"`
+	MaxConnLifetime   time.Duration `mapstructure:"POSTGRES_MAX_CONN_LIFETIME"`
+	HealthCheckPeriod time.Duration `mapstructure:"POSTGRES_HEALTH_CHECK_PERIOD"`
+
+	// SearchLanguage is the text search configuration used by product search,
+	// e.g. english or simple.
+	SearchLanguage string `mapstructure:"POSTGRES_SEARCH_LANGUAGE"`
+}
+
+type HTTP struct {
+	// RequireIfMatch rejects unconditional writes with 428 Precondition Required.
+	RequireIfMatch bool `mapstructure:"HTTP_REQUIRE_IF_MATCH"`
+
+	// Cache-Control values sent with product reads, empty values omit the header.
+	ProductCacheControl  string `mapstructure:"HTTP_PRODUCT_CACHE_CONTROL"`
+	ProductsCacheControl string `mapstructure:"HTTP_PRODUCTS_CACHE_CONTROL"`
+
+	// AdminAddr is the address of the listener serving the database pool
+	// statistics and the product lists including the deleted products, which
+	// must not be reachable by API clients. They are not served when it is
+	// empty.
+	AdminAddr string `mapstructure:"HTTP_ADMIN_ADDR"`
+
+	// TrustedProxies lists the addresses and CIDR ranges of the gateways whose
+	// X-Actor and X-Forwarded-For headers are believed, separated by commas.
+	// The headers of any other caller are ignored.
+	TrustedProxies []string `mapstructure:"HTTP_TRUSTED_PROXIES"`
+}
+
+// Workers configures the background jobs.
+type Workers struct {
+	// ReservationExpiryInterval is how often the stock held by expired
+	// reservations is made available again.
+	ReservationExpiryInterval time.Duration `mapstructure:"WORKER_RESERVATION_EXPIRY_INTERVAL"`
+
+	// ProductPurgeInterval is how often the products deleted for longer than
+	// DeletedProductRetention are deleted for good.
+	ProductPurgeInterval    time.Duration `mapstructure:"WORKER_PRODUCT_PURGE_INTERVAL"`
+	DeletedProductRetention time.Duration `mapstructure:"WORKER_DELETED_PRODUCT_RETENTION"`
+
+	// OutboxRelayInterval is how often the pending product events are
+	// published.
+	OutboxRelayInterval time.Duration `mapstructure:"WORKER_OUTBOX_RELAY_INTERVAL"`
+}
+
+// Events configures the delivery of the product events.
+type Events struct {
+	// WebhookURL receives the events as POST requests, the events are only
+	// logged when it is empty.
+	WebhookURL string `mapstructure:"EVENTS_WEBHOOK_URL"`
+}
+
+// LoadConfig loads configuration values from a file or env vars.
+func LoadConfig() (Config, error) {
+	viper.AddConfigPath(".")
+	viper.SetConfigName("app")
+	viper.SetConfigType("env")
+
+	err := viper.ReadInConfig()
+	if err != nil {
+		return Config{}, err
+	}
+
+	viper.AutomaticEnv()
+	viper.SetDefault("WORKER_RESERVATION_EXPIRY_INTERVAL", "30s")
+	viper.SetDefault("WORKER_PRODUCT_PURGE_INTERVAL", "1h")
+	viper.SetDefault("WORKER_DELETED_PRODUCT_RETENTION", "720h")
+	viper.SetDefault("WORKER_OUTBOX_RELAY_INTERVAL", "1s")
+	viper.SetDefault("EVENTS_WEBHOOK_URL", "")
+	viper.SetDefault("HTTP_ADMIN_ADDR", "")
+	viper.SetDefault("HTTP_TRUSTED_PROXIES", "")
+
+	var p Postgres
+	err = viper.Unmarshal(&p)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var h HTTP
+	err = viper.Unmarshal(&h)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var wk Workers
+	err = viper.Unmarshal(&wk)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var ev Events
+	err = viper.Unmarshal(&ev)
+	if err != nil {
+		return Config{}, err
+	}
+
+	return Config{
+		Postgres: p,
+		HTTP:     h,
+		Workers:  wk,
+		Events:   ev,
+	}, nil
+}
@@ -0,0 +1,179 @@
+package http
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+// actorHeader carries the identity of the caller. It is set by the gateway in
+// front of the service once it authenticated the request.
+const actorHeader = "X-Actor"
+
+// requestIDHeader carries the ID of the request, generated when the client or
+// the gateway did not set a valid one. It is echoed in the response.
+const requestIDHeader = "X-Request-ID"
+
+// forwardedForHeader lists the addresses of the client and the proxies the
+// request went through, the client first.
+const forwardedForHeader = "X-Forwarded-For"
+
+// requestIDMaxLength bounds the request IDs taken from the requests, which
+// end up in the audit log and in the responses.
+const requestIDMaxLength = 128
+
+// TrustedProxies are the gateways whose identity and forwarding headers are
+// believed. The headers of the requests coming from any other address are
+// ignored: their actor is app.AnonymousActor and their source address is the
+// one of the connection.
+type TrustedProxies []netip.Prefix
+
+// ParseTrustedProxies parses the IP addresses and the CIDR ranges of the
+// trusted proxies.
+func ParseTrustedProxies(values []string) (TrustedProxies, error) {
+	proxies := make(TrustedProxies, 0, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+
+		if strings.Contains(v, "/") {
+			prefix, err := netip.ParsePrefix(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", v, err)
+			}
+			proxies = append(proxies, prefix.Masked())
+			continue
+		}
+
+		addr, err := netip.ParseAddr(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", v, err)
+		}
+		addr = addr.Unmap()
+		proxies = append(proxies, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+
+	return proxies, nil
+}
+
+// contains reports whether addr is the address of a trusted proxy.
+func (p TrustedProxies) contains(addr string) bool {
+	ip, err := netip.ParseAddr(strings.TrimSpace(addr))
+	if err != nil {
+		return false
+	}
+	ip = ip.Unmap()
+
+	for _, prefix := range p {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WithActor passes the actor of each request on to the service through the
+// request context. Only the requests of trusted proxies carry one, the others
+// are made by app.AnonymousActor. The reserved actors of the service cannot
+// be claimed by a request.
+func WithActor(proxies TrustedProxies, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !proxies.contains(remoteIP(req)) {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		actor := strings.TrimSpace(req.Header.Get(actorHeader))
+		if strings.EqualFold(actor, app.SystemActor) || strings.EqualFold(actor, app.AnonymousActor) {
+			writeProblem(w, req, problemInvalidParameter, "The X-Actor header names a reserved actor.",
+				fieldError{Field: actorHeader, Message: "must not be a reserved actor"},
+			)
+			return
+		}
+
+		if actor != "" {
+			req = req.WithContext(app.WithActor(req.Context(), actor))
+		}
+
+		next.ServeHTTP(w, req)
+	})
+}
+
+// WithRequestInfo passes the ID and the source address of each request on to
+// the service through the request context.
+func WithRequestInfo(proxies TrustedProxies, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		id := strings.TrimSpace(req.Header.Get(requestIDHeader))
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+		w.Header().Set(requestIDHeader, id)
+
+		req = req.WithContext(app.WithRequest(req.Context(), app.RequestInfo{ID: id, SourceIP: sourceIP(proxies, req)}))
+
+		next.ServeHTTP(w, req)
+	})
+}
+
+// validRequestID reports whether id is short and made of letters, digits and
+// the separators found in the usual request ID formats.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > requestIDMaxLength {
+		return false
+	}
+
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// sourceIP returns the address of the client. Behind trusted proxies, it is
+// the last address of the X-Forwarded-For header that is not one of theirs:
+// the addresses before it are set by the client and cannot be believed.
+func sourceIP(proxies TrustedProxies, req *http.Request) string {
+	ip := remoteIP(req)
+	if !proxies.contains(ip) {
+		return ip
+	}
+
+	hops := strings.Split(req.Header.Get(forwardedForHeader), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if _, err := netip.ParseAddr(hop); err != nil {
+			break
+		}
+
+		ip = hop
+		if !proxies.contains(hop) {
+			break
+		}
+	}
+
+	return ip
+}
+
+// remoteIP returns the address of the peer of the connection.
+func remoteIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+
+	return host
+}
@@ -0,0 +1,200 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.0/8", " 192.168.1.10 ", "", "::ffff:172.16.0.1"})
+	assert.NoError(t, err)
+	assert.True(t, proxies.contains("10.1.2.3"))
+	assert.True(t, proxies.contains("192.168.1.10"))
+	assert.True(t, proxies.contains("172.16.0.1"))
+	assert.False(t, proxies.contains("192.168.1.11"))
+	assert.False(t, proxies.contains("not an address"))
+
+	_, err = ParseTrustedProxies([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+
+	_, err = ParseTrustedProxies([]string{"gateway"})
+	assert.Error(t, err)
+}
+
+func TestWithActor(t *testing.T) {
+	proxies, _ := ParseTrustedProxies([]string{"10.0.0.1"})
+
+	tests := []struct {
+		name        string
+		remoteAddr  string
+		actor       string
+		expStatus   int
+		expActor    string
+		expResponse []byte
+	}{
+		{
+			name:       "actor set by a trusted proxy",
+			remoteAddr: "10.0.0.1:4321",
+			actor:      "alice@example.com",
+			expStatus:  http.StatusOK,
+			expActor:   "alice@example.com",
+		},
+		{
+			name:       "no actor set by a trusted proxy",
+			remoteAddr: "10.0.0.1:4321",
+			expStatus:  http.StatusOK,
+			expActor:   app.AnonymousActor,
+		},
+		{
+			name:       "actor set by another caller",
+			remoteAddr: "203.0.113.7:4321",
+			actor:      "alice@example.com",
+			expStatus:  http.StatusOK,
+			expActor:   app.AnonymousActor,
+		},
+		{
+			name:       "system actor claimed by another caller",
+			remoteAddr: "203.0.113.7:4321",
+			actor:      app.SystemActor,
+			expStatus:  http.StatusOK,
+			expActor:   app.AnonymousActor,
+		},
+		{
+			name:        "system actor claimed through a trusted proxy",
+			remoteAddr:  "10.0.0.1:4321",
+			actor:       "System",
+			expStatus:   http.StatusBadRequest,
+			expResponse: []byte("{\"type\":\"/problems/invalid-parameter\",\"title\":\"Invalid request parameter\",\"status\":400,\"detail\":\"The X-Actor header names a reserved actor.\",\"instance\":\"/api/v1/products\",\"errors\":[{\"field\":\"X-Actor\",\"message\":\"must not be a reserved actor\"}]}\n"),
+		},
+		{
+			name:        "anonymous actor claimed through a trusted proxy",
+			remoteAddr:  "10.0.0.1:4321",
+			actor:       app.AnonymousActor,
+			expStatus:   http.StatusBadRequest,
+			expResponse: []byte("{\"type\":\"/problems/invalid-parameter\",\"title\":\"Invalid request parameter\",\"status\":400,\"detail\":\"The X-Actor header names a reserved actor.\",\"instance\":\"/api/v1/products\",\"errors\":[{\"field\":\"X-Actor\",\"message\":\"must not be a reserved actor\"}]}\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotActor string
+			handler := WithActor(proxies, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				gotActor = app.ActorFromContext(req.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/products", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.actor != "" {
+				req.Header.Set(actorHeader, tt.actor)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, tt.expStatus, res.StatusCode)
+			assert.Equal(t, tt.expActor, gotActor)
+			if tt.expResponse != nil {
+				body, _ := io.ReadAll(res.Body)
+				assert.Equal(t, string(tt.expResponse), string(body))
+			}
+		})
+	}
+}
+
+func TestWithRequestInfo(t *testing.T) {
+	proxies, _ := ParseTrustedProxies([]string{"10.0.0.0/24"})
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		requestID    string
+		forwardedFor string
+		expID        string
+		expSourceIP  string
+	}{
+		{
+			name:        "request ID and address of a direct caller",
+			remoteAddr:  "203.0.113.7:4321",
+			requestID:   "b7f2c1d0-5e9a-4c3b-8f1e-2a6d9c0b4e57",
+			expID:       "b7f2c1d0-5e9a-4c3b-8f1e-2a6d9c0b4e57",
+			expSourceIP: "203.0.113.7",
+		},
+		{
+			name:         "forwarded address of a direct caller is ignored",
+			remoteAddr:   "203.0.113.7:4321",
+			forwardedFor: "198.51.100.1",
+			expSourceIP:  "203.0.113.7",
+		},
+		{
+			name:         "forwarded address behind a trusted proxy",
+			remoteAddr:   "10.0.0.1:4321",
+			forwardedFor: "198.51.100.1",
+			expSourceIP:  "198.51.100.1",
+		},
+		{
+			name:         "addresses prepended by the client are ignored",
+			remoteAddr:   "10.0.0.1:4321",
+			forwardedFor: "192.0.2.99, 198.51.100.1, 10.0.0.2",
+			expSourceIP:  "198.51.100.1",
+		},
+		{
+			name:         "malformed forwarded address behind a trusted proxy",
+			remoteAddr:   "10.0.0.1:4321",
+			forwardedFor: "unknown",
+			expSourceIP:  "10.0.0.1",
+		},
+		{
+			name:        "request ID too long",
+			remoteAddr:  "203.0.113.7:4321",
+			requestID:   strings.Repeat("a", requestIDMaxLength+1),
+			expSourceIP: "203.0.113.7",
+		},
+		{
+			name:        "request ID with invalid characters",
+			remoteAddr:  "203.0.113.7:4321",
+			requestID:   "idé<script>",
+			expSourceIP: "203.0.113.7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got app.RequestInfo
+			handler := WithRequestInfo(proxies, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				got = app.RequestFromContext(req.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/products", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.requestID != "" {
+				req.Header.Set(requestIDHeader, tt.requestID)
+			}
+			if tt.forwardedFor != "" {
+				req.Header.Set(forwardedForHeader, tt.forwardedFor)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if tt.expID != "" {
+				assert.Equal(t, tt.expID, got.ID)
+			} else {
+				// A new ID is generated in place of a missing or invalid one.
+				assert.NotEqual(t, tt.requestID, got.ID)
+				assert.Len(t, got.ID, 36)
+			}
+			assert.Equal(t, got.ID, rec.Header().Get(requestIDHeader))
+			assert.Equal(t, tt.expSourceIP, got.SourceIP)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package http
+
+import (
+	"encoding/json"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+// jsonObject holds a JSON object decoded with its numbers kept verbatim, like
+// the product attributes and the attribute schemas. A JSON null is nil.
+type jsonObject map[string]any
+
+func (o *jsonObject) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*o = nil
+		return nil
+	}
+
+	obj, err := app.DecodeJSONObject(b)
+	if err != nil {
+		return err
+	}
+	*o = obj
+
+	return nil
+}
+
+// attributeParamPrefix prefixes the query parameters filtering on attribute
+// values, e.g. attr.color=red.
+const attributeParamPrefix = "attr."
+
+// parseAttributeFilter reads the attribute filter parameters. Values are
+// strings, except true, false and numbers which match the JSON values. A
+// value in double quotes is always a string, e.g. attr.size="42".
+func parseAttributeFilter(query url.Values) (map[string]any, []fieldError) {
+	var (
+		attributes map[string]any
+		fieldErrs  []fieldError
+	)
+
+	params := make([]string, 0, len(query))
+	for param := range query {
+		params = append(params, param)
+	}
+	slices.Sort(params)
+
+	for _, param := range params {
+		key, ok := strings.CutPrefix(param, attributeParamPrefix)
+		if !ok {
+			continue
+		}
+
+		if !app.IsAttributeKey(key) {
+			fieldErrs = append(fieldErrs, fieldError{Field: param, Message: "must name a valid attribute"})
+			continue
+		}
+
+		if attributes == nil {
+			attributes = map[string]any{}
+		}
+		attributes[key] = attributeValue(query.Get(param))
+	}
+
+	return attributes, fieldErrs
+}
+
+func attributeValue(v string) any {
+	switch {
+	case v == "true":
+		return true
+	case v == "false":
+		return false
+	case len(v) >= 2 && strings.HasPrefix(v, `"`) && strings.HasSuffix(v, `"`):
+		return v[1 : len(v)-1]
+	}
+
+	var n json.Number
+	if json.Unmarshal([]byte(v), &n) == nil && n != "" {
+		return n
+	}
+
+	return v
+}
@@ -0,0 +1,135 @@
+package http
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+const getAuditEntriesEndpoint string = "GET /api/v1/audit"
+
+// getAuditEntriesHandler serves the audit log, the most recent entries first.
+// It is never cached as entries keep being appended.
+func (r Router) getAuditEntriesHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	dto, fieldErrs := parseGetAuditEntriesQuery(req.URL.Query())
+	if fieldErrs != nil {
+		writeProblem(w, req, problemInvalidParameter, "The query parameters are invalid.", fieldErrs...)
+		return
+	}
+
+	page, err := r.service.GetAuditEntries(ctx, dto)
+	if err != nil {
+		writeServiceError(w, req, err)
+		return
+	}
+
+	offset := dto.Offset
+	res := auditEntriesResponse{
+		Entries: make([]auditEntryResponse, 0, len(page.Entries)),
+		Pagination: paginationResponse{
+			Limit:   dto.Limit,
+			Offset:  &offset,
+			HasMore: page.HasMore,
+		},
+	}
+	for _, e := range page.Entries {
+		res.Entries = append(res.Entries, auditEntryResponse{
+			ID:           e.ID,
+			Actor:        e.Actor,
+			Action:       string(e.Action),
+			ResourceType: string(e.ResourceType),
+			ResourceID:   e.ResourceID,
+			RequestID:    e.RequestID,
+			SourceIP:     e.SourceIP,
+			Before:       e.Before,
+			After:        e.After,
+			CreatedAt:    e.CreatedAt,
+		})
+	}
+
+	writeJSON(w, req, http.StatusOK, res)
+}
+
+// parseGetAuditEntriesQuery reads the filter and pagination parameters of the
+// audit log. The time range is given by the since and until RFC 3339
+// timestamps, since being inclusive and until exclusive.
+func parseGetAuditEntriesQuery(query url.Values) (app.GetAuditEntriesDTO, []fieldError) {
+	var (
+		dto       app.GetAuditEntriesDTO
+		fieldErrs []fieldError
+	)
+
+	dto.Filter.Actor = strings.TrimSpace(query.Get("actor"))
+
+	if v := query.Get("resource_type"); v != "" {
+		if !slices.Contains(app.AuditResources, app.AuditResource(v)) {
+			fieldErrs = append(fieldErrs, fieldError{Field: "resource_type", Message: "must be one of product, category, variant, inventory or reservation"})
+		} else {
+			dto.Filter.ResourceType = app.AuditResource(v)
+		}
+	}
+
+	if v := query.Get("resource_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			fieldErrs = append(fieldErrs, fieldError{Field: "resource_id", Message: "must be a UUID"})
+		} else {
+			dto.Filter.ResourceID = id
+		}
+	}
+
+	for _, param := range []string{"since", "until"} {
+		v := query.Get(param)
+		if v == "" {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			fieldErrs = append(fieldErrs, fieldError{Field: param, Message: "must be an RFC 3339 timestamp"})
+			continue
+		}
+
+		if param == "since" {
+			dto.Filter.Since = t
+		} else {
+			dto.Filter.Until = t
+		}
+	}
+
+	if !dto.Filter.Since.IsZero() && !dto.Filter.Until.IsZero() && !dto.Filter.Until.After(dto.Filter.Since) {
+		fieldErrs = append(fieldErrs, fieldError{Field: "until", Message: "must be later than since"})
+	}
+
+	var pageErrs []fieldError
+	dto.Limit, dto.Offset, pageErrs = parseLimitOffset(query)
+	fieldErrs = append(fieldErrs, pageErrs...)
+
+	return dto, fieldErrs
+}
+
+type auditEntriesResponse struct {
+	Entries    []auditEntryResponse `json:"entries"`
+	Pagination paginationResponse   `json:"pagination"`
+}
+
+type auditEntryResponse struct {
+	ID           uuid.UUID `json:"id"`
+	Actor        string    `json:"actor"`
+	Action       string    `json:"action"`
+	ResourceType string    `json:"resource_type"`
+	ResourceID   uuid.UUID `json:"resource_id"`
+	RequestID    string    `json:"request_id"`
+	SourceIP     string    `json:"source_ip"`
+	Before       any       `json:"before"`
+	After        any       `json:"after"`
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -0,0 +1,106 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/simpler-tha/internal/app"
+	"github.com/simpler-tha/internal/config"
+)
+
+func TestRouter_getAuditEntriesHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	entryID, _ := uuid.Parse("1b7f3b6e-3c1d-4d53-9a3b-0e6f8f0b2a11")
+	productID, _ := uuid.Parse("9f9f4340-6bf9-4948-808c-ebf2dd604e2c")
+
+	since, _ := time.Parse(time.RFC3339, "2024-10-01T00:00:00Z")
+	now, _ := time.Parse(time.RFC3339, "2024-10-02T14:28:34Z")
+
+	tests := []struct {
+		name                            string
+		query                           string
+		expServiceGetAuditEntriesDTO    *app.GetAuditEntriesDTO
+		expServiceGetAuditEntriesResult *app.AuditPage
+		expStatus                       int
+		expResponse                     []byte
+	}{
+		{
+			name:  "entries found",
+			query: "?actor=alice&resource_type=product&resource_id=9f9f4340-6bf9-4948-808c-ebf2dd604e2c&since=2024-10-01T00:00:00Z&limit=1",
+			expServiceGetAuditEntriesDTO: &app.GetAuditEntriesDTO{
+				Filter: app.AuditFilter{Actor: "alice", ResourceType: app.AuditProduct, ResourceID: productID, Since: since},
+				Limit:  1,
+			},
+			expServiceGetAuditEntriesResult: &app.AuditPage{
+				Entries: []app.AuditEntry{{
+					ID:           entryID,
+					Actor:        "alice",
+					Action:       app.AuditUpdated,
+					ResourceType: app.AuditProduct,
+					ResourceID:   productID,
+					RequestID:    "req-1",
+					SourceIP:     "203.0.113.7",
+					Before:       map[string]any{"name": "Lamp"},
+					After:        map[string]any{"name": "Desk Lamp"},
+					CreatedAt:    now,
+				}},
+				HasMore: true,
+			},
+			expStatus:   http.StatusOK,
+			expResponse: []byte("{\"entries\":[{\"id\":\"1b7f3b6e-3c1d-4d53-9a3b-0e6f8f0b2a11\",\"actor\":\"alice\",\"action\":\"updated\",\"resource_type\":\"product\",\"resource_id\":\"9f9f4340-6bf9-4948-808c-ebf2dd604e2c\",\"request_id\":\"req-1\",\"source_ip\":\"203.0.113.7\",\"before\":{\"name\":\"Lamp\"},\"after\":{\"name\":\"Desk Lamp\"},\"created_at\":\"2024-10-02T14:28:34Z\"}],\"pagination\":{\"limit\":1,\"offset\":0,\"has_more\":true}}\n"),
+		},
+		{
+			name:                            "no entries",
+			expServiceGetAuditEntriesDTO:    &app.GetAuditEntriesDTO{Limit: getProductsDefaultLimit},
+			expServiceGetAuditEntriesResult: &app.AuditPage{},
+			expStatus:                       http.StatusOK,
+			expResponse:                     []byte("{\"entries\":[],\"pagination\":{\"limit\":5,\"offset\":0,\"has_more\":false}}\n"),
+		},
+		{
+			name:        "invalid parameters",
+			query:       "?resource_type=order&resource_id=42&since=yesterday&until=2024-10-01T00:00:00Z",
+			expStatus:   http.StatusBadRequest,
+			expResponse: []byte("{\"type\":\"/problems/invalid-parameter\",\"title\":\"Invalid request parameter\",\"status\":400,\"detail\":\"The query parameters are invalid.\",\"instance\":\"/api/v1/audit\",\"errors\":[{\"field\":\"resource_type\",\"message\":\"must be one of product, category, variant, inventory or reservation\"},{\"field\":\"resource_id\",\"message\":\"must be a UUID\"},{\"field\":\"since\",\"message\":\"must be an RFC 3339 timestamp\"}]}\n"),
+		},
+		{
+			name:        "empty time range",
+			query:       "?since=2024-10-02T00:00:00Z&until=2024-10-01T00:00:00Z",
+			expStatus:   http.StatusBadRequest,
+			expResponse: []byte("{\"type\":\"/problems/invalid-parameter\",\"title\":\"Invalid request parameter\",\"status\":400,\"detail\":\"The query parameters are invalid.\",\"instance\":\"/api/v1/audit\",\"errors\":[{\"field\":\"until\",\"message\":\"must be later than since\"}]}\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		mockService := NewMockservice(ctrl)
+
+		if tt.expServiceGetAuditEntriesDTO != nil {
+			mockService.
+				EXPECT().
+				GetAuditEntries(gomock.Any(), *tt.expServiceGetAuditEntriesDTO).
+				Return(tt.expServiceGetAuditEntriesResult, nil)
+		}
+
+		router, err := NewRouter(mockService, config.HTTP{})
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/audit"+tt.query, nil)
+		recorder := httptest.NewRecorder()
+
+		router.getAuditEntriesHandler(recorder, req)
+
+		assert.Equal(t, tt.expStatus, recorder.Code)
+
+		b, err := io.ReadAll(recorder.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, tt.expResponse, b)
+	}
+}
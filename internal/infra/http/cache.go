@@ -0,0 +1,86 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// writeCacheable writes v as a 200 response carrying the cache policy and
+// the validators of the representation, or 304 Not Modified when the
+// conditional headers of the request show that the client already has it.
+// An empty etag is derived from the encoded body, a zero lastModified omits
+// the Last-Modified header.
+func writeCacheable(w http.ResponseWriter, req *http.Request, cacheControl, etag string, lastModified time.Time, v any) {
+	b, err := encodeJSON(v)
+	if err != nil {
+		log.Printf("failed to encode response: %v", err)
+		writeProblem(w, req, problemInternal, "The response could not be encoded.")
+		return
+	}
+
+	if etag == "" {
+		etag = contentETag(b)
+	}
+
+	h := w.Header()
+	if cacheControl != "" {
+		h.Set("Cache-Control", cacheControl)
+	}
+	h.Set("ETag", etag)
+	if !lastModified.IsZero() {
+		h.Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(req, etag, lastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	h.Set("Content-Type", jsonContentType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(b)
+}
+
+// contentETag returns a strong entity tag computed from a response body.
+func contentETag(b []byte) string {
+	sum := sha256.Sum256(b)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// notModified evaluates If-None-Match and If-Modified-Since as described in
+// RFC 9110 section 13.2.2: If-Modified-Since is ignored when If-None-Match
+// is present.
+func notModified(req *http.Request, etag string, lastModified time.Time) bool {
+	if inm := req.Header.Get("If-None-Match"); inm != "" {
+		return etagListMatches(inm, etag)
+	}
+
+	if lastModified.IsZero() {
+		return false
+	}
+
+	since, err := http.ParseTime(req.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	// HTTP dates have a one second resolution.
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+// etagListMatches reports whether etag is in the If-None-Match list, using
+// the weak comparison function.
+func etagListMatches(list, etag string) bool {
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,271 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+const (
+	createCategoryEndpoint       string = "POST /api/v1/categories"
+	updateCategoryEndpoint       string = "PUT /api/v1/categories/{category_id}"
+	deleteCategoryEndpoint       string = "DELETE /api/v1/categories/{category_id}"
+	getCategoryEndpoint          string = "GET /api/v1/categories/{category_id}"
+	getCategoriesEndpoint        string = "GET /api/v1/categories"
+	getCategoryProductsEndpoint  string = "GET /api/v1/categories/{category_id}/products"
+	getProductCategoriesEndpoint string = "GET /api/v1/products/{product_id}/categories"
+	setProductCategoriesEndpoint string = "PUT /api/v1/products/{product_id}/categories"
+)
+
+type categoryRequestBody struct {
+	Name            string     `json:"name"`
+	ParentID        *uuid.UUID `json:"parent_id"`
+	AttributeSchema jsonObject `json:"attribute_schema"`
+}
+
+func (r Router) createCategoryHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	var body categoryRequestBody
+	err := json.NewDecoder(req.Body).Decode(&body)
+	if err != nil {
+		writeBodyError(w, req, err)
+		return
+	}
+
+	dto := app.CreateCategoryDTO{
+		Name:            body.Name,
+		ParentID:        body.ParentID,
+		AttributeSchema: body.AttributeSchema,
+	}
+
+	c, err := r.service.CreateCategory(ctx, dto)
+	if err != nil {
+		writeServiceError(w, req, err)
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(c.Version))
+	writeJSON(w, req, http.StatusCreated, newCategoryResponse(c))
+}
+
+func (r Router) updateCategoryHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	var body categoryRequestBody
+	err := json.NewDecoder(req.Body).Decode(&body)
+	if err != nil {
+		writeBodyError(w, req, err)
+		return
+	}
+
+	categoryID, err := uuid.Parse(req.PathValue("category_id"))
+	if err != nil {
+		writeInvalidIDError(w, req, "category_id")
+		return
+	}
+
+	expectedVersion, ok := r.expectedVersion(w, req)
+	if !ok {
+		return
+	}
+
+	dto := app.UpdateCategoryDTO{
+		ID:              categoryID,
+		ExpectedVersion: expectedVersion,
+		Name:            body.Name,
+		ParentID:        body.ParentID,
+		AttributeSchema: body.AttributeSchema,
+	}
+
+	c, err := r.service.UpdateCategory(ctx, dto)
+	if err != nil {
+		writeServiceError(w, req, err)
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(c.Version))
+	writeJSON(w, req, http.StatusOK, newCategoryResponse(c))
+}
+
+func (r Router) deleteCategoryHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	categoryID, err := uuid.Parse(req.PathValue("category_id"))
+	if err != nil {
+		writeInvalidIDError(w, req, "category_id")
+		return
+	}
+
+	expectedVersion, ok := r.expectedVersion(w, req)
+	if !ok {
+		return
+	}
+
+	dto := app.DeleteCategoryDTO{
+		ID:              categoryID,
+		ExpectedVersion: expectedVersion,
+	}
+
+	err = r.service.DeleteCategory(ctx, dto)
+	if err != nil {
+		writeServiceError(w, req, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (r Router) getCategoryHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	categoryID, err := uuid.Parse(req.PathValue("category_id"))
+	if err != nil {
+		writeInvalidIDError(w, req, "category_id")
+		return
+	}
+
+	c, err := r.service.GetCategory(ctx, categoryID)
+	if err != nil {
+		writeServiceError(w, req, err)
+		return
+	}
+
+	writeCacheable(w, req, r.cfg.ProductCacheControl, versionETag(c.Version), c.UpdatedAt, newCategoryResponse(c))
+}
+
+// getCategoriesHandler returns the whole taxonomy as a flat list in which
+// every category follows its parent. Taxonomies are small enough not to be
+// paginated.
+func (r Router) getCategoriesHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	categories, err := r.service.GetCategories(ctx)
+	if err != nil {
+		writeServiceError(w, req, err)
+		return
+	}
+
+	writeCacheable(w, req, r.cfg.ProductsCacheControl, "", time.Time{}, newCategoriesResponse(categories))
+}
+
+// getCategoryProductsHandler lists the products of a category and of its
+// descendants. It accepts the parameters of the product list.
+func (r Router) getCategoryProductsHandler(w http.ResponseWriter, req *http.Request) {
+	categoryID, err := uuid.Parse(req.PathValue("category_id"))
+	if err != nil {
+		writeInvalidIDError(w, req, "category_id")
+		return
+	}
+
+	if !r.allowParameters(w, req) {
+		return
+	}
+
+	dto, fieldErrs := parseGetProductsQuery(req.URL.Query())
+	if fieldErrs != nil {
+		writeProblem(w, req, problemInvalidParameter, "The query parameters are invalid.", fieldErrs...)
+		return
+	}
+	dto.Filter.CategoryID = categoryID
+
+	r.writeProducts(w, req, dto)
+}
+
+func (r Router) getProductCategoriesHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	productID, err := uuid.Parse(req.PathValue("product_id"))
+	if err != nil {
+		writeInvalidIDError(w, req, "product_id")
+		return
+	}
+
+	categories, err := r.service.GetProductCategories(ctx, productID)
+	if err != nil {
+		writeServiceError(w, req, err)
+		return
+	}
+
+	writeCacheable(w, req, r.cfg.ProductCacheControl, "", time.Time{}, newCategoriesResponse(categories))
+}
+
+type productCategoriesRequestBody struct {
+	CategoryIDs []uuid.UUID `json:"category_ids"`
+}
+
+// setProductCategoriesHandler replaces the categories of a product with the
+// listed ones. An empty list removes the product from all categories.
+func (r Router) setProductCategoriesHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	var body productCategoriesRequestBody
+	err := json.NewDecoder(req.Body).Decode(&body)
+	if err != nil {
+		writeBodyError(w, req, err)
+		return
+	}
+
+	productID, err := uuid.Parse(req.PathValue("product_id"))
+	if err != nil {
+		writeInvalidIDError(w, req, "product_id")
+		return
+	}
+
+	dto := app.SetProductCategoriesDTO{
+		ProductID:   productID,
+		CategoryIDs: body.CategoryIDs,
+	}
+
+	categories, err := r.service.SetProductCategories(ctx, dto)
+	if err != nil {
+		writeServiceError(w, req, err)
+		return
+	}
+
+	writeJSON(w, req, http.StatusOK, newCategoriesResponse(categories))
+}
+
+type categoriesResponse struct {
+	Categories []categoryResponse `json:"categories"`
+}
+
+func newCategoriesResponse(categories []*app.Category) categoriesResponse {
+	res := categoriesResponse{
+		Categories: make([]categoryResponse, 0, len(categories)),
+	}
+	for _, c := range categories {
+		res.Categories = append(res.Categories, newCategoryResponse(c))
+	}
+
+	return res
+}
+
+// categoryResponse lists the ancestors of the category from the root so that
+// clients can render breadcrumbs without walking up the tree.
+type categoryResponse struct {
+	ID              uuid.UUID      `json:"id"`
+	ParentID        *uuid.UUID     `json:"parent_id"`
+	Name            string         `json:"name"`
+	Ancestors       []uuid.UUID    `json:"ancestor_ids"`
+	AttributeSchema map[string]any `json:"attribute_schema"`
+	Version         int64          `json:"version"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+}
+
+func newCategoryResponse(c *app.Category) categoryResponse {
+	return categoryResponse{
+		ID:              c.ID,
+		ParentID:        c.ParentID,
+		Name:            c.Name,
+		Ancestors:       c.Path[:len(c.Path)-1],
+		AttributeSchema: c.AttributeSchema,
+		Version:         c.Version,
+		CreatedAt:       c.CreatedAt,
+		UpdatedAt:       c.UpdatedAt,
+	}
+}
@@ -0,0 +1,265 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/simpler-tha/internal/app"
+	"github.com/simpler-tha/internal/config"
+)
+
+func TestRouter_createCategoryHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	parentID, _ := uuid.Parse("9f9f4340-6bf9-4948-808c-ebf2dd604e2c")
+	categoryID, _ := uuid.Parse("3d7c1f0a-2b8e-4c55-9a61-7f1e0b2c4d5e")
+
+	now, _ := time.Parse(time.RFC3339, "2024-10-02T14:28:34Z")
+
+	category := &app.Category{
+		ID:        categoryID,
+		ParentID:  &parentID,
+		Name:      "Audio",
+		Path:      []uuid.UUID{parentID, categoryID},
+		Version:   1,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	tests := []struct {
+		name                           string
+		reqBody                        []byte
+		expServiceCreateCategoryDTO    *app.CreateCategoryDTO
+		expServiceCreateCategoryResult *app.Category
+		expServiceCreateCategoryError  error
+		expStatus                      int
+		expResponse                    []byte
+	}{
+		{
+			name:                           "category created successfully",
+			reqBody:                        []byte(`{"name":"Audio","parent_id":"9f9f4340-6bf9-4948-808c-ebf2dd604e2c"}`),
+			expServiceCreateCategoryDTO:    &app.CreateCategoryDTO{Name: "Audio", ParentID: &parentID},
+			expServiceCreateCategoryResult: category,
+			expStatus:                      http.StatusCreated,
+			expResponse:                    []byte("{\"id\":\"3d7c1f0a-2b8e-4c55-9a61-7f1e0b2c4d5e\",\"parent_id\":\"9f9f4340-6bf9-4948-808c-ebf2dd604e2c\",\"name\":\"Audio\",\"ancestor_ids\":[\"9f9f4340-6bf9-4948-808c-ebf2dd604e2c\"],\"attribute_schema\":null,\"version\":1,\"created_at\":\"2024-10-02T14:28:34Z\",\"updated_at\":\"2024-10-02T14:28:34Z\"}\n"),
+		},
+		{
+			name:                          "category name taken among its siblings",
+			reqBody:                       []byte(`{"name":"Audio"}`),
+			expServiceCreateCategoryDTO:   &app.CreateCategoryDTO{Name: "Audio"},
+			expServiceCreateCategoryError: fmt.Errorf("failed to create category: %w", app.ErrConflict),
+			expStatus:                     http.StatusConflict,
+			expResponse:                   []byte("{\"type\":\"/problems/conflict\",\"title\":\"Resource conflict\",\"status\":409,\"detail\":\"The request conflicts with the current state of the resource.\",\"instance\":\"/api/v1/categories\"}\n"),
+		},
+		{
+			name:        "invalid parent ID",
+			reqBody:     []byte(`{"name":"Audio","parent_id":"nope"}`),
+			expStatus:   http.StatusBadRequest,
+			expResponse: []byte("{\"type\":\"/problems/invalid-request-body\",\"title\":\"Invalid request body\",\"status\":400,\"detail\":\"The request body is not valid JSON.\",\"instance\":\"/api/v1/categories\"}\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		mockService := NewMockservice(ctrl)
+
+		if tt.expServiceCreateCategoryDTO != nil {
+			mockService.
+				EXPECT().
+				CreateCategory(gomock.Any(), *tt.expServiceCreateCategoryDTO).
+				Return(tt.expServiceCreateCategoryResult, tt.expServiceCreateCategoryError)
+		}
+
+		router, err := NewRouter(mockService, config.HTTP{})
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/categories", bytes.NewReader(tt.reqBody))
+		recorder := httptest.NewRecorder()
+
+		router.createCategoryHandler(recorder, req)
+
+		assert.Equal(t, tt.expStatus, recorder.Code)
+		if tt.expStatus >= http.StatusBadRequest {
+			assert.Equal(t, problemContentType, recorder.Header().Get("Content-Type"))
+		}
+
+		b, err := io.ReadAll(recorder.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, tt.expResponse, b)
+	}
+}
+
+func TestRouter_getCategoryProductsHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	categoryID, _ := uuid.Parse("3d7c1f0a-2b8e-4c55-9a61-7f1e0b2c4d5e")
+
+	tests := []struct {
+		name                        string
+		categoryID                  string
+		expServiceGetProductsDTO    *app.GetProductsDTO
+		expServiceGetProductsResult *app.ProductPage
+		expServiceGetProductsError  error
+		expStatus                   int
+		expResponse                 []byte
+	}{
+		{
+			name:       "category products listed",
+			categoryID: categoryID.String(),
+			expServiceGetProductsDTO: &app.GetProductsDTO{
+				Filter: app.ProductFilter{CategoryID: categoryID},
+				Sort:   app.DefaultSort,
+				Limit:  getProductsDefaultLimit,
+			},
+			expServiceGetProductsResult: &app.ProductPage{},
+			expStatus:                   http.StatusOK,
+			expResponse:                 []byte("{\"products\":null,\"pagination\":{\"limit\":5,\"offset\":0,\"has_more\":false}}\n"),
+		},
+		{
+			name:       "category not found",
+			categoryID: categoryID.String(),
+			expServiceGetProductsDTO: &app.GetProductsDTO{
+				Filter: app.ProductFilter{CategoryID: categoryID},
+				Sort:   app.DefaultSort,
+				Limit:  getProductsDefaultLimit,
+			},
+			expServiceGetProductsError: fmt.Errorf("failed to get category: %w", app.ErrNotFound),
+			expStatus:                  http.StatusNotFound,
+			expResponse:                []byte("{\"type\":\"/problems/not-found\",\"title\":\"Resource not found\",\"status\":404,\"detail\":\"The requested resource does not exist.\",\"instance\":\"/api/v1/categories/{category_id}/products\"}\n"),
+		},
+		{
+			name:        "invalid category ID",
+			categoryID:  "",
+			expStatus:   http.StatusBadRequest,
+			expResponse: []byte("{\"type\":\"/problems/invalid-parameter\",\"title\":\"Invalid request parameter\",\"status\":400,\"detail\":\"The category_id path parameter is invalid.\",\"instance\":\"/api/v1/categories/{category_id}/products\",\"errors\":[{\"field\":\"category_id\",\"message\":\"must be a valid UUID\"}]}\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		mockService := NewMockservice(ctrl)
+
+		if tt.expServiceGetProductsDTO != nil {
+			mockService.
+				EXPECT().
+				GetProducts(gomock.Any(), *tt.expServiceGetProductsDTO).
+				Return(tt.expServiceGetProductsResult, tt.expServiceGetProductsError)
+		}
+
+		router, err := NewRouter(mockService, config.HTTP{})
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/categories/{category_id}/products", nil)
+		if tt.categoryID != "" {
+			req.SetPathValue("category_id", tt.categoryID)
+		}
+		recorder := httptest.NewRecorder()
+
+		router.getCategoryProductsHandler(recorder, req)
+
+		assert.Equal(t, tt.expStatus, recorder.Code)
+
+		b, err := io.ReadAll(recorder.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, tt.expResponse, b)
+	}
+}
+
+func TestRouter_setProductCategoriesHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	productID, _ := uuid.Parse("9f9f4340-6bf9-4948-808c-ebf2dd604e2c")
+	categoryID, _ := uuid.Parse("3d7c1f0a-2b8e-4c55-9a61-7f1e0b2c4d5e")
+
+	now, _ := time.Parse(time.RFC3339, "2024-10-02T14:28:34Z")
+
+	category := &app.Category{
+		ID:        categoryID,
+		Name:      "Audio",
+		Path:      []uuid.UUID{categoryID},
+		Version:   2,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	tests := []struct {
+		name                                 string
+		reqBody                              []byte
+		expServiceSetProductCategoriesDTO    *app.SetProductCategoriesDTO
+		expServiceSetProductCategoriesResult []*app.Category
+		expServiceSetProductCategoriesError  error
+		expStatus                            int
+		expResponse                          []byte
+	}{
+		{
+			name:                                 "product categories set",
+			reqBody:                              []byte(`{"category_ids":["3d7c1f0a-2b8e-4c55-9a61-7f1e0b2c4d5e"]}`),
+			expServiceSetProductCategoriesDTO:    &app.SetProductCategoriesDTO{ProductID: productID, CategoryIDs: []uuid.UUID{categoryID}},
+			expServiceSetProductCategoriesResult: []*app.Category{category},
+			expStatus:                            http.StatusOK,
+			expResponse:                          []byte("{\"categories\":[{\"id\":\"3d7c1f0a-2b8e-4c55-9a61-7f1e0b2c4d5e\",\"parent_id\":null,\"name\":\"Audio\",\"ancestor_ids\":[],\"attribute_schema\":null,\"version\":2,\"created_at\":\"2024-10-02T14:28:34Z\",\"updated_at\":\"2024-10-02T14:28:34Z\"}]}\n"),
+		},
+		{
+			name:                              "product categories cleared",
+			reqBody:                           []byte(`{"category_ids":[]}`),
+			expServiceSetProductCategoriesDTO: &app.SetProductCategoriesDTO{ProductID: productID, CategoryIDs: []uuid.UUID{}},
+			expStatus:                         http.StatusOK,
+			expResponse:                       []byte("{\"categories\":[]}\n"),
+		},
+		{
+			name:                              "unknown category",
+			reqBody:                           []byte(`{"category_ids":["3d7c1f0a-2b8e-4c55-9a61-7f1e0b2c4d5e"]}`),
+			expServiceSetProductCategoriesDTO: &app.SetProductCategoriesDTO{ProductID: productID, CategoryIDs: []uuid.UUID{categoryID}},
+			expServiceSetProductCategoriesError: fmt.Errorf("invalid product categories: %w", &app.ValidationError{Violations: []app.Violation{
+				{Field: "category_ids", Message: "must refer to existing categories"},
+			}}),
+			expStatus:   http.StatusUnprocessableEntity,
+			expResponse: []byte("{\"type\":\"/problems/validation-failed\",\"title\":\"Validation failed\",\"status\":422,\"detail\":\"The request contains invalid data.\",\"instance\":\"/api/v1/products/{product_id}/categories\",\"errors\":[{\"field\":\"category_ids\",\"message\":\"must refer to existing categories\"}]}\n"),
+		},
+		{
+			name:                                "product categories could not be set",
+			reqBody:                             []byte(`{"category_ids":[]}`),
+			expServiceSetProductCategoriesDTO:   &app.SetProductCategoriesDTO{ProductID: productID, CategoryIDs: []uuid.UUID{}},
+			expServiceSetProductCategoriesError: errors.New("service error"),
+			expStatus:                           http.StatusInternalServerError,
+			expResponse:                         []byte("{\"type\":\"/problems/internal-error\",\"title\":\"Internal server error\",\"status\":500,\"detail\":\"An unexpected error occurred.\",\"instance\":\"/api/v1/products/{product_id}/categories\"}\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		mockService := NewMockservice(ctrl)
+
+		if tt.expServiceSetProductCategoriesDTO != nil {
+			mockService.
+				EXPECT().
+				SetProductCategories(gomock.Any(), *tt.expServiceSetProductCategoriesDTO).
+				Return(tt.expServiceSetProductCategoriesResult, tt.expServiceSetProductCategoriesError)
+		}
+
+		router, err := NewRouter(mockService, config.HTTP{})
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/products/{product_id}/categories", bytes.NewReader(tt.reqBody))
+		req.SetPathValue("product_id", productID.String())
+		recorder := httptest.NewRecorder()
+
+		router.setProductCategoriesHandler(recorder, req)
+
+		assert.Equal(t, tt.expStatus, recorder.Code)
+
+		b, err := io.ReadAll(recorder.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, tt.expResponse, b)
+	}
+}
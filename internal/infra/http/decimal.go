@@ -0,0 +1,27 @@
+package http
+
+import "encoding/json"
+
+// decimalString holds a decimal amount sent either as a JSON string or as a
+// JSON number. Numbers are kept verbatim so that they never go through a
+// float and lose precision.
+type decimalString string
+
+func (d *decimalString) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		*d = decimalString(s)
+		return nil
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return err
+	}
+	*d = decimalString(n)
+
+	return nil
+}
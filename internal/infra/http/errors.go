@@ -0,0 +1,69 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+// writeServiceError maps an error returned by the service to a problem
+// document. Details of unexpected errors are logged, never returned.
+func writeServiceError(w http.ResponseWriter, req *http.Request, err error) {
+	switch {
+	case errors.Is(err, app.ErrInvalidPatch):
+		writeProblem(w, req, problemInvalidPatch, "The patch document is malformed or cannot be applied to the resource.")
+	case errors.As(err, new(*app.InsufficientStockError)):
+		writeProblem(w, req, problemInsufficientStock, "Not enough stock is available.", shortagesToFieldErrors(err)...)
+	case errors.Is(err, app.ErrNotFound):
+		writeProblem(w, req, problemNotFound, "The requested resource does not exist.")
+	case errors.Is(err, app.ErrConflict):
+		writeProblem(w, req, problemConflict, "The request conflicts with the current state of the resource.")
+	case errors.Is(err, app.ErrPreconditionFailed):
+		writeProblem(w, req, problemPreconditionFailed, "The resource has changed since it was last retrieved.")
+	case errors.Is(err, app.ErrValidation):
+		writeProblem(w, req, problemValidation, "The request contains invalid data.", violationsToFieldErrors(err)...)
+	case errors.Is(err, app.ErrUnavailable):
+		writeProblem(w, req, problemUnavailable, "The service is temporarily unavailable, please retry later.")
+	default:
+		log.Printf("unexpected error handling %s %s: %v", req.Method, req.URL.Path, err)
+		writeProblem(w, req, problemInternal, "An unexpected error occurred.")
+	}
+}
+
+// violationsToFieldErrors extracts the per-field violations of a validation
+// error, if the error carries any.
+func violationsToFieldErrors(err error) []fieldError {
+	var validationErr *app.ValidationError
+	if !errors.As(err, &validationErr) {
+		return nil
+	}
+
+	fieldErrors := make([]fieldError, 0, len(validationErr.Violations))
+	for _, v := range validationErr.Violations {
+		fieldErrors = append(fieldErrors, fieldError{Field: v.Field, Message: v.Message})
+	}
+
+	return fieldErrors
+}
+
+// shortagesToFieldErrors reports each product short of stock as an error
+// whose field is the ID of the product.
+func shortagesToFieldErrors(err error) []fieldError {
+	var stockErr *app.InsufficientStockError
+	if !errors.As(err, &stockErr) {
+		return nil
+	}
+
+	fieldErrors := make([]fieldError, 0, len(stockErr.Shortages))
+	for _, s := range stockErr.Shortages {
+		fieldErrors = append(fieldErrors, fieldError{
+			Field:   s.ProductID.String(),
+			Message: fmt.Sprintf("%d units requested, %d available", s.Requested, s.Available),
+		})
+	}
+
+	return fieldErrors
+}
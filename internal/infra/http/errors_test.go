@@ -0,0 +1,102 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+func TestWriteServiceError(t *testing.T) {
+	productID, _ := uuid.Parse("9f9f4340-6bf9-4948-808c-ebf2dd604e2c")
+
+	tests := []struct {
+		name        string
+		err         error
+		expStatus   int
+		expResponse []byte
+	}{
+		{
+			name:        "invalid patch",
+			err:         fmt.Errorf("failed to apply patch: %w", app.ErrInvalidPatch),
+			expStatus:   http.StatusBadRequest,
+			expResponse: []byte("{\"type\":\"/problems/invalid-patch\",\"title\":\"Invalid patch document\",\"status\":400,\"detail\":\"The patch document is malformed or cannot be applied to the resource.\",\"instance\":\"/api/v1/test\"}\n"),
+		},
+		{
+			name: "insufficient stock",
+			err: fmt.Errorf("failed to reserve stock: %w", &app.InsufficientStockError{Shortages: []app.StockShortage{
+				{ProductID: productID, Requested: 5, Available: 2},
+			}}),
+			expStatus:   http.StatusConflict,
+			expResponse: []byte("{\"type\":\"/problems/insufficient-stock\",\"title\":\"Insufficient stock\",\"status\":409,\"detail\":\"Not enough stock is available.\",\"instance\":\"/api/v1/test\",\"errors\":[{\"field\":\"9f9f4340-6bf9-4948-808c-ebf2dd604e2c\",\"message\":\"5 units requested, 2 available\"}]}\n"),
+		},
+		{
+			name:        "not found",
+			err:         fmt.Errorf("failed to get product: %w", app.ErrNotFound),
+			expStatus:   http.StatusNotFound,
+			expResponse: []byte("{\"type\":\"/problems/not-found\",\"title\":\"Resource not found\",\"status\":404,\"detail\":\"The requested resource does not exist.\",\"instance\":\"/api/v1/test\"}\n"),
+		},
+		{
+			name:        "conflict",
+			err:         fmt.Errorf("failed to create product: %w", app.ErrConflict),
+			expStatus:   http.StatusConflict,
+			expResponse: []byte("{\"type\":\"/problems/conflict\",\"title\":\"Resource conflict\",\"status\":409,\"detail\":\"The request conflicts with the current state of the resource.\",\"instance\":\"/api/v1/test\"}\n"),
+		},
+		{
+			name:        "precondition failed",
+			err:         fmt.Errorf("%w: product is at version 2, not 1", app.ErrPreconditionFailed),
+			expStatus:   http.StatusPreconditionFailed,
+			expResponse: []byte("{\"type\":\"/problems/precondition-failed\",\"title\":\"Precondition failed\",\"status\":412,\"detail\":\"The resource has changed since it was last retrieved.\",\"instance\":\"/api/v1/test\"}\n"),
+		},
+		{
+			name: "validation error with violations",
+			err: fmt.Errorf("invalid product: %w", &app.ValidationError{Violations: []app.Violation{
+				{Field: "name", Message: "must not be empty"},
+			}}),
+			expStatus:   http.StatusUnprocessableEntity,
+			expResponse: []byte("{\"type\":\"/problems/validation-failed\",\"title\":\"Validation failed\",\"status\":422,\"detail\":\"The request contains invalid data.\",\"instance\":\"/api/v1/test\",\"errors\":[{\"field\":\"name\",\"message\":\"must not be empty\"}]}\n"),
+		},
+		{
+			name:        "validation error without violations",
+			err:         fmt.Errorf("failed to create product: %w", app.ErrValidation),
+			expStatus:   http.StatusUnprocessableEntity,
+			expResponse: []byte("{\"type\":\"/problems/validation-failed\",\"title\":\"Validation failed\",\"status\":422,\"detail\":\"The request contains invalid data.\",\"instance\":\"/api/v1/test\"}\n"),
+		},
+		{
+			name:        "unavailable",
+			err:         fmt.Errorf("failed to get product: %w", app.ErrUnavailable),
+			expStatus:   http.StatusServiceUnavailable,
+			expResponse: []byte("{\"type\":\"/problems/service-unavailable\",\"title\":\"Service unavailable\",\"status\":503,\"detail\":\"The service is temporarily unavailable, please retry later.\",\"instance\":\"/api/v1/test\"}\n"),
+		},
+		{
+			name:        "unexpected error",
+			err:         errors.New("boom"),
+			expStatus:   http.StatusInternalServerError,
+			expResponse: []byte("{\"type\":\"/problems/internal-error\",\"title\":\"Internal server error\",\"status\":500,\"detail\":\"An unexpected error occurred.\",\"instance\":\"/api/v1/test\"}\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/test", nil)
+			rec := httptest.NewRecorder()
+
+			writeServiceError(rec, req, tt.err)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			body, _ := io.ReadAll(res.Body)
+			assert.Equal(t, tt.expStatus, res.StatusCode)
+			assert.Equal(t, problemContentType, res.Header.Get("Content-Type"))
+			assert.Equal(t, string(tt.expResponse), string(body))
+		})
+	}
+}
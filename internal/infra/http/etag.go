@@ -0,0 +1,71 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var (
+	errIfMatchWeak      = errors.New("weak entity tags never match If-Match")
+	errIfMatchMalformed = errors.New("malformed If-Match header")
+)
+
+// versionETag formats a resource version as a strong entity tag.
+func versionETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// parseIfMatch returns the version required by the If-Match header. The
+// version is zero when the header is absent or "*", present tells them apart.
+// Only a single entity tag is supported because a resource has exactly one
+// current version.
+func parseIfMatch(req *http.Request) (version int64, present bool, err error) {
+	h := strings.TrimSpace(req.Header.Get("If-Match"))
+	if h == "" {
+		return 0, false, nil
+	}
+
+	if h == "*" {
+		return 0, true, nil
+	}
+
+	if strings.HasPrefix(h, "W/") {
+		return 0, true, errIfMatchWeak
+	}
+
+	if len(h) < 2 || h[0] != '"' || h[len(h)-1] != '"' || strings.Contains(h[1:len(h)-1], `"`) {
+		return 0, true, errIfMatchMalformed
+	}
+
+	version, err = strconv.ParseInt(h[1:len(h)-1], 10, 64)
+	if err != nil || version < 1 {
+		// A well-formed tag that we never issued cannot match.
+		return 0, true, errIfMatchWeak
+	}
+
+	return version, true, nil
+}
+
+// expectedVersion reads the If-Match header of a write request and writes
+// the matching problem when it cannot be honoured. ok is false when the
+// response has already been written.
+func (r Router) expectedVersion(w http.ResponseWriter, req *http.Request) (version int64, ok bool) {
+	version, present, err := parseIfMatch(req)
+	switch {
+	case errors.Is(err, errIfMatchMalformed):
+		writeProblem(w, req, problemInvalidParameter, "The If-Match header must contain a single entity tag or *.",
+			fieldError{Field: "If-Match", Message: "must be a single quoted entity tag or *"},
+		)
+		return 0, false
+	case errors.Is(err, errIfMatchWeak):
+		writeProblem(w, req, problemPreconditionFailed, "The entity tag in If-Match does not match the current version of the resource.")
+		return 0, false
+	case !present && r.cfg.RequireIfMatch:
+		writeProblem(w, req, problemPreconditionRequired, "This request must be made conditional with an If-Match header.")
+		return 0, false
+	}
+
+	return version, true
+}
@@ -0,0 +1,187 @@
+package http
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+const getProductsMaxIDs = 100
+
+// adminParameters are the filter parameters only accepted on the admin
+// listener: they expose products the clients of the API must not see.
+var adminParameters = []string{"include_deleted"}
+
+// allowParameters writes a forbidden problem and returns false when a request
+// to the API uses one of the adminParameters.
+func (r Router) allowParameters(w http.ResponseWriter, req *http.Request) bool {
+	if r.admin {
+		return true
+	}
+
+	query := req.URL.Query()
+	for _, param := range adminParameters {
+		if query.Has(param) {
+			writeProblem(w, req, problemForbidden, "The query parameters are only accepted by the admin API.",
+				fieldError{Field: param, Message: "is only accepted by the admin API"},
+			)
+			return false
+		}
+	}
+
+	return true
+}
+
+// parseProductFilter reads the filter parameters of the product list.
+// Timestamps are RFC 3339 and prices are decimal amounts in the currency
+// parameter, or in the default currency when it is absent.
+func parseProductFilter(query url.Values) (app.ProductFilter, []fieldError) {
+	var (
+		f         app.ProductFilter
+		fieldErrs []fieldError
+	)
+
+	f.NameContains = query.Get("name_contains")
+
+	currency := app.DefaultCurrency
+	if v := query.Get("currency"); v != "" {
+		if _, ok := app.CurrencyExponent(v); !ok {
+			fieldErrs = append(fieldErrs, fieldError{Field: "currency", Message: "must be a supported ISO 4217 currency code"})
+		} else {
+			f.Currency = v
+			currency = v
+		}
+	}
+
+	price := func(param string) *app.Money {
+		v := query.Get(param)
+		if v == "" {
+			return nil
+		}
+
+		m, err := app.ParseMoney(v, currency)
+		if err != nil || m.Amount < 0 {
+			fieldErrs = append(fieldErrs, fieldError{Field: param, Message: "must be a non-negative decimal amount"})
+			return nil
+		}
+
+		return &m
+	}
+	f.MinPrice = price("price_min")
+	f.MaxPrice = price("price_max")
+
+	timestamp := func(param string) time.Time {
+		v := query.Get(param)
+		if v == "" {
+			return time.Time{}
+		}
+
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			fieldErrs = append(fieldErrs, fieldError{Field: param, Message: "must be an RFC 3339 timestamp"})
+		}
+
+		return t
+	}
+	f.CreatedAfter = timestamp("created_after")
+	f.CreatedBefore = timestamp("created_before")
+	f.UpdatedAfter = timestamp("updated_after")
+	f.UpdatedBefore = timestamp("updated_before")
+
+	if v := query.Get("ids"); v != "" {
+		parts := strings.Split(v, ",")
+		if len(parts) > getProductsMaxIDs {
+			fieldErrs = append(fieldErrs, fieldError{Field: "ids", Message: "must not contain more than 100 IDs"})
+		}
+
+		for _, part := range parts {
+			id, err := uuid.Parse(part)
+			if err != nil {
+				fieldErrs = append(fieldErrs, fieldError{Field: "ids", Message: "must be a comma separated list of UUIDs"})
+				break
+			}
+			f.IDs = append(f.IDs, id)
+		}
+	}
+
+	tags := func(param string) []string {
+		v := query.Get(param)
+		if v == "" {
+			return nil
+		}
+
+		return strings.Split(v, ",")
+	}
+	f.TagsAny = tags("tags_any")
+	f.TagsAll = tags("tags_all")
+
+	var attrErrs []fieldError
+	f.Attributes, attrErrs = parseAttributeFilter(query)
+	fieldErrs = append(fieldErrs, attrErrs...)
+
+	// Only published products are listed unless the client asks for other
+	// statuses, or for all of them.
+	if v := query.Get("status"); v != "" {
+		f.Statuses = parseStatuses(v)
+		if f.Statuses == nil {
+			fieldErrs = append(fieldErrs, fieldError{Field: "status", Message: "must be a comma separated list of draft, published and archived, or all"})
+		}
+	}
+
+	if v := query.Get("include_deleted"); v != "" {
+		includeDeleted, err := strconv.ParseBool(v)
+		if err != nil {
+			fieldErrs = append(fieldErrs, fieldError{Field: "include_deleted", Message: "must be a boolean"})
+		}
+		f.IncludeDeleted = includeDeleted
+	}
+
+	if f.MinPrice != nil && f.MaxPrice != nil && f.MinPrice.Amount > f.MaxPrice.Amount {
+		fieldErrs = append(fieldErrs, fieldError{Field: "price_max", Message: "must not be lower than price_min"})
+	}
+
+	return f, fieldErrs
+}
+
+// parseAsOf reads the as_of parameter of the product reads, the RFC 3339
+// instant the products are read as of. It is zero when the parameter is
+// absent.
+func parseAsOf(query url.Values) (time.Time, []fieldError) {
+	v := query.Get("as_of")
+	if v == "" {
+		return time.Time{}, nil
+	}
+
+	asOf, err := time.Parse(time.RFC3339Nano, v)
+	if err != nil {
+		return time.Time{}, []fieldError{{Field: "as_of", Message: "must be an RFC 3339 timestamp"}}
+	}
+
+	return asOf, nil
+}
+
+// parseStatuses parses a comma separated list of product statuses, or all. It
+// returns nil when the list contains an unknown status.
+func parseStatuses(v string) []app.ProductStatus {
+	if v == "all" {
+		return app.ProductStatuses
+	}
+
+	var statuses []app.ProductStatus
+	for _, part := range strings.Split(v, ",") {
+		status := app.ProductStatus(part)
+		if !slices.Contains(app.ProductStatuses, status) {
+			return nil
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses
+}
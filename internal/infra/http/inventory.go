@@ -0,0 +1,280 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+const (
+	getInventoryEndpoint        string = "GET /api/v1/products/{product_id}/inventory"
+	adjustStockEndpoint         string = "POST /api/v1/products/{product_id}/inventory/adjustments"
+	getStockAdjustmentsEndpoint string = "GET /api/v1/products/{product_id}/inventory/adjustments"
+	createReservationEndpoint   string = "POST /api/v1/reservations"
+	getReservationEndpoint      string = "GET /api/v1/reservations/{reservation_id}"
+	commitReservationEndpoint   string = "POST /api/v1/reservations/{reservation_id}/commit"
+	releaseReservationEndpoint  string = "POST /api/v1/reservations/{reservation_id}/release"
+)
+
+// Stock changes with every checkout, its responses are never cacheable.
+
+func (r Router) getInventoryHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	productID, err := uuid.Parse(req.PathValue("product_id"))
+	if err != nil {
+		writeInvalidIDError(w, req, "product_id")
+		return
+	}
+
+	inv, err := r.service.GetInventory(ctx, productID)
+	if err != nil {
+		writeServiceError(w, req, err)
+		return
+	}
+
+	writeJSON(w, req, http.StatusOK, newInventoryResponse(inv))
+}
+
+type stockAdjustmentRequestBody struct {
+	Delta  int    `json:"delta"`
+	Reason string `json:"reason"`
+	Note   string `json:"note"`
+}
+
+// adjustStockHandler records a stock adjustment and responds with the
+// resulting inventory.
+func (r Router) adjustStockHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	var body stockAdjustmentRequestBody
+	err := json.NewDecoder(req.Body).Decode(&body)
+	if err != nil {
+		writeBodyError(w, req, err)
+		return
+	}
+
+	productID, err := uuid.Parse(req.PathValue("product_id"))
+	if err != nil {
+		writeInvalidIDError(w, req, "product_id")
+		return
+	}
+
+	dto := app.AdjustStockDTO{
+		ProductID: productID,
+		Delta:     body.Delta,
+		Reason:    app.StockAdjustmentReason(body.Reason),
+		Note:      body.Note,
+	}
+
+	inv, err := r.service.AdjustStock(ctx, dto)
+	if err != nil {
+		writeServiceError(w, req, err)
+		return
+	}
+
+	writeJSON(w, req, http.StatusOK, newInventoryResponse(inv))
+}
+
+func (r Router) getStockAdjustmentsHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	productID, err := uuid.Parse(req.PathValue("product_id"))
+	if err != nil {
+		writeInvalidIDError(w, req, "product_id")
+		return
+	}
+
+	adjustments, err := r.service.GetStockAdjustments(ctx, productID)
+	if err != nil {
+		writeServiceError(w, req, err)
+		return
+	}
+
+	res := stockAdjustmentsResponse{
+		Adjustments: make([]stockAdjustmentResponse, 0, len(adjustments)),
+	}
+	for _, a := range adjustments {
+		res.Adjustments = append(res.Adjustments, newStockAdjustmentResponse(a))
+	}
+
+	writeJSON(w, req, http.StatusOK, res)
+}
+
+// reservationRequestBody reserves the items for TTLSeconds, or for the
+// default time to live when it is omitted.
+type reservationRequestBody struct {
+	Items []struct {
+		ProductID uuid.UUID `json:"product_id"`
+		Quantity  int       `json:"quantity"`
+	} `json:"items"`
+	TTLSeconds int `json:"ttl_seconds"`
+}
+
+func (r Router) createReservationHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	var body reservationRequestBody
+	err := json.NewDecoder(req.Body).Decode(&body)
+	if err != nil {
+		writeBodyError(w, req, err)
+		return
+	}
+
+	dto := app.CreateReservationDTO{
+		Items: make([]app.ReservationItem, 0, len(body.Items)),
+		TTL:   time.Duration(body.TTLSeconds) * time.Second,
+	}
+	for _, item := range body.Items {
+		dto.Items = append(dto.Items, app.ReservationItem{ProductID: item.ProductID, Quantity: item.Quantity})
+	}
+
+	res, err := r.service.CreateReservation(ctx, dto)
+	if err != nil {
+		writeServiceError(w, req, err)
+		return
+	}
+
+	writeJSON(w, req, http.StatusCreated, newReservationResponse(res))
+}
+
+func (r Router) getReservationHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	reservationID, err := uuid.Parse(req.PathValue("reservation_id"))
+	if err != nil {
+		writeInvalidIDError(w, req, "reservation_id")
+		return
+	}
+
+	res, err := r.service.GetReservation(ctx, reservationID)
+	if err != nil {
+		writeServiceError(w, req, err)
+		return
+	}
+
+	writeJSON(w, req, http.StatusOK, newReservationResponse(res))
+}
+
+func (r Router) commitReservationHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	reservationID, err := uuid.Parse(req.PathValue("reservation_id"))
+	if err != nil {
+		writeInvalidIDError(w, req, "reservation_id")
+		return
+	}
+
+	res, err := r.service.CommitReservation(ctx, reservationID)
+	if err != nil {
+		writeServiceError(w, req, err)
+		return
+	}
+
+	writeJSON(w, req, http.StatusOK, newReservationResponse(res))
+}
+
+func (r Router) releaseReservationHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	reservationID, err := uuid.Parse(req.PathValue("reservation_id"))
+	if err != nil {
+		writeInvalidIDError(w, req, "reservation_id")
+		return
+	}
+
+	res, err := r.service.ReleaseReservation(ctx, reservationID)
+	if err != nil {
+		writeServiceError(w, req, err)
+		return
+	}
+
+	writeJSON(w, req, http.StatusOK, newReservationResponse(res))
+}
+
+// inventoryResponse has a null updated_at when the stock of the product was
+// never adjusted.
+type inventoryResponse struct {
+	ProductID uuid.UUID  `json:"product_id"`
+	OnHand    int        `json:"on_hand"`
+	Reserved  int        `json:"reserved"`
+	Available int        `json:"available"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+func newInventoryResponse(inv *app.Inventory) inventoryResponse {
+	res := inventoryResponse{
+		ProductID: inv.ProductID,
+		OnHand:    inv.OnHand,
+		Reserved:  inv.Reserved,
+		Available: inv.Available(),
+	}
+	if !inv.UpdatedAt.IsZero() {
+		res.UpdatedAt = &inv.UpdatedAt
+	}
+
+	return res
+}
+
+type stockAdjustmentsResponse struct {
+	Adjustments []stockAdjustmentResponse `json:"adjustments"`
+}
+
+// stockAdjustmentResponse has a null reservation_id unless it records a sale.
+type stockAdjustmentResponse struct {
+	ID            uuid.UUID  `json:"id"`
+	Delta         int        `json:"delta"`
+	Reason        string     `json:"reason"`
+	Note          string     `json:"note"`
+	ReservationID *uuid.UUID `json:"reservation_id"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+func newStockAdjustmentResponse(a app.StockAdjustment) stockAdjustmentResponse {
+	res := stockAdjustmentResponse{
+		ID:        a.ID,
+		Delta:     a.Delta,
+		Reason:    string(a.Reason),
+		Note:      a.Note,
+		CreatedAt: a.CreatedAt,
+	}
+	if a.ReservationID != uuid.Nil {
+		res.ReservationID = &a.ReservationID
+	}
+
+	return res
+}
+
+type reservationResponse struct {
+	ID        uuid.UUID                 `json:"id"`
+	Status    string                    `json:"status"`
+	Items     []reservationItemResponse `json:"items"`
+	ExpiresAt time.Time                 `json:"expires_at"`
+	CreatedAt time.Time                 `json:"created_at"`
+	UpdatedAt time.Time                 `json:"updated_at"`
+}
+
+type reservationItemResponse struct {
+	ProductID uuid.UUID `json:"product_id"`
+	Quantity  int       `json:"quantity"`
+}
+
+func newReservationResponse(r *app.Reservation) reservationResponse {
+	res := reservationResponse{
+		ID:        r.ID,
+		Status:    string(r.Status),
+		Items:     make([]reservationItemResponse, 0, len(r.Items)),
+		ExpiresAt: r.ExpiresAt,
+		CreatedAt: r.CreatedAt,
+		UpdatedAt: r.UpdatedAt,
+	}
+	for _, item := range r.Items {
+		res.Items = append(res.Items, reservationItemResponse(item))
+	}
+
+	return res
+}
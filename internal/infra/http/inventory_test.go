@@ -0,0 +1,253 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/simpler-tha/internal/app"
+	"github.com/simpler-tha/internal/config"
+)
+
+func TestRouter_adjustStockHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	productID, _ := uuid.Parse("9f9f4340-6bf9-4948-808c-ebf2dd604e2c")
+
+	now, _ := time.Parse(time.RFC3339, "2024-10-02T14:28:34Z")
+
+	tests := []struct {
+		name                        string
+		reqBody                     []byte
+		expServiceAdjustStockDTO    *app.AdjustStockDTO
+		expServiceAdjustStockResult *app.Inventory
+		expServiceAdjustStockError  error
+		expStatus                   int
+		expResponse                 []byte
+	}{
+		{
+			name:    "stock adjusted successfully",
+			reqBody: []byte(`{"delta":10,"reason":"received","note":"PO-1234"}`),
+			expServiceAdjustStockDTO: &app.AdjustStockDTO{
+				ProductID: productID,
+				Delta:     10,
+				Reason:    app.StockAdjustmentReceived,
+				Note:      "PO-1234",
+			},
+			expServiceAdjustStockResult: &app.Inventory{ProductID: productID, OnHand: 12, Reserved: 2, UpdatedAt: now},
+			expStatus:                   http.StatusOK,
+			expResponse:                 []byte("{\"product_id\":\"9f9f4340-6bf9-4948-808c-ebf2dd604e2c\",\"on_hand\":12,\"reserved\":2,\"available\":10,\"updated_at\":\"2024-10-02T14:28:34Z\"}\n"),
+		},
+		{
+			name:    "stock on hand below the reserved units",
+			reqBody: []byte(`{"delta":-20,"reason":"damaged"}`),
+			expServiceAdjustStockDTO: &app.AdjustStockDTO{
+				ProductID: productID,
+				Delta:     -20,
+				Reason:    app.StockAdjustmentDamaged,
+			},
+			expServiceAdjustStockError: fmt.Errorf("failed to adjust stock: %w", &app.InsufficientStockError{Shortages: []app.StockShortage{
+				{ProductID: productID, Requested: 20, Available: 10},
+			}}),
+			expStatus:   http.StatusConflict,
+			expResponse: []byte("{\"type\":\"/problems/insufficient-stock\",\"title\":\"Insufficient stock\",\"status\":409,\"detail\":\"Not enough stock is available.\",\"instance\":\"/api/v1/products/9f9f4340-6bf9-4948-808c-ebf2dd604e2c/inventory/adjustments\",\"errors\":[{\"field\":\"9f9f4340-6bf9-4948-808c-ebf2dd604e2c\",\"message\":\"20 units requested, 10 available\"}]}\n"),
+		},
+		{
+			name:        "invalid delta",
+			reqBody:     []byte(`{"delta":"10","reason":"received"}`),
+			expStatus:   http.StatusBadRequest,
+			expResponse: []byte("{\"type\":\"/problems/invalid-request-body\",\"title\":\"Invalid request body\",\"status\":400,\"detail\":\"The request body contains a field of the wrong type.\",\"instance\":\"/api/v1/products/9f9f4340-6bf9-4948-808c-ebf2dd604e2c/inventory/adjustments\",\"errors\":[{\"field\":\"delta\",\"message\":\"must be of type int\"}]}\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		mockService := NewMockservice(ctrl)
+
+		if tt.expServiceAdjustStockDTO != nil {
+			mockService.
+				EXPECT().
+				AdjustStock(gomock.Any(), *tt.expServiceAdjustStockDTO).
+				Return(tt.expServiceAdjustStockResult, tt.expServiceAdjustStockError)
+		}
+
+		router, err := NewRouter(mockService, config.HTTP{})
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/products/"+productID.String()+"/inventory/adjustments", bytes.NewReader(tt.reqBody))
+		req.SetPathValue("product_id", productID.String())
+		recorder := httptest.NewRecorder()
+
+		router.adjustStockHandler(recorder, req)
+
+		assert.Equal(t, tt.expStatus, recorder.Code)
+
+		b, err := io.ReadAll(recorder.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, tt.expResponse, b)
+	}
+}
+
+func TestRouter_getInventoryHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	productID, _ := uuid.Parse("9f9f4340-6bf9-4948-808c-ebf2dd604e2c")
+
+	mockService := NewMockservice(ctrl)
+	mockService.
+		EXPECT().
+		GetInventory(gomock.Any(), productID).
+		Return(&app.Inventory{ProductID: productID}, nil)
+
+	router, err := NewRouter(mockService, config.HTTP{})
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/products/"+productID.String()+"/inventory", nil)
+	req.SetPathValue("product_id", productID.String())
+	recorder := httptest.NewRecorder()
+
+	router.getInventoryHandler(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "{\"product_id\":\"9f9f4340-6bf9-4948-808c-ebf2dd604e2c\",\"on_hand\":0,\"reserved\":0,\"available\":0,\"updated_at\":null}\n", recorder.Body.String())
+}
+
+func TestRouter_createReservationHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	productID, _ := uuid.Parse("9f9f4340-6bf9-4948-808c-ebf2dd604e2c")
+	reservationID, _ := uuid.Parse("5b0e7a4c-1d2f-4e3a-8b9c-0d1e2f3a4b5c")
+
+	now, _ := time.Parse(time.RFC3339, "2024-10-02T14:28:34Z")
+
+	tests := []struct {
+		name                              string
+		reqBody                           []byte
+		expServiceCreateReservationDTO    *app.CreateReservationDTO
+		expServiceCreateReservationResult *app.Reservation
+		expServiceCreateReservationError  error
+		expStatus                         int
+		expResponse                       []byte
+	}{
+		{
+			name:    "stock reserved successfully",
+			reqBody: []byte(`{"items":[{"product_id":"9f9f4340-6bf9-4948-808c-ebf2dd604e2c","quantity":2}],"ttl_seconds":600}`),
+			expServiceCreateReservationDTO: &app.CreateReservationDTO{
+				Items: []app.ReservationItem{{ProductID: productID, Quantity: 2}},
+				TTL:   10 * time.Minute,
+			},
+			expServiceCreateReservationResult: &app.Reservation{
+				ID:        reservationID,
+				Status:    app.ReservationPending,
+				Items:     []app.ReservationItem{{ProductID: productID, Quantity: 2}},
+				ExpiresAt: now.Add(10 * time.Minute),
+				CreatedAt: now,
+				UpdatedAt: now,
+			},
+			expStatus:   http.StatusCreated,
+			expResponse: []byte("{\"id\":\"5b0e7a4c-1d2f-4e3a-8b9c-0d1e2f3a4b5c\",\"status\":\"pending\",\"items\":[{\"product_id\":\"9f9f4340-6bf9-4948-808c-ebf2dd604e2c\",\"quantity\":2}],\"expires_at\":\"2024-10-02T14:38:34Z\",\"created_at\":\"2024-10-02T14:28:34Z\",\"updated_at\":\"2024-10-02T14:28:34Z\"}\n"),
+		},
+		{
+			name:    "not enough stock available",
+			reqBody: []byte(`{"items":[{"product_id":"9f9f4340-6bf9-4948-808c-ebf2dd604e2c","quantity":5}]}`),
+			expServiceCreateReservationDTO: &app.CreateReservationDTO{
+				Items: []app.ReservationItem{{ProductID: productID, Quantity: 5}},
+			},
+			expServiceCreateReservationError: fmt.Errorf("failed to create reservation: %w", &app.InsufficientStockError{Shortages: []app.StockShortage{
+				{ProductID: productID, Requested: 5, Available: 1},
+			}}),
+			expStatus:   http.StatusConflict,
+			expResponse: []byte("{\"type\":\"/problems/insufficient-stock\",\"title\":\"Insufficient stock\",\"status\":409,\"detail\":\"Not enough stock is available.\",\"instance\":\"/api/v1/reservations\",\"errors\":[{\"field\":\"9f9f4340-6bf9-4948-808c-ebf2dd604e2c\",\"message\":\"5 units requested, 1 available\"}]}\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		mockService := NewMockservice(ctrl)
+
+		if tt.expServiceCreateReservationDTO != nil {
+			mockService.
+				EXPECT().
+				CreateReservation(gomock.Any(), *tt.expServiceCreateReservationDTO).
+				Return(tt.expServiceCreateReservationResult, tt.expServiceCreateReservationError)
+		}
+
+		router, err := NewRouter(mockService, config.HTTP{})
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/reservations", bytes.NewReader(tt.reqBody))
+		recorder := httptest.NewRecorder()
+
+		router.createReservationHandler(recorder, req)
+
+		assert.Equal(t, tt.expStatus, recorder.Code)
+
+		b, err := io.ReadAll(recorder.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, tt.expResponse, b)
+	}
+}
+
+func TestRouter_commitReservationHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	reservationID, _ := uuid.Parse("5b0e7a4c-1d2f-4e3a-8b9c-0d1e2f3a4b5c")
+
+	tests := []struct {
+		name                              string
+		reservationID                     string
+		expServiceCommitReservationResult *app.Reservation
+		expServiceCommitReservationError  error
+		expStatus                         int
+		expResponse                       []byte
+	}{
+		{
+			name:                             "reservation has expired",
+			reservationID:                    reservationID.String(),
+			expServiceCommitReservationError: fmt.Errorf("%w: reservation %s has expired", app.ErrConflict, reservationID),
+			expStatus:                        http.StatusConflict,
+			expResponse:                      []byte("{\"type\":\"/problems/conflict\",\"title\":\"Resource conflict\",\"status\":409,\"detail\":\"The request conflicts with the current state of the resource.\",\"instance\":\"/api/v1/reservations/5b0e7a4c-1d2f-4e3a-8b9c-0d1e2f3a4b5c/commit\"}\n"),
+		},
+		{
+			name:          "invalid reservation ID",
+			reservationID: "abc",
+			expStatus:     http.StatusBadRequest,
+			expResponse:   []byte("{\"type\":\"/problems/invalid-parameter\",\"title\":\"Invalid request parameter\",\"status\":400,\"detail\":\"The reservation_id path parameter is invalid.\",\"instance\":\"/api/v1/reservations/abc/commit\",\"errors\":[{\"field\":\"reservation_id\",\"message\":\"must be a valid UUID\"}]}\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		mockService := NewMockservice(ctrl)
+
+		if tt.reservationID == reservationID.String() {
+			mockService.
+				EXPECT().
+				CommitReservation(gomock.Any(), reservationID).
+				Return(tt.expServiceCommitReservationResult, tt.expServiceCommitReservationError)
+		}
+
+		router, err := NewRouter(mockService, config.HTTP{})
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/reservations/"+tt.reservationID+"/commit", nil)
+		req.SetPathValue("reservation_id", tt.reservationID)
+		recorder := httptest.NewRecorder()
+
+		router.commitReservationHandler(recorder, req)
+
+		assert.Equal(t, tt.expStatus, recorder.Code)
+
+		b, err := io.ReadAll(recorder.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, tt.expResponse, b)
+	}
+}
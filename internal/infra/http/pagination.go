@@ -0,0 +1,172 @@
+package http
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+// parseGetProductsQuery reads the filter, sort and pagination parameters of
+// the product list. A page is selected either with a cursor from a previous
+// response or with an offset, which is kept for existing clients.
+func parseGetProductsQuery(query url.Values) (app.GetProductsDTO, []fieldError) {
+	dto := app.GetProductsDTO{
+		Sort: app.DefaultSort,
+	}
+
+	filter, fieldErrs := parseProductFilter(query)
+	dto.Filter = filter
+
+	var asOfErrs []fieldError
+	dto.Filter.AsOf, asOfErrs = parseAsOf(query)
+	fieldErrs = append(fieldErrs, asOfErrs...)
+
+	if v := query.Get("sort"); v != "" {
+		sort, err := app.ParseSort(v)
+		if err != nil {
+			fieldErrs = append(fieldErrs, fieldError{Field: "sort", Message: "must be a comma separated list of name, price, created_at or updated_at, each optionally prefixed with -"})
+		} else {
+			dto.Sort = sort
+		}
+	}
+
+	var pageErrs []fieldError
+	dto.Limit, dto.Offset, pageErrs = parseLimitOffset(query)
+	fieldErrs = append(fieldErrs, pageErrs...)
+
+	if v := query.Get("count"); v != "" {
+		count, err := strconv.ParseBool(v)
+		if err != nil {
+			fieldErrs = append(fieldErrs, fieldError{Field: "count", Message: "must be a boolean"})
+		} else {
+			dto.Count = count
+		}
+	}
+
+	for _, param := range []string{"after", "before"} {
+		v := query.Get(param)
+		if v == "" {
+			continue
+		}
+
+		c, err := app.DecodeCursor(v)
+		if err != nil {
+			fieldErrs = append(fieldErrs, fieldError{Field: param, Message: "must be a cursor returned by a previous request"})
+			continue
+		}
+		if c.Sort.String() != dto.Sort.String() {
+			fieldErrs = append(fieldErrs, fieldError{Field: param, Message: "was returned for a different sort"})
+			continue
+		}
+
+		if param == "after" {
+			dto.After = &c
+		} else {
+			dto.Before = &c
+		}
+	}
+
+	hasAfter, hasBefore := query.Get("after") != "", query.Get("before") != ""
+	switch {
+	case hasAfter && hasBefore:
+		fieldErrs = append(fieldErrs, fieldError{Field: "before", Message: "cannot be combined with after"})
+	case query.Get("offset") != "" && (hasAfter || hasBefore):
+		fieldErrs = append(fieldErrs, fieldError{Field: "offset", Message: "cannot be combined with a cursor"})
+	}
+
+	return dto, fieldErrs
+}
+
+// parseLimitOffset reads the limit and offset parameters of the endpoints
+// returning pages of products.
+func parseLimitOffset(query url.Values) (limit, offset int, fieldErrs []fieldError) {
+	limit, offset = getProductsDefaultLimit, getProductsDefaultOffset
+
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > getProductsMaxLimit {
+			fieldErrs = append(fieldErrs, fieldError{Field: "limit", Message: "must be an integer between 1 and " + strconv.Itoa(getProductsMaxLimit)})
+		} else {
+			limit = n
+		}
+	}
+
+	if v := query.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			fieldErrs = append(fieldErrs, fieldError{Field: "offset", Message: "must be a non-negative integer"})
+		} else {
+			offset = n
+		}
+	}
+
+	return limit, offset, fieldErrs
+}
+
+// paginationResponse describes the page in the body of the product list.
+// Offset is only set in offset mode and Total only when it was requested.
+type paginationResponse struct {
+	Limit   int  `json:"limit"`
+	Offset  *int `json:"offset,omitempty"`
+	Total   *int `json:"total,omitempty"`
+	HasMore bool `json:"has_more"`
+}
+
+func newPaginationResponse(dto app.GetProductsDTO, page *app.ProductPage) paginationResponse {
+	res := paginationResponse{
+		Limit:   dto.Limit,
+		Total:   page.Total,
+		HasMore: page.NextCursor != nil,
+	}
+	if dto.After == nil && dto.Before == nil {
+		offset := dto.Offset
+		res.Offset = &offset
+	}
+
+	return res
+}
+
+// setPaginationLinks adds the RFC 8288 Link headers of the page. Links keep
+// the other query parameters of the request and use the same mode as the
+// request, except for the last page which can only be addressed by offset.
+func setPaginationLinks(h http.Header, req *http.Request, dto app.GetProductsDTO, page *app.ProductPage) {
+	link := func(rel string, set func(q url.Values)) {
+		q := req.URL.Query()
+		q.Del("offset")
+		q.Del("after")
+		q.Del("before")
+		q.Set("limit", strconv.Itoa(dto.Limit))
+		set(q)
+
+		u := url.URL{Path: req.URL.Path, RawQuery: q.Encode()}
+		h.Add("Link", "<"+u.String()+`>; rel="`+rel+`"`)
+	}
+
+	cursorMode := dto.After != nil || dto.Before != nil
+
+	switch {
+	case cursorMode && page.NextCursor != nil:
+		link("next", func(q url.Values) { q.Set("after", page.NextCursor.Encode()) })
+	case !cursorMode && page.NextCursor != nil:
+		link("next", func(q url.Values) { q.Set("offset", strconv.Itoa(dto.Offset+dto.Limit)) })
+	}
+
+	switch {
+	case cursorMode && page.PrevCursor != nil:
+		link("prev", func(q url.Values) { q.Set("before", page.PrevCursor.Encode()) })
+	case !cursorMode && dto.Offset > 0:
+		link("prev", func(q url.Values) { q.Set("offset", strconv.Itoa(max(dto.Offset-dto.Limit, 0))) })
+	}
+
+	link("first", func(url.Values) {})
+
+	if page.Total != nil {
+		last := 0
+		if *page.Total > 0 {
+			last = (*page.Total - 1) / dto.Limit * dto.Limit
+		}
+		link("last", func(q url.Values) { q.Set("offset", strconv.Itoa(last)) })
+	}
+}
@@ -0,0 +1,33 @@
+package http
+
+import (
+	"mime"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+const (
+	mergePatchContentType = "application/merge-patch+json"
+	jsonPatchContentType  = "application/json-patch+json"
+
+	// acceptPatch is advertised in the Accept-Patch header (RFC 5789).
+	acceptPatch = mergePatchContentType + ", " + jsonPatchContentType
+
+	maxPatchBodySize = 1 << 20
+)
+
+var patchFormats = map[string]app.PatchFormat{
+	mergePatchContentType: app.PatchFormatMergePatch,
+	jsonPatchContentType:  app.PatchFormatJSONPatch,
+}
+
+// mediaType returns the media type of a Content-Type header without its
+// parameters, or an empty string when the header is malformed.
+func mediaType(contentType string) string {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+
+	return mt
+}
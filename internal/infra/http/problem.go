@@ -0,0 +1,176 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+)
+
+const (
+	jsonContentType    = "application/json"
+	problemContentType = "application/problem+json"
+)
+
+// problemType describes a class of failure reported as an RFC 7807 problem
+// document. The URIs are part of the public API: clients switch on them, so
+// existing values must never change.
+type problemType struct {
+	URI    string
+	Title  string
+	Status int
+}
+
+var (
+	problemInvalidRequestBody = problemType{
+		URI:    "/problems/invalid-request-body",
+		Title:  "Invalid request body",
+		Status: http.StatusBadRequest,
+	}
+	problemInvalidParameter = problemType{
+		URI:    "/problems/invalid-parameter",
+		Title:  "Invalid request parameter",
+		Status: http.StatusBadRequest,
+	}
+	problemInvalidPatch = problemType{
+		URI:    "/problems/invalid-patch",
+		Title:  "Invalid patch document",
+		Status: http.StatusBadRequest,
+	}
+	problemUnsupportedMediaType = problemType{
+		URI:    "/problems/unsupported-media-type",
+		Title:  "Unsupported media type",
+		Status: http.StatusUnsupportedMediaType,
+	}
+	problemForbidden = problemType{
+		URI:    "/problems/forbidden",
+		Title:  "Forbidden",
+		Status: http.StatusForbidden,
+	}
+	problemNotFound = problemType{
+		URI:    "/problems/not-found",
+		Title:  "Resource not found",
+		Status: http.StatusNotFound,
+	}
+	problemConflict = problemType{
+		URI:    "/problems/conflict",
+		Title:  "Resource conflict",
+		Status: http.StatusConflict,
+	}
+	problemInsufficientStock = problemType{
+		URI:    "/problems/insufficient-stock",
+		Title:  "Insufficient stock",
+		Status: http.StatusConflict,
+	}
+	problemPreconditionFailed = problemType{
+		URI:    "/problems/precondition-failed",
+		Title:  "Precondition failed",
+		Status: http.StatusPreconditionFailed,
+	}
+	problemPreconditionRequired = problemType{
+		URI:    "/problems/precondition-required",
+		Title:  "Precondition required",
+		Status: http.StatusPreconditionRequired,
+	}
+	problemValidation = problemType{
+		URI:    "/problems/validation-failed",
+		Title:  "Validation failed",
+		Status: http.StatusUnprocessableEntity,
+	}
+	problemUnavailable = problemType{
+		URI:    "/problems/service-unavailable",
+		Title:  "Service unavailable",
+		Status: http.StatusServiceUnavailable,
+	}
+	problemInternal = problemType{
+		URI:    "/problems/internal-error",
+		Title:  "Internal server error",
+		Status: http.StatusInternalServerError,
+	}
+)
+
+// problem is an RFC 7807 problem details document.
+type problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Errors   []fieldError `json:"errors,omitempty"`
+}
+
+// fieldError points at a single invalid field of the request.
+type fieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// writeProblem writes a problem document of the given type. It is the only
+// place where handlers produce error responses.
+func writeProblem(w http.ResponseWriter, req *http.Request, pt problemType, detail string, fieldErrors ...fieldError) {
+	p := problem{
+		Type:     pt.URI,
+		Title:    pt.Title,
+		Status:   pt.Status,
+		Detail:   detail,
+		Instance: req.URL.Path,
+		Errors:   fieldErrors,
+	}
+
+	b, err := encodeJSON(p)
+	if err != nil {
+		log.Printf("failed to encode problem document: %v", err)
+		w.WriteHeader(pt.Status)
+		return
+	}
+
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(pt.Status)
+	_, _ = w.Write(b)
+}
+
+// writeJSON encodes v before touching the response, so that an encoding
+// failure can still be reported as a problem.
+func writeJSON(w http.ResponseWriter, req *http.Request, status int, v any) {
+	b, err := encodeJSON(v)
+	if err != nil {
+		log.Printf("failed to encode response: %v", err)
+		writeProblem(w, req, problemInternal, "The response could not be encoded.")
+		return
+	}
+
+	w.Header().Set("Content-Type", jsonContentType)
+	w.WriteHeader(status)
+	_, _ = w.Write(b)
+}
+
+func encodeJSON(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeBodyError reports a request body that could not be decoded, pointing
+// at the offending field when the decoder knows it.
+func writeBodyError(w http.ResponseWriter, req *http.Request, err error) {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		writeProblem(w, req, problemInvalidRequestBody, "The request body contains a field of the wrong type.",
+			fieldError{Field: typeErr.Field, Message: "must be of type " + typeErr.Type.String()},
+		)
+		return
+	}
+
+	writeProblem(w, req, problemInvalidRequestBody, "The request body is not valid JSON.")
+}
+
+// writeInvalidIDError reports a path parameter that is not a valid UUID.
+func writeInvalidIDError(w http.ResponseWriter, req *http.Request, param string) {
+	writeProblem(w, req, problemInvalidParameter, "The "+param+" path parameter is invalid.",
+		fieldError{Field: param, Message: "must be a valid UUID"},
+	)
+}
@@ -0,0 +1,223 @@
+package http
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+const (
+	getProductRevisionsEndpoint  string = "GET /api/v1/products/{product_id}/revisions"
+	getProductRevisionEndpoint   string = "GET /api/v1/products/{product_id}/revisions/{version}"
+	diffProductRevisionsEndpoint string = "GET /api/v1/products/{product_id}/revisions/diff"
+	revertProductEndpoint        string = "POST /api/v1/products/{product_id}/revisions/{version}/revert"
+)
+
+func (r Router) getProductRevisionsHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	productID, err := uuid.Parse(req.PathValue("product_id"))
+	if err != nil {
+		writeInvalidIDError(w, req, "product_id")
+		return
+	}
+
+	revisions, err := r.service.GetProductRevisions(ctx, productID)
+	if err != nil {
+		writeServiceError(w, req, err)
+		return
+	}
+
+	res := productRevisionsResponse{
+		Revisions: make([]productRevisionResponse, 0, len(revisions)),
+	}
+	for i := range revisions {
+		res.Revisions = append(res.Revisions, newProductRevisionResponse(&revisions[i]))
+	}
+
+	writeJSON(w, req, http.StatusOK, res)
+}
+
+// getProductRevisionHandler serves a revision, which never changes once
+// recorded.
+func (r Router) getProductRevisionHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	productID, err := uuid.Parse(req.PathValue("product_id"))
+	if err != nil {
+		writeInvalidIDError(w, req, "product_id")
+		return
+	}
+
+	version, ok := parseVersion(req.PathValue("version"))
+	if !ok {
+		writeInvalidVersionError(w, req)
+		return
+	}
+
+	rev, err := r.service.GetProductRevision(ctx, productID, version)
+	if err != nil {
+		writeServiceError(w, req, err)
+		return
+	}
+
+	writeCacheable(w, req, r.cfg.ProductCacheControl, versionETag(rev.Version), rev.CreatedAt, newProductRevisionResponse(rev))
+}
+
+// diffProductRevisionsHandler compares the revisions given by the from and to
+// query parameters.
+func (r Router) diffProductRevisionsHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	productID, err := uuid.Parse(req.PathValue("product_id"))
+	if err != nil {
+		writeInvalidIDError(w, req, "product_id")
+		return
+	}
+
+	dto, fieldErrs := parseDiffQuery(req.URL.Query())
+	if fieldErrs != nil {
+		writeProblem(w, req, problemInvalidParameter, "The query parameters are invalid.", fieldErrs...)
+		return
+	}
+	dto.ProductID = productID
+
+	diff, err := r.service.DiffProductRevisions(ctx, dto)
+	if err != nil {
+		writeServiceError(w, req, err)
+		return
+	}
+
+	res := productDiffResponse{
+		ProductID: diff.ProductID,
+		From:      diff.From,
+		To:        diff.To,
+		Changes:   make([]fieldChangeResponse, 0, len(diff.Changes)),
+	}
+	for _, c := range diff.Changes {
+		res.Changes = append(res.Changes, fieldChangeResponse(c))
+	}
+
+	writeJSON(w, req, http.StatusOK, res)
+}
+
+// revertProductHandler reverts a product to one of its revisions. Like
+// updates, reverts can be made conditional with If-Match.
+func (r Router) revertProductHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	productID, err := uuid.Parse(req.PathValue("product_id"))
+	if err != nil {
+		writeInvalidIDError(w, req, "product_id")
+		return
+	}
+
+	version, ok := parseVersion(req.PathValue("version"))
+	if !ok {
+		writeInvalidVersionError(w, req)
+		return
+	}
+
+	expectedVersion, ok := r.expectedVersion(w, req)
+	if !ok {
+		return
+	}
+
+	dto := app.RevertProductDTO{
+		ID:              productID,
+		Version:         version,
+		ExpectedVersion: expectedVersion,
+	}
+
+	p, err := r.service.RevertProduct(ctx, dto)
+	if err != nil {
+		writeServiceError(w, req, err)
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(p.Version))
+	writeJSON(w, req, http.StatusOK, newProductResponse(p))
+}
+
+func parseDiffQuery(query url.Values) (app.DiffProductRevisionsDTO, []fieldError) {
+	var (
+		dto       app.DiffProductRevisionsDTO
+		fieldErrs []fieldError
+		ok        bool
+	)
+
+	dto.From, ok = parseVersion(query.Get("from"))
+	if !ok {
+		fieldErrs = append(fieldErrs, fieldError{Field: "from", Message: "must be a positive integer"})
+	}
+
+	dto.To, ok = parseVersion(query.Get("to"))
+	if !ok {
+		fieldErrs = append(fieldErrs, fieldError{Field: "to", Message: "must be a positive integer"})
+	}
+
+	return dto, fieldErrs
+}
+
+// parseVersion parses a revision number, which is a product version.
+func parseVersion(s string) (int64, bool) {
+	version, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || version < 1 {
+		return 0, false
+	}
+
+	return version, true
+}
+
+func writeInvalidVersionError(w http.ResponseWriter, req *http.Request) {
+	writeProblem(w, req, problemInvalidParameter, "The version path parameter is invalid.",
+		fieldError{Field: "version", Message: "must be a positive integer"},
+	)
+}
+
+type productRevisionsResponse struct {
+	Revisions []productRevisionResponse `json:"revisions"`
+}
+
+type productRevisionResponse struct {
+	Version       int64           `json:"version"`
+	Action        string          `json:"action"`
+	ChangedFields []string        `json:"changed_fields"`
+	Actor         string          `json:"actor"`
+	CreatedAt     time.Time       `json:"created_at"`
+	Product       productResponse `json:"product"`
+}
+
+func newProductRevisionResponse(rev *app.ProductRevision) productRevisionResponse {
+	changedFields := rev.ChangedFields
+	if changedFields == nil {
+		changedFields = []string{}
+	}
+
+	return productRevisionResponse{
+		Version:       rev.Version,
+		Action:        string(rev.Action),
+		ChangedFields: changedFields,
+		Actor:         rev.Actor,
+		CreatedAt:     rev.CreatedAt,
+		Product:       newProductResponse(&rev.Product),
+	}
+}
+
+type productDiffResponse struct {
+	ProductID uuid.UUID             `json:"product_id"`
+	From      int64                 `json:"from"`
+	To        int64                 `json:"to"`
+	Changes   []fieldChangeResponse `json:"changes"`
+}
+
+type fieldChangeResponse struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
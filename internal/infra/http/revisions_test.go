@@ -0,0 +1,258 @@
+package http
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/simpler-tha/internal/app"
+	"github.com/simpler-tha/internal/config"
+)
+
+func TestRouter_getProductRevisionHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	productID, _ := uuid.Parse("9f9f4340-6bf9-4948-808c-ebf2dd604e2c")
+
+	now, _ := time.Parse(time.RFC3339, "2024-10-02T14:28:34Z")
+
+	tests := []struct {
+		name                               string
+		version                            string
+		expServiceGetProductRevisionResult *app.ProductRevision
+		expServiceGetProductRevisionError  error
+		expStatus                          int
+		expETag                            string
+		expResponse                        []byte
+	}{
+		{
+			name:    "revision found",
+			version: "2",
+			expServiceGetProductRevisionResult: &app.ProductRevision{
+				ProductID: productID,
+				Version:   2,
+				Action:    app.RevisionUpdated,
+				Product: app.Product{
+					ID:         productID,
+					Slug:       "desk-lamp",
+					Status:     app.ProductDraft,
+					Name:       "Desk Lamp",
+					Price:      app.NewMoney(2500, "USD"),
+					Tags:       []string{},
+					Attributes: map[string]any{},
+					Version:    2,
+					CreatedAt:  now,
+					UpdatedAt:  now,
+				},
+				ChangedFields: []string{"name"},
+				Actor:         "alice",
+				CreatedAt:     now,
+			},
+			expStatus:   http.StatusOK,
+			expETag:     `"2"`,
+			expResponse: []byte("{\"version\":2,\"action\":\"updated\",\"changed_fields\":[\"name\"],\"actor\":\"alice\",\"created_at\":\"2024-10-02T14:28:34Z\",\"product\":{\"id\":\"9f9f4340-6bf9-4948-808c-ebf2dd604e2c\",\"sku\":null,\"slug\":\"desk-lamp\",\"status\":\"draft\",\"name\":\"Desk Lamp\",\"description\":\"\",\"price\":\"25.00\",\"currency\":\"USD\",\"tags\":[],\"attributes\":{},\"version\":2,\"created_at\":\"2024-10-02T14:28:34Z\",\"updated_at\":\"2024-10-02T14:28:34Z\"}}\n"),
+		},
+		{
+			name:                              "revision does not exist",
+			version:                           "7",
+			expServiceGetProductRevisionError: fmt.Errorf("failed to get product revision: %w", app.ErrNotFound),
+			expStatus:                         http.StatusNotFound,
+			expResponse:                       []byte("{\"type\":\"/problems/not-found\",\"title\":\"Resource not found\",\"status\":404,\"detail\":\"The requested resource does not exist.\",\"instance\":\"/api/v1/products/9f9f4340-6bf9-4948-808c-ebf2dd604e2c/revisions/7\"}\n"),
+		},
+		{
+			name:        "invalid version",
+			version:     "0",
+			expStatus:   http.StatusBadRequest,
+			expResponse: []byte("{\"type\":\"/problems/invalid-parameter\",\"title\":\"Invalid request parameter\",\"status\":400,\"detail\":\"The version path parameter is invalid.\",\"instance\":\"/api/v1/products/9f9f4340-6bf9-4948-808c-ebf2dd604e2c/revisions/0\",\"errors\":[{\"field\":\"version\",\"message\":\"must be a positive integer\"}]}\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		mockService := NewMockservice(ctrl)
+
+		if tt.expServiceGetProductRevisionResult != nil || tt.expServiceGetProductRevisionError != nil {
+			mockService.
+				EXPECT().
+				GetProductRevision(gomock.Any(), productID, gomock.Any()).
+				Return(tt.expServiceGetProductRevisionResult, tt.expServiceGetProductRevisionError)
+		}
+
+		router, err := NewRouter(mockService, config.HTTP{})
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/products/"+productID.String()+"/revisions/"+tt.version, nil)
+		req.SetPathValue("product_id", productID.String())
+		req.SetPathValue("version", tt.version)
+		recorder := httptest.NewRecorder()
+
+		router.getProductRevisionHandler(recorder, req)
+
+		assert.Equal(t, tt.expStatus, recorder.Code)
+		assert.Equal(t, tt.expETag, recorder.Header().Get("ETag"))
+
+		b, err := io.ReadAll(recorder.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, tt.expResponse, b)
+	}
+}
+
+func TestRouter_diffProductRevisionsHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	productID, _ := uuid.Parse("9f9f4340-6bf9-4948-808c-ebf2dd604e2c")
+
+	tests := []struct {
+		name                                 string
+		query                                string
+		expServiceDiffProductRevisionsDTO    *app.DiffProductRevisionsDTO
+		expServiceDiffProductRevisionsResult *app.ProductDiff
+		expStatus                            int
+		expResponse                          []byte
+	}{
+		{
+			name:                              "revisions compared successfully",
+			query:                             "?from=1&to=3",
+			expServiceDiffProductRevisionsDTO: &app.DiffProductRevisionsDTO{ProductID: productID, From: 1, To: 3},
+			expServiceDiffProductRevisionsResult: &app.ProductDiff{
+				ProductID: productID,
+				From:      1,
+				To:        3,
+				Changes: []app.FieldChange{
+					{Field: "name", From: "Lamp", To: "Desk Lamp"},
+					{Field: "tags", From: []any{}, To: []any{"office"}},
+				},
+			},
+			expStatus:   http.StatusOK,
+			expResponse: []byte("{\"product_id\":\"9f9f4340-6bf9-4948-808c-ebf2dd604e2c\",\"from\":1,\"to\":3,\"changes\":[{\"field\":\"name\",\"from\":\"Lamp\",\"to\":\"Desk Lamp\"},{\"field\":\"tags\",\"from\":[],\"to\":[\"office\"]}]}\n"),
+		},
+		{
+			name:        "missing and invalid revisions",
+			query:       "?from=first",
+			expStatus:   http.StatusBadRequest,
+			expResponse: []byte("{\"type\":\"/problems/invalid-parameter\",\"title\":\"Invalid request parameter\",\"status\":400,\"detail\":\"The query parameters are invalid.\",\"instance\":\"/api/v1/products/9f9f4340-6bf9-4948-808c-ebf2dd604e2c/revisions/diff\",\"errors\":[{\"field\":\"from\",\"message\":\"must be a positive integer\"},{\"field\":\"to\",\"message\":\"must be a positive integer\"}]}\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		mockService := NewMockservice(ctrl)
+
+		if tt.expServiceDiffProductRevisionsDTO != nil {
+			mockService.
+				EXPECT().
+				DiffProductRevisions(gomock.Any(), *tt.expServiceDiffProductRevisionsDTO).
+				Return(tt.expServiceDiffProductRevisionsResult, nil)
+		}
+
+		router, err := NewRouter(mockService, config.HTTP{})
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/products/"+productID.String()+"/revisions/diff"+tt.query, nil)
+		req.SetPathValue("product_id", productID.String())
+		recorder := httptest.NewRecorder()
+
+		router.diffProductRevisionsHandler(recorder, req)
+
+		assert.Equal(t, tt.expStatus, recorder.Code)
+
+		b, err := io.ReadAll(recorder.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, tt.expResponse, b)
+	}
+}
+
+func TestRouter_revertProductHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	productID, _ := uuid.Parse("9f9f4340-6bf9-4948-808c-ebf2dd604e2c")
+
+	now, _ := time.Parse(time.RFC3339, "2024-10-02T14:28:34Z")
+
+	tests := []struct {
+		name                          string
+		version                       string
+		ifMatch                       string
+		expServiceRevertProductDTO    *app.RevertProductDTO
+		expServiceRevertProductResult *app.Product
+		expServiceRevertProductError  error
+		expStatus                     int
+		expETag                       string
+		expResponse                   []byte
+	}{
+		{
+			name:                       "product reverted successfully",
+			version:                    "1",
+			ifMatch:                    `"3"`,
+			expServiceRevertProductDTO: &app.RevertProductDTO{ID: productID, Version: 1, ExpectedVersion: 3},
+			expServiceRevertProductResult: &app.Product{
+				ID:         productID,
+				Slug:       "desk-lamp",
+				Status:     app.ProductPublished,
+				Name:       "Lamp",
+				Price:      app.NewMoney(2500, "USD"),
+				Tags:       []string{},
+				Attributes: map[string]any{},
+				Version:    4,
+				CreatedAt:  now,
+				UpdatedAt:  now,
+			},
+			expStatus:   http.StatusOK,
+			expETag:     `"4"`,
+			expResponse: []byte("{\"id\":\"9f9f4340-6bf9-4948-808c-ebf2dd604e2c\",\"sku\":null,\"slug\":\"desk-lamp\",\"status\":\"published\",\"name\":\"Lamp\",\"description\":\"\",\"price\":\"25.00\",\"currency\":\"USD\",\"tags\":[],\"attributes\":{},\"version\":4,\"created_at\":\"2024-10-02T14:28:34Z\",\"updated_at\":\"2024-10-02T14:28:34Z\"}\n"),
+		},
+		{
+			name:                         "revision no longer satisfies the validation rules",
+			version:                      "1",
+			expServiceRevertProductDTO:   &app.RevertProductDTO{ID: productID, Version: 1},
+			expServiceRevertProductError: fmt.Errorf("invalid product: %w", &app.ValidationError{Violations: []app.Violation{{Field: "name", Message: "must not be empty"}}}),
+			expStatus:                    http.StatusUnprocessableEntity,
+			expResponse:                  []byte("{\"type\":\"/problems/validation-failed\",\"title\":\"Validation failed\",\"status\":422,\"detail\":\"The request contains invalid data.\",\"instance\":\"/api/v1/products/9f9f4340-6bf9-4948-808c-ebf2dd604e2c/revisions/1/revert\",\"errors\":[{\"field\":\"name\",\"message\":\"must not be empty\"}]}\n"),
+		},
+		{
+			name:        "invalid version",
+			version:     "latest",
+			expStatus:   http.StatusBadRequest,
+			expResponse: []byte("{\"type\":\"/problems/invalid-parameter\",\"title\":\"Invalid request parameter\",\"status\":400,\"detail\":\"The version path parameter is invalid.\",\"instance\":\"/api/v1/products/9f9f4340-6bf9-4948-808c-ebf2dd604e2c/revisions/latest/revert\",\"errors\":[{\"field\":\"version\",\"message\":\"must be a positive integer\"}]}\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		mockService := NewMockservice(ctrl)
+
+		if tt.expServiceRevertProductDTO != nil {
+			mockService.
+				EXPECT().
+				RevertProduct(gomock.Any(), *tt.expServiceRevertProductDTO).
+				Return(tt.expServiceRevertProductResult, tt.expServiceRevertProductError)
+		}
+
+		router, err := NewRouter(mockService, config.HTTP{})
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/products/"+productID.String()+"/revisions/"+tt.version+"/revert", nil)
+		req.SetPathValue("product_id", productID.String())
+		req.SetPathValue("version", tt.version)
+		if tt.ifMatch != "" {
+			req.Header.Set("If-Match", tt.ifMatch)
+		}
+		recorder := httptest.NewRecorder()
+
+		router.revertProductHandler(recorder, req)
+
+		assert.Equal(t, tt.expStatus, recorder.Code)
+		assert.Equal(t, tt.expETag, recorder.Header().Get("ETag"))
+
+		b, err := io.ReadAll(recorder.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, tt.expResponse, b)
+	}
+}
@@ -1,237 +1,557 @@
-package http
-
-import (
-	"context"
-	"encoding/json"
-	"errors"
-	"net/http"
-	"strconv"
-	"time"
-
-	"github.com/google/uuid"
-
-	"github.com/simpler-tha/internal/app"
-)
-
-const (
-	createProductEndpoint string = "POST /api/v1/products"
-	updateProductEndpoint string = "PUT /api/v1/products/{product_id}"
-	deleteProductEndpoint string = "DELETE /api/v1/products/{product_id}"
-	getProductEndpoint    string = "GET /api/v1/products/{product_id}"
-	getProductsEndpoint   string = "GET /api/v1/products"
-
-	getProductsDefaultLimit  = 5
-	getProductsDefaultOffset = 0
-)
-
-type Router struct {
-	service service
-}
-
-type service interface {
-	CreateProduct(ctx context.Context, dto app.CreateProductDTO) (*app.Product, error)
-	UpdateProduct(ctx context.Context, dto app.UpdateProductDTO) (*app.Product, error)
-	DeleteProduct(ctx context.Context, productID uuid.UUID) error
-	GetProduct(ctx context.Context, productID uuid.UUID) (*app.Product, error)
-	GetProducts(ctx context.Context, limit, offset int) ([]*app.Product, error)
-}
-
-func NewRouter(s service) (Router, error) {
-	if s == nil {
-		return Router{}, errors.New("service cannot be nil")
-	}
-	return Router{service: s}, nil
-}
-
-func (r Router) RegisterRoutes() {
-	http.HandleFunc(createProductEndpoint, r.createProductHandler)
-	http.HandleFunc(updateProductEndpoint, r.updateProductHandler)
-	http.HandleFunc(deleteProductEndpoint, r.deleteProductHandler)
-	http.HandleFunc(getProductEndpoint, r.getProductHandler)
-	http.HandleFunc(getProductsEndpoint, r.getProductsHandler)
-}
-
-type productRequestBody struct {
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float32 `json:"price"`
-}
-
-func (r Router) createProductHandler(w http.ResponseWriter, req *http.Request) {
-	ctx := req.Context()
-
-	var body productRequestBody
-	err := json.NewDecoder(req.Body).Decode(&body)
-	if err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
-		return
-	}
-
-	dto := app.CreateProductDTO{
-		Name:        body.Name,
-		Description: body.Description,
-		Price:       body.Price,
-	}
-
-	p, err := r.service.CreateProduct(ctx, dto)
-	if err != nil {
-		http.Error(w, "an error occurred", http.StatusInternalServerError)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-
-	productRes := newProductResponse(p)
-
-	err = json.NewEncoder(w).Encode(productRes)
-	if err != nil {
-		http.Error(w, "an error occurred", http.StatusInternalServerError)
-	}
-}
-
-func (r Router) updateProductHandler(w http.ResponseWriter, req *http.Request) {
-	ctx := req.Context()
-
-	var body productRequestBody
-	err := json.NewDecoder(req.Body).Decode(&body)
-	if err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
-		return
-	}
-
-	productIDStr := req.PathValue("product_id")
-	productID, err := uuid.Parse(productIDStr)
-	if err != nil {
-		http.Error(w, "invalid product id", http.StatusBadRequest)
-		return
-	}
-
-	dto := app.UpdateProductDTO{
-		ID:          productID,
-		Name:        body.Name,
-		Description: body.Description,
-		Price:       body.Price,
-	}
-
-	p, err := r.service.UpdateProduct(ctx, dto)
-	if err != nil {
-		http.Error(w, "an error occurred", http.StatusInternalServerError)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-
-	productRes := newProductResponse(p)
-
-	err = json.NewEncoder(w).Encode(productRes)
-	if err != nil {
-		http.Error(w, "an error occurred", http.StatusInternalServerError)
-	}
-}
-
-func (r Router) deleteProductHandler(w http.ResponseWriter, req *http.Request) {
-	ctx := req.Context()
-
-	productIDStr := req.PathValue("product_id")
-	productID, err := uuid.Parse(productIDStr)
-	if err != nil {
-		http.Error(w, "invalid product id", http.StatusBadRequest)
-		return
-	}
-
-	err = r.service.DeleteProduct(ctx, productID)
-	if err != nil {
-		http.Error(w, "an error occurred", http.StatusInternalServerError)
-		return
-	}
-	w.WriteHeader(http.StatusNoContent)
-}
-
-func (r Router) getProductHandler(w http.ResponseWriter, req *http.Request) {
-	ctx := req.Context()
-
-	productIDStr := req.PathValue("product_id")
-	productID, err := uuid.Parse(productIDStr)
-	if err != nil {
-		http.Error(w, "invalid product id", http.StatusBadRequest)
-		return
-	}
-
-	product, err := r.service.GetProduct(ctx, productID)
-	if err != nil {
-		http.Error(w, "an error occurred", http.StatusInternalServerError)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-
-	productRes := newProductResponse(product)
-
-	err = json.NewEncoder(w).Encode(productRes)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-	}
-}
-
-func (r Router) getProductsHandler(w http.ResponseWriter, req *http.Request) {
-	ctx := req.Context()
-
-	query := req.URL.Query()
-
-	limit, err := strconv.Atoi(query.Get("limit"))
-	if err != nil || limit < 1 {
-		limit = getProductsDefaultLimit
-	}
-
-	offset, err := strconv.Atoi(query.Get("offset"))
-	if err != nil || offset < 1 {
-		offset = getProductsDefaultOffset
-	}
-
-	products, err := r.service.GetProducts(ctx, limit, offset)
-	if err != nil {
-		http.Error(w, "an error occurred", http.StatusInternalServerError)
-		return
-	}
-
-	var res productsResponse
-	for _, p := range products {
-		productRes := newProductResponse(p)
-		res.Products = append(res.Products, productRes)
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-
-	err = json.NewEncoder(w).Encode(res)
-	if err != nil {
-		http.Error(w, "an error occurred", http.StatusInternalServerError)
-	}
-}
-
-type productsResponse struct {
-	Products []productResponse `json:"products"`
-}
-
-type productResponse struct {
-	ID          uuid.UUID `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Price       float32   `json:"price"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
-}
-
-func newProductResponse(p *app.Product) productResponse {
-	return productResponse{
-		ID:          p.ID,
-		Name:        p.Name,
-		Description: p.Description,
-		Price:       p.Price,
-		CreatedAt:   p.CreatedAt,
-		UpdatedAt:   p.UpdatedAt,
-	}
-}
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/simpler-tha/internal/app"
+	"github.com/simpler-tha/internal/config"
+)
+
+const (
+	createProductEndpoint string = "POST /api/v1/products"
+	updateProductEndpoint string = "PUT /api/v1/products/{product_id}"
+	patchProductEndpoint  string = "PATCH /api/v1/products/{product_id}"
+	deleteProductEndpoint string = "DELETE /api/v1/products/{product_id}"
+	// restoreProductEndpoint undoes a deletion, archived products are
+	// brought back by unarchiveProductEndpoint.
+	restoreProductEndpoint string = "POST /api/v1/products/{product_id}/restore"
+	getProductEndpoint     string = "GET /api/v1/products/{product_id}"
+	getProductsEndpoint    string = "GET /api/v1/products"
+	// getProductByKeyEndpoint serves GET /api/v1/products/by-sku/{sku} and
+	// GET /api/v1/products/by-slug/{slug}. ServeMux rejects these patterns as
+	// conflicting with the subresources of a product: both match
+	// /api/v1/products/by-sku/variants. The subresources, being more
+	// specific than this pattern, hand those paths over to the lookups. SKUs
+	// with slashes are sent escaped, e.g. by-sku/TP%2F001.
+	getProductByKeyEndpoint string = "GET /api/v1/products/{key}/{value}"
+	productBySKUKey         string = "by-sku"
+	productBySlugKey        string = "by-slug"
+
+	getProductsDefaultLimit  = 5
+	getProductsMaxLimit      = 100
+	getProductsDefaultOffset = 0
+)
+
+type Router struct {
+	service service
+	cfg     config.HTTP
+
+	// admin is set on the router of the admin listener, whose requests may
+	// use the adminParameters.
+	admin bool
+}
+
+type service interface {
+	CreateProduct(ctx context.Context, dto app.CreateProductDTO) (*app.Product, error)
+	UpdateProduct(ctx context.Context, dto app.UpdateProductDTO) (*app.Product, error)
+	PatchProduct(ctx context.Context, dto app.PatchProductDTO) (*app.Product, error)
+	DeleteProduct(ctx context.Context, dto app.DeleteProductDTO) error
+	RestoreProduct(ctx context.Context, dto app.RestoreProductDTO) (*app.Product, error)
+	GetProduct(ctx context.Context, productID uuid.UUID) (*app.Product, error)
+	GetProductAsOf(ctx context.Context, productID uuid.UUID, asOf time.Time) (*app.Product, error)
+	GetProductBySKU(ctx context.Context, sku string) (*app.Product, error)
+	GetProductBySlug(ctx context.Context, slug string) (*app.Product, error)
+	GetProducts(ctx context.Context, dto app.GetProductsDTO) (*app.ProductPage, error)
+	SearchProducts(ctx context.Context, dto app.SearchProductsDTO) (*app.ProductSearchPage, error)
+	SuggestProducts(ctx context.Context, dto app.SuggestProductsDTO) ([]app.ProductSuggestion, error)
+	GetTags(ctx context.Context, dto app.GetTagsDTO) ([]app.TagCount, error)
+	CreateCategory(ctx context.Context, dto app.CreateCategoryDTO) (*app.Category, error)
+	UpdateCategory(ctx context.Context, dto app.UpdateCategoryDTO) (*app.Category, error)
+	DeleteCategory(ctx context.Context, dto app.DeleteCategoryDTO) error
+	GetCategory(ctx context.Context, categoryID uuid.UUID) (*app.Category, error)
+	GetCategories(ctx context.Context) ([]*app.Category, error)
+	SetProductCategories(ctx context.Context, dto app.SetProductCategoriesDTO) ([]*app.Category, error)
+	GetProductCategories(ctx context.Context, productID uuid.UUID) ([]*app.Category, error)
+	CreateVariant(ctx context.Context, dto app.CreateVariantDTO) (*app.Variant, error)
+	UpdateVariant(ctx context.Context, dto app.UpdateVariantDTO) (*app.Variant, error)
+	DeleteVariant(ctx context.Context, dto app.DeleteVariantDTO) error
+	GetVariant(ctx context.Context, productID, variantID uuid.UUID) (*app.Variant, error)
+	GetVariants(ctx context.Context, productID uuid.UUID) ([]*app.Variant, error)
+	GetInventory(ctx context.Context, productID uuid.UUID) (*app.Inventory, error)
+	AdjustStock(ctx context.Context, dto app.AdjustStockDTO) (*app.Inventory, error)
+	GetStockAdjustments(ctx context.Context, productID uuid.UUID) ([]app.StockAdjustment, error)
+	CreateReservation(ctx context.Context, dto app.CreateReservationDTO) (*app.Reservation, error)
+	GetReservation(ctx context.Context, reservationID uuid.UUID) (*app.Reservation, error)
+	CommitReservation(ctx context.Context, reservationID uuid.UUID) (*app.Reservation, error)
+	ReleaseReservation(ctx context.Context, reservationID uuid.UUID) (*app.Reservation, error)
+	TransitionProduct(ctx context.Context, dto app.TransitionProductDTO) (*app.Product, error)
+	GetProductRevisions(ctx context.Context, productID uuid.UUID) ([]app.ProductRevision, error)
+	GetProductRevision(ctx context.Context, productID uuid.UUID, version int64) (*app.ProductRevision, error)
+	DiffProductRevisions(ctx context.Context, dto app.DiffProductRevisionsDTO) (*app.ProductDiff, error)
+	RevertProduct(ctx context.Context, dto app.RevertProductDTO) (*app.Product, error)
+	GetAuditEntries(ctx context.Context, dto app.GetAuditEntriesDTO) (*app.AuditPage, error)
+}
+
+func NewRouter(s service, cfg config.HTTP) (Router, error) {
+	if s == nil {
+		return Router{}, errors.New("service cannot be nil")
+	}
+	return Router{service: s, cfg: cfg}, nil
+}
+
+// RegisterRoutes registers the handlers of the API on mux.
+func (r Router) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc(createProductEndpoint, r.createProductHandler)
+	mux.HandleFunc(updateProductEndpoint, r.updateProductHandler)
+	mux.HandleFunc(patchProductEndpoint, r.patchProductHandler)
+	mux.HandleFunc(deleteProductEndpoint, r.deleteProductHandler)
+	mux.HandleFunc(restoreProductEndpoint, r.restoreProductHandler)
+	mux.HandleFunc(getProductEndpoint, r.getProductHandler)
+	mux.HandleFunc(getProductsEndpoint, r.getProductsHandler)
+	mux.HandleFunc(getProductByKeyEndpoint, r.getProductByKeyHandler)
+	mux.HandleFunc(searchProductsEndpoint, r.searchProductsHandler)
+	mux.HandleFunc(suggestProductsEndpoint, r.suggestProductsHandler)
+	mux.HandleFunc(getTagsEndpoint, r.getTagsHandler)
+	mux.HandleFunc(createCategoryEndpoint, r.createCategoryHandler)
+	mux.HandleFunc(updateCategoryEndpoint, r.updateCategoryHandler)
+	mux.HandleFunc(deleteCategoryEndpoint, r.deleteCategoryHandler)
+	mux.HandleFunc(getCategoryEndpoint, r.getCategoryHandler)
+	mux.HandleFunc(getCategoriesEndpoint, r.getCategoriesHandler)
+	mux.HandleFunc(getCategoryProductsEndpoint, r.getCategoryProductsHandler)
+	mux.HandleFunc(getProductCategoriesEndpoint, r.orProductLookup(r.getProductCategoriesHandler))
+	mux.HandleFunc(setProductCategoriesEndpoint, r.setProductCategoriesHandler)
+	mux.HandleFunc(createVariantEndpoint, r.createVariantHandler)
+	mux.HandleFunc(updateVariantEndpoint, r.updateVariantHandler)
+	mux.HandleFunc(deleteVariantEndpoint, r.deleteVariantHandler)
+	mux.HandleFunc(getVariantEndpoint, r.getVariantHandler)
+	mux.HandleFunc(getVariantsEndpoint, r.orProductLookup(r.getVariantsHandler))
+	mux.HandleFunc(getInventoryEndpoint, r.orProductLookup(r.getInventoryHandler))
+	mux.HandleFunc(adjustStockEndpoint, r.adjustStockHandler)
+	mux.HandleFunc(getStockAdjustmentsEndpoint, r.getStockAdjustmentsHandler)
+	mux.HandleFunc(createReservationEndpoint, r.createReservationHandler)
+	mux.HandleFunc(getReservationEndpoint, r.getReservationHandler)
+	mux.HandleFunc(commitReservationEndpoint, r.commitReservationHandler)
+	mux.HandleFunc(releaseReservationEndpoint, r.releaseReservationHandler)
+	mux.HandleFunc(publishProductEndpoint, r.transitionProductHandler(app.ProductPublish))
+	mux.HandleFunc(unpublishProductEndpoint, r.transitionProductHandler(app.ProductUnpublish))
+	mux.HandleFunc(archiveProductEndpoint, r.transitionProductHandler(app.ProductArchive))
+	mux.HandleFunc(unarchiveProductEndpoint, r.transitionProductHandler(app.ProductUnarchive))
+	mux.HandleFunc(getProductRevisionsEndpoint, r.orProductLookup(r.getProductRevisionsHandler))
+	mux.HandleFunc(getProductRevisionEndpoint, r.getProductRevisionHandler)
+	mux.HandleFunc(diffProductRevisionsEndpoint, r.diffProductRevisionsHandler)
+	mux.HandleFunc(revertProductEndpoint, r.revertProductHandler)
+	mux.HandleFunc(getAuditEntriesEndpoint, r.getAuditEntriesHandler)
+}
+
+// RegisterAdminRoutes registers on mux the product lists of the API, which
+// accept the adminParameters there. mux must be the one of the admin
+// listener, not the one of the API.
+func (r Router) RegisterAdminRoutes(mux *http.ServeMux) {
+	r.admin = true
+
+	mux.HandleFunc(getProductsEndpoint, r.getProductsHandler)
+	mux.HandleFunc(searchProductsEndpoint, r.searchProductsHandler)
+	mux.HandleFunc(getCategoryProductsEndpoint, r.getCategoryProductsHandler)
+}
+
+type productRequestBody struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Price       decimalString `json:"price"`
+	Currency    string        `json:"currency"`
+	Tags        []string      `json:"tags"`
+	Attributes  jsonObject    `json:"attributes"`
+	SKU         string        `json:"sku"`
+}
+
+// money parses the price and currency of the body, defaulting the currency
+// when the client omitted it.
+func (b productRequestBody) money() (app.Money, []fieldError) {
+	return parseMoney(b.Price, b.Currency)
+}
+
+// parseMoney parses a price in currency, or in the default currency when
+// currency is empty.
+func parseMoney(price decimalString, currency string) (app.Money, []fieldError) {
+	if currency == "" {
+		currency = app.DefaultCurrency
+	}
+
+	exp, ok := app.CurrencyExponent(currency)
+	if !ok {
+		return app.Money{}, []fieldError{{Field: "currency", Message: "must be a supported ISO 4217 currency code"}}
+	}
+
+	m, err := app.ParseMoney(string(price), currency)
+	if err != nil {
+		return app.Money{}, []fieldError{{Field: "price", Message: fmt.Sprintf("must be a decimal number with at most %d decimal places", exp)}}
+	}
+
+	return m, nil
+}
+
+func (r Router) createProductHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	var body productRequestBody
+	err := json.NewDecoder(req.Body).Decode(&body)
+	if err != nil {
+		writeBodyError(w, req, err)
+		return
+	}
+
+	price, fieldErrs := body.money()
+	if fieldErrs != nil {
+		writeProblem(w, req, problemValidation, "The request contains invalid data.", fieldErrs...)
+		return
+	}
+
+	dto := app.CreateProductDTO{
+		Name:        body.Name,
+		Description: body.Description,
+		Price:       price,
+		Tags:        body.Tags,
+		Attributes:  body.Attributes,
+		SKU:         body.SKU,
+	}
+
+	p, err := r.service.CreateProduct(ctx, dto)
+	if err != nil {
+		writeServiceError(w, req, err)
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(p.Version))
+	writeJSON(w, req, http.StatusCreated, newProductResponse(p))
+}
+
+func (r Router) updateProductHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	var body productRequestBody
+	err := json.NewDecoder(req.Body).Decode(&body)
+	if err != nil {
+		writeBodyError(w, req, err)
+		return
+	}
+
+	productIDStr := req.PathValue("product_id")
+	productID, err := uuid.Parse(productIDStr)
+	if err != nil {
+		writeInvalidIDError(w, req, "product_id")
+		return
+	}
+
+	price, fieldErrs := body.money()
+	if fieldErrs != nil {
+		writeProblem(w, req, problemValidation, "The request contains invalid data.", fieldErrs...)
+		return
+	}
+
+	expectedVersion, ok := r.expectedVersion(w, req)
+	if !ok {
+		return
+	}
+
+	dto := app.UpdateProductDTO{
+		ID:              productID,
+		ExpectedVersion: expectedVersion,
+		Name:            body.Name,
+		Description:     body.Description,
+		Price:           price,
+		Tags:            body.Tags,
+		Attributes:      body.Attributes,
+		SKU:             body.SKU,
+	}
+
+	p, err := r.service.UpdateProduct(ctx, dto)
+	if err != nil {
+		writeServiceError(w, req, err)
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(p.Version))
+	writeJSON(w, req, http.StatusOK, newProductResponse(p))
+}
+
+func (r Router) patchProductHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	format, ok := patchFormats[mediaType(req.Header.Get("Content-Type"))]
+	if !ok {
+		w.Header().Set("Accept-Patch", acceptPatch)
+		writeProblem(w, req, problemUnsupportedMediaType, "The request body must be a JSON Merge Patch or a JSON Patch document.")
+		return
+	}
+
+	patch, err := io.ReadAll(http.MaxBytesReader(w, req.Body, maxPatchBodySize))
+	if err != nil {
+		writeProblem(w, req, problemInvalidRequestBody, "The request body could not be read.")
+		return
+	}
+
+	productIDStr := req.PathValue("product_id")
+	productID, err := uuid.Parse(productIDStr)
+	if err != nil {
+		writeInvalidIDError(w, req, "product_id")
+		return
+	}
+
+	expectedVersion, ok := r.expectedVersion(w, req)
+	if !ok {
+		return
+	}
+
+	dto := app.PatchProductDTO{
+		ID:              productID,
+		ExpectedVersion: expectedVersion,
+		Format:          format,
+		Patch:           patch,
+	}
+
+	p, err := r.service.PatchProduct(ctx, dto)
+	if err != nil {
+		writeServiceError(w, req, err)
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(p.Version))
+	writeJSON(w, req, http.StatusOK, newProductResponse(p))
+}
+
+func (r Router) deleteProductHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	productIDStr := req.PathValue("product_id")
+	productID, err := uuid.Parse(productIDStr)
+	if err != nil {
+		writeInvalidIDError(w, req, "product_id")
+		return
+	}
+
+	expectedVersion, ok := r.expectedVersion(w, req)
+	if !ok {
+		return
+	}
+
+	dto := app.DeleteProductDTO{
+		ID:              productID,
+		ExpectedVersion: expectedVersion,
+	}
+
+	err = r.service.DeleteProduct(ctx, dto)
+	if err != nil {
+		writeServiceError(w, req, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// restoreProductHandler brings back a deleted product that was not purged yet.
+func (r Router) restoreProductHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	productID, err := uuid.Parse(req.PathValue("product_id"))
+	if err != nil {
+		writeInvalidIDError(w, req, "product_id")
+		return
+	}
+
+	expectedVersion, ok := r.expectedVersion(w, req)
+	if !ok {
+		return
+	}
+
+	dto := app.RestoreProductDTO{
+		ID:              productID,
+		ExpectedVersion: expectedVersion,
+	}
+
+	p, err := r.service.RestoreProduct(ctx, dto)
+	if err != nil {
+		writeServiceError(w, req, err)
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(p.Version))
+	writeJSON(w, req, http.StatusOK, newProductResponse(p))
+}
+
+// getProductHandler serves the product, or the product as it was at the
+// instant given by the as_of query parameter.
+func (r Router) getProductHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	productIDStr := req.PathValue("product_id")
+	productID, err := uuid.Parse(productIDStr)
+	if err != nil {
+		writeInvalidIDError(w, req, "product_id")
+		return
+	}
+
+	asOf, fieldErrs := parseAsOf(req.URL.Query())
+	if fieldErrs != nil {
+		writeProblem(w, req, problemInvalidParameter, "The query parameters are invalid.", fieldErrs...)
+		return
+	}
+
+	var product *app.Product
+	if asOf.IsZero() {
+		product, err = r.service.GetProduct(ctx, productID)
+	} else {
+		product, err = r.service.GetProductAsOf(ctx, productID, asOf)
+	}
+	if err != nil {
+		writeServiceError(w, req, err)
+		return
+	}
+
+	writeCacheable(w, req, r.cfg.ProductCacheControl, versionETag(product.Version), product.UpdatedAt, newProductResponse(product))
+}
+
+func (r Router) getProductByKeyHandler(w http.ResponseWriter, req *http.Request) {
+	r.lookupProduct(w, req, req.PathValue("key"), req.PathValue("value"))
+}
+
+// orProductLookup serves the lookups by SKU or slug whose value is the name
+// of the subresource served by next, such as /api/v1/products/by-sku/variants.
+func (r Router) orProductLookup(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		key := req.PathValue("product_id")
+		if key != productBySKUKey && key != productBySlugKey {
+			next(w, req)
+			return
+		}
+
+		r.lookupProduct(w, req, key, path.Base(req.URL.Path))
+	}
+}
+
+// lookupProduct writes the product whose SKU or slug, as selected by key, is
+// value.
+func (r Router) lookupProduct(w http.ResponseWriter, req *http.Request, key, value string) {
+	var (
+		product *app.Product
+		err     error
+	)
+	switch key {
+	case productBySKUKey:
+		product, err = r.service.GetProductBySKU(req.Context(), value)
+	case productBySlugKey:
+		product, err = r.service.GetProductBySlug(req.Context(), value)
+	default:
+		writeProblem(w, req, problemNotFound, "The requested resource does not exist.")
+		return
+	}
+	if err != nil {
+		writeServiceError(w, req, err)
+		return
+	}
+
+	writeCacheable(w, req, r.cfg.ProductCacheControl, versionETag(product.Version), product.UpdatedAt, newProductResponse(product))
+}
+
+func (r Router) getProductsHandler(w http.ResponseWriter, req *http.Request) {
+	if !r.allowParameters(w, req) {
+		return
+	}
+
+	dto, fieldErrs := parseGetProductsQuery(req.URL.Query())
+	if fieldErrs != nil {
+		writeProblem(w, req, problemInvalidParameter, "The query parameters are invalid.", fieldErrs...)
+		return
+	}
+
+	r.writeProducts(w, req, dto)
+}
+
+// writeProducts writes the page of the product list selected by dto.
+func (r Router) writeProducts(w http.ResponseWriter, req *http.Request, dto app.GetProductsDTO) {
+	ctx := req.Context()
+
+	page, err := r.service.GetProducts(ctx, dto)
+	if err != nil {
+		writeServiceError(w, req, err)
+		return
+	}
+
+	res := productsResponse{
+		Pagination: newPaginationResponse(dto, page),
+	}
+	for _, p := range page.Products {
+		productRes := newProductResponse(p)
+		res.Products = append(res.Products, productRes)
+	}
+	if page.NextCursor != nil {
+		res.NextCursor = page.NextCursor.Encode()
+	}
+	if page.PrevCursor != nil {
+		res.PrevCursor = page.PrevCursor.Encode()
+	}
+
+	setPaginationLinks(w.Header(), req, dto, page)
+
+	// The list has no Last-Modified: deleting a product changes the page
+	// without moving the most recent updated_at of the remaining ones.
+	writeCacheable(w, req, r.cfg.ProductsCacheControl, "", time.Time{}, res)
+}
+
+type productsResponse struct {
+	Products   []productResponse  `json:"products"`
+	Pagination paginationResponse `json:"pagination"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+	PrevCursor string             `json:"prev_cursor,omitempty"`
+}
+
+// productResponse has a null sku when the product has none.
+type productResponse struct {
+	ID          uuid.UUID      `json:"id"`
+	SKU         *string        `json:"sku"`
+	Slug        string         `json:"slug"`
+	Status      string         `json:"status"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Price       string         `json:"price"`
+	Currency    string         `json:"currency"`
+	Tags        []string       `json:"tags"`
+	Attributes  map[string]any `json:"attributes"`
+	Version     int64          `json:"version"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	// DeletedAt is only present on the soft deleted products of listings
+	// including them.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+func newProductResponse(p *app.Product) productResponse {
+	tags := p.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	attributes := p.Attributes
+	if attributes == nil {
+		attributes = map[string]any{}
+	}
+
+	var sku *string
+	if p.SKU != "" {
+		sku = &p.SKU
+	}
+
+	return productResponse{
+		ID:          p.ID,
+		SKU:         sku,
+		Slug:        p.Slug,
+		Status:      string(p.Status),
+		Name:        p.Name,
+		Description: p.Description,
+		Price:       p.Price.Decimal(),
+		Currency:    p.Price.Currency,
+		Tags:        tags,
+		Attributes:  attributes,
+		Version:     p.Version,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+		DeletedAt:   p.DeletedAt,
+	}
+}
@@ -1,116 +1,610 @@
-// Code generated by MockGen. DO NOT EDIT.
-// Source: ./internal/infra/http/router.go
-//
-// Generated by this command:
-//
-//	mockgen -source ./internal/infra/http/router.go -destination ./internal/infra/http/router_mocks.go -package http
-//
-
-// Package http is a generated GoMock package.
-package http
-
-import (
-	context "context"
-	reflect "reflect"
-
-	uuid "github.com/google/uuid"
-	app "github.com/simpler-tha/internal/app"
-	gomock "go.uber.org/mock/gomock"
-)
-
-// Mockservice is a mock of service interface.
-type Mockservice struct {
-	ctrl     *gomock.Controller
-	recorder *MockserviceMockRecorder
-}
-
-// MockserviceMockRecorder is the mock recorder for Mockservice.
-type MockserviceMockRecorder struct {
-	mock *Mockservice
-}
-
-// NewMockservice creates a new mock instance.
-func NewMockservice(ctrl *gomock.Controller) *Mockservice {
-	mock := &Mockservice{ctrl: ctrl}
-	mock.recorder = &MockserviceMockRecorder{mock}
-	return mock
-}
-
-// EXPECT returns an object that allows the caller to indicate expected use.
-func (m *Mockservice) EXPECT() *MockserviceMockRecorder {
-	return m.recorder
-}
-
-// CreateProduct mocks base method.
-func (m *Mockservice) CreateProduct(ctx context.Context, dto app.CreateProductDTO) (*app.Product, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateProduct", ctx, dto)
-	ret0, _ := ret[0].(*app.Product)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// CreateProduct indicates an expected call of CreateProduct.
-func (mr *MockserviceMockRecorder) CreateProduct(ctx, dto any) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProduct", reflect.TypeOf((*Mockservice)(nil).CreateProduct), ctx, dto)
-}
-
-// DeleteProduct mocks base method.
-func (m *Mockservice) DeleteProduct(ctx context.Context, productID uuid.UUID) error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteProduct", ctx, productID)
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// DeleteProduct indicates an expected call of DeleteProduct.
-func (mr *MockserviceMockRecorder) DeleteProduct(ctx, productID any) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProduct", reflect.TypeOf((*Mockservice)(nil).DeleteProduct), ctx, productID)
-}
-
-// GetProduct mocks base method.
-func (m *Mockservice) GetProduct(ctx context.Context, productID uuid.UUID) (*app.Product, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetProduct", ctx, productID)
-	ret0, _ := ret[0].(*app.Product)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// GetProduct indicates an expected call of GetProduct.
-func (mr *MockserviceMockRecorder) GetProduct(ctx, productID any) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProduct", reflect.TypeOf((*Mockservice)(nil).GetProduct), ctx, productID)
-}
-
-// GetProducts mocks base method.
-func (m *Mockservice) GetProducts(ctx context.Context, limit, offset int) ([]*app.Product, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetProducts", ctx, limit, offset)
-	ret0, _ := ret[0].([]*app.Product)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// GetProducts indicates an expected call of GetProducts.
-func (mr *MockserviceMockRecorder) GetProducts(ctx, limit, offset any) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProducts", reflect.TypeOf((*Mockservice)(nil).GetProducts), ctx, limit, offset)
-}
-
-// UpdateProduct mocks base method.
-func (m *Mockservice) UpdateProduct(ctx context.Context, dto app.UpdateProductDTO) (*app.Product, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateProduct", ctx, dto)
-	ret0, _ := ret[0].(*app.Product)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// UpdateProduct indicates an expected call of UpdateProduct.
-func (mr *MockserviceMockRecorder) UpdateProduct(ctx, dto any) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProduct", reflect.TypeOf((*Mockservice)(nil).UpdateProduct), ctx, dto)
-}
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/infra/http/router.go
+//
+// Generated by this command:
+//
+//	mockgen -source ./internal/infra/http/router.go -destination ./internal/infra/http/router_mocks.go -package http
+//
+
+// Package http is a generated GoMock package.
+package http
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	uuid "github.com/google/uuid"
+	app "github.com/simpler-tha/internal/app"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// Mockservice is a mock of service interface.
+type Mockservice struct {
+	ctrl     *gomock.Controller
+	recorder *MockserviceMockRecorder
+}
+
+// MockserviceMockRecorder is the mock recorder for Mockservice.
+type MockserviceMockRecorder struct {
+	mock *Mockservice
+}
+
+// NewMockservice creates a new mock instance.
+func NewMockservice(ctrl *gomock.Controller) *Mockservice {
+	mock := &Mockservice{ctrl: ctrl}
+	mock.recorder = &MockserviceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mockservice) EXPECT() *MockserviceMockRecorder {
+	return m.recorder
+}
+
+// AdjustStock mocks base method.
+func (m *Mockservice) AdjustStock(ctx context.Context, dto app.AdjustStockDTO) (*app.Inventory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustStock", ctx, dto)
+	ret0, _ := ret[0].(*app.Inventory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdjustStock indicates an expected call of AdjustStock.
+func (mr *MockserviceMockRecorder) AdjustStock(ctx, dto any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustStock", reflect.TypeOf((*Mockservice)(nil).AdjustStock), ctx, dto)
+}
+
+// CommitReservation mocks base method.
+func (m *Mockservice) CommitReservation(ctx context.Context, reservationID uuid.UUID) (*app.Reservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CommitReservation", ctx, reservationID)
+	ret0, _ := ret[0].(*app.Reservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CommitReservation indicates an expected call of CommitReservation.
+func (mr *MockserviceMockRecorder) CommitReservation(ctx, reservationID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitReservation", reflect.TypeOf((*Mockservice)(nil).CommitReservation), ctx, reservationID)
+}
+
+// CreateCategory mocks base method.
+func (m *Mockservice) CreateCategory(ctx context.Context, dto app.CreateCategoryDTO) (*app.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCategory", ctx, dto)
+	ret0, _ := ret[0].(*app.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCategory indicates an expected call of CreateCategory.
+func (mr *MockserviceMockRecorder) CreateCategory(ctx, dto any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCategory", reflect.TypeOf((*Mockservice)(nil).CreateCategory), ctx, dto)
+}
+
+// CreateProduct mocks base method.
+func (m *Mockservice) CreateProduct(ctx context.Context, dto app.CreateProductDTO) (*app.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateProduct", ctx, dto)
+	ret0, _ := ret[0].(*app.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateProduct indicates an expected call of CreateProduct.
+func (mr *MockserviceMockRecorder) CreateProduct(ctx, dto any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProduct", reflect.TypeOf((*Mockservice)(nil).CreateProduct), ctx, dto)
+}
+
+// CreateReservation mocks base method.
+func (m *Mockservice) CreateReservation(ctx context.Context, dto app.CreateReservationDTO) (*app.Reservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReservation", ctx, dto)
+	ret0, _ := ret[0].(*app.Reservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateReservation indicates an expected call of CreateReservation.
+func (mr *MockserviceMockRecorder) CreateReservation(ctx, dto any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReservation", reflect.TypeOf((*Mockservice)(nil).CreateReservation), ctx, dto)
+}
+
+// CreateVariant mocks base method.
+func (m *Mockservice) CreateVariant(ctx context.Context, dto app.CreateVariantDTO) (*app.Variant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateVariant", ctx, dto)
+	ret0, _ := ret[0].(*app.Variant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateVariant indicates an expected call of CreateVariant.
+func (mr *MockserviceMockRecorder) CreateVariant(ctx, dto any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVariant", reflect.TypeOf((*Mockservice)(nil).CreateVariant), ctx, dto)
+}
+
+// DeleteCategory mocks base method.
+func (m *Mockservice) DeleteCategory(ctx context.Context, dto app.DeleteCategoryDTO) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCategory", ctx, dto)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCategory indicates an expected call of DeleteCategory.
+func (mr *MockserviceMockRecorder) DeleteCategory(ctx, dto any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCategory", reflect.TypeOf((*Mockservice)(nil).DeleteCategory), ctx, dto)
+}
+
+// DeleteProduct mocks base method.
+func (m *Mockservice) DeleteProduct(ctx context.Context, dto app.DeleteProductDTO) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProduct", ctx, dto)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteProduct indicates an expected call of DeleteProduct.
+func (mr *MockserviceMockRecorder) DeleteProduct(ctx, dto any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProduct", reflect.TypeOf((*Mockservice)(nil).DeleteProduct), ctx, dto)
+}
+
+// DeleteVariant mocks base method.
+func (m *Mockservice) DeleteVariant(ctx context.Context, dto app.DeleteVariantDTO) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteVariant", ctx, dto)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteVariant indicates an expected call of DeleteVariant.
+func (mr *MockserviceMockRecorder) DeleteVariant(ctx, dto any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVariant", reflect.TypeOf((*Mockservice)(nil).DeleteVariant), ctx, dto)
+}
+
+// DiffProductRevisions mocks base method.
+func (m *Mockservice) DiffProductRevisions(ctx context.Context, dto app.DiffProductRevisionsDTO) (*app.ProductDiff, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DiffProductRevisions", ctx, dto)
+	ret0, _ := ret[0].(*app.ProductDiff)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DiffProductRevisions indicates an expected call of DiffProductRevisions.
+func (mr *MockserviceMockRecorder) DiffProductRevisions(ctx, dto any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiffProductRevisions", reflect.TypeOf((*Mockservice)(nil).DiffProductRevisions), ctx, dto)
+}
+
+// GetAuditEntries mocks base method.
+func (m *Mockservice) GetAuditEntries(ctx context.Context, dto app.GetAuditEntriesDTO) (*app.AuditPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditEntries", ctx, dto)
+	ret0, _ := ret[0].(*app.AuditPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditEntries indicates an expected call of GetAuditEntries.
+func (mr *MockserviceMockRecorder) GetAuditEntries(ctx, dto any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditEntries", reflect.TypeOf((*Mockservice)(nil).GetAuditEntries), ctx, dto)
+}
+
+// GetCategories mocks base method.
+func (m *Mockservice) GetCategories(ctx context.Context) ([]*app.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategories", ctx)
+	ret0, _ := ret[0].([]*app.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategories indicates an expected call of GetCategories.
+func (mr *MockserviceMockRecorder) GetCategories(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategories", reflect.TypeOf((*Mockservice)(nil).GetCategories), ctx)
+}
+
+// GetCategory mocks base method.
+func (m *Mockservice) GetCategory(ctx context.Context, categoryID uuid.UUID) (*app.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategory", ctx, categoryID)
+	ret0, _ := ret[0].(*app.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategory indicates an expected call of GetCategory.
+func (mr *MockserviceMockRecorder) GetCategory(ctx, categoryID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategory", reflect.TypeOf((*Mockservice)(nil).GetCategory), ctx, categoryID)
+}
+
+// GetInventory mocks base method.
+func (m *Mockservice) GetInventory(ctx context.Context, productID uuid.UUID) (*app.Inventory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInventory", ctx, productID)
+	ret0, _ := ret[0].(*app.Inventory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInventory indicates an expected call of GetInventory.
+func (mr *MockserviceMockRecorder) GetInventory(ctx, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInventory", reflect.TypeOf((*Mockservice)(nil).GetInventory), ctx, productID)
+}
+
+// GetProduct mocks base method.
+func (m *Mockservice) GetProduct(ctx context.Context, productID uuid.UUID) (*app.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProduct", ctx, productID)
+	ret0, _ := ret[0].(*app.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProduct indicates an expected call of GetProduct.
+func (mr *MockserviceMockRecorder) GetProduct(ctx, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProduct", reflect.TypeOf((*Mockservice)(nil).GetProduct), ctx, productID)
+}
+
+// GetProductAsOf mocks base method.
+func (m *Mockservice) GetProductAsOf(ctx context.Context, productID uuid.UUID, asOf time.Time) (*app.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductAsOf", ctx, productID, asOf)
+	ret0, _ := ret[0].(*app.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductAsOf indicates an expected call of GetProductAsOf.
+func (mr *MockserviceMockRecorder) GetProductAsOf(ctx, productID, asOf any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductAsOf", reflect.TypeOf((*Mockservice)(nil).GetProductAsOf), ctx, productID, asOf)
+}
+
+// GetProductBySKU mocks base method.
+func (m *Mockservice) GetProductBySKU(ctx context.Context, sku string) (*app.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductBySKU", ctx, sku)
+	ret0, _ := ret[0].(*app.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductBySKU indicates an expected call of GetProductBySKU.
+func (mr *MockserviceMockRecorder) GetProductBySKU(ctx, sku any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductBySKU", reflect.TypeOf((*Mockservice)(nil).GetProductBySKU), ctx, sku)
+}
+
+// GetProductBySlug mocks base method.
+func (m *Mockservice) GetProductBySlug(ctx context.Context, slug string) (*app.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductBySlug", ctx, slug)
+	ret0, _ := ret[0].(*app.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductBySlug indicates an expected call of GetProductBySlug.
+func (mr *MockserviceMockRecorder) GetProductBySlug(ctx, slug any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductBySlug", reflect.TypeOf((*Mockservice)(nil).GetProductBySlug), ctx, slug)
+}
+
+// GetProductCategories mocks base method.
+func (m *Mockservice) GetProductCategories(ctx context.Context, productID uuid.UUID) ([]*app.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductCategories", ctx, productID)
+	ret0, _ := ret[0].([]*app.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductCategories indicates an expected call of GetProductCategories.
+func (mr *MockserviceMockRecorder) GetProductCategories(ctx, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductCategories", reflect.TypeOf((*Mockservice)(nil).GetProductCategories), ctx, productID)
+}
+
+// GetProductRevision mocks base method.
+func (m *Mockservice) GetProductRevision(ctx context.Context, productID uuid.UUID, version int64) (*app.ProductRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductRevision", ctx, productID, version)
+	ret0, _ := ret[0].(*app.ProductRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductRevision indicates an expected call of GetProductRevision.
+func (mr *MockserviceMockRecorder) GetProductRevision(ctx, productID, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductRevision", reflect.TypeOf((*Mockservice)(nil).GetProductRevision), ctx, productID, version)
+}
+
+// GetProductRevisions mocks base method.
+func (m *Mockservice) GetProductRevisions(ctx context.Context, productID uuid.UUID) ([]app.ProductRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductRevisions", ctx, productID)
+	ret0, _ := ret[0].([]app.ProductRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductRevisions indicates an expected call of GetProductRevisions.
+func (mr *MockserviceMockRecorder) GetProductRevisions(ctx, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductRevisions", reflect.TypeOf((*Mockservice)(nil).GetProductRevisions), ctx, productID)
+}
+
+// GetProducts mocks base method.
+func (m *Mockservice) GetProducts(ctx context.Context, dto app.GetProductsDTO) (*app.ProductPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProducts", ctx, dto)
+	ret0, _ := ret[0].(*app.ProductPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProducts indicates an expected call of GetProducts.
+func (mr *MockserviceMockRecorder) GetProducts(ctx, dto any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProducts", reflect.TypeOf((*Mockservice)(nil).GetProducts), ctx, dto)
+}
+
+// GetReservation mocks base method.
+func (m *Mockservice) GetReservation(ctx context.Context, reservationID uuid.UUID) (*app.Reservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReservation", ctx, reservationID)
+	ret0, _ := ret[0].(*app.Reservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReservation indicates an expected call of GetReservation.
+func (mr *MockserviceMockRecorder) GetReservation(ctx, reservationID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReservation", reflect.TypeOf((*Mockservice)(nil).GetReservation), ctx, reservationID)
+}
+
+// GetStockAdjustments mocks base method.
+func (m *Mockservice) GetStockAdjustments(ctx context.Context, productID uuid.UUID) ([]app.StockAdjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStockAdjustments", ctx, productID)
+	ret0, _ := ret[0].([]app.StockAdjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStockAdjustments indicates an expected call of GetStockAdjustments.
+func (mr *MockserviceMockRecorder) GetStockAdjustments(ctx, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStockAdjustments", reflect.TypeOf((*Mockservice)(nil).GetStockAdjustments), ctx, productID)
+}
+
+// GetTags mocks base method.
+func (m *Mockservice) GetTags(ctx context.Context, dto app.GetTagsDTO) ([]app.TagCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTags", ctx, dto)
+	ret0, _ := ret[0].([]app.TagCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTags indicates an expected call of GetTags.
+func (mr *MockserviceMockRecorder) GetTags(ctx, dto any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTags", reflect.TypeOf((*Mockservice)(nil).GetTags), ctx, dto)
+}
+
+// GetVariant mocks base method.
+func (m *Mockservice) GetVariant(ctx context.Context, productID, variantID uuid.UUID) (*app.Variant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVariant", ctx, productID, variantID)
+	ret0, _ := ret[0].(*app.Variant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVariant indicates an expected call of GetVariant.
+func (mr *MockserviceMockRecorder) GetVariant(ctx, productID, variantID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVariant", reflect.TypeOf((*Mockservice)(nil).GetVariant), ctx, productID, variantID)
+}
+
+// GetVariants mocks base method.
+func (m *Mockservice) GetVariants(ctx context.Context, productID uuid.UUID) ([]*app.Variant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVariants", ctx, productID)
+	ret0, _ := ret[0].([]*app.Variant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVariants indicates an expected call of GetVariants.
+func (mr *MockserviceMockRecorder) GetVariants(ctx, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVariants", reflect.TypeOf((*Mockservice)(nil).GetVariants), ctx, productID)
+}
+
+// PatchProduct mocks base method.
+func (m *Mockservice) PatchProduct(ctx context.Context, dto app.PatchProductDTO) (*app.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchProduct", ctx, dto)
+	ret0, _ := ret[0].(*app.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchProduct indicates an expected call of PatchProduct.
+func (mr *MockserviceMockRecorder) PatchProduct(ctx, dto any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchProduct", reflect.TypeOf((*Mockservice)(nil).PatchProduct), ctx, dto)
+}
+
+// ReleaseReservation mocks base method.
+func (m *Mockservice) ReleaseReservation(ctx context.Context, reservationID uuid.UUID) (*app.Reservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseReservation", ctx, reservationID)
+	ret0, _ := ret[0].(*app.Reservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReleaseReservation indicates an expected call of ReleaseReservation.
+func (mr *MockserviceMockRecorder) ReleaseReservation(ctx, reservationID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseReservation", reflect.TypeOf((*Mockservice)(nil).ReleaseReservation), ctx, reservationID)
+}
+
+// RestoreProduct mocks base method.
+func (m *Mockservice) RestoreProduct(ctx context.Context, dto app.RestoreProductDTO) (*app.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreProduct", ctx, dto)
+	ret0, _ := ret[0].(*app.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreProduct indicates an expected call of RestoreProduct.
+func (mr *MockserviceMockRecorder) RestoreProduct(ctx, dto any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreProduct", reflect.TypeOf((*Mockservice)(nil).RestoreProduct), ctx, dto)
+}
+
+// RevertProduct mocks base method.
+func (m *Mockservice) RevertProduct(ctx context.Context, dto app.RevertProductDTO) (*app.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevertProduct", ctx, dto)
+	ret0, _ := ret[0].(*app.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevertProduct indicates an expected call of RevertProduct.
+func (mr *MockserviceMockRecorder) RevertProduct(ctx, dto any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevertProduct", reflect.TypeOf((*Mockservice)(nil).RevertProduct), ctx, dto)
+}
+
+// SearchProducts mocks base method.
+func (m *Mockservice) SearchProducts(ctx context.Context, dto app.SearchProductsDTO) (*app.ProductSearchPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchProducts", ctx, dto)
+	ret0, _ := ret[0].(*app.ProductSearchPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchProducts indicates an expected call of SearchProducts.
+func (mr *MockserviceMockRecorder) SearchProducts(ctx, dto any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchProducts", reflect.TypeOf((*Mockservice)(nil).SearchProducts), ctx, dto)
+}
+
+// SetProductCategories mocks base method.
+func (m *Mockservice) SetProductCategories(ctx context.Context, dto app.SetProductCategoriesDTO) ([]*app.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetProductCategories", ctx, dto)
+	ret0, _ := ret[0].([]*app.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetProductCategories indicates an expected call of SetProductCategories.
+func (mr *MockserviceMockRecorder) SetProductCategories(ctx, dto any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetProductCategories", reflect.TypeOf((*Mockservice)(nil).SetProductCategories), ctx, dto)
+}
+
+// SuggestProducts mocks base method.
+func (m *Mockservice) SuggestProducts(ctx context.Context, dto app.SuggestProductsDTO) ([]app.ProductSuggestion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SuggestProducts", ctx, dto)
+	ret0, _ := ret[0].([]app.ProductSuggestion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SuggestProducts indicates an expected call of SuggestProducts.
+func (mr *MockserviceMockRecorder) SuggestProducts(ctx, dto any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SuggestProducts", reflect.TypeOf((*Mockservice)(nil).SuggestProducts), ctx, dto)
+}
+
+// TransitionProduct mocks base method.
+func (m *Mockservice) TransitionProduct(ctx context.Context, dto app.TransitionProductDTO) (*app.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransitionProduct", ctx, dto)
+	ret0, _ := ret[0].(*app.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TransitionProduct indicates an expected call of TransitionProduct.
+func (mr *MockserviceMockRecorder) TransitionProduct(ctx, dto any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransitionProduct", reflect.TypeOf((*Mockservice)(nil).TransitionProduct), ctx, dto)
+}
+
+// UpdateCategory mocks base method.
+func (m *Mockservice) UpdateCategory(ctx context.Context, dto app.UpdateCategoryDTO) (*app.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCategory", ctx, dto)
+	ret0, _ := ret[0].(*app.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCategory indicates an expected call of UpdateCategory.
+func (mr *MockserviceMockRecorder) UpdateCategory(ctx, dto any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCategory", reflect.TypeOf((*Mockservice)(nil).UpdateCategory), ctx, dto)
+}
+
+// UpdateProduct mocks base method.
+func (m *Mockservice) UpdateProduct(ctx context.Context, dto app.UpdateProductDTO) (*app.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProduct", ctx, dto)
+	ret0, _ := ret[0].(*app.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProduct indicates an expected call of UpdateProduct.
+func (mr *MockserviceMockRecorder) UpdateProduct(ctx, dto any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProduct", reflect.TypeOf((*Mockservice)(nil).UpdateProduct), ctx, dto)
+}
+
+// UpdateVariant mocks base method.
+func (m *Mockservice) UpdateVariant(ctx context.Context, dto app.UpdateVariantDTO) (*app.Variant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateVariant", ctx, dto)
+	ret0, _ := ret[0].(*app.Variant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateVariant indicates an expected call of UpdateVariant.
+func (mr *MockserviceMockRecorder) UpdateVariant(ctx, dto any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateVariant", reflect.TypeOf((*Mockservice)(nil).UpdateVariant), ctx, dto)
+}
@@ -1,479 +1,1361 @@
-package http
-
-import (
-	"bytes"
-	"errors"
-	"fmt"
-	"io"
-	"net/http"
-	"net/http/httptest"
-	"strconv"
-	"testing"
-	"time"
-
-	"github.com/google/uuid"
-	"github.com/stretchr/testify/assert"
-	"go.uber.org/mock/gomock"
-
-	"github.com/simpler-tha/internal/app"
-)
-
-func TestNewRouter(t *testing.T) {
-	r, err := NewRouter(nil)
-	assert.EqualError(t, err, "service cannot be nil")
-	assert.Empty(t, r)
-}
-
-func TestRouter_createProductHandler(t *testing.T) {
-	ctrl := gomock.NewController(t)
-
-	reqBody := []byte(`{"name":"Test Product","description":"Test Description","price":100.0}`)
-
-	createProductDTO := app.CreateProductDTO{
-		Name:        "Test Product",
-		Description: "Test Description",
-		Price:       100.0,
-	}
-
-	productID, _ := uuid.Parse("9f9f4340-6bf9-4948-808c-ebf2dd604e2c")
-
-	now, _ := time.Parse(time.RFC3339, "2024-10-02T14:28:34Z")
-
-	product := &app.Product{
-		ID:          productID,
-		Name:        "Test Product",
-		Description: "Test Description",
-		Price:       100.0,
-		CreatedAt:   now,
-		UpdatedAt:   now,
-	}
-
-	responseBody := []byte("{\"id\":\"9f9f4340-6bf9-4948-808c-ebf2dd604e2c\",\"name\":\"Test Product\",\"description\":\"Test Description\",\"price\":100,\"created_at\":\"2024-10-02T14:28:34Z\",\"updated_at\":\"2024-10-02T14:28:34Z\"}\n")
-
-	tests := []struct {
-		name                          string
-		reqBody                       []byte
-		expServiceCreateProductResult *app.Product
-		expServiceCreateProductError  error
-		expStatus                     int
-		expResponse                   []byte
-	}{
-		{
-			name:                          "product created successfully",
-			reqBody:                       reqBody,
-			expServiceCreateProductResult: product,
-			expServiceCreateProductError:  nil,
-			expStatus:                     http.StatusCreated,
-			expResponse:                   responseBody,
-		},
-		{
-			name:                          "product could not be created",
-			reqBody:                       reqBody,
-			expServiceCreateProductResult: nil,
-			expServiceCreateProductError:  errors.New("service error"),
-			expStatus:                     http.StatusInternalServerError,
-			expResponse:                   []byte("an error occurred\n"),
-		},
-		{
-			name:                          "invalid request body",
-			reqBody:                       []byte(`{`),
-			expServiceCreateProductResult: nil,
-			expServiceCreateProductError:  nil,
-			expStatus:                     http.StatusBadRequest,
-			expResponse:                   []byte("invalid request body\n"),
-		},
-	}
-
-	for _, tt := range tests {
-		mockService := NewMockservice(ctrl)
-
-		if tt.expServiceCreateProductResult != nil || tt.expServiceCreateProductError != nil {
-			mockService.
-				EXPECT().
-				CreateProduct(gomock.Any(), createProductDTO).
-				Return(tt.expServiceCreateProductResult, tt.expServiceCreateProductError)
-		}
-
-		router, err := NewRouter(mockService)
-		assert.NoError(t, err)
-		assert.NotNil(t, router)
-
-		req := httptest.NewRequest(http.MethodPost, "/api/v1/products", bytes.NewBuffer(tt.reqBody))
-
-		recorder := httptest.NewRecorder()
-
-		router.createProductHandler(recorder, req)
-
-		assert.Equal(t, tt.expStatus, recorder.Code)
-
-		b, err := io.ReadAll(recorder.Body)
-		assert.NoError(t, err)
-
-		assert.Equal(t, tt.expResponse, b)
-	}
-}
-
-func TestRouter_updateProductHandler(t *testing.T) {
-	ctrl := gomock.NewController(t)
-
-	reqBody := []byte(`{"name":"Test Product","description":"Test Description","price":100.0}`)
-
-	productID, _ := uuid.Parse("9f9f4340-6bf9-4948-808c-ebf2dd604e2c")
-
-	updateProductDTO := app.UpdateProductDTO{
-		ID:          productID,
-		Name:        "Test Product",
-		Description: "Test Description",
-		Price:       100.0,
-	}
-
-	now, _ := time.Parse(time.RFC3339, "2024-10-02T14:28:34Z")
-
-	product := &app.Product{
-		ID:          productID,
-		Name:        "Test Product",
-		Description: "Test Description",
-		Price:       100.0,
-		CreatedAt:   now,
-		UpdatedAt:   now,
-	}
-
-	responseBody := []byte("{\"id\":\"9f9f4340-6bf9-4948-808c-ebf2dd604e2c\",\"name\":\"Test Product\",\"description\":\"Test Description\",\"price\":100,\"created_at\":\"2024-10-02T14:28:34Z\",\"updated_at\":\"2024-10-02T14:28:34Z\"}\n")
-
-	tests := []struct {
-		name                          string
-		productID                     string
-		reqBody                       []byte
-		expServiceUpdateProductResult *app.Product
-		expServiceUpdateProductError  error
-		expStatus                     int
-		expResponse                   []byte
-	}{
-		{
-			name:                          "product updated successfully",
-			productID:                     productID.String(),
-			reqBody:                       reqBody,
-			expServiceUpdateProductResult: product,
-			expServiceUpdateProductError:  nil,
-			expStatus:                     http.StatusOK,
-			expResponse:                   responseBody,
-		},
-		{
-			name:                          "product could not be updated",
-			productID:                     productID.String(),
-			reqBody:                       reqBody,
-			expServiceUpdateProductResult: nil,
-			expServiceUpdateProductError:  errors.New("service error"),
-			expStatus:                     http.StatusInternalServerError,
-			expResponse:                   []byte("an error occurred\n"),
-		},
-		{
-			name:                          "invalid request body",
-			productID:                     productID.String(),
-			reqBody:                       []byte(`{`),
-			expServiceUpdateProductResult: nil,
-			expServiceUpdateProductError:  nil,
-			expStatus:                     http.StatusBadRequest,
-			expResponse:                   []byte("invalid request body\n"),
-		},
-		{
-			name:                          "invalid product ID",
-			productID:                     "",
-			reqBody:                       reqBody,
-			expServiceUpdateProductResult: nil,
-			expServiceUpdateProductError:  nil,
-			expStatus:                     http.StatusBadRequest,
-			expResponse:                   []byte("invalid product id\n"),
-		},
-	}
-
-	for _, tt := range tests {
-		mockService := NewMockservice(ctrl)
-
-		if tt.expServiceUpdateProductResult != nil || tt.expServiceUpdateProductError != nil {
-			mockService.
-				EXPECT().
-				UpdateProduct(gomock.Any(), updateProductDTO).
-				Return(tt.expServiceUpdateProductResult, tt.expServiceUpdateProductError)
-		}
-
-		router, err := NewRouter(mockService)
-		assert.NoError(t, err)
-		assert.NotNil(t, router)
-
-		req := httptest.NewRequest(http.MethodPut, "/api/v1/products", bytes.NewBuffer(tt.reqBody))
-		if tt.productID != "" {
-			req.SetPathValue("product_id", tt.productID)
-		}
-
-		recorder := httptest.NewRecorder()
-
-		router.updateProductHandler(recorder, req)
-
-		assert.Equal(t, tt.expStatus, recorder.Code)
-
-		b, err := io.ReadAll(recorder.Body)
-		assert.NoError(t, err)
-
-		assert.Equal(t, tt.expResponse, b)
-	}
-}
-
-func TestRouter_deleteProductHandler(t *testing.T) {
-	ctrl := gomock.NewController(t)
-
-	productID, _ := uuid.Parse("9f9f4340-6bf9-4948-808c-ebf2dd604e2c")
-
-	tests := []struct {
-		name                         string
-		productID                    string
-		expServiceDeleteProductError error
-		expStatus                    int
-		expResponse                  []byte
-	}{
-		{
-			name:                         "product deleted successfully",
-			productID:                    productID.String(),
-			expServiceDeleteProductError: nil,
-			expStatus:                    http.StatusNoContent,
-			expResponse:                  []byte{},
-		},
-		{
-			name:                         "product could not be deleted",
-			productID:                    productID.String(),
-			expServiceDeleteProductError: errors.New("service error"),
-			expStatus:                    http.StatusInternalServerError,
-			expResponse:                  []byte("an error occurred\n"),
-		},
-		{
-			name:                         "invalid product ID",
-			productID:                    "",
-			expServiceDeleteProductError: nil,
-			expStatus:                    http.StatusBadRequest,
-			expResponse:                  []byte("invalid product id\n"),
-		},
-	}
-
-	for _, tt := range tests {
-		mockService := NewMockservice(ctrl)
-
-		if tt.productID != "" {
-			mockService.
-				EXPECT().
-				DeleteProduct(gomock.Any(), productID).
-				Return(tt.expServiceDeleteProductError)
-		}
-
-		router, err := NewRouter(mockService)
-		assert.NoError(t, err)
-		assert.NotNil(t, router)
-
-		req := httptest.NewRequest(http.MethodDelete, "/api/v1/products/{product_id}", nil)
-		if tt.productID != "" {
-			req.SetPathValue("product_id", tt.productID)
-		}
-
-		recorder := httptest.NewRecorder()
-
-		router.deleteProductHandler(recorder, req)
-
-		assert.Equal(t, tt.expStatus, recorder.Code)
-
-		b, err := io.ReadAll(recorder.Body)
-		assert.NoError(t, err)
-
-		assert.Equal(t, tt.expResponse, b)
-	}
-}
-
-func TestRouter_getProductHandler(t *testing.T) {
-	ctrl := gomock.NewController(t)
-
-	productID, _ := uuid.Parse("9f9f4340-6bf9-4948-808c-ebf2dd604e2c")
-
-	now, _ := time.Parse(time.RFC3339, "2024-10-02T14:28:34Z")
-
-	product := &app.Product{
-		ID:          productID,
-		Name:        "Test Product",
-		Description: "Test Description",
-		Price:       100.0,
-		CreatedAt:   now,
-		UpdatedAt:   now,
-	}
-
-	responseBody := []byte("{\"id\":\"9f9f4340-6bf9-4948-808c-ebf2dd604e2c\",\"name\":\"Test Product\",\"description\":\"Test Description\",\"price\":100,\"created_at\":\"2024-10-02T14:28:34Z\",\"updated_at\":\"2024-10-02T14:28:34Z\"}\n")
-
-	tests := []struct {
-		name                       string
-		productID                  string
-		expServiceGetProductResult *app.Product
-		expServiceGetProductError  error
-		expStatus                  int
-		expResponse                []byte
-	}{
-		{
-			name:                       "product retrieved successfully",
-			productID:                  productID.String(),
-			expServiceGetProductResult: product,
-			expServiceGetProductError:  nil,
-			expStatus:                  http.StatusOK,
-			expResponse:                responseBody,
-		},
-		{
-			name:                       "product could not be retrieved",
-			productID:                  productID.String(),
-			expServiceGetProductResult: nil,
-			expServiceGetProductError:  errors.New("service error"),
-			expStatus:                  http.StatusInternalServerError,
-			expResponse:                []byte("an error occurred\n"),
-		},
-		{
-			name:                      "invalid product ID",
-			productID:                 "",
-			expServiceGetProductError: nil,
-			expStatus:                 http.StatusBadRequest,
-			expResponse:               []byte("invalid product id\n"),
-		},
-	}
-
-	for _, tt := range tests {
-		mockService := NewMockservice(ctrl)
-
-		if tt.productID != "" {
-			mockService.
-				EXPECT().
-				GetProduct(gomock.Any(), productID).
-				Return(tt.expServiceGetProductResult, tt.expServiceGetProductError)
-		}
-
-		router, err := NewRouter(mockService)
-		assert.NoError(t, err)
-		assert.NotNil(t, router)
-
-		req := httptest.NewRequest(http.MethodGet, "/api/v1/products/{product_id}", nil)
-		if tt.productID != "" {
-			req.SetPathValue("product_id", tt.productID)
-		}
-
-		recorder := httptest.NewRecorder()
-
-		router.getProductHandler(recorder, req)
-
-		assert.Equal(t, tt.expStatus, recorder.Code)
-
-		b, err := io.ReadAll(recorder.Body)
-		assert.NoError(t, err)
-
-		assert.Equal(t, tt.expResponse, b)
-	}
-}
-
-func TestRouter_getProductsHandler(t *testing.T) {
-	ctrl := gomock.NewController(t)
-
-	productIDA, _ := uuid.Parse("9f9f4340-6bf9-4948-808c-ebf2dd604e2c")
-	productIDB, _ := uuid.Parse("9f9f4340-6bf9-4948-808c-ebf2dd60303d")
-
-	now, _ := time.Parse(time.RFC3339, "2024-10-02T14:28:34Z")
-
-	productA := &app.Product{
-		ID:          productIDA,
-		Name:        "Test Product A",
-		Description: "Test Description A",
-		Price:       100.0,
-		CreatedAt:   now,
-		UpdatedAt:   now,
-	}
-
-	productB := &app.Product{
-		ID:          productIDB,
-		Name:        "Test Product B",
-		Description: "Test Description B",
-		Price:       200.0,
-		CreatedAt:   now,
-		UpdatedAt:   now,
-	}
-
-	responseBody := []byte("{\"products\":[" +
-		"{\"id\":\"9f9f4340-6bf9-4948-808c-ebf2dd604e2c\",\"name\":\"Test Product A\",\"description\":\"Test Description A\",\"price\":100,\"created_at\":\"2024-10-02T14:28:34Z\",\"updated_at\":\"2024-10-02T14:28:34Z\"}," +
-		"{\"id\":\"9f9f4340-6bf9-4948-808c-ebf2dd60303d\",\"name\":\"Test Product B\",\"description\":\"Test Description B\",\"price\":200,\"created_at\":\"2024-10-02T14:28:34Z\",\"updated_at\":\"2024-10-02T14:28:34Z\"}" +
-		"]}\n")
-
-	tests := []struct {
-		name                        string
-		limit                       string
-		offset                      string
-		expServiceGetProductsResult []*app.Product
-		expServiceGetProductsError  error
-		expStatus                   int
-		expResponse                 []byte
-	}{
-		{
-			name:                        "product retrieved successfully",
-			limit:                       "10",
-			offset:                      "0",
-			expServiceGetProductsResult: []*app.Product{productA, productB},
-			expServiceGetProductsError:  nil,
-			expStatus:                   http.StatusOK,
-			expResponse:                 responseBody,
-		},
-		{
-			name:                        "product retrieved successfully with default limit and offset",
-			limit:                       "",
-			offset:                      "",
-			expServiceGetProductsResult: []*app.Product{productA, productB},
-			expServiceGetProductsError:  nil,
-			expStatus:                   http.StatusOK,
-			expResponse:                 responseBody,
-		},
-		{
-			name:                        "product could not be retrieved",
-			limit:                       "10",
-			offset:                      "0",
-			expServiceGetProductsResult: nil,
-			expServiceGetProductsError:  errors.New("service error"),
-			expStatus:                   http.StatusInternalServerError,
-			expResponse:                 []byte("an error occurred\n"),
-		},
-	}
-
-	for _, tt := range tests {
-		mockService := NewMockservice(ctrl)
-
-		limit := tt.limit
-		if limit == "" {
-			limit = "5"
-		}
-		limitNumber, _ := strconv.Atoi(limit)
-
-		offset := tt.offset
-		if offset == "" {
-			offset = "0"
-		}
-		offsetNumber, _ := strconv.Atoi(offset)
-
-		mockService.
-			EXPECT().
-			GetProducts(gomock.Any(), limitNumber, offsetNumber).
-			Return(tt.expServiceGetProductsResult, tt.expServiceGetProductsError)
-
-		router, err := NewRouter(mockService)
-		assert.NoError(t, err)
-		assert.NotNil(t, router)
-
-		url := fmt.Sprintf("/api/v1/products?limit=%s&offset=%s", tt.limit, tt.offset)
-		req := httptest.NewRequest(http.MethodGet, url, nil)
-
-		recorder := httptest.NewRecorder()
-
-		router.getProductsHandler(recorder, req)
-
-		assert.Equal(t, tt.expStatus, recorder.Code)
-
-		b, err := io.ReadAll(recorder.Body)
-		assert.NoError(t, err)
-
-		assert.Equal(t, tt.expResponse, b)
-	}
-}
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/simpler-tha/internal/app"
+	"github.com/simpler-tha/internal/config"
+)
+
+func TestNewRouter(t *testing.T) {
+	r, err := NewRouter(nil, config.HTTP{})
+	assert.EqualError(t, err, "service cannot be nil")
+	assert.Empty(t, r)
+}
+
+func TestRouter_createProductHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	reqBody := []byte(`{"name":"Test Product","description":"Test Description","price":100.0}`)
+
+	createProductDTO := app.CreateProductDTO{
+		Name:        "Test Product",
+		Description: "Test Description",
+		Price:       app.NewMoney(10000, "USD"),
+	}
+
+	productID, _ := uuid.Parse("9f9f4340-6bf9-4948-808c-ebf2dd604e2c")
+
+	now, _ := time.Parse(time.RFC3339, "2024-10-02T14:28:34Z")
+
+	product := &app.Product{
+		ID:          productID,
+		Name:        "Test Product",
+		Description: "Test Description",
+		Price:       app.NewMoney(10000, "USD"),
+		Version:     1,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	responseBody := []byte("{\"id\":\"9f9f4340-6bf9-4948-808c-ebf2dd604e2c\",\"sku\":null,\"slug\":\"\",\"status\":\"\",\"name\":\"Test Product\",\"description\":\"Test Description\",\"price\":\"100.00\",\"currency\":\"USD\",\"tags\":[],\"attributes\":{},\"version\":1,\"created_at\":\"2024-10-02T14:28:34Z\",\"updated_at\":\"2024-10-02T14:28:34Z\"}\n")
+
+	tests := []struct {
+		name                          string
+		reqBody                       []byte
+		expServiceCreateProductResult *app.Product
+		expServiceCreateProductError  error
+		expStatus                     int
+		expResponse                   []byte
+	}{
+		{
+			name:                          "product created successfully",
+			reqBody:                       reqBody,
+			expServiceCreateProductResult: product,
+			expServiceCreateProductError:  nil,
+			expStatus:                     http.StatusCreated,
+			expResponse:                   responseBody,
+		},
+		{
+			name:                          "product could not be created",
+			reqBody:                       reqBody,
+			expServiceCreateProductResult: nil,
+			expServiceCreateProductError:  errors.New("service error"),
+			expStatus:                     http.StatusInternalServerError,
+			expResponse:                   []byte("{\"type\":\"/problems/internal-error\",\"title\":\"Internal server error\",\"status\":500,\"detail\":\"An unexpected error occurred.\",\"instance\":\"/api/v1/products\"}\n"),
+		},
+		{
+			name:                          "product conflicts with an existing one",
+			reqBody:                       reqBody,
+			expServiceCreateProductResult: nil,
+			expServiceCreateProductError:  fmt.Errorf("failed to create product: %w", app.ErrConflict),
+			expStatus:                     http.StatusConflict,
+			expResponse:                   []byte("{\"type\":\"/problems/conflict\",\"title\":\"Resource conflict\",\"status\":409,\"detail\":\"The request conflicts with the current state of the resource.\",\"instance\":\"/api/v1/products\"}\n"),
+		},
+		{
+			name:                          "database unavailable",
+			reqBody:                       reqBody,
+			expServiceCreateProductResult: nil,
+			expServiceCreateProductError:  fmt.Errorf("failed to create product: %w", app.ErrUnavailable),
+			expStatus:                     http.StatusServiceUnavailable,
+			expResponse:                   []byte("{\"type\":\"/problems/service-unavailable\",\"title\":\"Service unavailable\",\"status\":503,\"detail\":\"The service is temporarily unavailable, please retry later.\",\"instance\":\"/api/v1/products\"}\n"),
+		},
+		{
+			name:                          "invalid request body",
+			reqBody:                       []byte(`{`),
+			expServiceCreateProductResult: nil,
+			expServiceCreateProductError:  nil,
+			expStatus:                     http.StatusBadRequest,
+			expResponse:                   []byte("{\"type\":\"/problems/invalid-request-body\",\"title\":\"Invalid request body\",\"status\":400,\"detail\":\"The request body is not valid JSON.\",\"instance\":\"/api/v1/products\"}\n"),
+		},
+		{
+			name:                          "request body field of the wrong type",
+			reqBody:                       []byte(`{"name":123,"price":"1.00"}`),
+			expServiceCreateProductResult: nil,
+			expServiceCreateProductError:  nil,
+			expStatus:                     http.StatusBadRequest,
+			expResponse:                   []byte("{\"type\":\"/problems/invalid-request-body\",\"title\":\"Invalid request body\",\"status\":400,\"detail\":\"The request body contains a field of the wrong type.\",\"instance\":\"/api/v1/products\",\"errors\":[{\"field\":\"name\",\"message\":\"must be of type string\"}]}\n"),
+		},
+		{
+			name:                          "price with too many decimals",
+			reqBody:                       []byte(`{"name":"Test Product","price":"19.999"}`),
+			expServiceCreateProductResult: nil,
+			expServiceCreateProductError:  nil,
+			expStatus:                     http.StatusUnprocessableEntity,
+			expResponse:                   []byte("{\"type\":\"/problems/validation-failed\",\"title\":\"Validation failed\",\"status\":422,\"detail\":\"The request contains invalid data.\",\"instance\":\"/api/v1/products\",\"errors\":[{\"field\":\"price\",\"message\":\"must be a decimal number with at most 2 decimal places\"}]}\n"),
+		},
+		{
+			name:                          "unsupported currency",
+			reqBody:                       []byte(`{"name":"Test Product","price":"19.99","currency":"XBT"}`),
+			expServiceCreateProductResult: nil,
+			expServiceCreateProductError:  nil,
+			expStatus:                     http.StatusUnprocessableEntity,
+			expResponse:                   []byte("{\"type\":\"/problems/validation-failed\",\"title\":\"Validation failed\",\"status\":422,\"detail\":\"The request contains invalid data.\",\"instance\":\"/api/v1/products\",\"errors\":[{\"field\":\"currency\",\"message\":\"must be a supported ISO 4217 currency code\"}]}\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		mockService := NewMockservice(ctrl)
+
+		if tt.expServiceCreateProductResult != nil || tt.expServiceCreateProductError != nil {
+			mockService.
+				EXPECT().
+				CreateProduct(gomock.Any(), createProductDTO).
+				Return(tt.expServiceCreateProductResult, tt.expServiceCreateProductError)
+		}
+
+		router, err := NewRouter(mockService, config.HTTP{})
+		assert.NoError(t, err)
+		assert.NotNil(t, router)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/products", bytes.NewBuffer(tt.reqBody))
+
+		recorder := httptest.NewRecorder()
+
+		router.createProductHandler(recorder, req)
+
+		assert.Equal(t, tt.expStatus, recorder.Code)
+		if tt.expStatus >= http.StatusBadRequest {
+			assert.Equal(t, problemContentType, recorder.Header().Get("Content-Type"))
+		} else {
+			assert.Equal(t, `"1"`, recorder.Header().Get("ETag"))
+		}
+
+		b, err := io.ReadAll(recorder.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, tt.expResponse, b)
+	}
+}
+
+func TestRouter_updateProductHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	reqBody := []byte(`{"name":"Test Product","description":"Test Description","price":"100.00","currency":"USD"}`)
+
+	productID, _ := uuid.Parse("9f9f4340-6bf9-4948-808c-ebf2dd604e2c")
+
+	updateProductDTO := app.UpdateProductDTO{
+		ID:          productID,
+		Name:        "Test Product",
+		Description: "Test Description",
+		Price:       app.NewMoney(10000, "USD"),
+	}
+
+	now, _ := time.Parse(time.RFC3339, "2024-10-02T14:28:34Z")
+
+	product := &app.Product{
+		ID:          productID,
+		Name:        "Test Product",
+		Description: "Test Description",
+		Price:       app.NewMoney(10000, "USD"),
+		Version:     1,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	responseBody := []byte("{\"id\":\"9f9f4340-6bf9-4948-808c-ebf2dd604e2c\",\"sku\":null,\"slug\":\"\",\"status\":\"\",\"name\":\"Test Product\",\"description\":\"Test Description\",\"price\":\"100.00\",\"currency\":\"USD\",\"tags\":[],\"attributes\":{},\"version\":1,\"created_at\":\"2024-10-02T14:28:34Z\",\"updated_at\":\"2024-10-02T14:28:34Z\"}\n")
+
+	tests := []struct {
+		name                          string
+		productID                     string
+		reqBody                       []byte
+		expServiceUpdateProductResult *app.Product
+		expServiceUpdateProductError  error
+		expStatus                     int
+		expResponse                   []byte
+	}{
+		{
+			name:                          "product updated successfully",
+			productID:                     productID.String(),
+			reqBody:                       reqBody,
+			expServiceUpdateProductResult: product,
+			expServiceUpdateProductError:  nil,
+			expStatus:                     http.StatusOK,
+			expResponse:                   responseBody,
+		},
+		{
+			name:                          "product could not be updated",
+			productID:                     productID.String(),
+			reqBody:                       reqBody,
+			expServiceUpdateProductResult: nil,
+			expServiceUpdateProductError:  errors.New("service error"),
+			expStatus:                     http.StatusInternalServerError,
+			expResponse:                   []byte("{\"type\":\"/problems/internal-error\",\"title\":\"Internal server error\",\"status\":500,\"detail\":\"An unexpected error occurred.\",\"instance\":\"/api/v1/products\"}\n"),
+		},
+		{
+			name:                          "product not found",
+			productID:                     productID.String(),
+			reqBody:                       reqBody,
+			expServiceUpdateProductResult: nil,
+			expServiceUpdateProductError:  fmt.Errorf("failed to get product: %w", app.ErrNotFound),
+			expStatus:                     http.StatusNotFound,
+			expResponse:                   []byte("{\"type\":\"/problems/not-found\",\"title\":\"Resource not found\",\"status\":404,\"detail\":\"The requested resource does not exist.\",\"instance\":\"/api/v1/products\"}\n"),
+		},
+		{
+			name:                          "invalid product data",
+			productID:                     productID.String(),
+			reqBody:                       reqBody,
+			expServiceUpdateProductResult: nil,
+			expServiceUpdateProductError:  fmt.Errorf("failed to update product: %w", app.ErrValidation),
+			expStatus:                     http.StatusUnprocessableEntity,
+			expResponse:                   []byte("{\"type\":\"/problems/validation-failed\",\"title\":\"Validation failed\",\"status\":422,\"detail\":\"The request contains invalid data.\",\"instance\":\"/api/v1/products\"}\n"),
+		},
+		{
+			name:                          "product fields fail validation",
+			productID:                     productID.String(),
+			reqBody:                       reqBody,
+			expServiceUpdateProductResult: nil,
+			expServiceUpdateProductError: fmt.Errorf("invalid product: %w", &app.ValidationError{Violations: []app.Violation{
+				{Field: "name", Message: "must not be empty"},
+				{Field: "price", Message: "must not be negative"},
+			}}),
+			expStatus:   http.StatusUnprocessableEntity,
+			expResponse: []byte("{\"type\":\"/problems/validation-failed\",\"title\":\"Validation failed\",\"status\":422,\"detail\":\"The request contains invalid data.\",\"instance\":\"/api/v1/products\",\"errors\":[{\"field\":\"name\",\"message\":\"must not be empty\"},{\"field\":\"price\",\"message\":\"must not be negative\"}]}\n"),
+		},
+		{
+			name:                          "invalid request body",
+			productID:                     productID.String(),
+			reqBody:                       []byte(`{`),
+			expServiceUpdateProductResult: nil,
+			expServiceUpdateProductError:  nil,
+			expStatus:                     http.StatusBadRequest,
+			expResponse:                   []byte("{\"type\":\"/problems/invalid-request-body\",\"title\":\"Invalid request body\",\"status\":400,\"detail\":\"The request body is not valid JSON.\",\"instance\":\"/api/v1/products\"}\n"),
+		},
+		{
+			name:                          "invalid product ID",
+			productID:                     "",
+			reqBody:                       reqBody,
+			expServiceUpdateProductResult: nil,
+			expServiceUpdateProductError:  nil,
+			expStatus:                     http.StatusBadRequest,
+			expResponse:                   []byte("{\"type\":\"/problems/invalid-parameter\",\"title\":\"Invalid request parameter\",\"status\":400,\"detail\":\"The product_id path parameter is invalid.\",\"instance\":\"/api/v1/products\",\"errors\":[{\"field\":\"product_id\",\"message\":\"must be a valid UUID\"}]}\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		mockService := NewMockservice(ctrl)
+
+		if tt.expServiceUpdateProductResult != nil || tt.expServiceUpdateProductError != nil {
+			mockService.
+				EXPECT().
+				UpdateProduct(gomock.Any(), updateProductDTO).
+				Return(tt.expServiceUpdateProductResult, tt.expServiceUpdateProductError)
+		}
+
+		router, err := NewRouter(mockService, config.HTTP{})
+		assert.NoError(t, err)
+		assert.NotNil(t, router)
+
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/products", bytes.NewBuffer(tt.reqBody))
+		if tt.productID != "" {
+			req.SetPathValue("product_id", tt.productID)
+		}
+
+		recorder := httptest.NewRecorder()
+
+		router.updateProductHandler(recorder, req)
+
+		assert.Equal(t, tt.expStatus, recorder.Code)
+		if tt.expStatus >= http.StatusBadRequest {
+			assert.Equal(t, problemContentType, recorder.Header().Get("Content-Type"))
+		} else {
+			assert.Equal(t, `"1"`, recorder.Header().Get("ETag"))
+		}
+
+		b, err := io.ReadAll(recorder.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, tt.expResponse, b)
+	}
+}
+
+func TestRouter_patchProductHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	reqBody := []byte(`{"name":"Test Product"}`)
+
+	productID, _ := uuid.Parse("9f9f4340-6bf9-4948-808c-ebf2dd604e2c")
+
+	patchProductDTO := app.PatchProductDTO{
+		ID:     productID,
+		Format: app.PatchFormatMergePatch,
+		Patch:  reqBody,
+	}
+
+	now, _ := time.Parse(time.RFC3339, "2024-10-02T14:28:34Z")
+
+	product := &app.Product{
+		ID:          productID,
+		Name:        "Test Product",
+		Description: "Test Description",
+		Price:       app.NewMoney(10000, "USD"),
+		Version:     1,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	responseBody := []byte("{\"id\":\"9f9f4340-6bf9-4948-808c-ebf2dd604e2c\",\"sku\":null,\"slug\":\"\",\"status\":\"\",\"name\":\"Test Product\",\"description\":\"Test Description\",\"price\":\"100.00\",\"currency\":\"USD\",\"tags\":[],\"attributes\":{},\"version\":1,\"created_at\":\"2024-10-02T14:28:34Z\",\"updated_at\":\"2024-10-02T14:28:34Z\"}\n")
+
+	tests := []struct {
+		name                         string
+		productID                    string
+		contentType                  string
+		expServicePatchProductResult *app.Product
+		expServicePatchProductError  error
+		expStatus                    int
+		expAcceptPatch               string
+		expResponse                  []byte
+	}{
+		{
+			name:                         "product patched successfully",
+			productID:                    productID.String(),
+			contentType:                  "application/merge-patch+json; charset=utf-8",
+			expServicePatchProductResult: product,
+			expStatus:                    http.StatusOK,
+			expResponse:                  responseBody,
+		},
+		{
+			name:                        "invalid patch document",
+			productID:                   productID.String(),
+			contentType:                 "application/merge-patch+json",
+			expServicePatchProductError: fmt.Errorf("failed to apply patch: %w", app.ErrInvalidPatch),
+			expStatus:                   http.StatusBadRequest,
+			expResponse:                 []byte("{\"type\":\"/problems/invalid-patch\",\"title\":\"Invalid patch document\",\"status\":400,\"detail\":\"The patch document is malformed or cannot be applied to the resource.\",\"instance\":\"/api/v1/products/{product_id}\"}\n"),
+		},
+		{
+			name:                        "product not found",
+			productID:                   productID.String(),
+			contentType:                 "application/merge-patch+json",
+			expServicePatchProductError: fmt.Errorf("failed to get product: %w", app.ErrNotFound),
+			expStatus:                   http.StatusNotFound,
+			expResponse:                 []byte("{\"type\":\"/problems/not-found\",\"title\":\"Resource not found\",\"status\":404,\"detail\":\"The requested resource does not exist.\",\"instance\":\"/api/v1/products/{product_id}\"}\n"),
+		},
+		{
+			name:           "unsupported content type",
+			productID:      productID.String(),
+			contentType:    "application/json",
+			expStatus:      http.StatusUnsupportedMediaType,
+			expAcceptPatch: "application/merge-patch+json, application/json-patch+json",
+			expResponse:    []byte("{\"type\":\"/problems/unsupported-media-type\",\"title\":\"Unsupported media type\",\"status\":415,\"detail\":\"The request body must be a JSON Merge Patch or a JSON Patch document.\",\"instance\":\"/api/v1/products/{product_id}\"}\n"),
+		},
+		{
+			name:        "invalid product ID",
+			productID:   "",
+			contentType: "application/merge-patch+json",
+			expStatus:   http.StatusBadRequest,
+			expResponse: []byte("{\"type\":\"/problems/invalid-parameter\",\"title\":\"Invalid request parameter\",\"status\":400,\"detail\":\"The product_id path parameter is invalid.\",\"instance\":\"/api/v1/products/{product_id}\",\"errors\":[{\"field\":\"product_id\",\"message\":\"must be a valid UUID\"}]}\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		mockService := NewMockservice(ctrl)
+
+		if tt.expServicePatchProductResult != nil || tt.expServicePatchProductError != nil {
+			mockService.
+				EXPECT().
+				PatchProduct(gomock.Any(), patchProductDTO).
+				Return(tt.expServicePatchProductResult, tt.expServicePatchProductError)
+		}
+
+		router, err := NewRouter(mockService, config.HTTP{})
+		assert.NoError(t, err)
+		assert.NotNil(t, router)
+
+		req := httptest.NewRequest(http.MethodPatch, "/api/v1/products/{product_id}", bytes.NewBuffer(reqBody))
+		req.Header.Set("Content-Type", tt.contentType)
+		if tt.productID != "" {
+			req.SetPathValue("product_id", tt.productID)
+		}
+
+		recorder := httptest.NewRecorder()
+
+		router.patchProductHandler(recorder, req)
+
+		assert.Equal(t, tt.expStatus, recorder.Code)
+		if tt.expStatus >= http.StatusBadRequest {
+			assert.Equal(t, problemContentType, recorder.Header().Get("Content-Type"))
+		}
+		assert.Equal(t, tt.expAcceptPatch, recorder.Header().Get("Accept-Patch"))
+
+		b, err := io.ReadAll(recorder.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, tt.expResponse, b)
+	}
+}
+
+func TestRouter_deleteProductHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	productID, _ := uuid.Parse("9f9f4340-6bf9-4948-808c-ebf2dd604e2c")
+
+	tests := []struct {
+		name                         string
+		productID                    string
+		ifMatch                      string
+		requireIfMatch               bool
+		expServiceDeleteProductDTO   *app.DeleteProductDTO
+		expServiceDeleteProductError error
+		expStatus                    int
+		expResponse                  []byte
+	}{
+		{
+			name:                       "product deleted successfully",
+			productID:                  productID.String(),
+			expServiceDeleteProductDTO: &app.DeleteProductDTO{ID: productID},
+			expStatus:                  http.StatusNoContent,
+			expResponse:                []byte{},
+		},
+		{
+			name:                       "product deleted conditionally",
+			productID:                  productID.String(),
+			ifMatch:                    `"3"`,
+			requireIfMatch:             true,
+			expServiceDeleteProductDTO: &app.DeleteProductDTO{ID: productID, ExpectedVersion: 3},
+			expStatus:                  http.StatusNoContent,
+			expResponse:                []byte{},
+		},
+		{
+			name:                       "product deleted with wildcard If-Match",
+			productID:                  productID.String(),
+			ifMatch:                    "*",
+			expServiceDeleteProductDTO: &app.DeleteProductDTO{ID: productID},
+			expStatus:                  http.StatusNoContent,
+			expResponse:                []byte{},
+		},
+		{
+			name:                         "product could not be deleted",
+			productID:                    productID.String(),
+			expServiceDeleteProductDTO:   &app.DeleteProductDTO{ID: productID},
+			expServiceDeleteProductError: errors.New("service error"),
+			expStatus:                    http.StatusInternalServerError,
+			expResponse:                  []byte("{\"type\":\"/problems/internal-error\",\"title\":\"Internal server error\",\"status\":500,\"detail\":\"An unexpected error occurred.\",\"instance\":\"/api/v1/products/{product_id}\"}\n"),
+		},
+		{
+			name:                         "product not found",
+			productID:                    productID.String(),
+			expServiceDeleteProductDTO:   &app.DeleteProductDTO{ID: productID},
+			expServiceDeleteProductError: fmt.Errorf("failed to delete product: %w", app.ErrNotFound),
+			expStatus:                    http.StatusNotFound,
+			expResponse:                  []byte("{\"type\":\"/problems/not-found\",\"title\":\"Resource not found\",\"status\":404,\"detail\":\"The requested resource does not exist.\",\"instance\":\"/api/v1/products/{product_id}\"}\n"),
+		},
+		{
+			name:                         "product version has changed",
+			productID:                    productID.String(),
+			ifMatch:                      `"3"`,
+			expServiceDeleteProductDTO:   &app.DeleteProductDTO{ID: productID, ExpectedVersion: 3},
+			expServiceDeleteProductError: fmt.Errorf("failed to delete product: %w", app.ErrPreconditionFailed),
+			expStatus:                    http.StatusPreconditionFailed,
+			expResponse:                  []byte("{\"type\":\"/problems/precondition-failed\",\"title\":\"Precondition failed\",\"status\":412,\"detail\":\"The resource has changed since it was last retrieved.\",\"instance\":\"/api/v1/products/{product_id}\"}\n"),
+		},
+		{
+			name:        "weak entity tag never matches",
+			productID:   productID.String(),
+			ifMatch:     `W/"3"`,
+			expStatus:   http.StatusPreconditionFailed,
+			expResponse: []byte("{\"type\":\"/problems/precondition-failed\",\"title\":\"Precondition failed\",\"status\":412,\"detail\":\"The entity tag in If-Match does not match the current version of the resource.\",\"instance\":\"/api/v1/products/{product_id}\"}\n"),
+		},
+		{
+			name:        "malformed If-Match",
+			productID:   productID.String(),
+			ifMatch:     `"3", "4"`,
+			expStatus:   http.StatusBadRequest,
+			expResponse: []byte("{\"type\":\"/problems/invalid-parameter\",\"title\":\"Invalid request parameter\",\"status\":400,\"detail\":\"The If-Match header must contain a single entity tag or *.\",\"instance\":\"/api/v1/products/{product_id}\",\"errors\":[{\"field\":\"If-Match\",\"message\":\"must be a single quoted entity tag or *\"}]}\n"),
+		},
+		{
+			name:           "missing required If-Match",
+			productID:      productID.String(),
+			requireIfMatch: true,
+			expStatus:      http.StatusPreconditionRequired,
+			expResponse:    []byte("{\"type\":\"/problems/precondition-required\",\"title\":\"Precondition required\",\"status\":428,\"detail\":\"This request must be made conditional with an If-Match header.\",\"instance\":\"/api/v1/products/{product_id}\"}\n"),
+		},
+		{
+			name:        "invalid product ID",
+			productID:   "",
+			expStatus:   http.StatusBadRequest,
+			expResponse: []byte("{\"type\":\"/problems/invalid-parameter\",\"title\":\"Invalid request parameter\",\"status\":400,\"detail\":\"The product_id path parameter is invalid.\",\"instance\":\"/api/v1/products/{product_id}\",\"errors\":[{\"field\":\"product_id\",\"message\":\"must be a valid UUID\"}]}\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		mockService := NewMockservice(ctrl)
+
+		if tt.expServiceDeleteProductDTO != nil {
+			mockService.
+				EXPECT().
+				DeleteProduct(gomock.Any(), *tt.expServiceDeleteProductDTO).
+				Return(tt.expServiceDeleteProductError)
+		}
+
+		router, err := NewRouter(mockService, config.HTTP{RequireIfMatch: tt.requireIfMatch})
+		assert.NoError(t, err)
+		assert.NotNil(t, router)
+
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/products/{product_id}", nil)
+		if tt.productID != "" {
+			req.SetPathValue("product_id", tt.productID)
+		}
+		if tt.ifMatch != "" {
+			req.Header.Set("If-Match", tt.ifMatch)
+		}
+
+		recorder := httptest.NewRecorder()
+
+		router.deleteProductHandler(recorder, req)
+
+		assert.Equal(t, tt.expStatus, recorder.Code)
+		if tt.expStatus >= http.StatusBadRequest {
+			assert.Equal(t, problemContentType, recorder.Header().Get("Content-Type"))
+		}
+
+		b, err := io.ReadAll(recorder.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, tt.expResponse, b)
+	}
+}
+
+func TestRouter_restoreProductHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	productID, _ := uuid.Parse("9f9f4340-6bf9-4948-808c-ebf2dd604e2c")
+
+	now, _ := time.Parse(time.RFC3339, "2024-10-02T14:28:34Z")
+
+	tests := []struct {
+		name                           string
+		ifMatch                        string
+		expServiceRestoreProductDTO    app.RestoreProductDTO
+		expServiceRestoreProductResult *app.Product
+		expServiceRestoreProductError  error
+		expStatus                      int
+		expETag                        string
+		expResponse                    []byte
+	}{
+		{
+			name:                        "product restored successfully",
+			ifMatch:                     `"2"`,
+			expServiceRestoreProductDTO: app.RestoreProductDTO{ID: productID, ExpectedVersion: 2},
+			expServiceRestoreProductResult: &app.Product{
+				ID:          productID,
+				Slug:        "test-product",
+				Status:      app.ProductPublished,
+				Name:        "Test Product",
+				Description: "Test Description",
+				Price:       app.NewMoney(10000, "USD"),
+				Version:     3,
+				CreatedAt:   now,
+				UpdatedAt:   now,
+			},
+			expStatus:   http.StatusOK,
+			expETag:     `"3"`,
+			expResponse: []byte("{\"id\":\"9f9f4340-6bf9-4948-808c-ebf2dd604e2c\",\"sku\":null,\"slug\":\"test-product\",\"status\":\"published\",\"name\":\"Test Product\",\"description\":\"Test Description\",\"price\":\"100.00\",\"currency\":\"USD\",\"tags\":[],\"attributes\":{},\"version\":3,\"created_at\":\"2024-10-02T14:28:34Z\",\"updated_at\":\"2024-10-02T14:28:34Z\"}\n"),
+		},
+		{
+			name:                          "product is not deleted",
+			expServiceRestoreProductDTO:   app.RestoreProductDTO{ID: productID},
+			expServiceRestoreProductError: fmt.Errorf("failed to restore product: %w", app.ErrConflict),
+			expStatus:                     http.StatusConflict,
+			expResponse:                   []byte("{\"type\":\"/problems/conflict\",\"title\":\"Resource conflict\",\"status\":409,\"detail\":\"The request conflicts with the current state of the resource.\",\"instance\":\"/api/v1/products/9f9f4340-6bf9-4948-808c-ebf2dd604e2c/restore\"}\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		mockService := NewMockservice(ctrl)
+		mockService.
+			EXPECT().
+			RestoreProduct(gomock.Any(), tt.expServiceRestoreProductDTO).
+			Return(tt.expServiceRestoreProductResult, tt.expServiceRestoreProductError)
+
+		router, err := NewRouter(mockService, config.HTTP{})
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/products/"+productID.String()+"/restore", nil)
+		req.SetPathValue("product_id", productID.String())
+		if tt.ifMatch != "" {
+			req.Header.Set("If-Match", tt.ifMatch)
+		}
+		recorder := httptest.NewRecorder()
+
+		router.restoreProductHandler(recorder, req)
+
+		assert.Equal(t, tt.expStatus, recorder.Code)
+		assert.Equal(t, tt.expETag, recorder.Header().Get("ETag"))
+
+		b, err := io.ReadAll(recorder.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, tt.expResponse, b)
+	}
+}
+
+func TestRouter_getProductHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	productID, _ := uuid.Parse("9f9f4340-6bf9-4948-808c-ebf2dd604e2c")
+
+	now, _ := time.Parse(time.RFC3339, "2024-10-02T14:28:34Z")
+
+	product := &app.Product{
+		ID:          productID,
+		Name:        "Test Product",
+		Description: "Test Description",
+		Price:       app.NewMoney(10000, "USD"),
+		Version:     1,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	responseBody := []byte("{\"id\":\"9f9f4340-6bf9-4948-808c-ebf2dd604e2c\",\"sku\":null,\"slug\":\"\",\"status\":\"\",\"name\":\"Test Product\",\"description\":\"Test Description\",\"price\":\"100.00\",\"currency\":\"USD\",\"tags\":[],\"attributes\":{},\"version\":1,\"created_at\":\"2024-10-02T14:28:34Z\",\"updated_at\":\"2024-10-02T14:28:34Z\"}\n")
+
+	tests := []struct {
+		name                       string
+		productID                  string
+		query                      string
+		header                     http.Header
+		expServiceGetProductAsOf   time.Time
+		expServiceGetProductResult *app.Product
+		expServiceGetProductError  error
+		expStatus                  int
+		expResponse                []byte
+	}{
+		{
+			name:                       "product retrieved successfully",
+			productID:                  productID.String(),
+			expServiceGetProductResult: product,
+			expServiceGetProductError:  nil,
+			expStatus:                  http.StatusOK,
+			expResponse:                responseBody,
+		},
+		{
+			name:                       "matching If-None-Match",
+			productID:                  productID.String(),
+			header:                     http.Header{"If-None-Match": {`"2", W/"1"`}},
+			expServiceGetProductResult: product,
+			expStatus:                  http.StatusNotModified,
+			expResponse:                []byte{},
+		},
+		{
+			name:                       "stale If-None-Match",
+			productID:                  productID.String(),
+			header:                     http.Header{"If-None-Match": {`"2"`}, "If-Modified-Since": {"Wed, 02 Oct 2024 14:28:34 GMT"}},
+			expServiceGetProductResult: product,
+			expStatus:                  http.StatusOK,
+			expResponse:                responseBody,
+		},
+		{
+			name:                       "not modified since",
+			productID:                  productID.String(),
+			header:                     http.Header{"If-Modified-Since": {"Wed, 02 Oct 2024 14:28:34 GMT"}},
+			expServiceGetProductResult: product,
+			expStatus:                  http.StatusNotModified,
+			expResponse:                []byte{},
+		},
+		{
+			name:                       "modified since",
+			productID:                  productID.String(),
+			header:                     http.Header{"If-Modified-Since": {"Wed, 02 Oct 2024 14:28:33 GMT"}},
+			expServiceGetProductResult: product,
+			expStatus:                  http.StatusOK,
+			expResponse:                responseBody,
+		},
+		{
+			name:                       "product could not be retrieved",
+			productID:                  productID.String(),
+			expServiceGetProductResult: nil,
+			expServiceGetProductError:  errors.New("service error"),
+			expStatus:                  http.StatusInternalServerError,
+			expResponse:                []byte("{\"type\":\"/problems/internal-error\",\"title\":\"Internal server error\",\"status\":500,\"detail\":\"An unexpected error occurred.\",\"instance\":\"/api/v1/products/{product_id}\"}\n"),
+		},
+		{
+			name:                       "product not found",
+			productID:                  productID.String(),
+			expServiceGetProductResult: nil,
+			expServiceGetProductError:  fmt.Errorf("failed to get product: %w", app.ErrNotFound),
+			expStatus:                  http.StatusNotFound,
+			expResponse:                []byte("{\"type\":\"/problems/not-found\",\"title\":\"Resource not found\",\"status\":404,\"detail\":\"The requested resource does not exist.\",\"instance\":\"/api/v1/products/{product_id}\"}\n"),
+		},
+		{
+			name:                       "product as it was at an instant",
+			productID:                  productID.String(),
+			query:                      "?as_of=2024-10-31T23:59:59Z",
+			expServiceGetProductAsOf:   time.Date(2024, 10, 31, 23, 59, 59, 0, time.UTC),
+			expServiceGetProductResult: product,
+			expStatus:                  http.StatusOK,
+			expResponse:                responseBody,
+		},
+		{
+			name:        "invalid as_of",
+			productID:   productID.String(),
+			query:       "?as_of=2024-10-31",
+			expStatus:   http.StatusBadRequest,
+			expResponse: []byte("{\"type\":\"/problems/invalid-parameter\",\"title\":\"Invalid request parameter\",\"status\":400,\"detail\":\"The query parameters are invalid.\",\"instance\":\"/api/v1/products/{product_id}\",\"errors\":[{\"field\":\"as_of\",\"message\":\"must be an RFC 3339 timestamp\"}]}\n"),
+		},
+		{
+			name:                      "invalid product ID",
+			productID:                 "",
+			expServiceGetProductError: nil,
+			expStatus:                 http.StatusBadRequest,
+			expResponse:               []byte("{\"type\":\"/problems/invalid-parameter\",\"title\":\"Invalid request parameter\",\"status\":400,\"detail\":\"The product_id path parameter is invalid.\",\"instance\":\"/api/v1/products/{product_id}\",\"errors\":[{\"field\":\"product_id\",\"message\":\"must be a valid UUID\"}]}\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		mockService := NewMockservice(ctrl)
+
+		switch {
+		case !tt.expServiceGetProductAsOf.IsZero():
+			mockService.
+				EXPECT().
+				GetProductAsOf(gomock.Any(), productID, tt.expServiceGetProductAsOf).
+				Return(tt.expServiceGetProductResult, tt.expServiceGetProductError)
+		case tt.productID != "" && tt.expStatus != http.StatusBadRequest:
+			mockService.
+				EXPECT().
+				GetProduct(gomock.Any(), productID).
+				Return(tt.expServiceGetProductResult, tt.expServiceGetProductError)
+		}
+
+		router, err := NewRouter(mockService, config.HTTP{ProductCacheControl: "public, max-age=60"})
+		assert.NoError(t, err)
+		assert.NotNil(t, router)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/products/{product_id}"+tt.query, nil)
+		if tt.productID != "" {
+			req.SetPathValue("product_id", tt.productID)
+		}
+		for k, v := range tt.header {
+			req.Header[k] = v
+		}
+
+		recorder := httptest.NewRecorder()
+
+		router.getProductHandler(recorder, req)
+
+		assert.Equal(t, tt.expStatus, recorder.Code)
+		if tt.expStatus >= http.StatusBadRequest {
+			assert.Equal(t, problemContentType, recorder.Header().Get("Content-Type"))
+		} else {
+			assert.Equal(t, `"1"`, recorder.Header().Get("ETag"))
+			assert.Equal(t, "Wed, 02 Oct 2024 14:28:34 GMT", recorder.Header().Get("Last-Modified"))
+			assert.Equal(t, "public, max-age=60", recorder.Header().Get("Cache-Control"))
+		}
+
+		b, err := io.ReadAll(recorder.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, tt.expResponse, b)
+	}
+}
+
+// TestRouter_productLookupRoutes routes the lookups through the mux of the
+// API, where SKUs and slugs named after the product subresources must not
+// reach the subresource handlers.
+func TestRouter_productLookupRoutes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	productID, _ := uuid.Parse("9f9f4340-6bf9-4948-808c-ebf2dd604e2c")
+
+	now, _ := time.Parse(time.RFC3339, "2024-10-02T14:28:34Z")
+
+	product := &app.Product{
+		ID:          productID,
+		SKU:         "TP/001",
+		Slug:        "test-product",
+		Name:        "Test Product",
+		Description: "Test Description",
+		Price:       app.NewMoney(10000, "USD"),
+		Version:     1,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	responseBody := []byte("{\"id\":\"9f9f4340-6bf9-4948-808c-ebf2dd604e2c\",\"sku\":\"TP/001\",\"slug\":\"test-product\",\"status\":\"\",\"name\":\"Test Product\",\"description\":\"Test Description\",\"price\":\"100.00\",\"currency\":\"USD\",\"tags\":[],\"attributes\":{},\"version\":1,\"created_at\":\"2024-10-02T14:28:34Z\",\"updated_at\":\"2024-10-02T14:28:34Z\"}\n")
+
+	tests := []struct {
+		name                       string
+		path                       string
+		expServiceGetProductBySKU  string
+		expServiceGetProductBySlug string
+		expServiceGetProductResult *app.Product
+		expServiceGetProductError  error
+		expServiceGetVariants      bool
+		expStatus                  int
+		expResponse                []byte
+	}{
+		{
+			name:                  "variants of a product are still served",
+			path:                  "/api/v1/products/9f9f4340-6bf9-4948-808c-ebf2dd604e2c/variants",
+			expServiceGetVariants: true,
+			expStatus:             http.StatusOK,
+			expResponse:           []byte("{\"variants\":[]}\n"),
+		},
+		{
+			name:                       "product found by SKU",
+			path:                       "/api/v1/products/by-sku/TP-001",
+			expServiceGetProductBySKU:  "TP-001",
+			expServiceGetProductResult: product,
+			expStatus:                  http.StatusOK,
+			expResponse:                responseBody,
+		},
+		{
+			name:                       "SKU with an escaped slash",
+			path:                       "/api/v1/products/by-sku/TP%2F001",
+			expServiceGetProductBySKU:  "TP/001",
+			expServiceGetProductResult: product,
+			expStatus:                  http.StatusOK,
+			expResponse:                responseBody,
+		},
+		{
+			name:                       "SKU named after the variants",
+			path:                       "/api/v1/products/by-sku/variants",
+			expServiceGetProductBySKU:  "variants",
+			expServiceGetProductResult: product,
+			expStatus:                  http.StatusOK,
+			expResponse:                responseBody,
+		},
+		{
+			name:                       "SKU named after the revisions",
+			path:                       "/api/v1/products/by-sku/revisions",
+			expServiceGetProductBySKU:  "revisions",
+			expServiceGetProductResult: product,
+			expStatus:                  http.StatusOK,
+			expResponse:                responseBody,
+		},
+		{
+			name:                       "SKU shaped like a subresource path",
+			path:                       "/api/v1/products/by-sku/variants%2F9f9f4340-6bf9-4948-808c-ebf2dd604e2c",
+			expServiceGetProductBySKU:  "variants/9f9f4340-6bf9-4948-808c-ebf2dd604e2c",
+			expServiceGetProductResult: product,
+			expStatus:                  http.StatusOK,
+			expResponse:                responseBody,
+		},
+		{
+			name:                       "product found by slug",
+			path:                       "/api/v1/products/by-slug/test-product",
+			expServiceGetProductBySlug: "test-product",
+			expServiceGetProductResult: product,
+			expStatus:                  http.StatusOK,
+			expResponse:                responseBody,
+		},
+		{
+			name:                       "slug named after the inventory",
+			path:                       "/api/v1/products/by-slug/inventory",
+			expServiceGetProductBySlug: "inventory",
+			expServiceGetProductResult: product,
+			expStatus:                  http.StatusOK,
+			expResponse:                responseBody,
+		},
+		{
+			name:                       "slug named after the categories",
+			path:                       "/api/v1/products/by-slug/categories",
+			expServiceGetProductBySlug: "categories",
+			expServiceGetProductResult: product,
+			expStatus:                  http.StatusOK,
+			expResponse:                responseBody,
+		},
+		{
+			name:                       "no product with the slug",
+			path:                       "/api/v1/products/by-slug/other-product",
+			expServiceGetProductBySlug: "other-product",
+			expServiceGetProductError:  fmt.Errorf("failed to get product: %w", app.ErrNotFound),
+			expStatus:                  http.StatusNotFound,
+			expResponse:                []byte("{\"type\":\"/problems/not-found\",\"title\":\"Resource not found\",\"status\":404,\"detail\":\"The requested resource does not exist.\",\"instance\":\"/api/v1/products/by-slug/other-product\"}\n"),
+		},
+		{
+			name:        "unknown lookup",
+			path:        "/api/v1/products/by-name/test-product",
+			expStatus:   http.StatusNotFound,
+			expResponse: []byte("{\"type\":\"/problems/not-found\",\"title\":\"Resource not found\",\"status\":404,\"detail\":\"The requested resource does not exist.\",\"instance\":\"/api/v1/products/by-name/test-product\"}\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		mockService := NewMockservice(ctrl)
+
+		if tt.expServiceGetProductBySKU != "" {
+			mockService.
+				EXPECT().
+				GetProductBySKU(gomock.Any(), tt.expServiceGetProductBySKU).
+				Return(tt.expServiceGetProductResult, tt.expServiceGetProductError)
+		}
+		if tt.expServiceGetProductBySlug != "" {
+			mockService.
+				EXPECT().
+				GetProductBySlug(gomock.Any(), tt.expServiceGetProductBySlug).
+				Return(tt.expServiceGetProductResult, tt.expServiceGetProductError)
+		}
+		if tt.expServiceGetVariants {
+			mockService.
+				EXPECT().
+				GetVariants(gomock.Any(), productID).
+				Return(nil, nil)
+		}
+
+		router, err := NewRouter(mockService, config.HTTP{})
+		assert.NoError(t, err)
+
+		mux := http.NewServeMux()
+		router.RegisterRoutes(mux)
+
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		recorder := httptest.NewRecorder()
+
+		mux.ServeHTTP(recorder, req)
+
+		assert.Equal(t, tt.expStatus, recorder.Code)
+
+		b, err := io.ReadAll(recorder.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, tt.expResponse, b)
+	}
+}
+
+func TestRouter_RegisterAdminRoutes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockService := NewMockservice(ctrl)
+	mockService.
+		EXPECT().
+		GetProducts(gomock.Any(), app.GetProductsDTO{
+			Filter: app.ProductFilter{IncludeDeleted: true},
+			Sort:   app.DefaultSort,
+			Limit:  getProductsDefaultLimit,
+		}).
+		Return(&app.ProductPage{}, nil)
+
+	router, err := NewRouter(mockService, config.HTTP{})
+	assert.NoError(t, err)
+
+	mux := http.NewServeMux()
+	router.RegisterRoutes(mux)
+	adminMux := http.NewServeMux()
+	router.RegisterAdminRoutes(adminMux)
+
+	for _, path := range []string{
+		"/api/v1/products?include_deleted=true",
+		"/api/v1/products/search?q=shirt&include_deleted=true",
+		"/api/v1/categories/9f9f4340-6bf9-4948-808c-ebf2dd604e2c/products?include_deleted=true",
+	} {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusForbidden, recorder.Code, path)
+	}
+
+	recorder := httptest.NewRecorder()
+	adminMux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/products?include_deleted=true", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	// The other routes of the API are not served by the admin listener.
+	recorder = httptest.NewRecorder()
+	adminMux.ServeHTTP(recorder, httptest.NewRequest(http.MethodDelete, "/api/v1/products/9f9f4340-6bf9-4948-808c-ebf2dd604e2c", nil))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestRouter_getProductsHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	productIDA, _ := uuid.Parse("9f9f4340-6bf9-4948-808c-ebf2dd604e2c")
+	productIDB, _ := uuid.Parse("9f9f4340-6bf9-4948-808c-ebf2dd60303d")
+
+	now, _ := time.Parse(time.RFC3339, "2024-10-02T14:28:34Z")
+
+	productA := &app.Product{
+		ID:          productIDA,
+		Name:        "Test Product A",
+		Description: "Test Description A",
+		Price:       app.NewMoney(10000, "USD"),
+		Version:     1,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	productB := &app.Product{
+		ID:          productIDB,
+		Name:        "Test Product B",
+		Description: "Test Description B",
+		Price:       app.NewMoney(20000, "USD"),
+		Version:     1,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	responseBody := func(rest string) []byte {
+		return []byte("{\"products\":[" +
+			"{\"id\":\"9f9f4340-6bf9-4948-808c-ebf2dd604e2c\",\"sku\":null,\"slug\":\"\",\"status\":\"\",\"name\":\"Test Product A\",\"description\":\"Test Description A\",\"price\":\"100.00\",\"currency\":\"USD\",\"tags\":[],\"attributes\":{},\"version\":1,\"created_at\":\"2024-10-02T14:28:34Z\",\"updated_at\":\"2024-10-02T14:28:34Z\"}," +
+			"{\"id\":\"9f9f4340-6bf9-4948-808c-ebf2dd60303d\",\"sku\":null,\"slug\":\"\",\"status\":\"\",\"name\":\"Test Product B\",\"description\":\"Test Description B\",\"price\":\"200.00\",\"currency\":\"USD\",\"tags\":[],\"attributes\":{},\"version\":1,\"created_at\":\"2024-10-02T14:28:34Z\",\"updated_at\":\"2024-10-02T14:28:34Z\"}" +
+			"]," + rest + "}\n")
+	}
+
+	cursor := app.Cursor{Sort: app.DefaultSort, Keys: []string{"2024-10-02T14:28:34Z", productIDB.String()}}
+	total := 7
+	minPrice, maxPrice := app.NewMoney(1000, "EUR"), app.NewMoney(2050, "EUR")
+
+	tests := []struct {
+		name                        string
+		query                       string
+		ifNoneMatch                 string
+		admin                       bool
+		expServiceGetProductsDTO    *app.GetProductsDTO
+		expServiceGetProductsResult *app.ProductPage
+		expServiceGetProductsError  error
+		expStatus                   int
+		expResponse                 []byte
+		expLinks                    []string
+	}{
+		{
+			name:                        "product retrieved successfully",
+			query:                       "limit=10&offset=0",
+			expServiceGetProductsDTO:    &app.GetProductsDTO{Sort: app.DefaultSort, Limit: 10},
+			expServiceGetProductsResult: &app.ProductPage{Products: []*app.Product{productA, productB}},
+			expServiceGetProductsError:  nil,
+			expStatus:                   http.StatusOK,
+			expResponse:                 responseBody("\"pagination\":{\"limit\":10,\"offset\":0,\"has_more\":false}"),
+			expLinks: []string{
+				`</api/v1/products?limit=10>; rel="first"`,
+			},
+		},
+		{
+			name:                        "product retrieved successfully with default limit and offset",
+			query:                       "limit=&offset=",
+			expServiceGetProductsDTO:    &app.GetProductsDTO{Sort: app.DefaultSort, Limit: 5},
+			expServiceGetProductsResult: &app.ProductPage{Products: []*app.Product{productA, productB}},
+			expServiceGetProductsError:  nil,
+			expStatus:                   http.StatusOK,
+			expResponse:                 responseBody("\"pagination\":{\"limit\":5,\"offset\":0,\"has_more\":false}"),
+			expLinks: []string{
+				`</api/v1/products?limit=5>; rel="first"`,
+			},
+		},
+		{
+			name:                        "offset page with total count",
+			query:                       "limit=2&offset=2&count=true",
+			expServiceGetProductsDTO:    &app.GetProductsDTO{Sort: app.DefaultSort, Limit: 2, Offset: 2, Count: true},
+			expServiceGetProductsResult: &app.ProductPage{Products: []*app.Product{productA, productB}, NextCursor: &cursor, PrevCursor: &cursor, Total: &total},
+			expStatus:                   http.StatusOK,
+			expResponse:                 responseBody("\"pagination\":{\"limit\":2,\"offset\":2,\"total\":7,\"has_more\":true},\"next_cursor\":\"" + cursor.Encode() + "\",\"prev_cursor\":\"" + cursor.Encode() + "\""),
+			expLinks: []string{
+				`</api/v1/products?count=true&limit=2&offset=4>; rel="next"`,
+				`</api/v1/products?count=true&limit=2&offset=0>; rel="prev"`,
+				`</api/v1/products?count=true&limit=2>; rel="first"`,
+				`</api/v1/products?count=true&limit=2&offset=6>; rel="last"`,
+			},
+		},
+		{
+			name:                        "page after a cursor",
+			query:                       "limit=2&after=" + cursor.Encode(),
+			expServiceGetProductsDTO:    &app.GetProductsDTO{Sort: app.DefaultSort, Limit: 2, After: &cursor},
+			expServiceGetProductsResult: &app.ProductPage{Products: []*app.Product{productA, productB}, NextCursor: &cursor, PrevCursor: &cursor},
+			expStatus:                   http.StatusOK,
+			expResponse:                 responseBody("\"pagination\":{\"limit\":2,\"has_more\":true},\"next_cursor\":\"" + cursor.Encode() + "\",\"prev_cursor\":\"" + cursor.Encode() + "\""),
+			expLinks: []string{
+				`</api/v1/products?after=` + cursor.Encode() + `&limit=2>; rel="next"`,
+				`</api/v1/products?before=` + cursor.Encode() + `&limit=2>; rel="prev"`,
+				`</api/v1/products?limit=2>; rel="first"`,
+			},
+		},
+		{
+			name:                        "page before a cursor",
+			query:                       "before=" + cursor.Encode(),
+			expServiceGetProductsDTO:    &app.GetProductsDTO{Sort: app.DefaultSort, Limit: 5, Before: &cursor},
+			expServiceGetProductsResult: &app.ProductPage{Products: []*app.Product{productA, productB}},
+			expStatus:                   http.StatusOK,
+			expResponse:                 responseBody("\"pagination\":{\"limit\":5,\"has_more\":false}"),
+			expLinks: []string{
+				`</api/v1/products?limit=5>; rel="first"`,
+			},
+		},
+		{
+			name:  "filtered and sorted products",
+			query: "name_contains=lamp&currency=EUR&price_min=10&price_max=20.5&created_after=2024-10-01T00:00:00Z&ids=" + productIDA.String() + "," + productIDB.String() + "&sort=price,-created_at",
+			expServiceGetProductsDTO: &app.GetProductsDTO{
+				Filter: app.ProductFilter{
+					NameContains: "lamp",
+					Currency:     "EUR",
+					MinPrice:     &minPrice,
+					MaxPrice:     &maxPrice,
+					CreatedAfter: time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC),
+					IDs:          []uuid.UUID{productIDA, productIDB},
+				},
+				Sort:  app.Sort{{Field: app.SortByPrice}, {Field: app.SortByCreatedAt, Desc: true}},
+				Limit: 5,
+			},
+			expServiceGetProductsResult: &app.ProductPage{Products: []*app.Product{productA, productB}},
+			expStatus:                   http.StatusOK,
+			expResponse:                 responseBody("\"pagination\":{\"limit\":5,\"offset\":0,\"has_more\":false}"),
+			expLinks: []string{
+				`</api/v1/products?created_after=2024-10-01T00%3A00%3A00Z&currency=EUR&ids=` + productIDA.String() + `%2C` + productIDB.String() + `&limit=5&name_contains=lamp&price_max=20.5&price_min=10&sort=price%2C-created_at>; rel="first"`,
+			},
+		},
+		{
+			name:  "products filtered by tags",
+			query: "tags_any=seasonal,clearance&tags_all=outdoor",
+			expServiceGetProductsDTO: &app.GetProductsDTO{
+				Filter: app.ProductFilter{
+					TagsAny: []string{"seasonal", "clearance"},
+					TagsAll: []string{"outdoor"},
+				},
+				Sort:  app.DefaultSort,
+				Limit: 5,
+			},
+			expServiceGetProductsResult: &app.ProductPage{Products: []*app.Product{productA, productB}},
+			expStatus:                   http.StatusOK,
+			expResponse:                 responseBody("\"pagination\":{\"limit\":5,\"offset\":0,\"has_more\":false}"),
+			expLinks: []string{
+				`</api/v1/products?limit=5&tags_all=outdoor&tags_any=seasonal%2Cclearance>; rel="first"`,
+			},
+		},
+		{
+			name:  "products filtered by attributes",
+			query: "attr.color=red&attr.size=42&attr.waterproof=true&attr.code=%2242%22",
+			expServiceGetProductsDTO: &app.GetProductsDTO{
+				Filter: app.ProductFilter{
+					Attributes: map[string]any{
+						"color":      "red",
+						"size":       json.Number("42"),
+						"waterproof": true,
+						"code":       "42",
+					},
+				},
+				Sort:  app.DefaultSort,
+				Limit: 5,
+			},
+			expServiceGetProductsResult: &app.ProductPage{Products: []*app.Product{productA, productB}},
+			expStatus:                   http.StatusOK,
+			expResponse:                 responseBody("\"pagination\":{\"limit\":5,\"offset\":0,\"has_more\":false}"),
+			expLinks: []string{
+				`</api/v1/products?attr.code=%2242%22&attr.color=red&attr.size=42&attr.waterproof=true&limit=5>; rel="first"`,
+			},
+		},
+		{
+			name:  "products filtered by status",
+			query: "status=draft,archived",
+			expServiceGetProductsDTO: &app.GetProductsDTO{
+				Filter: app.ProductFilter{Statuses: []app.ProductStatus{app.ProductDraft, app.ProductArchived}},
+				Sort:   app.DefaultSort,
+				Limit:  5,
+			},
+			expServiceGetProductsResult: &app.ProductPage{Products: []*app.Product{productA, productB}},
+			expStatus:                   http.StatusOK,
+			expResponse:                 responseBody("\"pagination\":{\"limit\":5,\"offset\":0,\"has_more\":false}"),
+			expLinks: []string{
+				`</api/v1/products?limit=5&status=draft%2Carchived>; rel="first"`,
+			},
+		},
+		{
+			name:  "products of every status",
+			query: "status=all",
+			expServiceGetProductsDTO: &app.GetProductsDTO{
+				Filter: app.ProductFilter{Statuses: app.ProductStatuses},
+				Sort:   app.DefaultSort,
+				Limit:  5,
+			},
+			expServiceGetProductsResult: &app.ProductPage{Products: []*app.Product{productA, productB}},
+			expStatus:                   http.StatusOK,
+			expResponse:                 responseBody("\"pagination\":{\"limit\":5,\"offset\":0,\"has_more\":false}"),
+			expLinks: []string{
+				`</api/v1/products?limit=5&status=all>; rel="first"`,
+			},
+		},
+		{
+			name:  "products including the deleted ones",
+			query: "include_deleted=true",
+			admin: true,
+			expServiceGetProductsDTO: &app.GetProductsDTO{
+				Filter: app.ProductFilter{IncludeDeleted: true},
+				Sort:   app.DefaultSort,
+				Limit:  5,
+			},
+			expServiceGetProductsResult: &app.ProductPage{Products: []*app.Product{productA, productB}},
+			expStatus:                   http.StatusOK,
+			expResponse:                 responseBody("\"pagination\":{\"limit\":5,\"offset\":0,\"has_more\":false}"),
+			expLinks: []string{
+				`</api/v1/products?include_deleted=true&limit=5>; rel="first"`,
+			},
+		},
+		{
+			name:        "deleted products requested through the API",
+			query:       "include_deleted=true",
+			expStatus:   http.StatusForbidden,
+			expResponse: []byte("{\"type\":\"/problems/forbidden\",\"title\":\"Forbidden\",\"status\":403,\"detail\":\"The query parameters are only accepted by the admin API.\",\"instance\":\"/api/v1/products\",\"errors\":[{\"field\":\"include_deleted\",\"message\":\"is only accepted by the admin API\"}]}\n"),
+		},
+		{
+			name:        "invalid include_deleted",
+			query:       "include_deleted=maybe",
+			admin:       true,
+			expStatus:   http.StatusBadRequest,
+			expResponse: []byte("{\"type\":\"/problems/invalid-parameter\",\"title\":\"Invalid request parameter\",\"status\":400,\"detail\":\"The query parameters are invalid.\",\"instance\":\"/api/v1/products\",\"errors\":[{\"field\":\"include_deleted\",\"message\":\"must be a boolean\"}]}\n"),
+		},
+		{
+			name:  "products as they were at an instant",
+			query: "as_of=2024-10-31T23:59:59Z",
+			expServiceGetProductsDTO: &app.GetProductsDTO{
+				Filter: app.ProductFilter{AsOf: time.Date(2024, 10, 31, 23, 59, 59, 0, time.UTC)},
+				Sort:   app.DefaultSort,
+				Limit:  5,
+			},
+			expServiceGetProductsResult: &app.ProductPage{Products: []*app.Product{productA, productB}},
+			expStatus:                   http.StatusOK,
+			expResponse:                 responseBody("\"pagination\":{\"limit\":5,\"offset\":0,\"has_more\":false}"),
+			expLinks: []string{
+				`</api/v1/products?as_of=2024-10-31T23%3A59%3A59Z&limit=5>; rel="first"`,
+			},
+		},
+		{
+			name:        "invalid as_of",
+			query:       "as_of=yesterday",
+			expStatus:   http.StatusBadRequest,
+			expResponse: []byte("{\"type\":\"/problems/invalid-parameter\",\"title\":\"Invalid request parameter\",\"status\":400,\"detail\":\"The query parameters are invalid.\",\"instance\":\"/api/v1/products\",\"errors\":[{\"field\":\"as_of\",\"message\":\"must be an RFC 3339 timestamp\"}]}\n"),
+		},
+		{
+			name:        "invalid status filter",
+			query:       "status=draft,deleted",
+			expStatus:   http.StatusBadRequest,
+			expResponse: []byte("{\"type\":\"/problems/invalid-parameter\",\"title\":\"Invalid request parameter\",\"status\":400,\"detail\":\"The query parameters are invalid.\",\"instance\":\"/api/v1/products\",\"errors\":[{\"field\":\"status\",\"message\":\"must be a comma separated list of draft, published and archived, or all\"}]}\n"),
+		},
+		{
+			name:        "invalid attribute filter",
+			query:       "attr.Color=red",
+			expStatus:   http.StatusBadRequest,
+			expResponse: []byte("{\"type\":\"/problems/invalid-parameter\",\"title\":\"Invalid request parameter\",\"status\":400,\"detail\":\"The query parameters are invalid.\",\"instance\":\"/api/v1/products\",\"errors\":[{\"field\":\"attr.Color\",\"message\":\"must name a valid attribute\"}]}\n"),
+		},
+		{
+			name:        "invalid filters",
+			query:       "currency=XXX&price_min=abc&updated_before=yesterday&ids=1,2&sort=description",
+			expStatus:   http.StatusBadRequest,
+			expResponse: []byte("{\"type\":\"/problems/invalid-parameter\",\"title\":\"Invalid request parameter\",\"status\":400,\"detail\":\"The query parameters are invalid.\",\"instance\":\"/api/v1/products\",\"errors\":[{\"field\":\"currency\",\"message\":\"must be a supported ISO 4217 currency code\"},{\"field\":\"price_min\",\"message\":\"must be a non-negative decimal amount\"},{\"field\":\"updated_before\",\"message\":\"must be an RFC 3339 timestamp\"},{\"field\":\"ids\",\"message\":\"must be a comma separated list of UUIDs\"},{\"field\":\"sort\",\"message\":\"must be a comma separated list of name, price, created_at or updated_at, each optionally prefixed with -\"}]}\n"),
+		},
+		{
+			name:        "price range is inverted",
+			query:       "price_min=20&price_max=10",
+			expStatus:   http.StatusBadRequest,
+			expResponse: []byte("{\"type\":\"/problems/invalid-parameter\",\"title\":\"Invalid request parameter\",\"status\":400,\"detail\":\"The query parameters are invalid.\",\"instance\":\"/api/v1/products\",\"errors\":[{\"field\":\"price_max\",\"message\":\"must not be lower than price_min\"}]}\n"),
+		},
+		{
+			name:        "cursor of another sort",
+			query:       "sort=name&after=" + cursor.Encode(),
+			expStatus:   http.StatusBadRequest,
+			expResponse: []byte("{\"type\":\"/problems/invalid-parameter\",\"title\":\"Invalid request parameter\",\"status\":400,\"detail\":\"The query parameters are invalid.\",\"instance\":\"/api/v1/products\",\"errors\":[{\"field\":\"after\",\"message\":\"was returned for a different sort\"}]}\n"),
+		},
+		{
+			name:        "invalid limit and offset",
+			query:       "limit=1000&offset=-1",
+			expStatus:   http.StatusBadRequest,
+			expResponse: []byte("{\"type\":\"/problems/invalid-parameter\",\"title\":\"Invalid request parameter\",\"status\":400,\"detail\":\"The query parameters are invalid.\",\"instance\":\"/api/v1/products\",\"errors\":[{\"field\":\"limit\",\"message\":\"must be an integer between 1 and 100\"},{\"field\":\"offset\",\"message\":\"must be a non-negative integer\"}]}\n"),
+		},
+		{
+			name:        "invalid count",
+			query:       "count=maybe",
+			expStatus:   http.StatusBadRequest,
+			expResponse: []byte("{\"type\":\"/problems/invalid-parameter\",\"title\":\"Invalid request parameter\",\"status\":400,\"detail\":\"The query parameters are invalid.\",\"instance\":\"/api/v1/products\",\"errors\":[{\"field\":\"count\",\"message\":\"must be a boolean\"}]}\n"),
+		},
+		{
+			name:        "invalid cursor",
+			query:       "after=abc",
+			expStatus:   http.StatusBadRequest,
+			expResponse: []byte("{\"type\":\"/problems/invalid-parameter\",\"title\":\"Invalid request parameter\",\"status\":400,\"detail\":\"The query parameters are invalid.\",\"instance\":\"/api/v1/products\",\"errors\":[{\"field\":\"after\",\"message\":\"must be a cursor returned by a previous request\"}]}\n"),
+		},
+		{
+			name:        "offset combined with a cursor",
+			query:       "offset=5&before=" + cursor.Encode(),
+			expStatus:   http.StatusBadRequest,
+			expResponse: []byte("{\"type\":\"/problems/invalid-parameter\",\"title\":\"Invalid request parameter\",\"status\":400,\"detail\":\"The query parameters are invalid.\",\"instance\":\"/api/v1/products\",\"errors\":[{\"field\":\"offset\",\"message\":\"cannot be combined with a cursor\"}]}\n"),
+		},
+		{
+			name:                        "products not modified",
+			query:                       "limit=10&offset=0",
+			ifNoneMatch:                 contentETag(responseBody("\"pagination\":{\"limit\":10,\"offset\":0,\"has_more\":false}")),
+			expServiceGetProductsDTO:    &app.GetProductsDTO{Sort: app.DefaultSort, Limit: 10},
+			expServiceGetProductsResult: &app.ProductPage{Products: []*app.Product{productA, productB}},
+			expStatus:                   http.StatusNotModified,
+			expResponse:                 []byte{},
+			expLinks: []string{
+				`</api/v1/products?limit=10>; rel="first"`,
+			},
+		},
+		{
+			name:                        "product could not be retrieved",
+			query:                       "limit=10&offset=0",
+			expServiceGetProductsDTO:    &app.GetProductsDTO{Sort: app.DefaultSort, Limit: 10},
+			expServiceGetProductsResult: nil,
+			expServiceGetProductsError:  errors.New("service error"),
+			expStatus:                   http.StatusInternalServerError,
+			expResponse:                 []byte("{\"type\":\"/problems/internal-error\",\"title\":\"Internal server error\",\"status\":500,\"detail\":\"An unexpected error occurred.\",\"instance\":\"/api/v1/products\"}\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		mockService := NewMockservice(ctrl)
+
+		if tt.expServiceGetProductsDTO != nil {
+			mockService.
+				EXPECT().
+				GetProducts(gomock.Any(), *tt.expServiceGetProductsDTO).
+				Return(tt.expServiceGetProductsResult, tt.expServiceGetProductsError)
+		}
+
+		router, err := NewRouter(mockService, config.HTTP{})
+		assert.NoError(t, err)
+		assert.NotNil(t, router)
+		router.admin = tt.admin
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/products?"+tt.query, nil)
+		if tt.ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", tt.ifNoneMatch)
+		}
+
+		recorder := httptest.NewRecorder()
+
+		router.getProductsHandler(recorder, req)
+
+		assert.Equal(t, tt.expStatus, recorder.Code)
+		if tt.expStatus >= http.StatusBadRequest {
+			assert.Equal(t, problemContentType, recorder.Header().Get("Content-Type"))
+		}
+
+		b, err := io.ReadAll(recorder.Body)
+		assert.NoError(t, err)
+
+		if tt.expStatus == http.StatusOK {
+			assert.Equal(t, contentETag(b), recorder.Header().Get("ETag"))
+			assert.Empty(t, recorder.Header().Get("Last-Modified"))
+		}
+		assert.Equal(t, tt.expLinks, recorder.Header().Values("Link"))
+
+		assert.Equal(t, tt.expResponse, b)
+	}
+}
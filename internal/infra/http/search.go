@@ -0,0 +1,169 @@
+package http
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+const (
+	searchProductsEndpoint  string = "GET /api/v1/products/search"
+	suggestProductsEndpoint string = "GET /api/v1/products/suggest"
+
+	searchQueryMaxLength = 200
+
+	suggestProductsDefaultLimit = 10
+	suggestProductsMaxLimit     = 25
+)
+
+func (r Router) searchProductsHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	if !r.allowParameters(w, req) {
+		return
+	}
+
+	dto, fieldErrs := parseSearchProductsQuery(req.URL.Query())
+	if fieldErrs != nil {
+		writeProblem(w, req, problemInvalidParameter, "The query parameters are invalid.", fieldErrs...)
+		return
+	}
+
+	page, err := r.service.SearchProducts(ctx, dto)
+	if err != nil {
+		writeServiceError(w, req, err)
+		return
+	}
+
+	offset := dto.Offset
+	res := productSearchResponse{
+		Results: make([]productMatchResponse, 0, len(page.Matches)),
+		Pagination: paginationResponse{
+			Limit:   dto.Limit,
+			Offset:  &offset,
+			HasMore: page.HasMore,
+		},
+	}
+	for _, m := range page.Matches {
+		res.Results = append(res.Results, productMatchResponse{
+			Product: newProductResponse(m.Product),
+			Rank:    m.Rank,
+			Highlights: productHighlightsResponse{
+				Name:        m.NameHighlight,
+				Description: m.DescriptionHighlight,
+			},
+		})
+	}
+
+	writeCacheable(w, req, r.cfg.ProductsCacheControl, "", time.Time{}, res)
+}
+
+// parseSearchProductsQuery reads the search text from q along with the
+// filter and pagination parameters of the product list.
+func parseSearchProductsQuery(query url.Values) (app.SearchProductsDTO, []fieldError) {
+	var dto app.SearchProductsDTO
+
+	dto.Query = strings.TrimSpace(query.Get("q"))
+
+	var fieldErrs []fieldError
+	switch {
+	case dto.Query == "":
+		fieldErrs = append(fieldErrs, fieldError{Field: "q", Message: "must not be empty"})
+	case utf8.RuneCountInString(dto.Query) > searchQueryMaxLength:
+		fieldErrs = append(fieldErrs, fieldError{Field: "q", Message: "must not be longer than 200 characters"})
+	}
+
+	filter, filterErrs := parseProductFilter(query)
+	dto.Filter = filter
+	fieldErrs = append(fieldErrs, filterErrs...)
+
+	var pageErrs []fieldError
+	dto.Limit, dto.Offset, pageErrs = parseLimitOffset(query)
+	fieldErrs = append(fieldErrs, pageErrs...)
+
+	return dto, fieldErrs
+}
+
+func (r Router) suggestProductsHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	dto, fieldErrs := parseSuggestProductsQuery(req.URL.Query())
+	if fieldErrs != nil {
+		writeProblem(w, req, problemInvalidParameter, "The query parameters are invalid.", fieldErrs...)
+		return
+	}
+
+	suggestions, err := r.service.SuggestProducts(ctx, dto)
+	if err != nil {
+		writeServiceError(w, req, err)
+		return
+	}
+
+	res := productSuggestionsResponse{
+		Suggestions: make([]productSuggestionResponse, 0, len(suggestions)),
+	}
+	for _, s := range suggestions {
+		res.Suggestions = append(res.Suggestions, productSuggestionResponse(s))
+	}
+
+	writeCacheable(w, req, r.cfg.ProductsCacheControl, "", time.Time{}, res)
+}
+
+func parseSuggestProductsQuery(query url.Values) (app.SuggestProductsDTO, []fieldError) {
+	dto := app.SuggestProductsDTO{
+		Prefix: strings.TrimSpace(query.Get("prefix")),
+		Limit:  suggestProductsDefaultLimit,
+	}
+
+	var fieldErrs []fieldError
+	switch {
+	case dto.Prefix == "":
+		fieldErrs = append(fieldErrs, fieldError{Field: "prefix", Message: "must not be empty"})
+	case utf8.RuneCountInString(dto.Prefix) > app.ProductNameMaxLength:
+		fieldErrs = append(fieldErrs, fieldError{Field: "prefix", Message: "must not be longer than a product name"})
+	}
+
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > suggestProductsMaxLimit {
+			fieldErrs = append(fieldErrs, fieldError{Field: "limit", Message: "must be an integer between 1 and " + strconv.Itoa(suggestProductsMaxLimit)})
+		} else {
+			dto.Limit = limit
+		}
+	}
+
+	return dto, fieldErrs
+}
+
+type productSearchResponse struct {
+	Results    []productMatchResponse `json:"results"`
+	Pagination paginationResponse     `json:"pagination"`
+}
+
+type productMatchResponse struct {
+	Product    productResponse           `json:"product"`
+	Rank       float64                   `json:"rank"`
+	Highlights productHighlightsResponse `json:"highlights"`
+}
+
+type productHighlightsResponse struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type productSuggestionsResponse struct {
+	Suggestions []productSuggestionResponse `json:"suggestions"`
+}
+
+type productSuggestionResponse struct {
+	ID    uuid.UUID `json:"id"`
+	Name  string    `json:"name"`
+	Score float64   `json:"score"`
+}
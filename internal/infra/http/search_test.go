@@ -0,0 +1,188 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/simpler-tha/internal/app"
+	"github.com/simpler-tha/internal/config"
+)
+
+func TestRouter_searchProductsHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	productID, _ := uuid.Parse("9f9f4340-6bf9-4948-808c-ebf2dd604e2c")
+
+	now, _ := time.Parse(time.RFC3339, "2024-10-02T14:28:34Z")
+
+	product := &app.Product{
+		ID:          productID,
+		Name:        "Desk Lamp",
+		Description: "A lamp for <your> desk",
+		Price:       app.NewMoney(2500, "USD"),
+		Version:     1,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	tests := []struct {
+		name                           string
+		query                          string
+		expServiceSearchProductsDTO    *app.SearchProductsDTO
+		expServiceSearchProductsResult *app.ProductSearchPage
+		expServiceSearchProductsError  error
+		expStatus                      int
+		expResponse                    []byte
+	}{
+		{
+			name:                        "products found",
+			query:                       "q=lamp&currency=USD&limit=1",
+			expServiceSearchProductsDTO: &app.SearchProductsDTO{Query: "lamp", Filter: app.ProductFilter{Currency: "USD"}, Limit: 1},
+			expServiceSearchProductsResult: &app.ProductSearchPage{
+				Matches: []app.ProductMatch{{
+					Product:              product,
+					Rank:                 0.5,
+					NameHighlight:        "Desk <mark>Lamp</mark>",
+					DescriptionHighlight: "A <mark>lamp</mark> for &lt;your&gt; desk",
+				}},
+				HasMore: true,
+			},
+			expStatus:   http.StatusOK,
+			expResponse: []byte("{\"results\":[{\"product\":{\"id\":\"9f9f4340-6bf9-4948-808c-ebf2dd604e2c\",\"sku\":null,\"slug\":\"\",\"status\":\"\",\"name\":\"Desk Lamp\",\"description\":\"A lamp for \\u003cyour\\u003e desk\",\"price\":\"25.00\",\"currency\":\"USD\",\"tags\":[],\"attributes\":{},\"version\":1,\"created_at\":\"2024-10-02T14:28:34Z\",\"updated_at\":\"2024-10-02T14:28:34Z\"},\"rank\":0.5,\"highlights\":{\"name\":\"Desk \\u003cmark\\u003eLamp\\u003c/mark\\u003e\",\"description\":\"A \\u003cmark\\u003elamp\\u003c/mark\\u003e for \\u0026lt;your\\u0026gt; desk\"}}],\"pagination\":{\"limit\":1,\"offset\":0,\"has_more\":true}}\n"),
+		},
+		{
+			name:                           "no products found",
+			query:                          "q=chair",
+			expServiceSearchProductsDTO:    &app.SearchProductsDTO{Query: "chair", Limit: 5},
+			expServiceSearchProductsResult: &app.ProductSearchPage{},
+			expStatus:                      http.StatusOK,
+			expResponse:                    []byte("{\"results\":[],\"pagination\":{\"limit\":5,\"offset\":0,\"has_more\":false}}\n"),
+		},
+		{
+			name:                          "products could not be searched",
+			query:                         "q=lamp",
+			expServiceSearchProductsDTO:   &app.SearchProductsDTO{Query: "lamp", Limit: 5},
+			expServiceSearchProductsError: errors.New("service error"),
+			expStatus:                     http.StatusInternalServerError,
+			expResponse:                   []byte("{\"type\":\"/problems/internal-error\",\"title\":\"Internal server error\",\"status\":500,\"detail\":\"An unexpected error occurred.\",\"instance\":\"/api/v1/products/search\"}\n"),
+		},
+		{
+			name:        "missing search text",
+			query:       "q=%20&limit=0",
+			expStatus:   http.StatusBadRequest,
+			expResponse: []byte("{\"type\":\"/problems/invalid-parameter\",\"title\":\"Invalid request parameter\",\"status\":400,\"detail\":\"The query parameters are invalid.\",\"instance\":\"/api/v1/products/search\",\"errors\":[{\"field\":\"q\",\"message\":\"must not be empty\"},{\"field\":\"limit\",\"message\":\"must be an integer between 1 and 100\"}]}\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		mockService := NewMockservice(ctrl)
+
+		if tt.expServiceSearchProductsDTO != nil {
+			mockService.
+				EXPECT().
+				SearchProducts(gomock.Any(), *tt.expServiceSearchProductsDTO).
+				Return(tt.expServiceSearchProductsResult, tt.expServiceSearchProductsError)
+		}
+
+		router, err := NewRouter(mockService, config.HTTP{})
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/products/search?"+tt.query, nil)
+		recorder := httptest.NewRecorder()
+
+		router.searchProductsHandler(recorder, req)
+
+		assert.Equal(t, tt.expStatus, recorder.Code)
+		if tt.expStatus >= http.StatusBadRequest {
+			assert.Equal(t, problemContentType, recorder.Header().Get("Content-Type"))
+		}
+
+		b, err := io.ReadAll(recorder.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, tt.expResponse, b)
+	}
+}
+
+func TestRouter_suggestProductsHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	productID, _ := uuid.Parse("9f9f4340-6bf9-4948-808c-ebf2dd604e2c")
+
+	tests := []struct {
+		name                            string
+		query                           string
+		expServiceSuggestProductsDTO    *app.SuggestProductsDTO
+		expServiceSuggestProductsResult []app.ProductSuggestion
+		expServiceSuggestProductsError  error
+		expStatus                       int
+		expResponse                     []byte
+	}{
+		{
+			name:                            "products suggested",
+			query:                           "prefix=desk+lmap&limit=3",
+			expServiceSuggestProductsDTO:    &app.SuggestProductsDTO{Prefix: "desk lmap", Limit: 3},
+			expServiceSuggestProductsResult: []app.ProductSuggestion{{ID: productID, Name: "Desk Lamp", Score: 0.625}},
+			expStatus:                       http.StatusOK,
+			expResponse:                     []byte("{\"suggestions\":[{\"id\":\"9f9f4340-6bf9-4948-808c-ebf2dd604e2c\",\"name\":\"Desk Lamp\",\"score\":0.625}]}\n"),
+		},
+		{
+			name:                         "no products suggested",
+			query:                        "prefix=xyz",
+			expServiceSuggestProductsDTO: &app.SuggestProductsDTO{Prefix: "xyz", Limit: 10},
+			expStatus:                    http.StatusOK,
+			expResponse:                  []byte("{\"suggestions\":[]}\n"),
+		},
+		{
+			name:                           "products could not be suggested",
+			query:                          "prefix=desk",
+			expServiceSuggestProductsDTO:   &app.SuggestProductsDTO{Prefix: "desk", Limit: 10},
+			expServiceSuggestProductsError: errors.New("service error"),
+			expStatus:                      http.StatusInternalServerError,
+			expResponse:                    []byte("{\"type\":\"/problems/internal-error\",\"title\":\"Internal server error\",\"status\":500,\"detail\":\"An unexpected error occurred.\",\"instance\":\"/api/v1/products/suggest\"}\n"),
+		},
+		{
+			name:        "missing prefix",
+			query:       "limit=100",
+			expStatus:   http.StatusBadRequest,
+			expResponse: []byte("{\"type\":\"/problems/invalid-parameter\",\"title\":\"Invalid request parameter\",\"status\":400,\"detail\":\"The query parameters are invalid.\",\"instance\":\"/api/v1/products/suggest\",\"errors\":[{\"field\":\"prefix\",\"message\":\"must not be empty\"},{\"field\":\"limit\",\"message\":\"must be an integer between 1 and 25\"}]}\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		mockService := NewMockservice(ctrl)
+
+		if tt.expServiceSuggestProductsDTO != nil {
+			mockService.
+				EXPECT().
+				SuggestProducts(gomock.Any(), *tt.expServiceSuggestProductsDTO).
+				Return(tt.expServiceSuggestProductsResult, tt.expServiceSuggestProductsError)
+		}
+
+		router, err := NewRouter(mockService, config.HTTP{})
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/products/suggest?"+tt.query, nil)
+		recorder := httptest.NewRecorder()
+
+		router.suggestProductsHandler(recorder, req)
+
+		assert.Equal(t, tt.expStatus, recorder.Code)
+		if tt.expStatus >= http.StatusBadRequest {
+			assert.Equal(t, problemContentType, recorder.Header().Get("Content-Type"))
+		}
+
+		b, err := io.ReadAll(recorder.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, tt.expResponse, b)
+	}
+}
@@ -0,0 +1,18 @@
+package http
+
+import "net/http"
+
+const getPostgresStatsEndpoint string = "GET /debug/postgres/stats"
+
+// RegisterStatsRoutes exposes the statistics of the database connection pool
+// so that it can be sized from real traffic. mux must not be the one of the
+// API, the statistics are not meant for its clients.
+func RegisterStatsRoutes(mux *http.ServeMux, postgresStats func() any) {
+	mux.HandleFunc(getPostgresStatsEndpoint, statsHandler(postgresStats))
+}
+
+func statsHandler(stats func() any) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		writeJSON(w, req, http.StatusOK, stats())
+	}
+}
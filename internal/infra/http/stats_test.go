@@ -0,0 +1,28 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatsHandler(t *testing.T) {
+	handler := statsHandler(func() any {
+		return map[string]int{"total_conns": 3}
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/postgres/stats", nil)
+	recorder := httptest.NewRecorder()
+
+	handler(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, jsonContentType, recorder.Header().Get("Content-Type"))
+
+	b, err := io.ReadAll(recorder.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("{\"total_conns\":3}\n"), b)
+}
@@ -0,0 +1,50 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+const (
+	publishProductEndpoint   string = "POST /api/v1/products/{product_id}/publish"
+	unpublishProductEndpoint string = "POST /api/v1/products/{product_id}/unpublish"
+	archiveProductEndpoint   string = "POST /api/v1/products/{product_id}/archive"
+	unarchiveProductEndpoint string = "POST /api/v1/products/{product_id}/unarchive"
+)
+
+// transitionProductHandler returns the handler of the endpoint applying t to
+// a product. Like updates, transitions can be made conditional with If-Match.
+func (r Router) transitionProductHandler(t app.ProductTransition) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+
+		productID, err := uuid.Parse(req.PathValue("product_id"))
+		if err != nil {
+			writeInvalidIDError(w, req, "product_id")
+			return
+		}
+
+		expectedVersion, ok := r.expectedVersion(w, req)
+		if !ok {
+			return
+		}
+
+		dto := app.TransitionProductDTO{
+			ID:              productID,
+			ExpectedVersion: expectedVersion,
+			Transition:      t,
+		}
+
+		p, err := r.service.TransitionProduct(ctx, dto)
+		if err != nil {
+			writeServiceError(w, req, err)
+			return
+		}
+
+		w.Header().Set("ETag", versionETag(p.Version))
+		writeJSON(w, req, http.StatusOK, newProductResponse(p))
+	}
+}
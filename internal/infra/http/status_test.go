@@ -0,0 +1,118 @@
+package http
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/simpler-tha/internal/app"
+	"github.com/simpler-tha/internal/config"
+)
+
+func TestRouter_transitionProductHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	productID, _ := uuid.Parse("9f9f4340-6bf9-4948-808c-ebf2dd604e2c")
+
+	now, _ := time.Parse(time.RFC3339, "2024-10-02T14:28:34Z")
+
+	tests := []struct {
+		name                              string
+		transition                        app.ProductTransition
+		ifMatch                           string
+		expServiceTransitionProductDTO    *app.TransitionProductDTO
+		expServiceTransitionProductResult *app.Product
+		expServiceTransitionProductError  error
+		expStatus                         int
+		expETag                           string
+		expResponse                       []byte
+	}{
+		{
+			name:       "product published successfully",
+			transition: app.ProductPublish,
+			ifMatch:    `"1"`,
+			expServiceTransitionProductDTO: &app.TransitionProductDTO{
+				ID:              productID,
+				ExpectedVersion: 1,
+				Transition:      app.ProductPublish,
+			},
+			expServiceTransitionProductResult: &app.Product{
+				ID:         productID,
+				Slug:       "desk-lamp",
+				Status:     app.ProductPublished,
+				Name:       "Desk Lamp",
+				Price:      app.NewMoney(2500, "USD"),
+				Tags:       []string{},
+				Attributes: map[string]any{},
+				Version:    2,
+				CreatedAt:  now,
+				UpdatedAt:  now,
+			},
+			expStatus:   http.StatusOK,
+			expETag:     `"2"`,
+			expResponse: []byte("{\"id\":\"9f9f4340-6bf9-4948-808c-ebf2dd604e2c\",\"sku\":null,\"slug\":\"desk-lamp\",\"status\":\"published\",\"name\":\"Desk Lamp\",\"description\":\"\",\"price\":\"25.00\",\"currency\":\"USD\",\"tags\":[],\"attributes\":{},\"version\":2,\"created_at\":\"2024-10-02T14:28:34Z\",\"updated_at\":\"2024-10-02T14:28:34Z\"}\n"),
+		},
+		{
+			name:       "archived product cannot be published",
+			transition: app.ProductPublish,
+			expServiceTransitionProductDTO: &app.TransitionProductDTO{
+				ID:         productID,
+				Transition: app.ProductPublish,
+			},
+			expServiceTransitionProductError: fmt.Errorf("%w: cannot publish product %s, it is archived", app.ErrConflict, productID),
+			expStatus:                        http.StatusConflict,
+			expResponse:                      []byte("{\"type\":\"/problems/conflict\",\"title\":\"Resource conflict\",\"status\":409,\"detail\":\"The request conflicts with the current state of the resource.\",\"instance\":\"/api/v1/products/9f9f4340-6bf9-4948-808c-ebf2dd604e2c/publish\"}\n"),
+		},
+		{
+			name:       "product changed since it was retrieved",
+			transition: app.ProductArchive,
+			ifMatch:    `"1"`,
+			expServiceTransitionProductDTO: &app.TransitionProductDTO{
+				ID:              productID,
+				ExpectedVersion: 1,
+				Transition:      app.ProductArchive,
+			},
+			expServiceTransitionProductError: fmt.Errorf("%w: product %s is at version 2, not 1", app.ErrPreconditionFailed, productID),
+			expStatus:                        http.StatusPreconditionFailed,
+			expResponse:                      []byte("{\"type\":\"/problems/precondition-failed\",\"title\":\"Precondition failed\",\"status\":412,\"detail\":\"The resource has changed since it was last retrieved.\",\"instance\":\"/api/v1/products/9f9f4340-6bf9-4948-808c-ebf2dd604e2c/archive\"}\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		mockService := NewMockservice(ctrl)
+
+		if tt.expServiceTransitionProductDTO != nil {
+			mockService.
+				EXPECT().
+				TransitionProduct(gomock.Any(), *tt.expServiceTransitionProductDTO).
+				Return(tt.expServiceTransitionProductResult, tt.expServiceTransitionProductError)
+		}
+
+		router, err := NewRouter(mockService, config.HTTP{})
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/products/"+productID.String()+"/"+string(tt.transition), nil)
+		req.SetPathValue("product_id", productID.String())
+		if tt.ifMatch != "" {
+			req.Header.Set("If-Match", tt.ifMatch)
+		}
+		recorder := httptest.NewRecorder()
+
+		router.transitionProductHandler(tt.transition)(recorder, req)
+
+		assert.Equal(t, tt.expStatus, recorder.Code)
+		assert.Equal(t, tt.expETag, recorder.Header().Get("ETag"))
+
+		b, err := io.ReadAll(recorder.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, tt.expResponse, b)
+	}
+}
@@ -0,0 +1,78 @@
+package http
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+const (
+	getTagsEndpoint string = "GET /api/v1/tags"
+
+	getTagsDefaultLimit = 100
+	getTagsMaxLimit     = 1000
+)
+
+// getTagsHandler lists the tags of the published products with the number of
+// products carrying each of them, the most used first.
+func (r Router) getTagsHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	dto, fieldErrs := parseGetTagsQuery(req.URL.Query())
+	if fieldErrs != nil {
+		writeProblem(w, req, problemInvalidParameter, "The query parameters are invalid.", fieldErrs...)
+		return
+	}
+
+	tags, err := r.service.GetTags(ctx, dto)
+	if err != nil {
+		writeServiceError(w, req, err)
+		return
+	}
+
+	res := tagsResponse{
+		Tags: make([]tagResponse, 0, len(tags)),
+	}
+	for _, t := range tags {
+		res.Tags = append(res.Tags, tagResponse(t))
+	}
+
+	writeCacheable(w, req, r.cfg.ProductsCacheControl, "", time.Time{}, res)
+}
+
+func parseGetTagsQuery(query url.Values) (app.GetTagsDTO, []fieldError) {
+	dto := app.GetTagsDTO{
+		Prefix: strings.TrimSpace(query.Get("prefix")),
+		Limit:  getTagsDefaultLimit,
+	}
+
+	var fieldErrs []fieldError
+	if utf8.RuneCountInString(dto.Prefix) > app.ProductTagMaxLength {
+		fieldErrs = append(fieldErrs, fieldError{Field: "prefix", Message: "must not be longer than a tag"})
+	}
+
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > getTagsMaxLimit {
+			fieldErrs = append(fieldErrs, fieldError{Field: "limit", Message: "must be an integer between 1 and " + strconv.Itoa(getTagsMaxLimit)})
+		} else {
+			dto.Limit = limit
+		}
+	}
+
+	return dto, fieldErrs
+}
+
+type tagsResponse struct {
+	Tags []tagResponse `json:"tags"`
+}
+
+type tagResponse struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
@@ -0,0 +1,83 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/simpler-tha/internal/app"
+	"github.com/simpler-tha/internal/config"
+)
+
+func TestRouter_getTagsHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	tests := []struct {
+		name                    string
+		query                   string
+		expServiceGetTagsDTO    *app.GetTagsDTO
+		expServiceGetTagsResult []app.TagCount
+		expServiceGetTagsError  error
+		expStatus               int
+		expResponse             []byte
+	}{
+		{
+			name:                    "tags listed",
+			query:                   "prefix=sea&limit=2",
+			expServiceGetTagsDTO:    &app.GetTagsDTO{Prefix: "sea", Limit: 2},
+			expServiceGetTagsResult: []app.TagCount{{Tag: "seasonal", Count: 3}, {Tag: "seaside", Count: 1}},
+			expStatus:               http.StatusOK,
+			expResponse:             []byte("{\"tags\":[{\"tag\":\"seasonal\",\"count\":3},{\"tag\":\"seaside\",\"count\":1}]}\n"),
+		},
+		{
+			name:                 "no tags",
+			expServiceGetTagsDTO: &app.GetTagsDTO{Limit: 100},
+			expStatus:            http.StatusOK,
+			expResponse:          []byte("{\"tags\":[]}\n"),
+		},
+		{
+			name:                   "tags could not be listed",
+			expServiceGetTagsDTO:   &app.GetTagsDTO{Limit: 100},
+			expServiceGetTagsError: errors.New("service error"),
+			expStatus:              http.StatusInternalServerError,
+			expResponse:            []byte("{\"type\":\"/problems/internal-error\",\"title\":\"Internal server error\",\"status\":500,\"detail\":\"An unexpected error occurred.\",\"instance\":\"/api/v1/tags\"}\n"),
+		},
+		{
+			name:        "invalid limit",
+			query:       "limit=0",
+			expStatus:   http.StatusBadRequest,
+			expResponse: []byte("{\"type\":\"/problems/invalid-parameter\",\"title\":\"Invalid request parameter\",\"status\":400,\"detail\":\"The query parameters are invalid.\",\"instance\":\"/api/v1/tags\",\"errors\":[{\"field\":\"limit\",\"message\":\"must be an integer between 1 and 1000\"}]}\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		mockService := NewMockservice(ctrl)
+
+		if tt.expServiceGetTagsDTO != nil {
+			mockService.
+				EXPECT().
+				GetTags(gomock.Any(), *tt.expServiceGetTagsDTO).
+				Return(tt.expServiceGetTagsResult, tt.expServiceGetTagsError)
+		}
+
+		router, err := NewRouter(mockService, config.HTTP{})
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tags?"+tt.query, nil)
+		recorder := httptest.NewRecorder()
+
+		router.getTagsHandler(recorder, req)
+
+		assert.Equal(t, tt.expStatus, recorder.Code)
+
+		b, err := io.ReadAll(recorder.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, tt.expResponse, b)
+	}
+}
@@ -0,0 +1,266 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+const (
+	createVariantEndpoint string = "POST /api/v1/products/{product_id}/variants"
+	updateVariantEndpoint string = "PUT /api/v1/products/{product_id}/variants/{variant_id}"
+	deleteVariantEndpoint string = "DELETE /api/v1/products/{product_id}/variants/{variant_id}"
+	getVariantEndpoint    string = "GET /api/v1/products/{product_id}/variants/{variant_id}"
+	getVariantsEndpoint   string = "GET /api/v1/products/{product_id}/variants"
+)
+
+// variantRequestBody omits the price for a variant sold at the price of its
+// product. Its stock is informational, the stock that is sold is the
+// inventory of the product.
+type variantRequestBody struct {
+	SKU      string            `json:"sku"`
+	Price    *decimalString    `json:"price"`
+	Currency string            `json:"currency"`
+	Options  map[string]string `json:"options"`
+	Stock    int               `json:"stock"`
+}
+
+func (b variantRequestBody) money() (*app.Money, []fieldError) {
+	if b.Price == nil {
+		return nil, nil
+	}
+
+	m, fieldErrs := parseMoney(*b.Price, b.Currency)
+	if fieldErrs != nil {
+		return nil, fieldErrs
+	}
+
+	return &m, nil
+}
+
+func (r Router) createVariantHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	var body variantRequestBody
+	err := json.NewDecoder(req.Body).Decode(&body)
+	if err != nil {
+		writeBodyError(w, req, err)
+		return
+	}
+
+	productID, err := uuid.Parse(req.PathValue("product_id"))
+	if err != nil {
+		writeInvalidIDError(w, req, "product_id")
+		return
+	}
+
+	price, fieldErrs := body.money()
+	if fieldErrs != nil {
+		writeProblem(w, req, problemValidation, "The request contains invalid data.", fieldErrs...)
+		return
+	}
+
+	dto := app.CreateVariantDTO{
+		ProductID: productID,
+		SKU:       body.SKU,
+		Price:     price,
+		Options:   body.Options,
+		Stock:     body.Stock,
+	}
+
+	v, err := r.service.CreateVariant(ctx, dto)
+	if err != nil {
+		writeServiceError(w, req, err)
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(v.Version))
+	writeJSON(w, req, http.StatusCreated, newVariantResponse(v))
+}
+
+func (r Router) updateVariantHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	var body variantRequestBody
+	err := json.NewDecoder(req.Body).Decode(&body)
+	if err != nil {
+		writeBodyError(w, req, err)
+		return
+	}
+
+	productID, variantID, ok := variantPathIDs(w, req)
+	if !ok {
+		return
+	}
+
+	price, fieldErrs := body.money()
+	if fieldErrs != nil {
+		writeProblem(w, req, problemValidation, "The request contains invalid data.", fieldErrs...)
+		return
+	}
+
+	expectedVersion, ok := r.expectedVersion(w, req)
+	if !ok {
+		return
+	}
+
+	dto := app.UpdateVariantDTO{
+		ProductID:       productID,
+		ID:              variantID,
+		ExpectedVersion: expectedVersion,
+		SKU:             body.SKU,
+		Price:           price,
+		Options:         body.Options,
+		Stock:           body.Stock,
+	}
+
+	v, err := r.service.UpdateVariant(ctx, dto)
+	if err != nil {
+		writeServiceError(w, req, err)
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(v.Version))
+	writeJSON(w, req, http.StatusOK, newVariantResponse(v))
+}
+
+func (r Router) deleteVariantHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	productID, variantID, ok := variantPathIDs(w, req)
+	if !ok {
+		return
+	}
+
+	expectedVersion, ok := r.expectedVersion(w, req)
+	if !ok {
+		return
+	}
+
+	dto := app.DeleteVariantDTO{
+		ProductID:       productID,
+		ID:              variantID,
+		ExpectedVersion: expectedVersion,
+	}
+
+	err := r.service.DeleteVariant(ctx, dto)
+	if err != nil {
+		writeServiceError(w, req, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (r Router) getVariantHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	productID, variantID, ok := variantPathIDs(w, req)
+	if !ok {
+		return
+	}
+
+	v, err := r.service.GetVariant(ctx, productID, variantID)
+	if err != nil {
+		writeServiceError(w, req, err)
+		return
+	}
+
+	writeCacheable(w, req, r.cfg.ProductCacheControl, versionETag(v.Version), v.UpdatedAt, newVariantResponse(v))
+}
+
+func (r Router) getVariantsHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	productID, err := uuid.Parse(req.PathValue("product_id"))
+	if err != nil {
+		writeInvalidIDError(w, req, "product_id")
+		return
+	}
+
+	variants, err := r.service.GetVariants(ctx, productID)
+	if err != nil {
+		writeServiceError(w, req, err)
+		return
+	}
+
+	writeCacheable(w, req, r.cfg.ProductCacheControl, "", time.Time{}, newVariantsResponse(variants))
+}
+
+// variantPathIDs parses the product and variant IDs of the path, writing the
+// problem when one is invalid.
+func variantPathIDs(w http.ResponseWriter, req *http.Request) (productID, variantID uuid.UUID, ok bool) {
+	productID, err := uuid.Parse(req.PathValue("product_id"))
+	if err != nil {
+		writeInvalidIDError(w, req, "product_id")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	variantID, err = uuid.Parse(req.PathValue("variant_id"))
+	if err != nil {
+		writeInvalidIDError(w, req, "variant_id")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return productID, variantID, true
+}
+
+type variantsResponse struct {
+	Variants []variantResponse `json:"variants"`
+}
+
+func newVariantsResponse(variants []*app.Variant) variantsResponse {
+	res := variantsResponse{
+		Variants: make([]variantResponse, 0, len(variants)),
+	}
+	for _, v := range variants {
+		res.Variants = append(res.Variants, newVariantResponse(v))
+	}
+
+	return res
+}
+
+// variantResponse has a null price and currency when the variant is sold at
+// the price of its product. Its stock is informational, see
+// variantRequestBody.
+type variantResponse struct {
+	ID        uuid.UUID         `json:"id"`
+	ProductID uuid.UUID         `json:"product_id"`
+	SKU       string            `json:"sku"`
+	Price     *string           `json:"price"`
+	Currency  *string           `json:"currency"`
+	Options   map[string]string `json:"options"`
+	Stock     int               `json:"stock"`
+	Version   int64             `json:"version"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+func newVariantResponse(v *app.Variant) variantResponse {
+	options := v.Options
+	if options == nil {
+		options = map[string]string{}
+	}
+
+	res := variantResponse{
+		ID:        v.ID,
+		ProductID: v.ProductID,
+		SKU:       v.SKU,
+		Options:   options,
+		Stock:     v.Stock,
+		Version:   v.Version,
+		CreatedAt: v.CreatedAt,
+		UpdatedAt: v.UpdatedAt,
+	}
+
+	if v.Price != nil {
+		price := v.Price.Decimal()
+		res.Price = &price
+		res.Currency = &v.Price.Currency
+	}
+
+	return res
+}
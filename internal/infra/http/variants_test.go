@@ -0,0 +1,204 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/simpler-tha/internal/app"
+	"github.com/simpler-tha/internal/config"
+)
+
+func TestRouter_createVariantHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	productID, _ := uuid.Parse("9f9f4340-6bf9-4948-808c-ebf2dd604e2c")
+	variantID, _ := uuid.Parse("3d7c1f0a-2b8e-4c55-9a61-7f1e0b2c4d5e")
+
+	now, _ := time.Parse(time.RFC3339, "2024-10-02T14:28:34Z")
+
+	price := app.NewMoney(1250, "USD")
+
+	tests := []struct {
+		name                          string
+		reqBody                       []byte
+		expServiceCreateVariantDTO    *app.CreateVariantDTO
+		expServiceCreateVariantResult *app.Variant
+		expServiceCreateVariantError  error
+		expStatus                     int
+		expResponse                   []byte
+	}{
+		{
+			name:    "variant created successfully",
+			reqBody: []byte(`{"sku":"TS-M","price":"12.50","options":{"size":"M"},"stock":5}`),
+			expServiceCreateVariantDTO: &app.CreateVariantDTO{
+				ProductID: productID,
+				SKU:       "TS-M",
+				Price:     &price,
+				Options:   map[string]string{"size": "M"},
+				Stock:     5,
+			},
+			expServiceCreateVariantResult: &app.Variant{
+				ID:        variantID,
+				ProductID: productID,
+				SKU:       "TS-M",
+				Price:     &price,
+				Options:   map[string]string{"size": "M"},
+				Stock:     5,
+				Version:   1,
+				CreatedAt: now,
+				UpdatedAt: now,
+			},
+			expStatus:   http.StatusCreated,
+			expResponse: []byte("{\"id\":\"3d7c1f0a-2b8e-4c55-9a61-7f1e0b2c4d5e\",\"product_id\":\"9f9f4340-6bf9-4948-808c-ebf2dd604e2c\",\"sku\":\"TS-M\",\"price\":\"12.50\",\"currency\":\"USD\",\"options\":{\"size\":\"M\"},\"stock\":5,\"version\":1,\"created_at\":\"2024-10-02T14:28:34Z\",\"updated_at\":\"2024-10-02T14:28:34Z\"}\n"),
+		},
+		{
+			name:    "variant at the price of the product",
+			reqBody: []byte(`{"sku":"TS-L","options":{"size":"L"}}`),
+			expServiceCreateVariantDTO: &app.CreateVariantDTO{
+				ProductID: productID,
+				SKU:       "TS-L",
+				Options:   map[string]string{"size": "L"},
+			},
+			expServiceCreateVariantResult: &app.Variant{
+				ID:        variantID,
+				ProductID: productID,
+				SKU:       "TS-L",
+				Options:   map[string]string{"size": "L"},
+				Version:   1,
+				CreatedAt: now,
+				UpdatedAt: now,
+			},
+			expStatus:   http.StatusCreated,
+			expResponse: []byte("{\"id\":\"3d7c1f0a-2b8e-4c55-9a61-7f1e0b2c4d5e\",\"product_id\":\"9f9f4340-6bf9-4948-808c-ebf2dd604e2c\",\"sku\":\"TS-L\",\"price\":null,\"currency\":null,\"options\":{\"size\":\"L\"},\"stock\":0,\"version\":1,\"created_at\":\"2024-10-02T14:28:34Z\",\"updated_at\":\"2024-10-02T14:28:34Z\"}\n"),
+		},
+		{
+			name:    "SKU already taken",
+			reqBody: []byte(`{"sku":"TS-L","options":{"size":"L"}}`),
+			expServiceCreateVariantDTO: &app.CreateVariantDTO{
+				ProductID: productID,
+				SKU:       "TS-L",
+				Options:   map[string]string{"size": "L"},
+			},
+			expServiceCreateVariantError: fmt.Errorf("failed to create variant: %w", app.ErrConflict),
+			expStatus:                    http.StatusConflict,
+			expResponse:                  []byte("{\"type\":\"/problems/conflict\",\"title\":\"Resource conflict\",\"status\":409,\"detail\":\"The request conflicts with the current state of the resource.\",\"instance\":\"/api/v1/products/9f9f4340-6bf9-4948-808c-ebf2dd604e2c/variants\"}\n"),
+		},
+		{
+			name:        "invalid price",
+			reqBody:     []byte(`{"sku":"TS-L","price":"12.505"}`),
+			expStatus:   http.StatusUnprocessableEntity,
+			expResponse: []byte("{\"type\":\"/problems/validation-failed\",\"title\":\"Validation failed\",\"status\":422,\"detail\":\"The request contains invalid data.\",\"instance\":\"/api/v1/products/9f9f4340-6bf9-4948-808c-ebf2dd604e2c/variants\",\"errors\":[{\"field\":\"price\",\"message\":\"must be a decimal number with at most 2 decimal places\"}]}\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		mockService := NewMockservice(ctrl)
+
+		if tt.expServiceCreateVariantDTO != nil {
+			mockService.
+				EXPECT().
+				CreateVariant(gomock.Any(), *tt.expServiceCreateVariantDTO).
+				Return(tt.expServiceCreateVariantResult, tt.expServiceCreateVariantError)
+		}
+
+		router, err := NewRouter(mockService, config.HTTP{})
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/products/"+productID.String()+"/variants", bytes.NewReader(tt.reqBody))
+		req.SetPathValue("product_id", productID.String())
+		recorder := httptest.NewRecorder()
+
+		router.createVariantHandler(recorder, req)
+
+		assert.Equal(t, tt.expStatus, recorder.Code)
+		if tt.expStatus >= http.StatusBadRequest {
+			assert.Equal(t, problemContentType, recorder.Header().Get("Content-Type"))
+		}
+
+		b, err := io.ReadAll(recorder.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, tt.expResponse, b)
+	}
+}
+
+func TestRouter_getVariantsHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	productID, _ := uuid.Parse("9f9f4340-6bf9-4948-808c-ebf2dd604e2c")
+	variantID, _ := uuid.Parse("3d7c1f0a-2b8e-4c55-9a61-7f1e0b2c4d5e")
+
+	now, _ := time.Parse(time.RFC3339, "2024-10-02T14:28:34Z")
+
+	tests := []struct {
+		name                        string
+		productID                   string
+		expServiceGetVariantsResult []*app.Variant
+		expServiceGetVariantsError  error
+		expStatus                   int
+		expResponse                 []byte
+	}{
+		{
+			name:      "variants of a product",
+			productID: productID.String(),
+			expServiceGetVariantsResult: []*app.Variant{{
+				ID:        variantID,
+				ProductID: productID,
+				SKU:       "TS-M",
+				Stock:     2,
+				Version:   3,
+				CreatedAt: now,
+				UpdatedAt: now,
+			}},
+			expStatus:   http.StatusOK,
+			expResponse: []byte("{\"variants\":[{\"id\":\"3d7c1f0a-2b8e-4c55-9a61-7f1e0b2c4d5e\",\"product_id\":\"9f9f4340-6bf9-4948-808c-ebf2dd604e2c\",\"sku\":\"TS-M\",\"price\":null,\"currency\":null,\"options\":{},\"stock\":2,\"version\":3,\"created_at\":\"2024-10-02T14:28:34Z\",\"updated_at\":\"2024-10-02T14:28:34Z\"}]}\n"),
+		},
+		{
+			name:        "product without variants",
+			productID:   productID.String(),
+			expStatus:   http.StatusOK,
+			expResponse: []byte("{\"variants\":[]}\n"),
+		},
+		{
+			name:                       "product does not exist",
+			productID:                  productID.String(),
+			expServiceGetVariantsError: fmt.Errorf("failed to get product: %w", app.ErrNotFound),
+			expStatus:                  http.StatusNotFound,
+			expResponse:                []byte("{\"type\":\"/problems/not-found\",\"title\":\"Resource not found\",\"status\":404,\"detail\":\"The requested resource does not exist.\",\"instance\":\"/api/v1/products/9f9f4340-6bf9-4948-808c-ebf2dd604e2c/variants\"}\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		mockService := NewMockservice(ctrl)
+
+		mockService.
+			EXPECT().
+			GetVariants(gomock.Any(), productID).
+			Return(tt.expServiceGetVariantsResult, tt.expServiceGetVariantsError)
+
+		router, err := NewRouter(mockService, config.HTTP{})
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/products/"+tt.productID+"/variants", nil)
+		req.SetPathValue("product_id", tt.productID)
+		recorder := httptest.NewRecorder()
+
+		router.getVariantsHandler(recorder, req)
+
+		assert.Equal(t, tt.expStatus, recorder.Code)
+
+		b, err := io.ReadAll(recorder.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, tt.expResponse, b)
+	}
+}
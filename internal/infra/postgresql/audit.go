@@ -0,0 +1,129 @@
+package postgresql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+// CreateAuditEntry stores the entry, in the transaction of the mutation it
+// records when ctx carries one.
+func (r Repository) CreateAuditEntry(ctx context.Context, e *app.AuditEntry) error {
+	const sqlQuery = `
+		INSERT INTO public.audit_log (` + auditColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	before, err := auditPayload(e.Before)
+	if err != nil {
+		return fmt.Errorf("failed to encode before payload of audit entry %s: %w", e.ID, err)
+	}
+
+	after, err := auditPayload(e.After)
+	if err != nil {
+		return fmt.Errorf("failed to encode after payload of audit entry %s: %w", e.ID, err)
+	}
+
+	_, err = r.db(ctx).Exec(ctx, sqlQuery,
+		e.ID, e.Actor, e.Action, e.ResourceType, e.ResourceID, e.RequestID, e.SourceIP, before, after, e.CreatedAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert audit entry in the database: %w", translateError(err))
+	}
+
+	return nil
+}
+
+func (r Repository) GetAuditEntries(ctx context.Context, q app.AuditQuery) ([]app.AuditEntry, error) {
+	var b queryBuilder
+	if q.Filter.Actor != "" {
+		b.where("actor = " + b.arg(q.Filter.Actor))
+	}
+	if q.Filter.ResourceType != "" {
+		b.where("resource_type = " + b.arg(q.Filter.ResourceType))
+	}
+	if q.Filter.ResourceID != uuid.Nil {
+		b.where("resource_id = " + b.arg(q.Filter.ResourceID))
+	}
+	if !q.Filter.Since.IsZero() {
+		b.where("created_at >= " + b.arg(q.Filter.Since.UTC()))
+	}
+	if !q.Filter.Until.IsZero() {
+		b.where("created_at < " + b.arg(q.Filter.Until.UTC()))
+	}
+
+	sqlQuery := `
+		SELECT ` + auditColumns + `
+		FROM public.audit_log` + b.whereClause() + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT ` + b.arg(q.Limit) + ` OFFSET ` + b.arg(q.Offset)
+
+	rows, err := r.client.Pool.Query(ctx, sqlQuery, b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch audit entries from the database: %w", translateError(err))
+	}
+	defer rows.Close()
+
+	var entries []app.AuditEntry
+
+	for rows.Next() {
+		e, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry row: %w", err)
+		}
+		entries = append(entries, *e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error occurred during iteration over audit entry rows: %w", translateError(err))
+	}
+
+	return entries, nil
+}
+
+// auditColumns lists the audit log columns in the order expected by
+// scanAuditEntry.
+const auditColumns = `id, actor, action, resource_type, resource_id, request_id, source_ip, before, after, created_at`
+
+func scanAuditEntry(row pgx.Row) (*app.AuditEntry, error) {
+	var (
+		e             app.AuditEntry
+		before, after []byte
+	)
+
+	err := row.Scan(&e.ID, &e.Actor, &e.Action, &e.ResourceType, &e.ResourceID, &e.RequestID, &e.SourceIP, &before, &after, &e.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if before != nil {
+		err = json.Unmarshal(before, &e.Before)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode before payload of audit entry %s: %w", e.ID, err)
+		}
+	}
+
+	if after != nil {
+		err = json.Unmarshal(after, &e.After)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode after payload of audit entry %s: %w", e.ID, err)
+		}
+	}
+
+	return &e, nil
+}
+
+// auditPayload returns the payload as a query argument, a SQL NULL rather
+// than a JSON null when there is none.
+func auditPayload(v any) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(v)
+}
@@ -0,0 +1,412 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+// Categories are stored with a materialized path: the IDs of the ancestors of
+// a category followed by its own ID, each of them terminated by a slash, e.g.
+// "/<root id>/<parent id>/<id>/". The path of every descendant of a category
+// starts with the path of the category.
+
+// lockCategories serializes the writes that read or rewrite paths. Category
+// writes are rare, a table lock saves reasoning about concurrent moves of
+// overlapping subtrees. Reads are not blocked.
+func lockCategories(ctx context.Context, tx querier) error {
+	_, err := tx.Exec(ctx, `LOCK TABLE public.categories IN SHARE ROW EXCLUSIVE MODE`)
+	return err
+}
+
+// shareCategories blocks the category writes until the transaction of ctx
+// ends, so that a product validated against the attribute schemas in it is
+// not committed after they changed, and the category writes checking the
+// products already stored see it. The lock does not conflict with itself:
+// product writes still run side by side. Outside of a transaction it does
+// nothing.
+func (r Repository) shareCategories(ctx context.Context) error {
+	tx, ok := ctx.Value(txKey{}).(pgx.Tx)
+	if !ok {
+		return nil
+	}
+
+	_, err := tx.Exec(ctx, `LOCK TABLE public.categories IN SHARE MODE`)
+	if err != nil {
+		return fmt.Errorf("failed to lock categories in the database: %w", translateError(err))
+	}
+
+	return nil
+}
+
+// CreateCategory inserts c. Its path is derived from the one of its parent in
+// the database, in case the parent moved since it was read.
+func (r Repository) CreateCategory(ctx context.Context, c *app.Category) error {
+	const sqlQuery = `
+		INSERT INTO public.categories (id, parent_id, name, path, attribute_schema, version, created_at, updated_at)
+		SELECT $1, $2, $3, COALESCE((SELECT path FROM public.categories WHERE id = $2), '/') || $7 || '/', $8::jsonb, $4, $5, $6
+		RETURNING path
+	`
+
+	var path string
+	err := r.WithinTx(ctx, func(ctx context.Context) error {
+		tx := r.db(ctx)
+
+		err := lockCategories(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		return tx.QueryRow(ctx, sqlQuery,
+			c.ID, c.ParentID, c.Name, c.Version, c.CreatedAt.UTC(), c.UpdatedAt.UTC(), c.ID.String(), c.AttributeSchema,
+		).Scan(&path)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to insert category in the database: %w", translateError(err))
+	}
+
+	c.Path, err = parsePath(path)
+	if err != nil {
+		return fmt.Errorf("failed to parse path of category %s: %w", c.ID, err)
+	}
+
+	return nil
+}
+
+// errCategoryVersion reports that a category is missing or changed while it
+// was locked, the reason is looked up after the transaction.
+var errCategoryVersion = errors.New("category version mismatch")
+
+// UpdateCategory stores c and, when it moved, rewrites the paths of its
+// subtree. The descendants get a new version too since their path changed.
+func (r Repository) UpdateCategory(ctx context.Context, c *app.Category) error {
+	const (
+		selectPathQuery = `
+			SELECT path FROM public.categories
+			WHERE id = $1 AND version = $2
+		`
+		selectParentPathQuery = `
+			SELECT path FROM public.categories
+			WHERE id = $1
+		`
+		moveSubtreeQuery = `
+			UPDATE public.categories
+			SET path = $1 || substr(path, $2), updated_at = $3, version = version + 1
+			WHERE starts_with(path, $4) AND id <> $5
+		`
+		updateQuery = `
+			UPDATE public.categories
+			SET name = $1, parent_id = $2, path = $3, attribute_schema = $4, updated_at = $5, version = version + 1
+			WHERE id = $6
+			RETURNING version
+		`
+	)
+
+	var newPath string
+	err := r.WithinTx(ctx, func(ctx context.Context) error {
+		tx := r.db(ctx)
+
+		err := lockCategories(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		var oldPath string
+		err = tx.QueryRow(ctx, selectPathQuery, c.ID, c.Version).Scan(&oldPath)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errCategoryVersion
+		}
+		if err != nil {
+			return err
+		}
+
+		newPath = "/"
+		if c.ParentID != nil {
+			err = tx.QueryRow(ctx, selectParentPathQuery, *c.ParentID).Scan(&newPath)
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("%w: parent category %s has been deleted", app.ErrConflict, *c.ParentID)
+			}
+			if err != nil {
+				return err
+			}
+
+			if strings.Contains(newPath, "/"+c.ID.String()+"/") {
+				return fmt.Errorf("%w: parent category %s has been moved under category %s", app.ErrConflict, *c.ParentID, c.ID)
+			}
+		}
+		newPath += c.ID.String() + "/"
+
+		if newPath != oldPath {
+			_, err = tx.Exec(ctx, moveSubtreeQuery, newPath, len(oldPath)+1, c.UpdatedAt.UTC(), oldPath, c.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.QueryRow(ctx, updateQuery, c.Name, c.ParentID, newPath, c.AttributeSchema, c.UpdatedAt.UTC(), c.ID).Scan(&c.Version)
+	})
+	if errors.Is(err, errCategoryVersion) {
+		err = r.versionMismatchError(ctx, "categories", c.ID, app.ErrConflict)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update category with id %s in the database: %w", c.ID, translateError(err))
+	}
+
+	c.Path, err = parsePath(newPath)
+	if err != nil {
+		return fmt.Errorf("failed to parse path of category %s: %w", c.ID, err)
+	}
+
+	return nil
+}
+
+// DeleteCategory deletes the category and returns it as it was before the
+// deletion.
+func (r Repository) DeleteCategory(ctx context.Context, categoryID uuid.UUID, expectedVersion int64) (*app.Category, error) {
+	const sqlQuery = `
+		DELETE FROM public.categories
+		WHERE id = $1 AND ($2::bigint = 0 OR version = $2::bigint)
+		RETURNING ` + categoryColumns + `
+	`
+
+	c, err := scanCategory(r.db(ctx).QueryRow(ctx, sqlQuery, categoryID, expectedVersion))
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = r.versionMismatchError(ctx, "categories", categoryID, app.ErrPreconditionFailed)
+	}
+
+	// The parent_id foreign key restricts the deletion of a parent.
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgCodeForeignKeyViolation {
+		err = fmt.Errorf("%w: category %s has subcategories", app.ErrConflict, categoryID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete category with id %s from the database: %w", categoryID, translateError(err))
+	}
+
+	return c, nil
+}
+
+func (r Repository) GetCategory(ctx context.Context, categoryID uuid.UUID) (*app.Category, error) {
+	const sqlQuery = `
+		SELECT ` + categoryColumns + `
+		FROM public.categories
+		WHERE id = $1
+	`
+
+	c, err := scanCategory(r.client.Pool.QueryRow(ctx, sqlQuery, categoryID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch category with id %s from the database: %w", categoryID, translateError(err))
+	}
+
+	return c, nil
+}
+
+// GetCategories returns all the categories in path order, so that every
+// category follows its parent.
+func (r Repository) GetCategories(ctx context.Context) ([]*app.Category, error) {
+	const sqlQuery = `
+		SELECT ` + categoryColumns + `
+		FROM public.categories
+		ORDER BY path
+	`
+
+	return r.queryCategories(ctx, sqlQuery)
+}
+
+func (r Repository) GetCategoriesByID(ctx context.Context, ids []uuid.UUID) ([]*app.Category, error) {
+	const sqlQuery = `
+		SELECT ` + categoryColumns + `
+		FROM public.categories
+		WHERE id = ANY($1::uuid[])
+		ORDER BY path
+	`
+
+	return r.queryCategories(ctx, sqlQuery, uuidStrings(ids))
+}
+
+// SetProductCategories replaces the categories of a product. The product row
+// is locked so that concurrent replacements apply one after the other.
+func (r Repository) SetProductCategories(ctx context.Context, productID uuid.UUID, categoryIDs []uuid.UUID) error {
+	const (
+		lockQuery = `
+			SELECT 1 FROM public.products
+			WHERE id = $1 AND deleted_at IS NULL
+			FOR NO KEY UPDATE
+		`
+		deleteQuery = `
+			DELETE FROM public.product_categories
+			WHERE product_id = $1
+		`
+		insertQuery = `
+			INSERT INTO public.product_categories (product_id, category_id)
+			SELECT $1, unnest($2::uuid[])
+		`
+	)
+
+	err := r.WithinTx(ctx, func(ctx context.Context) error {
+		tx := r.db(ctx)
+
+		var one int
+		err := tx.QueryRow(ctx, lockQuery, productID).Scan(&one)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, deleteQuery, productID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, insertQuery, productID, uuidStrings(categoryIDs))
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set categories of product with id %s in the database: %w", productID, translateError(err))
+	}
+
+	return nil
+}
+
+func (r Repository) GetProductCategories(ctx context.Context, productID uuid.UUID) ([]*app.Category, error) {
+	const sqlQuery = `
+		SELECT ` + categoryColumns + `
+		FROM public.categories
+		JOIN public.product_categories pc ON pc.category_id = categories.id
+		WHERE pc.product_id = $1
+		ORDER BY path
+	`
+
+	return r.queryCategories(ctx, sqlQuery, productID)
+}
+
+// GetCategoryProducts returns the products linked to the category or to one
+// of its descendants, in the transaction of ctx when it carries one.
+func (r Repository) GetCategoryProducts(ctx context.Context, categoryID uuid.UUID) ([]*app.Product, error) {
+	const sqlQuery = `
+		SELECT ` + productColumns + `
+		FROM public.products
+		WHERE deleted_at IS NULL AND id IN (
+			SELECT pc.product_id
+			FROM public.product_categories pc
+			JOIN public.categories ON categories.id = pc.category_id
+			WHERE starts_with(categories.path, (SELECT path FROM public.categories WHERE id = $1))
+		)
+		ORDER BY id
+	`
+
+	rows, err := r.db(ctx).Query(ctx, sqlQuery, categoryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch products of category %s from the database: %w", categoryID, translateError(err))
+	}
+	defer rows.Close()
+
+	var products []*app.Product
+
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan product row: %w", err)
+		}
+		products = append(products, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error occurred during iteration over product rows: %w", translateError(err))
+	}
+
+	return products, nil
+}
+
+// queryCategories reads categories, in the transaction of ctx when it carries
+// one. The categories then stay as read until the transaction ends.
+func (r Repository) queryCategories(ctx context.Context, sqlQuery string, args ...any) ([]*app.Category, error) {
+	err := r.shareCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db(ctx).Query(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch categories from the database: %w", translateError(err))
+	}
+	defer rows.Close()
+
+	var categories []*app.Category
+
+	for rows.Next() {
+		c, err := scanCategory(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan category row: %w", err)
+		}
+		categories = append(categories, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error occurred during iteration over category rows: %w", translateError(err))
+	}
+
+	return categories, nil
+}
+
+// categoryColumns lists the category columns in the order expected by
+// scanCategory.
+const categoryColumns = `id, parent_id, name, path, attribute_schema, version, created_at, updated_at`
+
+func scanCategory(row pgx.Row) (*app.Category, error) {
+	var (
+		c      app.Category
+		path   string
+		schema []byte
+	)
+
+	err := row.Scan(&c.ID, &c.ParentID, &c.Name, &path, &schema, &c.Version, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if schema != nil {
+		c.AttributeSchema, err = app.DecodeJSONObject(schema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode attribute schema of category %s: %w", c.ID, err)
+		}
+	}
+
+	c.Path, err = parsePath(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse path of category %s: %w", c.ID, err)
+	}
+
+	return &c, nil
+}
+
+// parsePath parses a materialized path into the IDs it lists.
+func parsePath(path string) ([]uuid.UUID, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+
+	ids := make([]uuid.UUID, len(parts))
+	for i, part := range parts {
+		id, err := uuid.Parse(part)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+
+	return ids, nil
+}
+
+func uuidStrings(ids []uuid.UUID) []string {
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = id.String()
+	}
+
+	return s
+}
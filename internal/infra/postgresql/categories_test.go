@@ -0,0 +1,35 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+func TestParsePath(t *testing.T) {
+	root := uuid.MustParse("9f9f4340-6bf9-4948-808c-ebf2dd604e2c")
+	child := uuid.MustParse("3d7c1f0a-2b8e-4c55-9a61-7f1e0b2c4d5e")
+
+	ids, err := parsePath("/" + root.String() + "/" + child.String() + "/")
+	assert.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{root, child}, ids)
+
+	_, err = parsePath("/not-a-uuid/")
+	assert.Error(t, err)
+}
+
+func TestQueryBuilder_filterCategory(t *testing.T) {
+	id := uuid.MustParse("9f9f4340-6bf9-4948-808c-ebf2dd604e2c")
+
+	var b queryBuilder
+	b.filter(app.ProductFilter{Currency: "EUR", CategoryID: id})
+
+	assert.Equal(t, " WHERE deleted_at IS NULL AND currency = $1 AND EXISTS (SELECT 1 FROM public.product_categories pc"+
+		" JOIN public.categories c ON c.id = pc.category_id"+
+		" JOIN public.categories root ON starts_with(c.path, root.path)"+
+		" WHERE root.id = $2 AND pc.product_id = products.id)", b.whereClause())
+	assert.Equal(t, []any{"EUR", id}, b.args)
+}
@@ -0,0 +1,79 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+// PostgreSQL error codes, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pgCodeUniqueViolation       = "23505"
+	pgCodeForeignKeyViolation   = "23503"
+	pgCodeCheckViolation        = "23514"
+	pgCodeNotNullViolation      = "23502"
+	pgCodeNumericOutOfRange     = "22003"
+	pgCodeStringDataTruncation  = "22001"
+	pgCodeSerializationFailure  = "40001"
+	pgCodeDeadlockDetected      = "40P01"
+	pgCodeTooManyConnections    = "53300"
+	pgCodeAdminShutdown         = "57P01"
+	pgCodeCannotConnectNow      = "57P03"
+	pgClassConnectionException  = "08"
+	pgClassInsufficientResource = "53"
+)
+
+// translateError wraps a database error with the matching app domain error so
+// that callers can classify it. The original error is kept in the chain.
+func translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if kind := classifyError(err); kind != nil {
+		return fmt.Errorf("%w: %w", kind, err)
+	}
+
+	return err
+}
+
+func classifyError(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return app.ErrNotFound
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgCodeUniqueViolation:
+			return app.ErrConflict
+		case pgCodeForeignKeyViolation, pgCodeCheckViolation, pgCodeNotNullViolation,
+			pgCodeNumericOutOfRange, pgCodeStringDataTruncation:
+			return app.ErrValidation
+		case pgCodeSerializationFailure, pgCodeDeadlockDetected, pgCodeTooManyConnections,
+			pgCodeAdminShutdown, pgCodeCannotConnectNow:
+			return app.ErrUnavailable
+		}
+
+		if len(pgErr.Code) >= 2 {
+			switch pgErr.Code[:2] {
+			case pgClassConnectionException, pgClassInsufficientResource:
+				return app.ErrUnavailable
+			}
+		}
+
+		return nil
+	}
+
+	var connectErr *pgconn.ConnectError
+	if errors.As(err, &connectErr) || pgconn.Timeout(err) || errors.Is(err, context.DeadlineExceeded) {
+		return app.ErrUnavailable
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		expErr error
+	}{
+		{name: "no rows", err: pgx.ErrNoRows, expErr: app.ErrNotFound},
+		{name: "wrapped no rows", err: fmt.Errorf("scan: %w", pgx.ErrNoRows), expErr: app.ErrNotFound},
+		{name: "unique violation", err: &pgconn.PgError{Code: pgCodeUniqueViolation}, expErr: app.ErrConflict},
+		{name: "foreign key violation", err: &pgconn.PgError{Code: pgCodeForeignKeyViolation}, expErr: app.ErrValidation},
+		{name: "check violation", err: &pgconn.PgError{Code: pgCodeCheckViolation}, expErr: app.ErrValidation},
+		{name: "not null violation", err: &pgconn.PgError{Code: pgCodeNotNullViolation}, expErr: app.ErrValidation},
+		{name: "numeric value out of range", err: &pgconn.PgError{Code: pgCodeNumericOutOfRange}, expErr: app.ErrValidation},
+		{name: "string data right truncation", err: &pgconn.PgError{Code: pgCodeStringDataTruncation}, expErr: app.ErrValidation},
+		{name: "serialization failure", err: &pgconn.PgError{Code: pgCodeSerializationFailure}, expErr: app.ErrUnavailable},
+		{name: "deadlock detected", err: &pgconn.PgError{Code: pgCodeDeadlockDetected}, expErr: app.ErrUnavailable},
+		{name: "too many connections", err: &pgconn.PgError{Code: pgCodeTooManyConnections}, expErr: app.ErrUnavailable},
+		{name: "admin shutdown", err: &pgconn.PgError{Code: pgCodeAdminShutdown}, expErr: app.ErrUnavailable},
+		{name: "cannot connect now", err: &pgconn.PgError{Code: pgCodeCannotConnectNow}, expErr: app.ErrUnavailable},
+		{name: "connection exception class", err: &pgconn.PgError{Code: "08006"}, expErr: app.ErrUnavailable},
+		{name: "insufficient resources class", err: &pgconn.PgError{Code: "53200"}, expErr: app.ErrUnavailable},
+		{name: "wrapped postgres error", err: fmt.Errorf("insert: %w", &pgconn.PgError{Code: pgCodeUniqueViolation}), expErr: app.ErrConflict},
+		{name: "other postgres error", err: &pgconn.PgError{Code: "42601"}, expErr: nil},
+		{name: "postgres error without code", err: &pgconn.PgError{}, expErr: nil},
+		{name: "connect error", err: &pgconn.ConnectError{}, expErr: app.ErrUnavailable},
+		{name: "deadline exceeded", err: context.DeadlineExceeded, expErr: app.ErrUnavailable},
+		{name: "unknown error", err: errors.New("boom"), expErr: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expErr, classifyError(tt.err))
+		})
+	}
+}
+
+func TestTranslateError(t *testing.T) {
+	assert.NoError(t, translateError(nil))
+
+	pgErr := &pgconn.PgError{Code: pgCodeUniqueViolation}
+	err := translateError(pgErr)
+	assert.ErrorIs(t, err, app.ErrConflict)
+	assert.ErrorIs(t, err, pgErr)
+
+	err = translateError(pgx.ErrNoRows)
+	assert.ErrorIs(t, err, app.ErrNotFound)
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+
+	unknown := errors.New("boom")
+	assert.Same(t, unknown, translateError(unknown))
+}
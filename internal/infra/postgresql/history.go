@@ -0,0 +1,70 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+// historyColumns lists the product columns copied to the history.
+const historyColumns = `id, sku, slug, status, name, description, price, currency, tags, attributes, version, created_at, updated_at, deleted_at`
+
+// recordProductHistory closes the validity period of the version of the
+// product in the history and opens the one of the version now in the products
+// table, in the transaction of the write that stored it. The periods of
+// consecutive versions meet, and they never go back in time even if the
+// database clock does. The first version is valid from the creation of the
+// product, unless the clock of the application runs ahead.
+func (r Repository) recordProductHistory(ctx context.Context, productID uuid.UUID) error {
+	const sqlQuery = `
+		WITH closed AS (
+			UPDATE public.products_history
+			SET valid_period = tstzrange(lower(valid_period), greatest(lower(valid_period), clock_timestamp()))
+			WHERE id = $1 AND upper_inf(valid_period)
+			RETURNING upper(valid_period) AS valid_until
+		)
+		INSERT INTO public.products_history (` + historyColumns + `, valid_period)
+		SELECT ` + historyColumns + `, tstzrange(coalesce((SELECT valid_until FROM closed), least(created_at, clock_timestamp())), NULL)
+		FROM public.products
+		WHERE id = $1
+	`
+
+	_, err := r.db(ctx).Exec(ctx, sqlQuery, productID)
+	if err != nil {
+		return fmt.Errorf("failed to record history of product %s in the database: %w", productID, err)
+	}
+
+	return nil
+}
+
+// GetProductAsOf reads the version of the product whose validity period
+// contains asOf.
+func (r Repository) GetProductAsOf(ctx context.Context, productID uuid.UUID, asOf time.Time) (*app.Product, error) {
+	const sqlQuery = `
+		SELECT ` + productColumns + `
+		FROM public.products_history
+		WHERE id = $1 AND valid_period @> $2::timestamptz AND deleted_at IS NULL
+	`
+
+	p, err := scanProduct(r.client.Pool.QueryRow(ctx, sqlQuery, productID, asOf.UTC()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch product with id %s as of %s from the database: %w", productID, asOf.Format(time.RFC3339Nano), translateError(err))
+	}
+
+	return p, nil
+}
+
+// productsTable returns the table the products matching f are read from. The
+// history is aliased to the name of the products table so that the
+// conditions of the filter apply to both.
+func productsTable(f app.ProductFilter) string {
+	if !f.AsOf.IsZero() {
+		return `public.products_history AS products`
+	}
+
+	return `public.products`
+}
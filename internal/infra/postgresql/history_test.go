@@ -0,0 +1,106 @@
+package postgresql
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+// testRepository returns a repository on the migrated database of
+// POSTGRES_TEST_URL, and skips the test when it is not set.
+func testRepository(t *testing.T) Repository {
+	t.Helper()
+
+	url := os.Getenv("POSTGRES_TEST_URL")
+	if url == "" {
+		t.Skip("POSTGRES_TEST_URL is not set")
+	}
+
+	pool, err := pgxpool.New(context.Background(), url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+
+	r, err := NewRepository(&Client{Pool: pool}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return r
+}
+
+func TestRepository_GetProductAsOf(t *testing.T) {
+	r := testRepository(t)
+	ctx := context.Background()
+
+	t.Run("product created before the history", func(t *testing.T) {
+		p := app.NewProduct("Backfilled Product", "Description", app.NewMoney(1000, "EUR"), nil, nil, "")
+		p.Slug += "-" + p.ID.String()
+		p.CreatedAt = time.Now().UTC().Add(-2 * time.Hour).Truncate(time.Millisecond)
+		p.UpdatedAt = p.CreatedAt.Add(time.Hour)
+		t.Cleanup(func() { deleteTestProduct(t, r, p) })
+
+		// The product is stored without a history, as by the versions before
+		// it was recorded, and backfilled by running its migration again.
+		_, err := r.client.Pool.Exec(ctx, `
+			INSERT INTO public.products (id, sku, slug, status, name, description, price, currency, tags, attributes, version, created_at, updated_at)
+			VALUES ($1, NULL, $2, $3, $4, $5, $6, $7, '{}', '{}', $8, $9, $10)
+		`, p.ID, p.Slug, p.Status, p.Name, p.Description, p.Price.Decimal(), p.Price.Currency, p.Version, p.CreatedAt, p.UpdatedAt)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		migration, err := os.ReadFile("../../../migrations/000016_create_products_history.up.sql")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = r.client.Pool.Exec(ctx, string(migration))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := r.GetProductAsOf(ctx, p.ID, p.CreatedAt.Add(30*time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, p.Name, got.Name)
+
+		_, err = r.GetProductAsOf(ctx, p.ID, p.CreatedAt.Add(-time.Minute))
+		assert.ErrorIs(t, err, app.ErrNotFound)
+	})
+
+	t.Run("product created with the history", func(t *testing.T) {
+		p := app.NewProduct("Recorded Product", "Description", app.NewMoney(1000, "EUR"), nil, nil, "")
+		p.Slug += "-" + p.ID.String()
+		t.Cleanup(func() { deleteTestProduct(t, r, p) })
+
+		err := r.CreateProduct(ctx, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := r.GetProductAsOf(ctx, p.ID, p.CreatedAt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, p.Name, got.Name)
+	})
+}
+
+// deleteTestProduct removes p and its history from the database.
+func deleteTestProduct(t *testing.T, r Repository, p *app.Product) {
+	t.Helper()
+
+	ctx := context.Background()
+	_, err := r.client.Pool.Exec(ctx, `DELETE FROM public.products WHERE id = $1`, p.ID)
+	assert.NoError(t, err)
+	_, err = r.client.Pool.Exec(ctx, `DELETE FROM public.products_history WHERE id = $1`, p.ID)
+	assert.NoError(t, err)
+}
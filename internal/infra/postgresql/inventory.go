@@ -0,0 +1,485 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+// Stock is never read and then written without holding the lock of the
+// inventory rows involved: every write locks them with lockInventories, in the
+// order of their product IDs so that two checkouts of the same products cannot
+// deadlock. Writes that involve a reservation lock the reservation first.
+
+// GetInventory returns the inventory of the product. Products whose stock was
+// never adjusted have no inventory row and an empty inventory.
+func (r Repository) GetInventory(ctx context.Context, productID uuid.UUID) (*app.Inventory, error) {
+	const sqlQuery = `
+		SELECT p.id, coalesce(i.on_hand, 0), coalesce(i.reserved, 0), i.updated_at
+		FROM public.products AS p
+		LEFT JOIN public.product_inventory AS i ON i.product_id = p.id
+		WHERE p.id = $1 AND p.deleted_at IS NULL
+	`
+
+	var (
+		inv       app.Inventory
+		updatedAt *time.Time
+	)
+	err := r.client.Pool.QueryRow(ctx, sqlQuery, productID).Scan(&inv.ProductID, &inv.OnHand, &inv.Reserved, &updatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch inventory of product %s from the database: %w", productID, translateError(err))
+	}
+	if updatedAt != nil {
+		inv.UpdatedAt = *updatedAt
+	}
+
+	return &inv, nil
+}
+
+func (r Repository) AdjustStock(ctx context.Context, a *app.StockAdjustment) (*app.Inventory, error) {
+	const (
+		updateQuery = `
+			UPDATE public.product_inventory
+			SET on_hand = on_hand + $2, updated_at = $3
+			WHERE product_id = $1
+			RETURNING on_hand, reserved, updated_at
+		`
+		insertQuery = `
+			INSERT INTO public.stock_adjustments (id, product_id, delta, reason, note, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`
+	)
+
+	inv := app.Inventory{ProductID: a.ProductID}
+	err := r.WithinTx(ctx, func(ctx context.Context) error {
+		tx := r.db(ctx)
+
+		locked, err := lockInventories(ctx, tx, []uuid.UUID{a.ProductID})
+		if err != nil {
+			return err
+		}
+
+		if current := locked[a.ProductID]; current.OnHand+a.Delta < current.Reserved {
+			return &app.InsufficientStockError{Shortages: []app.StockShortage{
+				{ProductID: a.ProductID, Requested: -a.Delta, Available: current.Available()},
+			}}
+		}
+
+		err = tx.QueryRow(ctx, updateQuery, a.ProductID, a.Delta, a.CreatedAt.UTC()).Scan(&inv.OnHand, &inv.Reserved, &inv.UpdatedAt)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, insertQuery, a.ID, a.ProductID, a.Delta, a.Reason, a.Note, a.CreatedAt.UTC())
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to adjust stock of product %s in the database: %w", a.ProductID, translateError(err))
+	}
+
+	return &inv, nil
+}
+
+func (r Repository) GetStockAdjustments(ctx context.Context, productID uuid.UUID, limit int) ([]app.StockAdjustment, error) {
+	const sqlQuery = `
+		SELECT id, product_id, delta, reason, note, reservation_id, created_at
+		FROM public.stock_adjustments
+		WHERE product_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2
+	`
+
+	rows, err := r.client.Pool.Query(ctx, sqlQuery, productID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch stock adjustments from the database: %w", translateError(err))
+	}
+	defer rows.Close()
+
+	var adjustments []app.StockAdjustment
+
+	for rows.Next() {
+		var (
+			a             app.StockAdjustment
+			reservationID *uuid.UUID
+		)
+		err := rows.Scan(&a.ID, &a.ProductID, &a.Delta, &a.Reason, &a.Note, &reservationID, &a.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan stock adjustment row: %w", err)
+		}
+		if reservationID != nil {
+			a.ReservationID = *reservationID
+		}
+		adjustments = append(adjustments, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error occurred during iteration over stock adjustment rows: %w", translateError(err))
+	}
+
+	return adjustments, nil
+}
+
+func (r Repository) CreateReservation(ctx context.Context, res *app.Reservation) error {
+	const (
+		reserveQuery = `
+			UPDATE public.product_inventory AS i
+			SET reserved = i.reserved + items.quantity, updated_at = $3
+			FROM unnest($1::uuid[], $2::int[]) AS items (product_id, quantity)
+			WHERE i.product_id = items.product_id
+		`
+		insertQuery = `
+			INSERT INTO public.stock_reservations (id, status, expires_at, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5)
+		`
+		insertItemsQuery = `
+			INSERT INTO public.stock_reservation_items (reservation_id, product_id, quantity)
+			SELECT $1, product_id, quantity
+			FROM unnest($2::uuid[], $3::int[]) AS items (product_id, quantity)
+		`
+	)
+
+	productIDs, quantities := reservationItemsArgs(res.Items)
+
+	err := r.WithinTx(ctx, func(ctx context.Context) error {
+		tx := r.db(ctx)
+
+		locked, err := lockInventories(ctx, tx, productIDs)
+		if err != nil {
+			return err
+		}
+
+		var shortages []app.StockShortage
+		for _, item := range res.Items {
+			if available := locked[item.ProductID].Available(); item.Quantity > available {
+				shortages = append(shortages, app.StockShortage{ProductID: item.ProductID, Requested: item.Quantity, Available: available})
+			}
+		}
+		if shortages != nil {
+			return &app.InsufficientStockError{Shortages: shortages}
+		}
+
+		_, err = tx.Exec(ctx, reserveQuery, productIDs, quantities, res.CreatedAt.UTC())
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, insertQuery, res.ID, res.Status, res.ExpiresAt.UTC(), res.CreatedAt.UTC(), res.UpdatedAt.UTC())
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, insertItemsQuery, res.ID, productIDs, quantities)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to insert reservation in the database: %w", translateError(err))
+	}
+
+	return nil
+}
+
+func (r Repository) GetReservation(ctx context.Context, reservationID uuid.UUID) (*app.Reservation, error) {
+	const (
+		reservationQuery = `
+			SELECT id, status, expires_at, created_at, updated_at
+			FROM public.stock_reservations
+			WHERE id = $1
+		`
+		itemsQuery = `
+			SELECT product_id, quantity
+			FROM public.stock_reservation_items
+			WHERE reservation_id = $1
+			ORDER BY product_id
+		`
+	)
+
+	var res app.Reservation
+	err := r.client.Pool.QueryRow(ctx, reservationQuery, reservationID).Scan(&res.ID, &res.Status, &res.ExpiresAt, &res.CreatedAt, &res.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch reservation with id %s from the database: %w", reservationID, translateError(err))
+	}
+
+	res.Items, err = reservationItems(ctx, r.client.Pool, itemsQuery, reservationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch items of reservation %s from the database: %w", reservationID, translateError(err))
+	}
+
+	return &res, nil
+}
+
+// UpdateReservationStatus stores the new status of res if the reservation is
+// still pending, then releases its units and, when it was committed, takes
+// them out of the stock on hand. The items are read again from the database:
+// the ones of products deleted in the meantime are gone.
+func (r Repository) UpdateReservationStatus(ctx context.Context, res *app.Reservation) error {
+	const (
+		updateQuery = `
+			UPDATE public.stock_reservations
+			SET status = $2, updated_at = $3
+			WHERE id = $1 AND status = 'pending'
+		`
+		itemsQuery = `
+			SELECT product_id, quantity
+			FROM public.stock_reservation_items
+			WHERE reservation_id = $1
+			ORDER BY product_id
+		`
+	)
+
+	err := r.WithinTx(ctx, func(ctx context.Context) error {
+		tx := r.db(ctx)
+
+		tag, err := tx.Exec(ctx, updateQuery, res.ID, res.Status, res.UpdatedAt.UTC())
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return r.reservationNotPendingError(ctx, tx, res.ID)
+		}
+
+		items, err := reservationItems(ctx, tx, itemsQuery, res.ID)
+		if err != nil {
+			return err
+		}
+
+		return releaseStock(ctx, tx, items, res.UpdatedAt, res.Status == app.ReservationCommitted, res.ID)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update reservation with id %s in the database: %w", res.ID, translateError(err))
+	}
+
+	return nil
+}
+
+// reservationNotPendingError explains why a pending reservation could not be
+// updated: either it does not exist or another request moved it first.
+func (r Repository) reservationNotPendingError(ctx context.Context, tx querier, reservationID uuid.UUID) error {
+	var status app.ReservationStatus
+	err := tx.QueryRow(ctx, `SELECT status FROM public.stock_reservations WHERE id = $1`, reservationID).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return app.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	return fmt.Errorf("%w: reservation %s is %s", app.ErrConflict, reservationID, status)
+}
+
+// ExpireReservations expires the oldest pending reservations past their
+// expiry. Reservations locked by a concurrent commit or release are skipped,
+// that request decides what happens to them. It returns the IDs of the expired
+// reservations.
+func (r Repository) ExpireReservations(ctx context.Context, now time.Time, limit int) ([]uuid.UUID, error) {
+	const (
+		expireQuery = `
+			WITH expired AS (
+				SELECT id
+				FROM public.stock_reservations
+				WHERE status = 'pending' AND expires_at <= $1
+				ORDER BY expires_at
+				LIMIT $2
+				FOR UPDATE SKIP LOCKED
+			)
+			UPDATE public.stock_reservations AS r
+			SET status = 'expired', updated_at = $1
+			FROM expired
+			WHERE r.id = expired.id
+			RETURNING r.id
+		`
+		itemsQuery = `
+			SELECT product_id, sum(quantity)::int
+			FROM public.stock_reservation_items
+			WHERE reservation_id = ANY($1)
+			GROUP BY product_id
+			ORDER BY product_id
+		`
+	)
+
+	var expired []uuid.UUID
+	err := r.WithinTx(ctx, func(ctx context.Context) error {
+		tx := r.db(ctx)
+
+		rows, err := tx.Query(ctx, expireQuery, now.UTC(), limit)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var id uuid.UUID
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			expired = append(expired, id)
+		}
+		if err := rows.Err(); err != nil || len(expired) == 0 {
+			return err
+		}
+
+		items, err := reservationItems(ctx, tx, itemsQuery, expired)
+		if err != nil {
+			return err
+		}
+
+		return releaseStock(ctx, tx, items, now, false, uuid.Nil)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to expire reservations in the database: %w", translateError(err))
+	}
+
+	return expired, nil
+}
+
+// lockInventories locks the inventory rows of the products, creating the
+// missing ones, and returns them by product ID. It returns ErrNotFound when
+// one of the products does not exist or is soft deleted.
+func lockInventories(ctx context.Context, tx querier, productIDs []uuid.UUID) (map[uuid.UUID]app.Inventory, error) {
+	const (
+		createQuery = `
+			INSERT INTO public.product_inventory (product_id, updated_at)
+			SELECT id, now()
+			FROM public.products
+			WHERE id = ANY($1) AND deleted_at IS NULL
+			ORDER BY id
+			ON CONFLICT (product_id) DO NOTHING
+		`
+		lockQuery = `
+			SELECT i.product_id, i.on_hand, i.reserved, i.updated_at
+			FROM public.product_inventory AS i
+			JOIN public.products AS p ON p.id = i.product_id
+			WHERE i.product_id = ANY($1) AND p.deleted_at IS NULL
+			ORDER BY i.product_id
+			FOR UPDATE OF i
+		`
+	)
+
+	_, err := tx.Exec(ctx, createQuery, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, lockQuery, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	locked := make(map[uuid.UUID]app.Inventory, len(productIDs))
+
+	for rows.Next() {
+		var inv app.Inventory
+		if err := rows.Scan(&inv.ProductID, &inv.OnHand, &inv.Reserved, &inv.UpdatedAt); err != nil {
+			return nil, err
+		}
+		locked[inv.ProductID] = inv
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, id := range productIDs {
+		if _, ok := locked[id]; !ok {
+			return nil, fmt.Errorf("%w: product %s does not exist", app.ErrNotFound, id)
+		}
+	}
+
+	return locked, nil
+}
+
+// releaseStock gives back the reserved units of items. When sold is set, the
+// units also leave the stock on hand and a sale of reservationID is recorded
+// for every item.
+func releaseStock(ctx context.Context, tx querier, items []app.ReservationItem, now time.Time, sold bool, reservationID uuid.UUID) error {
+	const (
+		releaseQuery = `
+			UPDATE public.product_inventory AS i
+			SET reserved = i.reserved - items.quantity,
+				on_hand = i.on_hand - CASE WHEN $3::boolean THEN items.quantity ELSE 0 END,
+				updated_at = $4
+			FROM unnest($1::uuid[], $2::int[]) AS items (product_id, quantity)
+			WHERE i.product_id = items.product_id
+		`
+		saleQuery = `
+			INSERT INTO public.stock_adjustments (id, product_id, delta, reason, note, reservation_id, created_at)
+			SELECT id, product_id, -quantity, $4, '', $5, $6
+			FROM unnest($1::uuid[], $2::uuid[], $3::int[]) AS items (id, product_id, quantity)
+		`
+	)
+
+	if len(items) == 0 {
+		return nil
+	}
+
+	productIDs, quantities := reservationItemsArgs(items)
+
+	// Take the row locks in product ID order before the update does.
+	_, err := tx.Exec(ctx, `
+		SELECT 1 FROM public.product_inventory
+		WHERE product_id = ANY($1)
+		ORDER BY product_id
+		FOR UPDATE
+	`, productIDs)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, releaseQuery, productIDs, quantities, sold, now.UTC())
+	if err != nil || !sold {
+		return err
+	}
+
+	adjustmentIDs := make([]uuid.UUID, len(productIDs))
+	for i := range adjustmentIDs {
+		adjustmentIDs[i] = uuid.New()
+	}
+
+	_, err = tx.Exec(ctx, saleQuery, adjustmentIDs, productIDs, quantities, app.StockAdjustmentSale, reservationID, now.UTC())
+	return err
+}
+
+// reservationItems runs a query selecting product IDs and quantities.
+func reservationItems(ctx context.Context, q querier, sqlQuery string, args ...any) ([]app.ReservationItem, error) {
+	rows, err := q.Query(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []app.ReservationItem
+
+	for rows.Next() {
+		var item app.ReservationItem
+		if err := rows.Scan(&item.ProductID, &item.Quantity); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// reservationItemsArgs returns the product IDs and quantities of the items as
+// parallel arrays, sorted by product ID.
+func reservationItemsArgs(items []app.ReservationItem) ([]uuid.UUID, []int32) {
+	sorted := slices.Clone(items)
+	slices.SortFunc(sorted, func(a, b app.ReservationItem) int {
+		return slices.Compare(a.ProductID[:], b.ProductID[:])
+	})
+
+	productIDs := make([]uuid.UUID, len(sorted))
+	quantities := make([]int32, len(sorted))
+	for i, item := range sorted {
+		productIDs[i] = item.ProductID
+		quantities[i] = int32(item.Quantity)
+	}
+
+	return productIDs, quantities
+}
@@ -0,0 +1,131 @@
+package postgresql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+// CreateOutboxEvent writes the event, in the transaction of the change it
+// announces when ctx carries one. The event is due right away.
+func (r Repository) CreateOutboxEvent(ctx context.Context, e *app.Event) error {
+	const sqlQuery = `
+		INSERT INTO public.outbox_events (id, event_type, aggregate_id, aggregate_version, payload, occurred_at, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+	`
+
+	payload, err := json.Marshal(e.Payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload of event %s: %w", e.ID, err)
+	}
+
+	_, err = r.db(ctx).Exec(ctx, sqlQuery, e.ID, e.Type, e.AggregateID, e.AggregateVersion, payload, e.OccurredAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to insert event in the outbox: %w", translateError(err))
+	}
+
+	return nil
+}
+
+// ClaimOutboxEvents leases the oldest due events that no earlier pending
+// event of their product precedes, so that the events of a product are
+// published one after the other, in order, even when one of them keeps
+// failing. The lease is taken in a single statement, events being claimed by
+// a concurrent relay are skipped.
+func (r Repository) ClaimOutboxEvents(ctx context.Context, now, leaseUntil time.Time, limit int) ([]app.Event, error) {
+	const sqlQuery = `
+		WITH claimed AS (
+			SELECT id
+			FROM public.outbox_events AS e
+			WHERE published_at IS NULL
+				AND next_attempt_at <= $1
+				AND NOT EXISTS (
+					SELECT 1
+					FROM public.outbox_events AS earlier
+					WHERE earlier.aggregate_id = e.aggregate_id
+						AND earlier.published_at IS NULL
+						AND (earlier.occurred_at, earlier.id) < (e.occurred_at, e.id)
+				)
+			ORDER BY occurred_at, id
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		), leased AS (
+			UPDATE public.outbox_events AS e
+			SET next_attempt_at = $2
+			FROM claimed
+			WHERE e.id = claimed.id
+			RETURNING e.id, e.event_type, e.aggregate_id, e.aggregate_version, e.payload, e.occurred_at, e.attempts
+		)
+		SELECT id, event_type, aggregate_id, aggregate_version, payload, occurred_at, attempts
+		FROM leased
+		ORDER BY occurred_at, id
+	`
+
+	rows, err := r.db(ctx).Query(ctx, sqlQuery, now.UTC(), leaseUntil.UTC(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch events from the outbox: %w", translateError(err))
+	}
+	defer rows.Close()
+
+	var events []app.Event
+
+	for rows.Next() {
+		var (
+			e       app.Event
+			payload []byte
+		)
+
+		err := rows.Scan(&e.ID, &e.Type, &e.AggregateID, &e.AggregateVersion, &payload, &e.OccurredAt, &e.Attempts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan outbox event row: %w", err)
+		}
+
+		err = json.Unmarshal(payload, &e.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode payload of event %s: %w", e.ID, err)
+		}
+
+		events = append(events, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error occurred during iteration over outbox event rows: %w", translateError(err))
+	}
+
+	return events, nil
+}
+
+func (r Repository) MarkOutboxEventPublished(ctx context.Context, eventID uuid.UUID, publishedAt time.Time) error {
+	const sqlQuery = `
+		UPDATE public.outbox_events
+		SET published_at = $2, last_error = NULL
+		WHERE id = $1
+	`
+
+	_, err := r.db(ctx).Exec(ctx, sqlQuery, eventID, publishedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to mark event %s as published in the outbox: %w", eventID, translateError(err))
+	}
+
+	return nil
+}
+
+func (r Repository) MarkOutboxEventFailed(ctx context.Context, eventID uuid.UUID, retryAt time.Time, reason string) error {
+	const sqlQuery = `
+		UPDATE public.outbox_events
+		SET attempts = attempts + 1, next_attempt_at = $2, last_error = $3
+		WHERE id = $1
+	`
+
+	_, err := r.db(ctx).Exec(ctx, sqlQuery, eventID, retryAt.UTC(), reason)
+	if err != nil {
+		return fmt.Errorf("failed to mark event %s as failed in the outbox: %w", eventID, translateError(err))
+	}
+
+	return nil
+}
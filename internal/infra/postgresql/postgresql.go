@@ -1,37 +1,118 @@
-package postgresql
-
-import (
-	"context"
-	"fmt"
-
-	"github.com/jackc/pgx/v5"
-
-	"github.com/simpler-tha/internal/config"
-)
-
-type Client struct {
-	Conn *pgx.Conn
-}
-
-// NewClient sets up the Postgres client.
-func NewClient(ctx context.Context, cfg config.Postgres) (*Client, error) {
-	connStr := buildConnString(cfg.Username, cfg.Pass, cfg.Host, cfg.Database, cfg.Port)
-
-	conn, err := pgx.Connect(ctx, connStr)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
-	}
-
-	err = conn.Ping(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to ping database: %w", err)
-	}
-
-	return &Client{
-		Conn: conn,
-	}, nil
-}
-
-func buildConnString(user, pass, host, dbName string, port int) string {
-	return fmt.Sprintf("postgres://%s:%s@%s:%d/%s", user, pass, host, port, dbName)
-}
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/simpler-tha/internal/config"
+)
+
+type Client struct {
+	Pool *pgxpool.Pool
+}
+
+// NewClient sets up the Postgres client backed by a connection pool.
+func NewClient(ctx context.Context, cfg config.Postgres) (*Client, error) {
+	connStr := buildConnString(cfg.Username, cfg.Pass, cfg.Host, cfg.Database, cfg.Port)
+
+	poolCfg, err := pgxpool.ParseConfig(connStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse connection string: %w", err)
+	}
+
+	err = applyPoolConfig(poolCfg, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	err = pool.Ping(ctx)
+	if err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return &Client{
+		Pool: pool,
+	}, nil
+}
+
+// Close closes all the connections of the pool.
+func (c *Client) Close() {
+	c.Pool.Close()
+}
+
+// applyPoolConfig overrides the pgxpool defaults with the values that are set
+// in the configuration.
+func applyPoolConfig(poolCfg *pgxpool.Config, cfg config.Postgres) error {
+	if cfg.MaxConns > 0 {
+		poolCfg.MaxConns = cfg.MaxConns
+	}
+	if cfg.MinConns > 0 {
+		poolCfg.MinConns = cfg.MinConns
+	}
+	if cfg.MaxConnIdleTime > 0 {
+		poolCfg.MaxConnIdleTime = cfg.MaxConnIdleTime
+	}
+	if cfg.MaxConnLifetime > 0 {
+		poolCfg.MaxConnLifetime = cfg.MaxConnLifetime
+	}
+	if cfg.HealthCheckPeriod > 0 {
+		poolCfg.HealthCheckPeriod = cfg.HealthCheckPeriod
+	}
+
+	if poolCfg.MinConns > poolCfg.MaxConns {
+		return fmt.Errorf("min connections (%d) cannot exceed max connections (%d)", poolCfg.MinConns, poolCfg.MaxConns)
+	}
+
+	return nil
+}
+
+// PoolStats is a snapshot of the connection pool usage.
+type PoolStats struct {
+	MaxConns                int32         `json:"max_conns"`
+	TotalConns              int32         `json:"total_conns"`
+	AcquiredConns           int32         `json:"acquired_conns"`
+	IdleConns               int32         `json:"idle_conns"`
+	ConstructingConns       int32         `json:"constructing_conns"`
+	AcquireCount            int64         `json:"acquire_count"`
+	EmptyAcquireCount       int64         `json:"empty_acquire_count"`
+	CanceledAcquireCount    int64         `json:"canceled_acquire_count"`
+	AcquireDuration         time.Duration `json:"acquire_duration_ns"`
+	NewConnsCount           int64         `json:"new_conns_count"`
+	MaxLifetimeDestroyCount int64         `json:"max_lifetime_destroy_count"`
+	MaxIdleDestroyCount     int64         `json:"max_idle_destroy_count"`
+}
+
+// Stats returns the current statistics of the connection pool. A high
+// empty acquire count relative to the acquire count means that requests
+// are waiting for a connection and the pool is undersized.
+func (c *Client) Stats() PoolStats {
+	s := c.Pool.Stat()
+
+	return PoolStats{
+		MaxConns:                s.MaxConns(),
+		TotalConns:              s.TotalConns(),
+		AcquiredConns:           s.AcquiredConns(),
+		IdleConns:               s.IdleConns(),
+		ConstructingConns:       s.ConstructingConns(),
+		AcquireCount:            s.AcquireCount(),
+		EmptyAcquireCount:       s.EmptyAcquireCount(),
+		CanceledAcquireCount:    s.CanceledAcquireCount(),
+		AcquireDuration:         s.AcquireDuration(),
+		NewConnsCount:           s.NewConnsCount(),
+		MaxLifetimeDestroyCount: s.MaxLifetimeDestroyCount(),
+		MaxIdleDestroyCount:     s.MaxIdleDestroyCount(),
+	}
+}
+
+func buildConnString(user, pass, host, dbName string, port int) string {
+	return fmt.Sprintf("postgres://%s:%s@%s:%d/%s", user, pass, host, port, dbName)
+}
@@ -0,0 +1,200 @@
+package postgresql
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+// sortColumns maps the sortable fields to their column and to the type
+// that cursor keys, which are strings, must be cast to.
+var sortColumns = map[app.SortField]struct{ column, cast string }{
+	app.SortByName:      {column: "name", cast: "text"},
+	app.SortByPrice:     {column: "price", cast: "numeric"},
+	app.SortByCreatedAt: {column: "created_at", cast: "timestamptz"},
+	app.SortByUpdatedAt: {column: "updated_at", cast: "timestamptz"},
+}
+
+type orderKey struct {
+	column string
+	cast   string
+	desc   bool
+}
+
+// orderKeys returns the columns of the sort followed by the id tie-breaker,
+// which takes the direction of the last key.
+func orderKeys(sort app.Sort) []orderKey {
+	keys := make([]orderKey, 0, len(sort)+1)
+	for _, k := range sort {
+		c := sortColumns[k.Field]
+		keys = append(keys, orderKey{column: c.column, cast: c.cast, desc: k.Desc})
+	}
+
+	return append(keys, orderKey{column: "id", cast: "uuid", desc: sort[len(sort)-1].Desc})
+}
+
+// queryBuilder assembles the WHERE clause of a query. Values are always
+// passed as arguments, only column names from fixed tables reach the SQL.
+type queryBuilder struct {
+	conds []string
+	args  []any
+}
+
+// arg adds a query argument and returns its placeholder.
+func (b *queryBuilder) arg(v any) string {
+	b.args = append(b.args, v)
+	return "$" + strconv.Itoa(len(b.args))
+}
+
+func (b *queryBuilder) where(cond string) {
+	b.conds = append(b.conds, cond)
+}
+
+func (b *queryBuilder) whereClause() string {
+	if len(b.conds) == 0 {
+		return ""
+	}
+
+	return " WHERE " + strings.Join(b.conds, " AND ")
+}
+
+func (b *queryBuilder) filter(f app.ProductFilter) {
+	if !f.AsOf.IsZero() {
+		// Served by the GiST index on the validity period of the history.
+		b.where("valid_period @> " + b.arg(f.AsOf.UTC()) + "::timestamptz")
+	}
+	if !f.IncludeDeleted {
+		b.where("deleted_at IS NULL")
+	}
+	if f.NameContains != "" {
+		// Served by the trigram index on name.
+		b.where("name ILIKE " + b.arg("%"+escapeLike(f.NameContains)+"%"))
+	}
+	if f.Currency != "" {
+		b.where("currency = " + b.arg(f.Currency))
+	}
+	if f.MinPrice != nil {
+		b.where("price >= " + b.arg(f.MinPrice.Decimal()) + "::numeric")
+	}
+	if f.MaxPrice != nil {
+		b.where("price <= " + b.arg(f.MaxPrice.Decimal()) + "::numeric")
+	}
+	if !f.CreatedAfter.IsZero() {
+		b.where("created_at >= " + b.arg(f.CreatedAfter))
+	}
+	if !f.CreatedBefore.IsZero() {
+		b.where("created_at < " + b.arg(f.CreatedBefore))
+	}
+	if !f.UpdatedAfter.IsZero() {
+		b.where("updated_at >= " + b.arg(f.UpdatedAfter))
+	}
+	if !f.UpdatedBefore.IsZero() {
+		b.where("updated_at < " + b.arg(f.UpdatedBefore))
+	}
+	if len(f.IDs) > 0 {
+		b.where("id = ANY(" + b.arg(uuidStrings(f.IDs)) + "::uuid[])")
+	}
+	if len(f.TagsAny) > 0 {
+		// Both array operators are served by the GIN index on tags.
+		b.where("tags && " + b.arg(f.TagsAny) + "::text[]")
+	}
+	if len(f.TagsAll) > 0 {
+		b.where("tags @> " + b.arg(f.TagsAll) + "::text[]")
+	}
+	if len(f.Attributes) > 0 {
+		// Served by the jsonb_path_ops GIN index on attributes.
+		b.where("attributes @> " + b.arg(f.Attributes) + "::jsonb")
+	}
+	if len(f.Statuses) > 0 {
+		b.where("status = ANY(" + b.arg(statusStrings(f.Statuses)) + "::text[])")
+	}
+	if f.CategoryID != uuid.Nil {
+		// The path of a category starts with the path of each of its ancestors
+		// and with its own.
+		b.where("EXISTS (SELECT 1 FROM public.product_categories pc" +
+			" JOIN public.categories c ON c.id = pc.category_id" +
+			" JOIN public.categories root ON starts_with(c.path, root.path)" +
+			" WHERE root.id = " + b.arg(f.CategoryID) + " AND pc.product_id = products.id)")
+	}
+}
+
+// keyset restricts the query to the rows strictly after the cursor in the
+// order of keys, or strictly before it when backwards is set. When all keys
+// have the same direction a row value comparison is used because Postgres
+// can answer it with a single index range scan.
+func (b *queryBuilder) keyset(keys []orderKey, cursor []string, backwards bool) {
+	op := func(k orderKey) string {
+		if k.desc != backwards {
+			return " < "
+		}
+		return " > "
+	}
+
+	placeholders := make([]string, len(keys))
+	for i, k := range keys {
+		placeholders[i] = b.arg(cursor[i]) + "::" + k.cast
+	}
+
+	if sameDirection(keys) {
+		columns := make([]string, len(keys))
+		for i, k := range keys {
+			columns[i] = k.column
+		}
+		b.where("(" + strings.Join(columns, ", ") + ")" + op(keys[0]) + "(" + strings.Join(placeholders, ", ") + ")")
+		return
+	}
+
+	// (k1 op v1) OR (k1 = v1 AND k2 op v2) OR ...
+	ors := make([]string, len(keys))
+	for i, k := range keys {
+		ands := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			ands = append(ands, keys[j].column+" = "+placeholders[j])
+		}
+		ands = append(ands, k.column+op(k)+placeholders[i])
+		ors[i] = "(" + strings.Join(ands, " AND ") + ")"
+	}
+	b.where("(" + strings.Join(ors, " OR ") + ")")
+}
+
+// orderBy returns the ORDER BY list of keys, reversed when backwards is set.
+func orderBy(keys []orderKey, backwards bool) string {
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		dir := " ASC"
+		if k.desc != backwards {
+			dir = " DESC"
+		}
+		parts[i] = k.column + dir
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func sameDirection(keys []orderKey) bool {
+	for _, k := range keys[1:] {
+		if k.desc != keys[0].desc {
+			return false
+		}
+	}
+
+	return true
+}
+
+// escapeLike escapes the LIKE wildcards of s, using the default escape
+// character.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func statusStrings(statuses []app.ProductStatus) []string {
+	s := make([]string, len(statuses))
+	for i, status := range statuses {
+		s[i] = string(status)
+	}
+
+	return s
+}
@@ -0,0 +1,111 @@
+package postgresql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+func TestQueryBuilder_filter(t *testing.T) {
+	id := uuid.MustParse("9f9f4340-6bf9-4948-808c-ebf2dd604e2c")
+	from := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+	minPrice := app.NewMoney(1000, "EUR")
+
+	var b queryBuilder
+	b.filter(app.ProductFilter{
+		NameContains: "50%_off",
+		Currency:     "EUR",
+		MinPrice:     &minPrice,
+		CreatedAfter: from,
+		IDs:          []uuid.UUID{id},
+	})
+
+	assert.Equal(t, " WHERE deleted_at IS NULL AND name ILIKE $1 AND currency = $2 AND price >= $3::numeric AND created_at >= $4 AND id = ANY($5::uuid[])", b.whereClause())
+	assert.Equal(t, []any{`%50\%\_off%`, "EUR", "10.00", from, []string{id.String()}}, b.args)
+}
+
+func TestQueryBuilder_keyset(t *testing.T) {
+	tests := []struct {
+		name       string
+		sort       app.Sort
+		backwards  bool
+		expWhere   string
+		expOrderBy string
+	}{
+		{
+			name:       "same direction",
+			sort:       app.DefaultSort,
+			expWhere:   " WHERE (created_at, id) < ($1::timestamptz, $2::uuid)",
+			expOrderBy: "created_at DESC, id DESC",
+		},
+		{
+			name:       "same direction backwards",
+			sort:       app.DefaultSort,
+			backwards:  true,
+			expWhere:   " WHERE (created_at, id) > ($1::timestamptz, $2::uuid)",
+			expOrderBy: "created_at ASC, id ASC",
+		},
+		{
+			name:       "mixed directions",
+			sort:       app.Sort{{Field: app.SortByPrice}, {Field: app.SortByName, Desc: true}},
+			expWhere:   " WHERE ((price > $1::numeric) OR (price = $1::numeric AND name < $2::text) OR (price = $1::numeric AND name = $2::text AND id < $3::uuid))",
+			expOrderBy: "price ASC, name DESC, id DESC",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := orderKeys(tt.sort)
+			cursor := make([]string, len(keys))
+
+			var b queryBuilder
+			b.keyset(keys, cursor, tt.backwards)
+
+			assert.Equal(t, tt.expWhere, b.whereClause())
+			assert.Len(t, b.args, len(keys))
+			assert.Equal(t, tt.expOrderBy, orderBy(keys, tt.backwards))
+		})
+	}
+}
+
+func TestQueryBuilder_filterTags(t *testing.T) {
+	var b queryBuilder
+	b.filter(app.ProductFilter{TagsAny: []string{"clearance", "seasonal"}, TagsAll: []string{"outdoor"}})
+
+	assert.Equal(t, " WHERE deleted_at IS NULL AND tags && $1::text[] AND tags @> $2::text[]", b.whereClause())
+	assert.Equal(t, []any{[]string{"clearance", "seasonal"}, []string{"outdoor"}}, b.args)
+}
+
+func TestQueryBuilder_filterAttributes(t *testing.T) {
+	var b queryBuilder
+	b.filter(app.ProductFilter{Attributes: map[string]any{"color": "red"}})
+
+	assert.Equal(t, " WHERE deleted_at IS NULL AND attributes @> $1::jsonb", b.whereClause())
+	assert.Equal(t, []any{map[string]any{"color": "red"}}, b.args)
+}
+
+func TestQueryBuilder_filterStatusesIncludingDeleted(t *testing.T) {
+	var b queryBuilder
+	b.filter(app.ProductFilter{Statuses: []app.ProductStatus{app.ProductDraft, app.ProductArchived}, IncludeDeleted: true})
+
+	assert.Equal(t, " WHERE status = ANY($1::text[])", b.whereClause())
+	assert.Equal(t, []any{[]string{"draft", "archived"}}, b.args)
+}
+
+func TestQueryBuilder_filterAsOf(t *testing.T) {
+	asOf := time.Date(2024, 10, 31, 23, 59, 59, 0, time.UTC)
+
+	f := app.ProductFilter{AsOf: asOf, Statuses: []app.ProductStatus{app.ProductPublished}}
+
+	var b queryBuilder
+	b.filter(f)
+
+	assert.Equal(t, " WHERE valid_period @> $1::timestamptz AND deleted_at IS NULL AND status = ANY($2::text[])", b.whereClause())
+	assert.Equal(t, []any{asOf, []string{"published"}}, b.args)
+	assert.Equal(t, "public.products_history AS products", productsTable(f))
+	assert.Equal(t, "public.products", productsTable(app.ProductFilter{}))
+}
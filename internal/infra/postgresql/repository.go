@@ -1,120 +1,470 @@
-package postgresql
-
-import (
-	"context"
-	"errors"
-	"fmt"
-
-	"github.com/google/uuid"
-
-	"github.com/simpler-tha/internal/app"
-)
-
-type Repository struct {
-	client *Client
-}
-
-func NewRepository(cl *Client) (Repository, error) {
-	if cl == nil {
-		return Repository{}, errors.New("client is nil")
-	}
-
-	return Repository{client: cl}, nil
-}
-
-func (r Repository) CreateProduct(ctx context.Context, p *app.Product) error {
-	const sqlQuery = `
-		INSERT INTO public.products (id, name, description, price, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
-	`
-
-	_, err := r.client.Conn.Exec(ctx, sqlQuery,
-		p.ID, p.Name, p.Description, p.Price, p.CreatedAt.UTC(), p.UpdatedAt.UTC(),
-	)
-	if err != nil {
-		return fmt.Errorf("failed to insert product in the database: %w", err)
-	}
-
-	return nil
-}
-
-func (r Repository) UpdateProduct(ctx context.Context, p *app.Product) error {
-	const sqlQuery = `
-		UPDATE public.products
-		SET name = $1, description = $2, price = $3, updated_at = $4
-		WHERE id = $5
-	`
-
-	_, err := r.client.Conn.Exec(ctx, sqlQuery,
-		p.Name, p.Description, p.Price, p.UpdatedAt.UTC(), p.ID,
-	)
-	if err != nil {
-		return fmt.Errorf("failed to update product with id %s in the database: %w", p.ID, err)
-	}
-
-	return nil
-}
-
-func (r Repository) DeleteProduct(ctx context.Context, productID uuid.UUID) error {
-	const sqlQuery = `
-		DELETE FROM public.products
-		WHERE id = $1
-	`
-
-	_, err := r.client.Conn.Exec(ctx, sqlQuery, productID)
-	if err != nil {
-		return fmt.Errorf("failed to delete product with id %s from the database: %w", productID, err)
-	}
-
-	return nil
-}
-
-func (r Repository) GetProduct(ctx context.Context, productID uuid.UUID) (*app.Product, error) {
-	const sqlQuery = `
-		SELECT id, name, description, price, created_at, updated_at
-		FROM public.products
-		WHERE id = $1
-	`
-
-	var p app.Product
-
-	err := r.client.Conn.QueryRow(ctx, sqlQuery, productID).Scan(
-		&p.ID, &p.Name, &p.Description, &p.Price, &p.CreatedAt, &p.UpdatedAt,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch product with id %s from the database: %w", productID, err)
-	}
-
-	return &p, nil
-}
-
-func (r Repository) GetProducts(ctx context.Context, limit, offset int) ([]*app.Product, error) {
-	const sqlQuery = `
-		SELECT id, name, description, price, created_at, updated_at
-		FROM public.products
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2
-	`
-
-	rows, err := r.client.Conn.Query(ctx, sqlQuery, limit, offset)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch products from the database: %w", err)
-	}
-	defer rows.Close()
-
-	var products []*app.Product
-
-	for rows.Next() {
-		var p app.Product
-		if err := rows.Scan(&p.ID, &p.Name, &p.Description, &p.Price, &p.CreatedAt, &p.UpdatedAt); err != nil {
-			return nil, fmt.Errorf("failed to scan product row: %w", err)
-		}
-		products = append(products, &p)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error occurred during iteration over product rows: %w", err)
-	}
-
-	return products, nil
-}
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+type Repository struct {
+	client         *Client
+	searchLanguage string
+}
+
+// NewRepository returns a repository using the text search configuration
+// searchLanguage, or the one of the search index when it is empty.
+func NewRepository(cl *Client, searchLanguage string) (Repository, error) {
+	if cl == nil {
+		return Repository{}, errors.New("client is nil")
+	}
+
+	if searchLanguage == "" {
+		searchLanguage = indexedSearchLanguage
+	}
+
+	return Repository{client: cl, searchLanguage: searchLanguage}, nil
+}
+
+// CreateProduct stores the product and starts its history.
+func (r Repository) CreateProduct(ctx context.Context, p *app.Product) error {
+	const sqlQuery = `
+		INSERT INTO public.products (id, sku, slug, status, name, description, price, currency, tags, attributes, version, created_at, updated_at)
+		VALUES ($1, nullif($2, ''), $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	err := r.WithinTx(ctx, func(ctx context.Context) error {
+		_, err := r.db(ctx).Exec(ctx, sqlQuery,
+			p.ID, p.SKU, p.Slug, p.Status, p.Name, p.Description, p.Price.Decimal(), p.Price.Currency, tagsArg(p.Tags), attributesArg(p.Attributes), p.Version, p.CreatedAt.UTC(), p.UpdatedAt.UTC(),
+		)
+		if err != nil {
+			return err
+		}
+
+		return r.recordProductHistory(ctx, p.ID)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to insert product in the database: %w", translateError(err))
+	}
+
+	return nil
+}
+
+func (r Repository) UpdateProduct(ctx context.Context, p *app.Product) error {
+	const sqlQuery = `
+		UPDATE public.products
+		SET sku = nullif($1, ''), status = $2, name = $3, description = $4, price = $5, currency = $6, tags = $7, attributes = $8, updated_at = $9, version = version + 1
+		WHERE id = $10 AND version = $11 AND deleted_at IS NULL
+		RETURNING version
+	`
+
+	err := r.WithinTx(ctx, func(ctx context.Context) error {
+		var version int64
+		err := r.db(ctx).QueryRow(ctx, sqlQuery,
+			p.SKU, p.Status, p.Name, p.Description, p.Price.Decimal(), p.Price.Currency, tagsArg(p.Tags), attributesArg(p.Attributes), p.UpdatedAt.UTC(), p.ID, p.Version,
+		).Scan(&version)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return r.versionMismatchError(ctx, "products", p.ID, app.ErrConflict)
+		}
+		if err != nil {
+			return err
+		}
+
+		p.Version = version
+		return r.recordProductHistory(ctx, p.ID)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update product with id %s in the database: %w", p.ID, translateError(err))
+	}
+
+	return nil
+}
+
+// DeleteProduct soft deletes the product. Its variants, category
+// assignments and stock stay until the product is purged.
+func (r Repository) DeleteProduct(ctx context.Context, productID uuid.UUID, expectedVersion int64) (*app.Product, error) {
+	const sqlQuery = `
+		UPDATE public.products
+		SET deleted_at = now(), updated_at = now(), version = version + 1
+		WHERE id = $1 AND ($2::bigint = 0 OR version = $2::bigint) AND deleted_at IS NULL
+		RETURNING ` + productColumns + `
+	`
+
+	var p *app.Product
+	err := r.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		p, err = scanProduct(r.db(ctx).QueryRow(ctx, sqlQuery, productID, expectedVersion))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return r.versionMismatchError(ctx, "products", productID, app.ErrPreconditionFailed)
+		}
+		if err != nil {
+			return err
+		}
+
+		return r.recordProductHistory(ctx, productID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete product with id %s from the database: %w", productID, translateError(err))
+	}
+
+	return p, nil
+}
+
+// RestoreProduct clears the deletion time of a soft deleted product.
+func (r Repository) RestoreProduct(ctx context.Context, productID uuid.UUID, expectedVersion int64) (*app.Product, error) {
+	const (
+		restoreQuery = `
+			UPDATE public.products
+			SET deleted_at = NULL, updated_at = now(), version = version + 1
+			WHERE id = $1 AND ($2::bigint = 0 OR version = $2::bigint) AND deleted_at IS NOT NULL
+			RETURNING ` + productColumns + `
+		`
+		deletedQuery = `
+			SELECT deleted_at IS NOT NULL
+			FROM public.products
+			WHERE id = $1
+		`
+	)
+
+	var p *app.Product
+	err := r.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		p, err = scanProduct(r.db(ctx).QueryRow(ctx, restoreQuery, productID, expectedVersion))
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Either the product does not exist, it is not deleted or its
+			// version changed.
+			var deleted bool
+			err = r.db(ctx).QueryRow(ctx, deletedQuery, productID).Scan(&deleted)
+			switch {
+			case err != nil:
+				return err
+			case !deleted:
+				return fmt.Errorf("%w: product %s is not deleted", app.ErrConflict, productID)
+			default:
+				return fmt.Errorf("%w: version of products row %s has changed", app.ErrPreconditionFailed, productID)
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		return r.recordProductHistory(ctx, productID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore product with id %s in the database: %w", productID, translateError(err))
+	}
+
+	return p, nil
+}
+
+// PurgeProducts hard deletes the oldest products soft deleted before
+// deletedBefore. Their variants, category assignments and stock go with them
+// through the ON DELETE CASCADE of their foreign keys. Products locked by a
+// concurrent write are left for the next purge. The history of the purged
+// products is kept, their last validity period ends with the purge. It
+// returns the IDs of the purged products.
+func (r Repository) PurgeProducts(ctx context.Context, deletedBefore time.Time, limit int) ([]uuid.UUID, error) {
+	const sqlQuery = `
+		WITH purged AS (
+			DELETE FROM public.products
+			WHERE id IN (
+				SELECT id
+				FROM public.products
+				WHERE deleted_at < $1
+				ORDER BY deleted_at
+				LIMIT $2
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id
+		), closed AS (
+			UPDATE public.products_history
+			SET valid_period = tstzrange(lower(valid_period), greatest(lower(valid_period), clock_timestamp()))
+			WHERE id IN (SELECT id FROM purged) AND upper_inf(valid_period)
+		)
+		SELECT id FROM purged
+	`
+
+	rows, err := r.db(ctx).Query(ctx, sqlQuery, deletedBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge products from the database: %w", translateError(err))
+	}
+
+	defer rows.Close()
+
+	var purged []uuid.UUID
+
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan purged product row: %w", err)
+		}
+		purged = append(purged, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error occurred during iteration over purged product rows: %w", translateError(err))
+	}
+
+	return purged, nil
+}
+
+// versionMismatchError explains why a versioned write to the product or
+// category table affected no row: either the row does not exist or its
+// version changed, in which case mismatch is returned. Soft deleted products
+// do not exist.
+func (r Repository) versionMismatchError(ctx context.Context, table string, id uuid.UUID, mismatch error) error {
+	cond := "id = $1"
+	if table == "products" {
+		cond += " AND deleted_at IS NULL"
+	}
+
+	return r.rowMismatchError(ctx, table, cond, []any{id}, id, mismatch)
+}
+
+// rowMismatchError returns ErrNotFound when no row of the table matches cond,
+// and mismatch otherwise.
+func (r Repository) rowMismatchError(ctx context.Context, table, cond string, args []any, id uuid.UUID, mismatch error) error {
+	sqlQuery := `
+		SELECT EXISTS (SELECT 1 FROM public.` + table + ` WHERE ` + cond + `)
+	`
+
+	var exists bool
+	err := r.db(ctx).QueryRow(ctx, sqlQuery, args...).Scan(&exists)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return app.ErrNotFound
+	}
+
+	return fmt.Errorf("%w: version of %s row %s has changed", mismatch, table, id)
+}
+
+// GetProduct reads the product, in the transaction of ctx when it carries one.
+func (r Repository) GetProduct(ctx context.Context, productID uuid.UUID) (*app.Product, error) {
+	const sqlQuery = `
+		SELECT ` + productColumns + `
+		FROM public.products
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	p, err := scanProduct(r.db(ctx).QueryRow(ctx, sqlQuery, productID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch product with id %s from the database: %w", productID, translateError(err))
+	}
+
+	return p, nil
+}
+
+// LockProduct reads the product with a row lock held until the transaction
+// of ctx ends. The lock does not block reads nor the foreign keys referencing
+// the product.
+func (r Repository) LockProduct(ctx context.Context, productID uuid.UUID) (*app.Product, error) {
+	const sqlQuery = `
+		SELECT ` + productColumns + `
+		FROM public.products
+		WHERE id = $1 AND deleted_at IS NULL
+		FOR NO KEY UPDATE
+	`
+
+	p, err := scanProduct(r.db(ctx).QueryRow(ctx, sqlQuery, productID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock product with id %s in the database: %w", productID, translateError(err))
+	}
+
+	return p, nil
+}
+
+func (r Repository) GetProductBySKU(ctx context.Context, sku string) (*app.Product, error) {
+	const sqlQuery = `
+		SELECT ` + productColumns + `
+		FROM public.products
+		WHERE sku = $1 AND deleted_at IS NULL
+	`
+
+	p, err := scanProduct(r.client.Pool.QueryRow(ctx, sqlQuery, sku))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch product with sku %q from the database: %w", sku, translateError(err))
+	}
+
+	return p, nil
+}
+
+func (r Repository) GetProductBySlug(ctx context.Context, slug string) (*app.Product, error) {
+	const sqlQuery = `
+		SELECT ` + productColumns + `
+		FROM public.products
+		WHERE slug = $1 AND deleted_at IS NULL
+	`
+
+	p, err := scanProduct(r.client.Pool.QueryRow(ctx, sqlQuery, slug))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch product with slug %q from the database: %w", slug, translateError(err))
+	}
+
+	return p, nil
+}
+
+// GetSlugs returns base and the slugs made of base, a dash and a number that
+// are taken. Soft deleted products keep their slug so that they can be
+// restored.
+func (r Repository) GetSlugs(ctx context.Context, base string) ([]string, error) {
+	const sqlQuery = `
+		SELECT slug
+		FROM public.products
+		WHERE slug = $1
+			OR (starts_with(slug, $1 || '-') AND substr(slug, length($1) + 2) ~ '^[0-9]+$')
+	`
+
+	rows, err := r.client.Pool.Query(ctx, sqlQuery, base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch slugs from the database: %w", translateError(err))
+	}
+
+	defer rows.Close()
+
+	var slugs []string
+
+	for rows.Next() {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
+			return nil, fmt.Errorf("failed to scan slug row: %w", err)
+		}
+		slugs = append(slugs, slug)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error occurred during iteration over slug rows: %w", translateError(err))
+	}
+
+	return slugs, nil
+}
+
+// GetProducts returns a slice of the filtered product list in the order of
+// q.Sort. Cursor pages are read with a keyset condition on the sort columns
+// instead of an offset, so their cost does not grow with the depth of the
+// page.
+func (r Repository) GetProducts(ctx context.Context, q app.ProductsQuery) ([]*app.Product, error) {
+	var b queryBuilder
+	b.filter(q.Filter)
+
+	keys := orderKeys(q.Sort)
+	backwards := q.Before != nil
+
+	switch {
+	case q.After != nil:
+		b.keyset(keys, q.After.Keys, false)
+	case q.Before != nil:
+		// Walk backwards from the cursor, the rows are put back in list
+		// order below.
+		b.keyset(keys, q.Before.Keys, true)
+	}
+
+	sqlQuery := `SELECT ` + productColumns + ` FROM ` + productsTable(q.Filter) + b.whereClause() +
+		` ORDER BY ` + orderBy(keys, backwards) +
+		` LIMIT ` + b.arg(q.Limit)
+	if q.After == nil && q.Before == nil {
+		sqlQuery += ` OFFSET ` + b.arg(q.Offset)
+	}
+
+	rows, err := r.client.Pool.Query(ctx, sqlQuery, b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch products from the database: %w", translateError(err))
+	}
+	defer rows.Close()
+
+	var products []*app.Product
+
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan product row: %w", err)
+		}
+		products = append(products, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error occurred during iteration over product rows: %w", translateError(err))
+	}
+
+	if q.Before != nil {
+		slices.Reverse(products)
+	}
+
+	return products, nil
+}
+
+func (r Repository) CountProducts(ctx context.Context, f app.ProductFilter) (int, error) {
+	var b queryBuilder
+	b.filter(f)
+
+	sqlQuery := `SELECT count(*) FROM ` + productsTable(f) + b.whereClause()
+
+	var total int
+	err := r.client.Pool.QueryRow(ctx, sqlQuery, b.args...).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count products in the database: %w", translateError(err))
+	}
+
+	return total, nil
+}
+
+// productColumns lists the product columns in the order expected by scanProduct.
+// The price is read as text so that it can be parsed without going through a float.
+const productColumns = `id, coalesce(sku, ''), slug, status, name, description, price::text, currency, tags, attributes, version, created_at, updated_at, deleted_at`
+
+// scanProduct scans a row starting with productColumns. Any column selected
+// after them is scanned into extra.
+func scanProduct(row pgx.Row, extra ...any) (*app.Product, error) {
+	var (
+		p          app.Product
+		price      string
+		currency   string
+		attributes []byte
+	)
+
+	dest := append([]any{&p.ID, &p.SKU, &p.Slug, &p.Status, &p.Name, &p.Description, &price, &currency, &p.Tags, &attributes, &p.Version, &p.CreatedAt, &p.UpdatedAt, &p.DeletedAt}, extra...)
+	err := row.Scan(dest...)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Price, err = app.ParseMoney(price, currency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse price of product %s: %w", p.ID, err)
+	}
+
+	p.Attributes, err = app.DecodeJSONObject(attributes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode attributes of product %s: %w", p.ID, err)
+	}
+
+	return &p, nil
+}
+
+// tagsArg returns the tags as a query argument, an empty array rather than
+// NULL when there are none.
+func tagsArg(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+
+	return tags
+}
+
+// attributesArg returns the attributes as a query argument, an empty object
+// rather than a JSON null when there are none.
+func attributesArg(attributes map[string]any) map[string]any {
+	if attributes == nil {
+		return map[string]any{}
+	}
+
+	return attributes
+}
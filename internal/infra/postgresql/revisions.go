@@ -0,0 +1,183 @@
+package postgresql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+// productSnapshot is the JSON document a revision stores the product as. The
+// ID and the version of the product are columns of the revision.
+type productSnapshot struct {
+	SKU         string          `json:"sku"`
+	Slug        string          `json:"slug"`
+	Status      string          `json:"status"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Price       string          `json:"price"`
+	Currency    string          `json:"currency"`
+	Tags        []string        `json:"tags"`
+	Attributes  json.RawMessage `json:"attributes"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	DeletedAt   *time.Time      `json:"deleted_at"`
+}
+
+func newProductSnapshot(p *app.Product) ([]byte, error) {
+	attributes, err := json.Marshal(attributesArg(p.Attributes))
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(productSnapshot{
+		SKU:         p.SKU,
+		Slug:        p.Slug,
+		Status:      string(p.Status),
+		Name:        p.Name,
+		Description: p.Description,
+		Price:       p.Price.Decimal(),
+		Currency:    p.Price.Currency,
+		Tags:        tagsArg(p.Tags),
+		Attributes:  attributes,
+		CreatedAt:   p.CreatedAt.UTC(),
+		UpdatedAt:   p.UpdatedAt.UTC(),
+		DeletedAt:   p.DeletedAt,
+	})
+}
+
+func (s productSnapshot) product(id uuid.UUID, version int64) (app.Product, error) {
+	price, err := app.ParseMoney(s.Price, s.Currency)
+	if err != nil {
+		return app.Product{}, fmt.Errorf("failed to parse price: %w", err)
+	}
+
+	attributes, err := app.DecodeJSONObject(s.Attributes)
+	if err != nil {
+		return app.Product{}, fmt.Errorf("failed to decode attributes: %w", err)
+	}
+
+	return app.Product{
+		ID:          id,
+		SKU:         s.SKU,
+		Slug:        s.Slug,
+		Status:      app.ProductStatus(s.Status),
+		Name:        s.Name,
+		Description: s.Description,
+		Price:       price,
+		Tags:        s.Tags,
+		Attributes:  attributes,
+		Version:     version,
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   s.UpdatedAt,
+		DeletedAt:   s.DeletedAt,
+	}, nil
+}
+
+// CreateProductRevision stores the revision, in the transaction of the
+// change it records when ctx carries one.
+func (r Repository) CreateProductRevision(ctx context.Context, rev *app.ProductRevision) error {
+	const sqlQuery = `
+		INSERT INTO public.product_revisions (product_id, version, action, snapshot, changed_fields, actor, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	snapshot, err := newProductSnapshot(&rev.Product)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot of product %s: %w", rev.ProductID, err)
+	}
+
+	changedFields := rev.ChangedFields
+	if changedFields == nil {
+		changedFields = []string{}
+	}
+
+	_, err = r.db(ctx).Exec(ctx, sqlQuery,
+		rev.ProductID, rev.Version, rev.Action, snapshot, changedFields, rev.Actor, rev.CreatedAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert revision %d of product %s in the database: %w", rev.Version, rev.ProductID, translateError(err))
+	}
+
+	return nil
+}
+
+func (r Repository) GetProductRevisions(ctx context.Context, productID uuid.UUID) ([]app.ProductRevision, error) {
+	const sqlQuery = `
+		SELECT ` + revisionColumns + `
+		FROM public.product_revisions
+		WHERE product_id = $1
+		ORDER BY version
+	`
+
+	rows, err := r.client.Pool.Query(ctx, sqlQuery, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch revisions of product %s from the database: %w", productID, translateError(err))
+	}
+	defer rows.Close()
+
+	var revisions []app.ProductRevision
+
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan product revision row: %w", err)
+		}
+		revisions = append(revisions, *rev)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error occurred during iteration over product revision rows: %w", translateError(err))
+	}
+
+	return revisions, nil
+}
+
+func (r Repository) GetProductRevision(ctx context.Context, productID uuid.UUID, version int64) (*app.ProductRevision, error) {
+	const sqlQuery = `
+		SELECT ` + revisionColumns + `
+		FROM public.product_revisions
+		WHERE product_id = $1 AND version = $2
+	`
+
+	rev, err := scanRevision(r.client.Pool.QueryRow(ctx, sqlQuery, productID, version))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch revision %d of product %s from the database: %w", version, productID, translateError(err))
+	}
+
+	return rev, nil
+}
+
+// revisionColumns lists the revision columns in the order expected by
+// scanRevision.
+const revisionColumns = `product_id, version, action, snapshot, changed_fields, actor, created_at`
+
+func scanRevision(row pgx.Row) (*app.ProductRevision, error) {
+	var (
+		rev      app.ProductRevision
+		snapshot []byte
+	)
+
+	err := row.Scan(&rev.ProductID, &rev.Version, &rev.Action, &snapshot, &rev.ChangedFields, &rev.Actor, &rev.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	var s productSnapshot
+	err = json.Unmarshal(snapshot, &s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot of revision %d of product %s: %w", rev.Version, rev.ProductID, err)
+	}
+
+	rev.Product, err = s.product(rev.ProductID, rev.Version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot of revision %d of product %s: %w", rev.Version, rev.ProductID, err)
+	}
+
+	return &rev, nil
+}
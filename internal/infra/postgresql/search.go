@@ -0,0 +1,118 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+// indexedSearchLanguage is the text search configuration of the generated
+// search_vector column. Searching in another language cannot use its index
+// and computes the vectors of every candidate row instead.
+const indexedSearchLanguage = "english"
+
+// searchVector returns the expression of the weighted document searched by
+// SearchProducts: matches in the name rank higher than in the description.
+func (r Repository) searchVector(lang string) string {
+	if r.searchLanguage == indexedSearchLanguage {
+		return "search_vector"
+	}
+
+	return "(setweight(to_tsvector(" + lang + ", name), 'A') || setweight(to_tsvector(" + lang + ", description), 'B'))"
+}
+
+// SearchProducts runs a full-text search over the names and descriptions of
+// the filtered products, most relevant first.
+func (r Repository) SearchProducts(ctx context.Context, q app.ProductSearchQuery) ([]app.ProductMatch, error) {
+	var b queryBuilder
+	lang := b.arg(r.searchLanguage) + "::regconfig"
+	text := b.arg(q.Text)
+	vector := r.searchVector(lang)
+
+	b.where(vector + " @@ query")
+	b.filter(q.Filter)
+
+	sqlQuery := `SELECT ` + productColumns + `,
+			ts_rank(` + vector + `, query)::float8 AS rank,
+			ts_headline(` + lang + `, ` + escapeHTML("name") + `, query, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true'),
+			ts_headline(` + lang + `, ` + escapeHTML("description") + `, query, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, FragmentDelimiter=" … "')
+		FROM public.products, websearch_to_tsquery(` + lang + `, ` + text + `) AS query` +
+		b.whereClause() +
+		` ORDER BY rank DESC, id ASC` +
+		` LIMIT ` + b.arg(q.Limit) +
+		` OFFSET ` + b.arg(q.Offset)
+
+	rows, err := r.client.Pool.Query(ctx, sqlQuery, b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search products in the database: %w", translateError(err))
+	}
+	defer rows.Close()
+
+	var matches []app.ProductMatch
+
+	for rows.Next() {
+		var m app.ProductMatch
+		m.Product, err = scanProduct(rows, &m.Rank, &m.NameHighlight, &m.DescriptionHighlight)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan product match row: %w", err)
+		}
+		matches = append(matches, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error occurred during iteration over product match rows: %w", translateError(err))
+	}
+
+	return matches, nil
+}
+
+// escapeHTML returns an expression escaping the HTML special characters of
+// column, so that the only markup in a headline is the one it adds.
+func escapeHTML(column string) string {
+	expr := column
+	for _, r := range [][2]string{{"&", "&amp;"}, {"<", "&lt;"}, {">", "&gt;"}, {`"`, "&quot;"}, {"'", "&#39;"}} {
+		expr = "replace(" + expr + ", '" + strings.ReplaceAll(r[0], "'", "''") + "', '" + r[1] + "')"
+	}
+
+	return expr
+}
+
+// SuggestProducts looks up the names closest to prefix with the trigram
+// index on name. The <% operator keeps the names containing a word similar
+// to the prefix, above the pg_trgm.word_similarity_threshold setting, so
+// that typos still find a match. Names starting with the prefix are listed
+// first. Only published products are suggested.
+func (r Repository) SuggestProducts(ctx context.Context, prefix string, limit int) ([]app.ProductSuggestion, error) {
+	const sqlQuery = `
+		SELECT id, name, word_similarity($1, name)::float8 AS score
+		FROM public.products
+		WHERE ($1 <% name OR name ILIKE $2) AND status = 'published' AND deleted_at IS NULL
+		ORDER BY name ILIKE $2 DESC, score DESC, name ASC, id ASC
+		LIMIT $3
+	`
+
+	rows, err := r.client.Pool.Query(ctx, sqlQuery, prefix, escapeLike(prefix)+"%", limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest products from the database: %w", translateError(err))
+	}
+	defer rows.Close()
+
+	var suggestions []app.ProductSuggestion
+
+	for rows.Next() {
+		var s app.ProductSuggestion
+		err := rows.Scan(&s.ID, &s.Name, &s.Score)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan product suggestion row: %w", err)
+		}
+		suggestions = append(suggestions, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error occurred during iteration over product suggestion rows: %w", translateError(err))
+	}
+
+	return suggestions, nil
+}
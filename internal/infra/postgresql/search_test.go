@@ -0,0 +1,22 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_searchVector(t *testing.T) {
+	assert.Equal(t, "search_vector", Repository{searchLanguage: "english"}.searchVector("$1::regconfig"))
+	assert.Equal(t,
+		"(setweight(to_tsvector($1::regconfig, name), 'A') || setweight(to_tsvector($1::regconfig, description), 'B'))",
+		Repository{searchLanguage: "simple"}.searchVector("$1::regconfig"),
+	)
+}
+
+func TestEscapeHTML(t *testing.T) {
+	assert.Equal(t,
+		`replace(replace(replace(replace(replace(name, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;')`,
+		escapeHTML("name"),
+	)
+}
@@ -0,0 +1,44 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+// GetTags counts the tags of the published products, the draft, archived and
+// deleted products are not shown to the clients.
+func (r Repository) GetTags(ctx context.Context, prefix string, limit int) ([]app.TagCount, error) {
+	const sqlQuery = `
+		SELECT tag, count(*)
+		FROM public.products, unnest(tags) AS tag
+		WHERE starts_with(tag, $1) AND status = 'published' AND deleted_at IS NULL
+		GROUP BY tag
+		ORDER BY count(*) DESC, tag
+		LIMIT $2
+	`
+
+	rows, err := r.client.Pool.Query(ctx, sqlQuery, prefix, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tags from the database: %w", translateError(err))
+	}
+	defer rows.Close()
+
+	var tags []app.TagCount
+
+	for rows.Next() {
+		var t app.TagCount
+		err := rows.Scan(&t.Tag, &t.Count)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tag row: %w", err)
+		}
+		tags = append(tags, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error occurred during iteration over tag rows: %w", translateError(err))
+	}
+
+	return tags, nil
+}
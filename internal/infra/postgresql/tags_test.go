@@ -0,0 +1,36 @@
+package postgresql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+func TestRepository_GetTags(t *testing.T) {
+	r := testRepository(t)
+	ctx := context.Background()
+
+	tag := "tag-" + uuid.NewString()
+
+	for _, status := range []app.ProductStatus{app.ProductDraft, app.ProductPublished, app.ProductPublished, app.ProductArchived} {
+		p := app.NewProduct("Tagged Product", "Description", app.NewMoney(1000, "EUR"), []string{tag}, nil, "")
+		p.Slug += "-" + p.ID.String()
+		p.Status = status
+		t.Cleanup(func() { deleteTestProduct(t, r, p) })
+
+		err := r.CreateProduct(ctx, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tags, err := r.GetTags(ctx, tag, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []app.TagCount{{Tag: tag, Count: 2}}, tags)
+}
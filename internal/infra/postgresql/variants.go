@@ -0,0 +1,181 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+func (r Repository) CreateVariant(ctx context.Context, v *app.Variant) error {
+	const sqlQuery = `
+		INSERT INTO public.product_variants (id, product_id, sku, price, currency, options, stock, version, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	price, currency := variantPriceArgs(v.Price)
+
+	_, err := r.db(ctx).Exec(ctx, sqlQuery,
+		v.ID, v.ProductID, v.SKU, price, currency, optionsArg(v.Options), v.Stock, v.Version, v.CreatedAt.UTC(), v.UpdatedAt.UTC(),
+	)
+
+	// The product was deleted since the service read it.
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgCodeForeignKeyViolation {
+		err = fmt.Errorf("%w: product %s does not exist", app.ErrNotFound, v.ProductID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to insert variant in the database: %w", translateError(err))
+	}
+
+	return nil
+}
+
+func (r Repository) UpdateVariant(ctx context.Context, v *app.Variant) error {
+	const sqlQuery = `
+		UPDATE public.product_variants
+		SET sku = $1, price = $2, currency = $3, options = $4, stock = $5, updated_at = $6, version = version + 1
+		WHERE id = $7 AND product_id = $8 AND version = $9
+		RETURNING version
+	`
+
+	price, currency := variantPriceArgs(v.Price)
+
+	err := r.db(ctx).QueryRow(ctx, sqlQuery,
+		v.SKU, price, currency, optionsArg(v.Options), v.Stock, v.UpdatedAt.UTC(), v.ID, v.ProductID, v.Version,
+	).Scan(&v.Version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = r.variantVersionMismatchError(ctx, v.ProductID, v.ID, app.ErrConflict)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update variant with id %s in the database: %w", v.ID, translateError(err))
+	}
+
+	return nil
+}
+
+// DeleteVariant deletes the variant and returns it as it was before the
+// deletion.
+func (r Repository) DeleteVariant(ctx context.Context, productID, variantID uuid.UUID, expectedVersion int64) (*app.Variant, error) {
+	const sqlQuery = `
+		DELETE FROM public.product_variants
+		WHERE id = $1 AND product_id = $2 AND ($3::bigint = 0 OR version = $3::bigint)
+		RETURNING ` + variantColumns + `
+	`
+
+	v, err := scanVariant(r.db(ctx).QueryRow(ctx, sqlQuery, variantID, productID, expectedVersion))
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = r.variantVersionMismatchError(ctx, productID, variantID, app.ErrPreconditionFailed)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete variant with id %s from the database: %w", variantID, translateError(err))
+	}
+
+	return v, nil
+}
+
+func (r Repository) GetVariant(ctx context.Context, productID, variantID uuid.UUID) (*app.Variant, error) {
+	const sqlQuery = `
+		SELECT ` + variantColumns + `
+		FROM public.product_variants
+		WHERE id = $1 AND product_id = $2
+	`
+
+	v, err := scanVariant(r.client.Pool.QueryRow(ctx, sqlQuery, variantID, productID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch variant with id %s from the database: %w", variantID, translateError(err))
+	}
+
+	return v, nil
+}
+
+func (r Repository) GetVariants(ctx context.Context, productID uuid.UUID) ([]*app.Variant, error) {
+	const sqlQuery = `
+		SELECT ` + variantColumns + `
+		FROM public.product_variants
+		WHERE product_id = $1
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.client.Pool.Query(ctx, sqlQuery, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch variants from the database: %w", translateError(err))
+	}
+	defer rows.Close()
+
+	var variants []*app.Variant
+
+	for rows.Next() {
+		v, err := scanVariant(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan variant row: %w", err)
+		}
+		variants = append(variants, v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error occurred during iteration over variant rows: %w", translateError(err))
+	}
+
+	return variants, nil
+}
+
+// variantVersionMismatchError is the versionMismatchError of a variant, which
+// only exists under its own product.
+func (r Repository) variantVersionMismatchError(ctx context.Context, productID, variantID uuid.UUID, mismatch error) error {
+	return r.rowMismatchError(ctx, "product_variants", "id = $1 AND product_id = $2", []any{variantID, productID}, variantID, mismatch)
+}
+
+// variantColumns lists the variant columns in the order expected by
+// scanVariant.
+const variantColumns = `id, product_id, sku, price::text, currency, options, stock, version, created_at, updated_at`
+
+func scanVariant(row pgx.Row) (*app.Variant, error) {
+	var (
+		v        app.Variant
+		price    *string
+		currency *string
+	)
+
+	err := row.Scan(&v.ID, &v.ProductID, &v.SKU, &price, &currency, &v.Options, &v.Stock, &v.Version, &v.CreatedAt, &v.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if price != nil && currency != nil {
+		m, err := app.ParseMoney(*price, *currency)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse price of variant %s: %w", v.ID, err)
+		}
+		v.Price = &m
+	}
+
+	return &v, nil
+}
+
+// variantPriceArgs returns the price override as query arguments, NULLs when
+// the variant has the price of its product.
+func variantPriceArgs(price *app.Money) (*string, *string) {
+	if price == nil {
+		return nil, nil
+	}
+
+	amount := price.Decimal()
+
+	return &amount, &price.Currency
+}
+
+// optionsArg returns the options as a query argument, an empty object rather
+// than a JSON null when there are none.
+func optionsArg(options map[string]string) map[string]string {
+	if options == nil {
+		return map[string]string{}
+	}
+
+	return options
+}
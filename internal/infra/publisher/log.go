@@ -0,0 +1,35 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+// Log publishes events by writing them to a logger, one JSON message per
+// line. It is used when no downstream system is configured.
+type Log struct {
+	logger *log.Logger
+}
+
+func NewLog(logger *log.Logger) (Log, error) {
+	if logger == nil {
+		return Log{}, errors.New("logger cannot be nil")
+	}
+
+	return Log{logger: logger}, nil
+}
+
+func (p Log) Publish(_ context.Context, e app.Event) error {
+	b, err := encodeMessage(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode event %s: %w", e.ID, err)
+	}
+
+	p.logger.Printf("%s", b)
+
+	return nil
+}
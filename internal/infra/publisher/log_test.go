@@ -0,0 +1,35 @@
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+func TestLog_Publish(t *testing.T) {
+	var buf bytes.Buffer
+
+	p, err := NewLog(log.New(&buf, "", 0))
+	assert.NoError(t, err)
+
+	occurredAt, _ := time.Parse(time.RFC3339, "2024-10-02T14:28:34Z")
+
+	err = p.Publish(context.Background(), app.Event{
+		ID:               uuid.MustParse("1b7f3b6e-3c1d-4d53-9a3b-0e6f8f0b2a11"),
+		Type:             app.ProductCreated,
+		AggregateID:      uuid.MustParse("9f9f4340-6bf9-4948-808c-ebf2dd604e2c"),
+		AggregateVersion: 1,
+		OccurredAt:       occurredAt,
+		Payload:          map[string]any{"name": "Desk Lamp"},
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "{\"id\":\"1b7f3b6e-3c1d-4d53-9a3b-0e6f8f0b2a11\",\"type\":\"product.created\",\"aggregate_id\":\"9f9f4340-6bf9-4948-808c-ebf2dd604e2c\",\"aggregate_version\":1,\"occurred_at\":\"2024-10-02T14:28:34Z\",\"payload\":{\"name\":\"Desk Lamp\"}}\n", buf.String())
+}
@@ -0,0 +1,33 @@
+// Package publisher delivers the product events of the outbox to the
+// downstream systems.
+package publisher
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+// message is the JSON representation of an event sent by every publisher.
+type message struct {
+	ID               uuid.UUID      `json:"id"`
+	Type             string         `json:"type"`
+	AggregateID      uuid.UUID      `json:"aggregate_id"`
+	AggregateVersion int64          `json:"aggregate_version"`
+	OccurredAt       time.Time      `json:"occurred_at"`
+	Payload          map[string]any `json:"payload"`
+}
+
+func encodeMessage(e app.Event) ([]byte, error) {
+	return json.Marshal(message{
+		ID:               e.ID,
+		Type:             string(e.Type),
+		AggregateID:      e.AggregateID,
+		AggregateVersion: e.AggregateVersion,
+		OccurredAt:       e.OccurredAt.UTC(),
+		Payload:          e.Payload,
+	})
+}
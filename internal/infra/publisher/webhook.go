@@ -0,0 +1,62 @@
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+// webhookTimeout bounds the delivery of an event to the webhook.
+const webhookTimeout = 10 * time.Second
+
+// Webhook publishes events by posting them to a URL. Any response other than
+// a 2xx is a failure and the event is retried. The ID of the event is sent as
+// the Idempotency-Key header so that the receiver can drop redeliveries.
+type Webhook struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhook(url string) (Webhook, error) {
+	if url == "" {
+		return Webhook{}, errors.New("webhook URL cannot be empty")
+	}
+
+	return Webhook{
+		url:    url,
+		client: &http.Client{Timeout: webhookTimeout},
+	}, nil
+}
+
+func (p Webhook) Publish(ctx context.Context, e app.Event) error {
+	b, err := encodeMessage(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode event %s: %w", e.ID, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("failed to create request for event %s: %w", e.ID, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", e.ID.String())
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post event %s: %w", e.ID, err)
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("failed to post event %s: webhook responded with status %d", e.ID, res.StatusCode)
+	}
+
+	return nil
+}
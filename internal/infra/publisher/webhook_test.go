@@ -0,0 +1,62 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/simpler-tha/internal/app"
+)
+
+func TestWebhook_Publish(t *testing.T) {
+	eventID := uuid.MustParse("1b7f3b6e-3c1d-4d53-9a3b-0e6f8f0b2a11")
+
+	tests := []struct {
+		name      string
+		resStatus int
+		expErr    error
+	}{
+		{
+			name:      "event delivered",
+			resStatus: http.StatusAccepted,
+		},
+		{
+			name:      "receiver failed",
+			resStatus: http.StatusServiceUnavailable,
+			expErr:    errors.New("failed to post event 1b7f3b6e-3c1d-4d53-9a3b-0e6f8f0b2a11: webhook responded with status 503"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, http.MethodPost, req.Method)
+				assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+				assert.Equal(t, eventID.String(), req.Header.Get("Idempotency-Key"))
+
+				b, err := io.ReadAll(req.Body)
+				assert.NoError(t, err)
+				assert.Contains(t, string(b), "\"type\":\"product.deleted\"")
+
+				w.WriteHeader(tt.resStatus)
+			}))
+			defer server.Close()
+
+			p, err := NewWebhook(server.URL)
+			assert.NoError(t, err)
+
+			err = p.Publish(context.Background(), app.Event{ID: eventID, Type: app.ProductDeleted})
+			if tt.expErr != nil {
+				assert.EqualError(t, err, tt.expErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
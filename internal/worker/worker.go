@@ -0,0 +1,32 @@
+// Package worker runs the periodic background jobs of the service.
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Job is a single run of a periodic job.
+type Job func(ctx context.Context) error
+
+// Run runs job right away and then every interval until ctx is done. A failed
+// run is logged and the job is tried again at the next tick. Run returns once
+// the run in progress, if any, has returned.
+func Run(ctx context.Context, name string, interval time.Duration, job Job) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		err := job(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("%s failed: %v", name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	runs := 0
+	job := func(ctx context.Context) error {
+		runs++
+		if runs == 3 {
+			cancel()
+		}
+		return errors.New("job error")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		Run(ctx, "test job", time.Millisecond, job)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after its context was canceled")
+	}
+
+	assert.Equal(t, 3, runs)
+}
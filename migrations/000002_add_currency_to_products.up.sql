@@ -0,0 +1,2 @@
+ALTER TABLE public.products
+    ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE public.products
+    DROP COLUMN IF EXISTS version;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE public.products
+    ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
\ No newline at end of file
@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS products_created_at_id_idx
+    ON public.products (created_at DESC, id DESC);
\ No newline at end of file
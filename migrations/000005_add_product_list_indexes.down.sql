@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS public.products_currency_idx;
+DROP INDEX IF EXISTS public.products_updated_at_id_idx;
+DROP INDEX IF EXISTS public.products_price_id_idx;
+DROP INDEX IF EXISTS public.products_name_id_idx;
+DROP INDEX IF EXISTS public.products_name_trgm_idx;
\ No newline at end of file
@@ -0,0 +1,16 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS products_name_trgm_idx
+    ON public.products USING GIN (name gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS products_name_id_idx
+    ON public.products (name, id);
+
+CREATE INDEX IF NOT EXISTS products_price_id_idx
+    ON public.products (price, id);
+
+CREATE INDEX IF NOT EXISTS products_updated_at_id_idx
+    ON public.products (updated_at, id);
+
+CREATE INDEX IF NOT EXISTS products_currency_idx
+    ON public.products (currency);
\ No newline at end of file
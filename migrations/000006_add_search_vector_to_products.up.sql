@@ -0,0 +1,8 @@
+ALTER TABLE public.products
+    ADD COLUMN IF NOT EXISTS search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', name), 'A') ||
+        setweight(to_tsvector('english', description), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS products_search_vector_idx
+    ON public.products USING GIN (search_vector);
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS public.product_categories;
+DROP TABLE IF EXISTS public.categories;
\ No newline at end of file
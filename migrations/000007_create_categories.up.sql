@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS public.categories (
+    id UUID PRIMARY KEY,
+    parent_id UUID REFERENCES public.categories (id) ON DELETE RESTRICT,
+    name TEXT NOT NULL,
+    path TEXT NOT NULL,
+    version BIGINT NOT NULL DEFAULT 1,
+    created_at TIMESTAMP(3) WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP(3) WITH TIME ZONE NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS categories_parent_id_name_idx
+    ON public.categories (COALESCE(parent_id, '00000000-0000-0000-0000-000000000000'), lower(name));
+
+CREATE TABLE IF NOT EXISTS public.product_categories (
+    product_id UUID NOT NULL REFERENCES public.products (id) ON DELETE CASCADE,
+    category_id UUID NOT NULL REFERENCES public.categories (id) ON DELETE CASCADE,
+    PRIMARY KEY (product_id, category_id)
+);
+
+CREATE INDEX IF NOT EXISTS product_categories_category_id_idx
+    ON public.product_categories (category_id);
\ No newline at end of file
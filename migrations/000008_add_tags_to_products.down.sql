@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS public.products_tags_idx;
+
+ALTER TABLE public.products
+    DROP COLUMN IF EXISTS tags;
\ No newline at end of file
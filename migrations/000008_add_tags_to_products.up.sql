@@ -0,0 +1,5 @@
+ALTER TABLE public.products
+    ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS products_tags_idx
+    ON public.products USING GIN (tags);
\ No newline at end of file
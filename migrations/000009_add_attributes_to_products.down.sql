@@ -0,0 +1,7 @@
+ALTER TABLE public.categories
+    DROP COLUMN IF EXISTS attribute_schema;
+
+DROP INDEX IF EXISTS public.products_attributes_idx;
+
+ALTER TABLE public.products
+    DROP COLUMN IF EXISTS attributes;
\ No newline at end of file
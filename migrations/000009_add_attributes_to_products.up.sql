@@ -0,0 +1,8 @@
+ALTER TABLE public.products
+    ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS products_attributes_idx
+    ON public.products USING GIN (attributes jsonb_path_ops);
+
+ALTER TABLE public.categories
+    ADD COLUMN IF NOT EXISTS attribute_schema JSONB;
\ No newline at end of file
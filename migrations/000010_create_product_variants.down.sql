@@ -0,0 +1 @@
+DROP TABLE IF EXISTS public.product_variants;
\ No newline at end of file
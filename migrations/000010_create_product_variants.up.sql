@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS public.product_variants (
+    id UUID PRIMARY KEY,
+    product_id UUID NOT NULL REFERENCES public.products (id) ON DELETE CASCADE,
+    sku TEXT NOT NULL,
+    price NUMERIC(12, 2),
+    currency CHAR(3),
+    options JSONB NOT NULL DEFAULT '{}',
+    stock INTEGER NOT NULL DEFAULT 0 CHECK (stock >= 0),
+    version BIGINT NOT NULL DEFAULT 1,
+    created_at TIMESTAMP(3) WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP(3) WITH TIME ZONE NOT NULL,
+    CHECK ((price IS NULL) = (currency IS NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS product_variants_sku_idx
+    ON public.product_variants (sku);
+
+CREATE UNIQUE INDEX IF NOT EXISTS product_variants_product_id_options_idx
+    ON public.product_variants (product_id, options);
\ No newline at end of file
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS public.products_slug_idx;
+
+DROP INDEX IF EXISTS public.products_sku_idx;
+
+ALTER TABLE public.products
+    DROP COLUMN IF EXISTS slug,
+    DROP COLUMN IF EXISTS sku;
\ No newline at end of file
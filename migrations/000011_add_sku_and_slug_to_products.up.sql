@@ -0,0 +1,28 @@
+ALTER TABLE public.products
+    ADD COLUMN IF NOT EXISTS sku TEXT,
+    ADD COLUMN IF NOT EXISTS slug TEXT;
+
+-- Existing products get a slug made of the ASCII letters and digits of their
+-- name. Products sharing it, after the oldest one, are told apart by the
+-- start of their ID.
+WITH slugs AS (
+    SELECT id, coalesce(nullif(left(trim(BOTH '-' FROM regexp_replace(lower(name), '[^a-z0-9]+', '-', 'g')), 100), ''), 'product') AS base
+    FROM public.products
+    WHERE slug IS NULL
+), numbered AS (
+    SELECT id, base, row_number() OVER (PARTITION BY base ORDER BY id) AS n
+    FROM slugs
+)
+UPDATE public.products AS p
+SET slug = CASE WHEN numbered.n = 1 THEN numbered.base ELSE numbered.base || '-' || left(p.id::text, 8) END
+FROM numbered
+WHERE p.id = numbered.id;
+
+ALTER TABLE public.products
+    ALTER COLUMN slug SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS products_sku_idx
+    ON public.products (sku);
+
+CREATE UNIQUE INDEX IF NOT EXISTS products_slug_idx
+    ON public.products (slug);
\ No newline at end of file
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS public.stock_reservation_items;
+
+DROP TABLE IF EXISTS public.stock_reservations;
+
+DROP TABLE IF EXISTS public.stock_adjustments;
+
+DROP TABLE IF EXISTS public.product_inventory;
\ No newline at end of file
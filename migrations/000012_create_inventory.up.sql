@@ -0,0 +1,39 @@
+CREATE TABLE IF NOT EXISTS public.product_inventory (
+    product_id UUID PRIMARY KEY REFERENCES public.products (id) ON DELETE CASCADE,
+    on_hand INTEGER NOT NULL DEFAULT 0,
+    reserved INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP(3) WITH TIME ZONE NOT NULL,
+    CHECK (reserved >= 0 AND reserved <= on_hand)
+);
+
+CREATE TABLE IF NOT EXISTS public.stock_adjustments (
+    id UUID PRIMARY KEY,
+    product_id UUID NOT NULL REFERENCES public.products (id) ON DELETE CASCADE,
+    delta INTEGER NOT NULL CHECK (delta <> 0),
+    reason TEXT NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    reservation_id UUID,
+    created_at TIMESTAMP(3) WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS stock_adjustments_product_id_created_at_idx
+    ON public.stock_adjustments (product_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS public.stock_reservations (
+    id UUID PRIMARY KEY,
+    status TEXT NOT NULL CHECK (status IN ('pending', 'committed', 'released', 'expired')),
+    expires_at TIMESTAMP(3) WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP(3) WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP(3) WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS stock_reservations_pending_expires_at_idx
+    ON public.stock_reservations (expires_at)
+    WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS public.stock_reservation_items (
+    reservation_id UUID NOT NULL REFERENCES public.stock_reservations (id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES public.products (id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (reservation_id, product_id)
+);
\ No newline at end of file
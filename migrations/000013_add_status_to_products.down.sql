@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS public.products_status_idx;
+
+ALTER TABLE public.products
+    DROP COLUMN IF EXISTS status;
\ No newline at end of file
@@ -0,0 +1,11 @@
+ALTER TABLE public.products
+    ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'published'
+        CHECK (status IN ('draft', 'published', 'archived'));
+
+-- Existing products were listed, they stay published. New products start as
+-- drafts.
+ALTER TABLE public.products
+    ALTER COLUMN status SET DEFAULT 'draft';
+
+CREATE INDEX IF NOT EXISTS products_status_idx
+    ON public.products (status);
\ No newline at end of file
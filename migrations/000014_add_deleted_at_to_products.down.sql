@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS public.products_deleted_at_idx;
+
+ALTER TABLE public.products
+    DROP COLUMN IF EXISTS deleted_at;
\ No newline at end of file
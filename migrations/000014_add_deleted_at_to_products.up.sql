@@ -0,0 +1,7 @@
+ALTER TABLE public.products
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+-- Serves the purge of the products deleted for longer than the retention.
+CREATE INDEX IF NOT EXISTS products_deleted_at_idx
+    ON public.products (deleted_at)
+    WHERE deleted_at IS NOT NULL;
\ No newline at end of file
@@ -0,0 +1,12 @@
+-- Revisions are only ever inserted. They go with their product when it is
+-- purged.
+CREATE TABLE IF NOT EXISTS public.product_revisions (
+    product_id UUID NOT NULL REFERENCES public.products (id) ON DELETE CASCADE,
+    version BIGINT NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('created', 'updated', 'deleted', 'restored', 'reverted')),
+    snapshot JSONB NOT NULL,
+    changed_fields TEXT[] NOT NULL DEFAULT '{}',
+    actor TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (product_id, version)
+);
\ No newline at end of file
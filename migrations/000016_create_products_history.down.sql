@@ -0,0 +1 @@
+DROP TABLE IF EXISTS public.products_history;
\ No newline at end of file
@@ -0,0 +1,35 @@
+-- Each row is a version of a product, valid_period being the time during
+-- which it was the current one. The period of the current version of a
+-- product has no upper bound, the one of a purged product is closed. History
+-- rows outlive the products they describe.
+CREATE TABLE IF NOT EXISTS public.products_history (
+    id UUID NOT NULL,
+    sku TEXT,
+    slug TEXT NOT NULL,
+    status TEXT NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL,
+    price NUMERIC(12, 2) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    tags TEXT[] NOT NULL,
+    attributes JSONB NOT NULL,
+    version BIGINT NOT NULL,
+    created_at TIMESTAMP(3) WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP(3) WITH TIME ZONE NOT NULL,
+    deleted_at TIMESTAMPTZ,
+    valid_period TSTZRANGE NOT NULL,
+    PRIMARY KEY (id, version)
+);
+
+-- Serves the point-in-time reads of the product list.
+CREATE INDEX IF NOT EXISTS products_history_valid_period_idx
+    ON public.products_history USING GIST (valid_period);
+
+-- The history of existing products starts with their current version. Their
+-- earlier versions are unknown, the current one stands for them back to the
+-- creation of the product: a point-in-time read before the last update finds
+-- the product as it is now rather than not at all.
+INSERT INTO public.products_history (id, sku, slug, status, name, description, price, currency, tags, attributes, version, created_at, updated_at, deleted_at, valid_period)
+SELECT id, sku, slug, status, name, description, price, currency, tags, attributes, version, created_at, updated_at, deleted_at, tstzrange(created_at, NULL)
+FROM public.products
+ON CONFLICT DO NOTHING;
\ No newline at end of file
@@ -0,0 +1,25 @@
+-- The audit log is append-only: the service only ever inserts entries, and
+-- they outlive the resources they describe.
+CREATE TABLE IF NOT EXISTS public.audit_log (
+    id UUID PRIMARY KEY,
+    actor TEXT NOT NULL,
+    action TEXT NOT NULL,
+    resource_type TEXT NOT NULL,
+    resource_id UUID NOT NULL,
+    request_id TEXT NOT NULL DEFAULT '',
+    source_ip TEXT NOT NULL DEFAULT '',
+    before JSONB,
+    after JSONB,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+-- Serve the listing of the log, the most recent entries first, overall and
+-- by actor or resource.
+CREATE INDEX IF NOT EXISTS audit_log_created_at_id_idx
+    ON public.audit_log (created_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS audit_log_actor_created_at_idx
+    ON public.audit_log (actor, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS audit_log_resource_created_at_idx
+    ON public.audit_log (resource_type, resource_id, created_at DESC);
\ No newline at end of file
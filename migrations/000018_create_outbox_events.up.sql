@@ -0,0 +1,24 @@
+-- Product events are written here in the transaction of the change they
+-- announce and published by the relay. Published events are kept, the
+-- pending ones are served by the partial index.
+CREATE TABLE IF NOT EXISTS public.outbox_events (
+    id UUID PRIMARY KEY,
+    event_type TEXT NOT NULL,
+    aggregate_id UUID NOT NULL,
+    aggregate_version BIGINT NOT NULL,
+    payload JSONB NOT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    last_error TEXT,
+    published_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS outbox_events_pending_idx
+    ON public.outbox_events (occurred_at, id)
+    WHERE published_at IS NULL;
+
+-- Serves the lookup of the earlier pending events of a product.
+CREATE INDEX IF NOT EXISTS outbox_events_pending_aggregate_idx
+    ON public.outbox_events (aggregate_id, occurred_at, id)
+    WHERE published_at IS NULL;
\ No newline at end of file
@@ -0,0 +1 @@
+COMMENT ON COLUMN public.product_variants.stock IS NULL;
\ No newline at end of file
@@ -0,0 +1,2 @@
+COMMENT ON COLUMN public.product_variants.stock IS
+    'Informational only, never reserved nor adjusted. The stock that is sold is the one of the product in product_inventory.';
\ No newline at end of file